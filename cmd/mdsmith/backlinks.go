@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -142,22 +143,38 @@ func validateBacklinksArgs(opts backlinksOptions, posArgs []string) (targetPath,
 		fmt.Fprintf(os.Stderr, "mdsmith: list backlinks takes one target argument, got %d\n", len(posArgs))
 		return "", "", 2
 	}
+	targetPath, targetAnchor, err := parseBacklinksTarget(posArgs[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return "", "", 2
+	}
+	if err := validateIncludePatterns(opts.includePatterns); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return "", "", 2
+	}
+	if opts.limit < 0 {
+		fmt.Fprintf(os.Stderr, "mdsmith: --limit must be >= 0 (got %d)\n", opts.limit)
+		return "", "", 2
+	}
+	return targetPath, targetAnchor, -1
+}
+
+// parseBacklinksTarget splits a backlinks target into its
+// workspace-relative path and optional anchor.
+func parseBacklinksTarget(raw string) (targetPath, targetAnchor string, err error) {
 	// Route the target through the same parser the link walker uses
 	// (linkgraph.ParseTarget) so the CLI accepts exactly the shapes
 	// that can ever appear as a link destination. This rejects
 	// malformed percent escapes, query-only inputs, schemed URLs, and
 	// other forms with no corresponding link target — all of which
 	// would otherwise pass and produce a silent empty result.
-	parsed, ok := linkgraph.ParseTarget(posArgs[0])
+	parsed, ok := linkgraph.ParseTarget(raw)
 	if !ok {
-		fmt.Fprintf(os.Stderr, "mdsmith: invalid target %q\n", posArgs[0])
-		return "", "", 2
+		return "", "", fmt.Errorf("invalid target %q", raw)
 	}
 	if parsed.LocalAnchor {
-		fmt.Fprint(os.Stderr, "mdsmith: list backlinks target must include a file path\n")
-		return "", "", 2
+		return "", "", errors.New("list backlinks target must include a file path")
 	}
-	targetPath, targetAnchor = parsed.Path, parsed.Anchor
 	// `<target>` is workspace-relative by contract. An absolute path
 	// or a parent-traversal entry normalises to something outside the
 	// workspace and would silently match nothing — which a caller
@@ -165,19 +182,10 @@ func validateBacklinksArgs(opts backlinksOptions, posArgs []string) (targetPath,
 	// so the failure is loud. ParseTarget already percent-decoded the
 	// path, so `%2Fetc...` and `%2e%2e/...` are checked here in their
 	// decoded form.
-	if !isWorkspaceRelativeTarget(targetPath) {
-		fmt.Fprintf(os.Stderr, "mdsmith: target %q must be workspace-relative\n", targetPath)
-		return "", "", 2
+	if !isWorkspaceRelativeTarget(parsed.Path) {
+		return "", "", fmt.Errorf("target %q must be workspace-relative", parsed.Path)
 	}
-	if err := validateIncludePatterns(opts.includePatterns); err != nil {
-		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-		return "", "", 2
-	}
-	if opts.limit < 0 {
-		fmt.Fprintf(os.Stderr, "mdsmith: --limit must be >= 0 (got %d)\n", opts.limit)
-		return "", "", 2
-	}
-	return targetPath, targetAnchor, -1
+	return parsed.Path, parsed.Anchor, nil
 }
 
 // validateIncludePatterns rejects any --include glob that doublestar
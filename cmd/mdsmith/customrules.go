@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/customrule"
+	"github.com/jeduden/mdsmith/internal/plugin"
+	"github.com/jeduden/mdsmith/internal/rule"
+	ruledocs "github.com/jeduden/mdsmith/internal/rules"
+)
+
+// pluginPool runs the plugin processes of this invocation, so every
+// file checked shares one process per plugin. run closes it on exit.
+var pluginPool = newPluginPool()
+
+// newPluginPool returns a pool that runs command plugins only when
+// the user opted in through plugin.TrustEnv. The LSP shares this
+// pool, so an editor opening an untrusted checkout never executes
+// the commands its config names.
+func newPluginPool() *plugin.Pool {
+	p := plugin.NewPool()
+	if plugin.TrustedByEnv() {
+		p.TrustCommands()
+	}
+	return p
+}
+
+// configuredRules returns the built-in rules followed by the custom
+// rules cfg declares under `custom-rules:` and the rules of its
+// `plugins:`.
+func configuredRules(cfg *config.Config) []rule.Rule {
+	all := customrule.With(rule.All(), cfg.CustomRules)
+	return append(all, plugin.Rules(pluginPool, cfg.Plugins)...)
+}
+
+// customRuleInfos describes the custom and plugin rules of the
+// discovered config for `help rule`. A config that fails to load is reported on stderr
+// and contributes no rules: help must not depend on a valid config.
+func customRuleInfos() []ruledocs.RuleInfo {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	path, err := config.Discover(cwd)
+	if err != nil || path == "" {
+		return nil
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil
+	}
+	infos := make([]ruledocs.RuleInfo, 0, len(loaded.CustomRules))
+	for _, d := range loaded.CustomRules {
+		infos = append(infos, customrule.Info(d))
+	}
+	for _, s := range loaded.Plugins {
+		infos = append(infos, plugin.Infos(s)...)
+	}
+	return infos
+}
+
+// lookupCustomRuleInfo finds a custom or plugin rule by ID (case-insensitive)
+// or name, mirroring ruledocs.LookupRuleInfo.
+func lookupCustomRuleInfo(query string) (ruledocs.RuleInfo, bool) {
+	for _, info := range customRuleInfos() {
+		if strings.EqualFold(info.ID, query) || info.Name == query {
+			return info, true
+		}
+	}
+	return ruledocs.RuleInfo{}, false
+}
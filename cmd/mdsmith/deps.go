@@ -10,7 +10,6 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/jeduden/mdsmith/internal/index"
-	"github.com/jeduden/mdsmith/internal/lint"
 )
 
 // depRecord is one dependency edge, either an outgoing reference from
@@ -28,6 +27,9 @@ type depsOptions struct {
 	format       string
 	maxInputSize string
 	incoming     bool
+	graph        bool
+	depth        int
+	cluster      bool
 	walk         walkCLI
 }
 
@@ -47,6 +49,10 @@ func edgeKindString(k index.EdgeKind) string {
 		return "catalog"
 	case index.EdgeBuild:
 		return "build"
+	case index.EdgeTable:
+		return "table"
+	case index.EdgeSnippet:
+		return "snippet"
 	default:
 		return "unknown"
 	}
@@ -149,9 +155,14 @@ func parseDepsFlags(args []string) (depsOptions, []string, error) {
 		noGitignore, followSymlinks bool
 	)
 	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
-	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json")
+	fs.StringVarP(&opts.format, "format", "f", "",
+		"Output format: text, json (default text); with --graph: dot, mermaid, json (default dot)")
 	fs.BoolVar(&opts.incoming, "incoming", false,
 		"List files that depend on <file> instead of what <file> depends on")
+	fs.BoolVar(&opts.graph, "graph", false,
+		"Emit the workspace graph, or the part rooted at [path], instead of edge records")
+	fs.IntVar(&opts.depth, "depth", 0, "With --graph and a file root, stop N edges from it (0 = no limit)")
+	fs.BoolVar(&opts.cluster, "cluster", false, "With --graph, group nodes by directory")
 	fs.BoolVar(&noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
 	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
 		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
@@ -160,10 +171,14 @@ func parseDepsFlags(args []string) (depsOptions, []string, error) {
 		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
 
 	fs.Usage = func() {
-		fmt.Fprint(os.Stderr, "Usage: mdsmith deps [flags] <file>\n\n"+
+		fmt.Fprint(os.Stderr, "Usage: mdsmith deps [flags] <file>\n"+
+			"       mdsmith deps --graph [flags] [path]\n\n"+
 			"List the dependency edges of <file>: the includes, catalogs,\n"+
 			"build sources, and links it points at. With --incoming, list\n"+
 			"every workspace file that points at <file> instead.\n\n"+
+			"With --graph, emit the workspace graph as Graphviz DOT, a Mermaid\n"+
+			"flowchart, or JSON Graph Format. A file [path] roots the graph at\n"+
+			"that file; a directory keeps the files under it.\n\n"+
 			"Exit codes: 0 found, 1 none, 2 error\n\nFlags:\n")
 		fs.PrintDefaults()
 	}
@@ -171,6 +186,12 @@ func parseDepsFlags(args []string) (depsOptions, []string, error) {
 	if err := fs.Parse(args); err != nil {
 		return opts, nil, err
 	}
+	if opts.format == "" {
+		opts.format = "text"
+		if opts.graph {
+			opts.format = "dot"
+		}
+	}
 	opts.walk = walkCLI{
 		noGitignore:    noGitignore,
 		followSymlinks: followSymlinksOverride(fs, followSymlinks),
@@ -187,6 +208,13 @@ func runDeps(args []string) int {
 			return code
 		}
 	}
+	if opts.graph {
+		return runDepsGraph(opts, posArgs)
+	}
+	if opts.depth != 0 || opts.cluster {
+		fmt.Fprint(os.Stderr, "mdsmith: --depth and --cluster require --graph\n")
+		return 2
+	}
 	if len(posArgs) != 1 {
 		fmt.Fprint(os.Stderr, "mdsmith: deps requires exactly one <file> argument\n")
 		return 2
@@ -197,34 +225,59 @@ func runDeps(args []string) int {
 		return 2
 	}
 
-	cfg, cfgPath, _, files, code := discoverFiles(opts.configPath, false, opts.walk)
-	if code >= 0 {
-		if code == 0 {
-			return emitDeps(os.Stdout, nil, opts.format)
-		}
-		return code
-	}
-	maxBytes, err := resolveMaxInputBytes(cfg, opts.maxInputSize)
+	ws, err := loadWorkspace(opts.configPath, opts.walk, opts.maxInputSize)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
 		return 2
 	}
-
-	rootDir := rootDirFromConfig(cfgPath)
-	relToAbs := make(map[string]string, len(files))
-	rels := make([]string, 0, len(files))
-	for _, src := range files {
-		rel := index.NormalizePath(workspaceRelativePath(src, rootDir))
-		relToAbs[rel] = src
-		rels = append(rels, rel)
+	if ws.idx == nil {
+		return emitDeps(os.Stdout, nil, opts.format)
 	}
-	idx := index.New(rootDir)
-	idx.BuildSerial(rels, func(rel string) ([]byte, error) {
-		// rel always comes from rels, and rels is built in
-		// lockstep with relToAbs, so the lookup never misses.
-		return lint.ReadFileLimited(relToAbs[rel], maxBytes)
-	})
 
-	recs := collectDeps(idx, target, opts.incoming)
+	recs := collectDeps(ws.idx, target, opts.incoming)
 	return emitDeps(os.Stdout, recs, opts.format)
 }
+
+// runDepsGraph implements `deps --graph`: render the workspace graph,
+// or the part of it rooted at posArgs[0]. Exit code: 0 when the graph
+// has nodes, 1 when it is empty, 2 on error.
+func runDepsGraph(opts depsOptions, posArgs []string) int {
+	if len(posArgs) > 1 {
+		fmt.Fprintf(os.Stderr, "mdsmith: deps --graph takes at most one [path] argument, got %d\n", len(posArgs))
+		return 2
+	}
+	if opts.depth < 0 {
+		fmt.Fprintf(os.Stderr, "mdsmith: --depth must be >= 0 (got %d)\n", opts.depth)
+		return 2
+	}
+	switch opts.format {
+	case "dot", "mermaid", "json":
+	default:
+		fmt.Fprintf(os.Stderr, "mdsmith: unknown --format %q (want dot, mermaid, or json)\n", opts.format)
+		return 2
+	}
+	root := ""
+	if len(posArgs) == 1 {
+		root = normalizeWorkspacePath(posArgs[0])
+		if !isWorkspaceRelativeTarget(root) {
+			fmt.Fprintf(os.Stderr, "mdsmith: path %q must be workspace-relative\n", root)
+			return 2
+		}
+	}
+	cfg, _, err := loadConfig(opts.configPath)
+	if err != nil {
+		return printErr(err)
+	}
+	ws, err := loadWorkspace(opts.configPath, opts.walk, opts.maxInputSize)
+	if err != nil {
+		return printErr(err)
+	}
+	g := buildDepGraph(ws.idx, cfg, root, opts.incoming, opts.depth)
+	if err := writeGraph(os.Stdout, g, opts.format, opts.cluster); err != nil {
+		return printErr(err)
+	}
+	if len(g.nodes) == 0 {
+		return 1
+	}
+	return 0
+}
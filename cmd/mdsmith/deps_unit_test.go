@@ -41,6 +41,8 @@ func TestEdgeKindString(t *testing.T) {
 		{index.EdgeInclude, "include"},
 		{index.EdgeCatalog, "catalog"},
 		{index.EdgeBuild, "build"},
+		{index.EdgeTable, "table"},
+		{index.EdgeSnippet, "snippet"},
 		{index.EdgeKind(999), "unknown"},
 	}
 	for _, tc := range cases {
@@ -199,6 +201,19 @@ func TestParseDepsFlags(t *testing.T) {
 		_, _, err := parseDepsFlags([]string{"--help"})
 		assert.Error(t, err)
 	})
+	t.Run("format defaults per mode", func(t *testing.T) {
+		opts, _, err := parseDepsFlags([]string{"a.md"})
+		require.NoError(t, err)
+		assert.Equal(t, "text", opts.format)
+		opts, _, err = parseDepsFlags([]string{"--graph"})
+		require.NoError(t, err)
+		assert.Equal(t, "dot", opts.format)
+	})
+	t.Run("help shows one format default", func(t *testing.T) {
+		stderr := captureStderr(func() { _, _, _ = parseDepsFlags([]string{"--help"}) })
+		assert.Contains(t, stderr, "(default text); with --graph: dot, mermaid, json (default dot)\n")
+		assert.NotContains(t, stderr, `(default "text")`)
+	})
 }
 
 func TestRunDeps_Success(t *testing.T) {
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/index"
+)
+
+// depGraph is the workspace dependency graph `deps --graph` renders.
+// Nodes are sorted by path; edges by source, target, then kind.
+type depGraph struct {
+	nodes []depNode
+	edges []depEdge
+}
+
+// depNode is one Markdown file with its effective kinds.
+type depNode struct {
+	path  string
+	kinds []string
+}
+
+// depEdge is one file-to-file reference. Edges of one kind between
+// the same two files collapse into one.
+type depEdge struct {
+	source, target, kind string
+}
+
+// kindPalette colors nodes by their first kind. Kinds take colors in
+// name order and wrap around when there are more kinds than colors.
+var kindPalette = []string{
+	"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3", "#fdb462",
+	"#b3de69", "#fccde5", "#d9d9d9", "#bc80bd", "#ccebc5", "#ffed6f",
+}
+
+// buildDepGraph selects the nodes of idx and the edges between them.
+// An empty root selects every file. A root naming an indexed file
+// selects the files reachable from it within depth hops (0 means no
+// limit), following edges backwards when incoming is set. Any other
+// root is a directory and selects the files under it.
+func buildDepGraph(idx *index.Index, cfg *config.Config, root string, incoming bool, depth int) depGraph {
+	files := idx.Files()
+	sort.Strings(files)
+	out := map[string][]string{}
+	in := map[string][]string{}
+	seen := map[depEdge]bool{}
+	var edges []depEdge
+	for _, f := range files {
+		for _, e := range idx.OutgoingEdges(f) {
+			for _, t := range idx.EdgeTargets(e, files) {
+				de := depEdge{source: f, target: t, kind: edgeKindString(e.Kind)}
+				if t == f || seen[de] {
+					continue
+				}
+				seen[de] = true
+				edges = append(edges, de)
+				out[f] = append(out[f], t)
+				in[t] = append(in[t], f)
+			}
+		}
+	}
+
+	selected := selectDepNodes(idx, files, root, incoming, depth, out, in)
+	var g depGraph
+	for _, f := range files {
+		if !selected[f] {
+			continue
+		}
+		fe, _ := idx.File(f)
+		g.nodes = append(g.nodes, depNode{path: f, kinds: config.EffectiveKinds(cfg, f, fe.Kinds, nil)})
+	}
+	for _, e := range edges {
+		if selected[e.source] && selected[e.target] {
+			g.edges = append(g.edges, e)
+		}
+	}
+	sort.Slice(g.edges, func(a, b int) bool {
+		x, y := g.edges[a], g.edges[b]
+		if x.source != y.source {
+			return x.source < y.source
+		}
+		if x.target != y.target {
+			return x.target < y.target
+		}
+		return x.kind < y.kind
+	})
+	return g
+}
+
+// selectDepNodes returns the files buildDepGraph keeps for root.
+func selectDepNodes(
+	idx *index.Index, files []string, root string, incoming bool, depth int,
+	out, in map[string][]string,
+) map[string]bool {
+	selected := map[string]bool{}
+	if _, ok := idx.File(root); root != "" && ok {
+		next := out
+		if incoming {
+			next = in
+		}
+		root = index.NormalizePath(root)
+		selected[root] = true
+		frontier := []string{root}
+		for hop := 0; len(frontier) > 0 && (depth == 0 || hop < depth); hop++ {
+			var following []string
+			for _, f := range frontier {
+				for _, t := range next[f] {
+					if !selected[t] {
+						selected[t] = true
+						following = append(following, t)
+					}
+				}
+			}
+			frontier = following
+		}
+		return selected
+	}
+	prefix := strings.TrimSuffix(root, "/") + "/"
+	for _, f := range files {
+		if root == "" || root == "." || strings.HasPrefix(f, prefix) {
+			selected[f] = true
+		}
+	}
+	return selected
+}
+
+// kindColors maps each kind used by g's nodes to a palette color.
+func (g depGraph) kindColors() map[string]string {
+	var kinds []string
+	seen := map[string]bool{}
+	for _, n := range g.nodes {
+		for _, k := range n.kinds {
+			if !seen[k] {
+				seen[k] = true
+				kinds = append(kinds, k)
+			}
+		}
+	}
+	sort.Strings(kinds)
+	colors := make(map[string]string, len(kinds))
+	for i, k := range kinds {
+		colors[k] = kindPalette[i%len(kindPalette)]
+	}
+	return colors
+}
+
+// nodeColor returns the fill color of n, or "" when it has no kind.
+func nodeColor(n depNode, colors map[string]string) string {
+	if len(n.kinds) == 0 {
+		return ""
+	}
+	return colors[n.kinds[0]]
+}
+
+// clusters groups node indexes by directory, in directory order.
+func (g depGraph) clusters() ([]string, map[string][]int) {
+	byDir := map[string][]int{}
+	var dirs []string
+	for i, n := range g.nodes {
+		d := path.Dir(n.path)
+		if _, ok := byDir[d]; !ok {
+			dirs = append(dirs, d)
+		}
+		byDir[d] = append(byDir[d], i)
+	}
+	sort.Strings(dirs)
+	return dirs, byDir
+}
+
+// nodeLabel is the name shown for n: its base name inside a directory
+// cluster, its path otherwise.
+func nodeLabel(n depNode, cluster bool) string {
+	if cluster {
+		return path.Base(n.path)
+	}
+	return n.path
+}
+
+// writeGraph renders g in format: dot, mermaid, or json.
+func writeGraph(w io.Writer, g depGraph, format string, cluster bool) error {
+	switch format {
+	case "dot":
+		return writeDOT(w, g, cluster)
+	case "mermaid":
+		return writeMermaid(w, g, cluster)
+	case "json":
+		return writeJGF(w, g, cluster)
+	default:
+		return fmt.Errorf("unknown --format %q (want dot, mermaid, or json)", format)
+	}
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func dotQuote(s string) string { return `"` + dotEscaper.Replace(s) + `"` }
+
+// writeDOT renders g as a Graphviz digraph. Kinds get a legend
+// cluster so the colors can be read off the picture.
+func writeDOT(w io.Writer, g depGraph, cluster bool) error {
+	var b strings.Builder
+	colors := g.kindColors()
+	b.WriteString("digraph deps {\n  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];\n")
+	writeNode := func(indent string, n depNode) {
+		fmt.Fprintf(&b, "%s%s [label=%s", indent, dotQuote(n.path), dotQuote(nodeLabel(n, cluster)))
+		if c := nodeColor(n, colors); c != "" {
+			fmt.Fprintf(&b, ", fillcolor=%s, tooltip=%s",
+				dotQuote(c), dotQuote("kinds: "+strings.Join(n.kinds, ", ")))
+		}
+		b.WriteString("];\n")
+	}
+	if cluster {
+		dirs, byDir := g.clusters()
+		for i, d := range dirs {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n    label=%s;\n", i, dotQuote(d))
+			for _, n := range byDir[d] {
+				writeNode("    ", g.nodes[n])
+			}
+			b.WriteString("  }\n")
+		}
+	} else {
+		for _, n := range g.nodes {
+			writeNode("  ", n)
+		}
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.source), dotQuote(e.target), dotQuote(e.kind))
+	}
+	if len(colors) > 0 {
+		b.WriteString("  subgraph cluster_kinds {\n    label=\"kinds\";\n")
+		for _, k := range sortedKeys(colors) {
+			fmt.Fprintf(&b, "    %s [label=%s, fillcolor=%s];\n",
+				dotQuote("kind:"+k), dotQuote(k), dotQuote(colors[k]))
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;")
+
+// writeMermaid renders g as a Mermaid flowchart. Nodes get short ids
+// (n0, n1, …) since Mermaid ids cannot hold every path character;
+// kinds become classes named k0, k1, … in kind order.
+func writeMermaid(w io.Writer, g depGraph, cluster bool) error {
+	var b strings.Builder
+	colors := g.kindColors()
+	ids := make(map[string]string, len(g.nodes))
+	for i, n := range g.nodes {
+		ids[n.path] = fmt.Sprintf("n%d", i)
+	}
+	b.WriteString("flowchart LR\n")
+	writeNode := func(indent string, n depNode) {
+		fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, ids[n.path], mermaidEscaper.Replace(nodeLabel(n, cluster)))
+	}
+	if cluster {
+		dirs, byDir := g.clusters()
+		for i, d := range dirs {
+			fmt.Fprintf(&b, "  subgraph c%d [\"%s\"]\n", i, mermaidEscaper.Replace(d))
+			for _, n := range byDir[d] {
+				writeNode("    ", g.nodes[n])
+			}
+			b.WriteString("  end\n")
+		}
+	} else {
+		for _, n := range g.nodes {
+			writeNode("  ", n)
+		}
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[e.source], e.kind, ids[e.target])
+	}
+	for i, k := range sortedKeys(colors) {
+		var members []string
+		for _, n := range g.nodes {
+			if len(n.kinds) > 0 && n.kinds[0] == k {
+				members = append(members, ids[n.path])
+			}
+		}
+		fmt.Fprintf(&b, "  classDef k%d fill:%s\n", i, colors[k])
+		if len(members) > 0 {
+			fmt.Fprintf(&b, "  class %s k%d\n", strings.Join(members, ","), i)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// jgfDocument is the JSON Graph Format (v2) envelope.
+type jgfDocument struct {
+	Graph jgfGraph `json:"graph"`
+}
+
+type jgfGraph struct {
+	Directed bool               `json:"directed"`
+	Type     string             `json:"type"`
+	Nodes    map[string]jgfNode `json:"nodes"`
+	Edges    []jgfEdge          `json:"edges"`
+}
+
+type jgfNode struct {
+	Label    string      `json:"label"`
+	Metadata jgfNodeMeta `json:"metadata"`
+}
+
+type jgfNodeMeta struct {
+	Kinds   []string `json:"kinds,omitempty"`
+	Color   string   `json:"color,omitempty"`
+	Cluster string   `json:"cluster,omitempty"`
+}
+
+type jgfEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+	Label    string `json:"label"`
+}
+
+// writeJGF renders g in JSON Graph Format. Node ids are file paths.
+func writeJGF(w io.Writer, g depGraph, cluster bool) error {
+	colors := g.kindColors()
+	doc := jgfDocument{Graph: jgfGraph{
+		Directed: true,
+		Type:     "mdsmith-deps",
+		Nodes:    make(map[string]jgfNode, len(g.nodes)),
+		Edges:    []jgfEdge{},
+	}}
+	for _, n := range g.nodes {
+		meta := jgfNodeMeta{Kinds: n.kinds, Color: nodeColor(n, colors)}
+		if cluster {
+			meta.Cluster = path.Dir(n.path)
+		}
+		doc.Graph.Nodes[n.path] = jgfNode{Label: nodeLabel(n, cluster), Metadata: meta}
+	}
+	for _, e := range g.edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, jgfEdge{
+			Source: e.source, Target: e.target, Relation: e.kind, Label: e.kind,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
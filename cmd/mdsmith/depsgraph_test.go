@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/index"
+)
+
+// graphIndex builds a small workspace: README links to the guide and
+// the API page, the guide catalogs the topics, and the API page
+// includes a snippet and links back to the guide twice.
+func graphIndex() *index.Index {
+	idx := index.New("/ws")
+	idx.Update("README.md", []byte("# Home\n\n[Guide](docs/guide.md) [API](docs/api.md)\n"))
+	idx.Update("docs/guide.md", []byte("---\nkinds: [guide]\n---\n# Guide\n\n"+
+		"<?catalog\nglob: \"topics/*.md\"\n?>\n<?/catalog?>\n"))
+	idx.Update("docs/api.md", []byte("---\nkinds: [reference]\n---\n# API\n\n"+
+		"[a](guide.md) [b](guide.md#x) [self](#api)\n\n<?include\nfile: snippets/auth.md\n?>\n<?/include?>\n"))
+	idx.Update("docs/snippets/auth.md", []byte("Use a \"token\".\n"))
+	idx.Update("docs/topics/a.md", []byte("# A\n"))
+	return idx
+}
+
+func graphPaths(g depGraph) []string {
+	var out []string
+	for _, n := range g.nodes {
+		out = append(out, n.path)
+	}
+	return out
+}
+
+func TestBuildDepGraph_WholeWorkspace(t *testing.T) {
+	g := buildDepGraph(graphIndex(), &config.Config{}, "", false, 0)
+	assert.Equal(t, []string{
+		"README.md", "docs/api.md", "docs/guide.md", "docs/snippets/auth.md", "docs/topics/a.md",
+	}, graphPaths(g))
+	assert.Equal(t, []depEdge{
+		{"README.md", "docs/api.md", "file-link"},
+		{"README.md", "docs/guide.md", "file-link"},
+		{"docs/api.md", "docs/guide.md", "file-link"},
+		{"docs/api.md", "docs/snippets/auth.md", "include"},
+		{"docs/guide.md", "docs/topics/a.md", "catalog"},
+	}, g.edges)
+	assert.Equal(t, []string{"reference"}, g.nodes[1].kinds)
+}
+
+func TestBuildDepGraph_Roots(t *testing.T) {
+	idx := graphIndex()
+	cfg := &config.Config{}
+	assert.Equal(t, []string{"README.md", "docs/api.md", "docs/guide.md"},
+		graphPaths(buildDepGraph(idx, cfg, "README.md", false, 1)))
+	assert.Len(t, buildDepGraph(idx, cfg, "README.md", false, 0).nodes, 5)
+	assert.Equal(t, []string{"README.md", "docs/api.md", "docs/guide.md"},
+		graphPaths(buildDepGraph(idx, cfg, "docs/guide.md", true, 0)))
+	assert.Equal(t, []string{"docs/snippets/auth.md"},
+		graphPaths(buildDepGraph(idx, cfg, "docs/snippets/", false, 0)))
+	assert.Empty(t, buildDepGraph(idx, cfg, "nowhere", false, 0).nodes)
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := buildDepGraph(graphIndex(), &config.Config{}, "docs", false, 0)
+	var buf bytes.Buffer
+	require.NoError(t, writeGraph(&buf, g, "dot", true))
+	out := buf.String()
+	assert.Contains(t, out, "digraph deps {\n")
+	assert.Contains(t, out, "  subgraph cluster_0 {\n    label=\"docs\";\n")
+	assert.Contains(t, out, `    "docs/api.md" [label="api.md", fillcolor="#ffffb3", tooltip="kinds: reference"];`)
+	assert.Contains(t, out, `  "docs/api.md" -> "docs/snippets/auth.md" [label="include"];`)
+	assert.Contains(t, out, `    "kind:guide" [label="guide", fillcolor="#8dd3c7"];`)
+}
+
+func TestWriteMermaid(t *testing.T) {
+	g := buildDepGraph(graphIndex(), &config.Config{}, "", false, 0)
+	var buf bytes.Buffer
+	require.NoError(t, writeGraph(&buf, g, "mermaid", false))
+	assert.Equal(t, "flowchart LR\n"+
+		"  n0[\"README.md\"]\n"+
+		"  n1[\"docs/api.md\"]\n"+
+		"  n2[\"docs/guide.md\"]\n"+
+		"  n3[\"docs/snippets/auth.md\"]\n"+
+		"  n4[\"docs/topics/a.md\"]\n"+
+		"  n0 -->|file-link| n1\n"+
+		"  n0 -->|file-link| n2\n"+
+		"  n1 -->|file-link| n2\n"+
+		"  n1 -->|include| n3\n"+
+		"  n2 -->|catalog| n4\n"+
+		"  classDef k0 fill:#8dd3c7\n"+
+		"  class n2 k0\n"+
+		"  classDef k1 fill:#ffffb3\n"+
+		"  class n1 k1\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeGraph(&buf, g, "mermaid", true))
+	assert.Contains(t, buf.String(), "  subgraph c2 [\"docs/snippets\"]\n    n3[\"auth.md\"]\n  end\n")
+}
+
+func TestWriteJGF(t *testing.T) {
+	g := buildDepGraph(graphIndex(), &config.Config{}, "docs/api.md", false, 1)
+	var buf bytes.Buffer
+	require.NoError(t, writeGraph(&buf, g, "json", true))
+	var doc jgfDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.True(t, doc.Graph.Directed)
+	assert.Equal(t, jgfNode{Label: "api.md", Metadata: jgfNodeMeta{
+		Kinds: []string{"reference"}, Color: "#ffffb3", Cluster: "docs",
+	}}, doc.Graph.Nodes["docs/api.md"])
+	assert.Len(t, doc.Graph.Nodes, 3)
+	assert.Equal(t, jgfEdge{
+		Source: "docs/api.md", Target: "docs/snippets/auth.md", Relation: "include", Label: "include",
+	}, doc.Graph.Edges[1])
+}
+
+func TestWriteGraph_UnknownFormat(t *testing.T) {
+	assert.Error(t, writeGraph(&bytes.Buffer{}, depGraph{}, "svg", false))
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/doctest"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+type testOptions struct {
+	configPath string
+	format     string
+	timeout    time.Duration
+	update     bool
+}
+
+func parseTestFlags(args []string) (testOptions, []string, error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var opts testOptions
+	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
+	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, junit")
+	fs.DurationVar(&opts.timeout, "timeout", doctest.DefaultTimeout,
+		"Per-block timeout; a block's {timeout=...} overrides it")
+	fs.BoolVar(&opts.update, "update", false, "Rewrite mismatched output blocks with the actual output")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith test [flags] [files...]\n\n"+
+			"Run the fenced code blocks marked {test} (sh, bash, shell, go) and\n"+
+			"compare their stdout with the output block that follows each one.\n"+
+			"The blocks of one file run in order in a shared temporary directory.\n"+
+			"With no file arguments, discovers files from the config.\n\n"+
+			"Exit codes: 0 pass, 1 failures, 2 error\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	return opts, fs.Args(), nil
+}
+
+// runTest implements `mdsmith test`.
+func runTest(args []string) int {
+	opts, paths, err := parseTestFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: test"); code >= 0 {
+			return code
+		}
+	}
+	if opts.format != "text" && opts.format != "junit" {
+		fmt.Fprintf(os.Stderr, "mdsmith: unknown --format %q (want text or junit)\n", opts.format)
+		return 2
+	}
+	files, maxBytes, err := testFiles(opts.configPath, paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	dt := &docTest{opts: opts, out: os.Stdout}
+	errored := false
+	for _, path := range files {
+		if err := dt.testFile(path, maxBytes); err != nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+			errored = true
+		}
+	}
+	if opts.format == "junit" {
+		if err := doctest.WriteJUnit(dt.out, dt.suites); err != nil {
+			return printErr(err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "test: %d blocks in %d files, %d failed\n", dt.blocks, len(dt.suites), dt.failed)
+	switch {
+	case errored:
+		return 2
+	case dt.failed > 0:
+		return 1
+	}
+	return 0
+}
+
+// testFiles resolves the Markdown files to test: paths when given,
+// else the files the config discovers.
+func testFiles(configPath string, paths []string) ([]string, int64, error) {
+	cfg, _, err := loadConfig(configPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(paths) == 0 {
+		files, err := discoverConfigFiles(cfg, walkCLI{})
+		return files, maxBytes, err
+	}
+	files, err := lint.ResolveFilesWithOpts(paths, resolveOpts(cfg, walkCLI{}))
+	return files, maxBytes, err
+}
+
+// docTest holds the state of one `mdsmith test` run.
+type docTest struct {
+	opts   testOptions
+	out    io.Writer
+	suites []doctest.Suite
+	blocks int
+	failed int
+}
+
+// testFile runs the blocks of one file in a fresh temporary directory
+// and reports the failures. In update mode it rewrites mismatched
+// exact output blocks, which then count as passing.
+func (dt *docTest) testFile(path string, maxBytes int64) error {
+	src, err := lint.ReadFileLimited(path, maxBytes)
+	if err != nil {
+		return fmt.Errorf("reading %q: %w", path, err)
+	}
+	blocks, err := doctest.Parse(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "mdsmith-test-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	results := make([]doctest.Result, 0, len(blocks))
+	for _, b := range blocks {
+		results = append(results, doctest.Run(context.Background(), b, dir, dt.opts.timeout))
+	}
+	if dt.opts.update {
+		if err := dt.update(path, src, results); err != nil {
+			return err
+		}
+	}
+	dt.suites = append(dt.suites, doctest.Suite{Path: path, Results: results})
+	dt.blocks += len(results)
+	for _, r := range results {
+		if r.Passed() {
+			continue
+		}
+		dt.failed++
+		if dt.opts.format == "text" {
+			if _, err := fmt.Fprint(dt.out, failureText(path, r)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// update writes the rewritten output blocks back to path and marks
+// the rewritten results as passing.
+func (dt *docTest) update(path string, src []byte, results []doctest.Result) error {
+	updated := doctest.Update(src, results)
+	if bytes.Equal(updated, src) {
+		return nil
+	}
+	if err := writeFilePreservingMode(path, updated); err != nil {
+		return fmt.Errorf("writing %q: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "test: updated %s\n", path)
+	for i, r := range results {
+		if r.Mismatch() && r.Block.Want.Match == doctest.MatchExact {
+			results[i].Failure = ""
+		}
+	}
+	return nil
+}
+
+// failureText formats one failed block. An output mismatch shows the
+// expected and actual output.
+func failureText(path string, r doctest.Result) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s:%d: %s block: %s\n", path, r.Block.Line, r.Block.Lang, r.Failure)
+	if r.Mismatch() {
+		fmt.Fprintf(&sb, "  want (%s):\n%s  got:\n%s", r.Block.Want.Match,
+			indentLines(r.Block.Want.Text), indentLines(r.Got))
+	}
+	return sb.String()
+}
+
+func indentLines(s string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		sb.WriteString("    " + line + "\n")
+	}
+	return sb.String()
+}
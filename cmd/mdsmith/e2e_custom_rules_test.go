@@ -0,0 +1,99 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const customRulesConfig = `custom-rules:
+  - id: ACME001
+    name: prefer-color
+    description: Use US spelling of color.
+    message: "use color, not {match}"
+    select: paragraph
+    pattern: \bcolour\b
+    replace: color
+  - id: ACME002
+    name: draft-title
+    category: release
+    message: title must not be a draft
+    severity: error
+    select: front-matter
+    field: title
+    cue: |
+      import "strings"
+      strings.HasPrefix("Draft")
+`
+
+// setupCustomRulesWorkspace writes a config declaring one fixable
+// paragraph rule and one front-matter rule, plus a file breaking both.
+func setupCustomRulesWorkspace(t *testing.T, extra string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"),
+		[]byte(customRulesConfig+extra), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"),
+		[]byte("---\ntitle: Draft notes\n---\n# Notes\n\nThe colour of the colour wheel.\n"), 0o644))
+	return dir
+}
+
+func TestE2E_CustomRules_CheckAndFix(t *testing.T) {
+	dir := setupCustomRulesWorkspace(t, "")
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", "doc.md")
+	require.Equal(t, 1, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Contains(t, stderr, "doc.md:2:1 ACME002 title must not be a draft")
+	assert.Contains(t, stderr, "doc.md:6:5 ACME001 use color, not colour")
+	assert.Contains(t, stderr, "doc.md:6:19 ACME001 use color, not colour")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "fix", "doc.md")
+	assert.Equal(t, 1, code, "draft-title is not fixable: %s", stderr)
+	got, err := os.ReadFile(filepath.Join(dir, "doc.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "The color of the color wheel.")
+}
+
+func TestE2E_CustomRules_ConfiguredLikeBuiltins(t *testing.T) {
+	dir := setupCustomRulesWorkspace(t, `categories:
+  release: false
+overrides:
+  - glob: ["doc.md"]
+    rules:
+      prefer-color:
+        message: "spelling: {match}"
+`)
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", "--explain", "doc.md")
+	require.Equal(t, 1, code, stderr)
+	assert.NotContains(t, stderr, "ACME002")
+	assert.Contains(t, stderr, "ACME001 spelling: colour")
+	assert.Contains(t, stderr, "overrides[0]")
+}
+
+func TestE2E_CustomRules_Help(t *testing.T) {
+	dir := setupCustomRulesWorkspace(t, "")
+	stdout, _, code := runBinaryInDir(t, dir, "", "help", "rule")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "MDS001")
+	assert.Regexp(t, `ACME001\s+prefer-color\s+custom\s+Use US spelling of color.`, stdout)
+
+	stdout, _, code = runBinaryInDir(t, dir, "", "help", "rule", "draft-title")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "# ACME002: draft-title")
+	assert.Contains(t, stdout, "- Category: release")
+}
+
+func TestE2E_CustomRules_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"),
+		[]byte("custom-rules:\n  - id: MDS900\n    name: x\n    message: m\n"+
+			"    select: heading\n    pattern: x\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc\n"), 0o644))
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "doc.md")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "the MDS prefix is reserved")
+}
@@ -106,3 +106,45 @@ func TestE2E_Deps_UnknownFormat_ExitsTwo(t *testing.T) {
 	_, _, code := runBinaryInDir(t, dir, "", "deps", "--format", "yaml", "docs/index.md")
 	require.Equal(t, 2, code)
 }
+
+func TestE2E_Deps_GraphFormats(t *testing.T) {
+	dir := setupDepsWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "deps", "--graph")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.True(t, strings.HasPrefix(stdout, "digraph deps {\n"), stdout)
+	assert.Contains(t, stdout, `"docs/index.md" -> "docs/api.md" [label="file-link"];`)
+	assert.Contains(t, stdout, `"docs/index.md" -> "docs/frag.md" [label="include"];`)
+
+	stdout, _, code = runBinaryInDir(t, dir, "", "deps", "--graph", "-f", "mermaid",
+		"--depth", "1", "--cluster", "docs/index.md")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "flowchart LR\n  subgraph c0 [\"docs\"]\n")
+	assert.Contains(t, stdout, "-->|include|")
+
+	stdout, _, code = runBinaryInDir(t, dir, "", "deps", "--graph", "-f", "json", "docs/api.md", "--incoming")
+	require.Equal(t, 0, code)
+	var doc struct {
+		Graph struct {
+			Nodes map[string]json.RawMessage `json:"nodes"`
+			Edges []map[string]string        `json:"edges"`
+		} `json:"graph"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &doc))
+	assert.Len(t, doc.Graph.Nodes, 2)
+	assert.Equal(t, "file-link", doc.Graph.Edges[0]["relation"])
+}
+
+func TestE2E_Deps_GraphErrors(t *testing.T) {
+	dir := setupDepsWorkspace(t)
+	_, stderr, code := runBinaryInDir(t, dir, "", "deps", "--cluster", "docs/index.md")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "require --graph")
+	_, stderr, code = runBinaryInDir(t, dir, "", "deps", "--graph", "-f", "text")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "want dot, mermaid, or json")
+	_, stderr, code = runBinaryInDir(t, dir, "", "deps", "--graph", "--depth", "-1")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "--depth must be >= 0")
+	_, _, code = runBinaryInDir(t, dir, "", "deps", "--graph", "missing")
+	assert.Equal(t, 1, code)
+}
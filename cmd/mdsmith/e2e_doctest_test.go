@@ -0,0 +1,92 @@
+package main_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDocTestWorkspace writes the given Markdown files into a fresh
+// project with an empty config.
+func setupDocTestWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not in PATH")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"), []byte("rules: {}\n"), 0o644))
+	for name, body := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644))
+	}
+	return dir
+}
+
+const docTestReadme = "# Demo\n\n```sh {test}\necho hello\nmkdir out\nls\n```\n\n```output\nhello\nout\n```\n"
+
+func TestE2E_DocTest_Pass(t *testing.T) {
+	dir := setupDocTestWorkspace(t, map[string]string{"README.md": docTestReadme})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "test", "README.md")
+	assert.Equal(t, 0, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "test: 1 blocks in 1 files, 0 failed")
+	_, err := os.Stat(filepath.Join(dir, "out"))
+	assert.True(t, os.IsNotExist(err), "blocks must run in a temporary directory")
+}
+
+func TestE2E_DocTest_Mismatch(t *testing.T) {
+	dir := setupDocTestWorkspace(t, map[string]string{
+		"README.md": "# Demo\n\n```sh {test}\necho bye\n```\n\n```output\nhello\n```\n",
+	})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "test", "README.md")
+	require.Equal(t, 1, code, stderr)
+	assert.Equal(t, "README.md:3: sh block: output does not match\n"+
+		"  want (exact):\n    hello\n  got:\n    bye\n", stdout)
+	assert.Contains(t, stderr, "test: 1 blocks in 1 files, 1 failed")
+}
+
+func TestE2E_DocTest_UnsafeCommand(t *testing.T) {
+	dir := setupDocTestWorkspace(t, map[string]string{
+		"README.md": "# Demo\n\n```sh {test}\necho hi > file\n```\n",
+	})
+	stdout, _, code := runBinaryInDir(t, dir, "", "test", "README.md")
+	require.Equal(t, 1, code)
+	assert.Contains(t, stdout, `README.md:3: sh block: line 4: command contains shell operator ">"`)
+}
+
+func TestE2E_DocTest_Update(t *testing.T) {
+	dir := setupDocTestWorkspace(t, map[string]string{
+		"README.md": "# Demo\n\n```sh {test}\necho new\n```\n\n```output\nold\n```\n",
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "", "test", "--update", "README.md")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "test: updated README.md")
+	got, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Demo\n\n```sh {test}\necho new\n```\n\n```output\nnew\n```\n", string(got))
+}
+
+func TestE2E_DocTest_JUnit(t *testing.T) {
+	dir := setupDocTestWorkspace(t, map[string]string{"README.md": docTestReadme})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "test", "--format", "junit", "README.md")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, `<testsuite name="README.md" tests="1" failures="0"`)
+	assert.Contains(t, stdout, `<testcase name="README.md:3 (sh)" classname="README.md"`)
+}
+
+func TestE2E_DocTest_Errors(t *testing.T) {
+	dir := setupDocTestWorkspace(t, map[string]string{
+		"README.md": "# Demo\n\n```ruby {test}\nputs 1\n```\n",
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "", "test", "README.md")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `README.md: line 3: no runner for language "ruby"`)
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "test", "--format", "json", "README.md")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown --format "json"`)
+}
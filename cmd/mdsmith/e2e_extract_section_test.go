@@ -0,0 +1,46 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2E_ExtractSection_ThenInlineRoundTrips(t *testing.T) {
+	dir := setupSplitWorkspace(t)
+	original, _ := os.ReadFile(filepath.Join(dir, "docs/guide.md"))
+
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "extract-section", "docs/guide.md", "Setup")
+	require.Equal(t, 0, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Contains(t, stdout, "docs/setup.md: created")
+	setup, _ := os.ReadFile(filepath.Join(dir, "docs/setup.md"))
+	assert.Equal(t, "# Setup\n\nInstall.\n\n## Details\n\nMore.\n", string(setup))
+	guide, _ := os.ReadFile(filepath.Join(dir, "docs/guide.md"))
+	assert.Contains(t, string(guide), "<?include\nfile: setup.md\nheading-level: absolute\n?>\n## Setup\n")
+	readme, _ := os.ReadFile(filepath.Join(dir, "README.md"))
+	assert.Contains(t, string(readme), "[more](./docs/setup.md#details)")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "check", ".")
+	assert.Equal(t, 0, code, "check after extract: %s", stderr)
+
+	stdout, stderr, code = runBinaryInDir(t, dir, "", "inline-include", "--format", "json", "docs/guide.md", "setup.md")
+	require.Equal(t, 0, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Contains(t, stdout, `"inlined": "docs/setup.md"`)
+	guide, _ = os.ReadFile(filepath.Join(dir, "docs/guide.md"))
+	assert.Equal(t, string(original), string(guide))
+	readme, _ = os.ReadFile(filepath.Join(dir, "README.md"))
+	assert.Contains(t, string(readme), "[more](./docs/guide.md#details)")
+}
+
+func TestE2E_ExtractSection_ExitCodes(t *testing.T) {
+	dir := setupSplitWorkspace(t)
+	_, _, code := runBinaryInDir(t, dir, "", "extract-section", "docs/guide.md", "Ghost")
+	assert.Equal(t, 1, code)
+	_, _, code = runBinaryInDir(t, dir, "", "extract-section", "--name", "a/b.md", "docs/guide.md", "Setup")
+	assert.Equal(t, 2, code)
+	_, _, code = runBinaryInDir(t, dir, "", "inline-include", "docs/guide.md", "setup.md")
+	assert.Equal(t, 1, code)
+}
@@ -0,0 +1,121 @@
+package main_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLinksWorkspace builds a workspace whose docs link to hosts the
+// stand-in server answers for, and returns it with the server URL and
+// a request counter.
+func setupLinksWorkspace(t *testing.T) (string, string, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/guide":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<h2 id="install">Install</h2>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	wf := func(rel, body string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, rel), []byte(body), 0o644))
+	}
+	wf(".mdsmith.yml", "files:\n  - \"**/*.md\"\n")
+	wf("index.md", "---\ntitle: Index\n---\n# Index\n\n"+
+		"Read the [guide](https://docs.example.com/guide#install).\n"+
+		"The [setup](https://docs.example.com/guide#setup) moved.\n"+
+		"The [FAQ](https://docs.example.com/faq) is gone.\n"+
+		"[Local](other.md) links are not checked.\n")
+	wf("other.md", "# Other\n\nSee <https://docs.example.com/guide>.\n")
+	return dir, srv.URL, &hits
+}
+
+func TestE2E_LinksCheckExternal_ReportsAndCaches(t *testing.T) {
+	dir, base, hits := setupLinksWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "links", "check-external",
+		"--base-url", base, "--host-interval", "-1ns", "--retries", "-1")
+	require.Equal(t, 1, code, "stderr=%q", stderr)
+	assert.Equal(t,
+		"index.md:7:13: https://docs.example.com/guide#setup: fragment \"#setup\" not found\n"+
+			"index.md:8:11: https://docs.example.com/faq: HTTP 404\n",
+		stdout)
+	assert.Contains(t, stderr, "links: 4 URLs checked, 2 broken")
+	assert.FileExists(t, filepath.Join(dir, ".mdsmith-cache", "links.json"))
+	fetched := hits.Load()
+
+	// A second run answers from the cache without requests.
+	_, _, code = runBinaryInDir(t, dir, "", "links", "check-external", "--base-url", base)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, fetched, hits.Load())
+
+	// The rule reads the same cache.
+	wfCfg := "files:\n  - \"**/*.md\"\nrules:\n  external-links: true\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"), []byte(wfCfg), 0o644))
+	stdout, stderr, code = runBinaryInDir(t, dir, "", "check", "--no-color", "index.md")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout+stderr,
+		`index.md:8:11 MDS075 broken external link "https://docs.example.com/faq": HTTP 404`)
+}
+
+func TestE2E_LinksCheckExternal_OfflineUsesCacheOnly(t *testing.T) {
+	dir, base, hits := setupLinksWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "links", "check-external", "--offline",
+		"--base-url", base)
+	assert.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "4 URLs not in the cache, skipped (offline)")
+	assert.Equal(t, int32(0), hits.Load())
+	assert.NoFileExists(t, filepath.Join(dir, ".mdsmith-cache", "links.json"))
+}
+
+func TestE2E_LinksCheckExternal_JSONAndExclude(t *testing.T) {
+	dir, base, _ := setupLinksWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "links", "check-external",
+		"--base-url", base, "--format", "json", "--exclude", `/faq$`, "--cache", "tmp/c.json", "index.md")
+	require.Equal(t, 1, code, "stderr=%q", stderr)
+	var recs []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &recs))
+	require.Len(t, recs, 1)
+	assert.Equal(t, "https://docs.example.com/guide#setup", recs[0]["url"])
+	assert.Equal(t, float64(200), recs[0]["status"])
+	assert.FileExists(t, filepath.Join(dir, "tmp", "c.json"))
+}
+
+func TestE2E_Links_UsageAndErrors(t *testing.T) {
+	_, stderr, code := runBinary(t, "", "links")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stderr, "check-external")
+
+	_, stderr, code = runBinary(t, "", "links", "bogus")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown subcommand "bogus"`)
+
+	_, _, code = runBinary(t, "", "links", "check-external", "--format", "xml")
+	assert.Equal(t, 2, code)
+
+	for _, n := range []string{"0", "-1"} {
+		_, stderr, code = runBinary(t, "", "links", "check-external", "--concurrency", n)
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "--concurrency must be >= 1")
+	}
+
+	_, stderr, code = runBinary(t, "", "links", "check-external", "--exclude", "(")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "invalid --exclude")
+}
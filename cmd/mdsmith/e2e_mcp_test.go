@@ -0,0 +1,143 @@
+package main_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mcpSession runs `mdsmith mcp` in dir over the given requests (an
+// initialize handshake is prepended) and returns each reply's result
+// keyed by request id.
+func mcpSession(t *testing.T, dir string, calls ...string) map[int]map[string]any {
+	t.Helper()
+	lines := []string{
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-06-18",` +
+			`"capabilities":{},"clientInfo":{"name":"test","version":"0"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+	}
+	for i, c := range calls {
+		lines = append(lines, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,%s}`, i+1, c))
+	}
+	stdout, stderr, code := runBinaryInDir(t, dir, strings.Join(lines, "\n")+"\n", "mcp")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+
+	out := map[int]map[string]any{}
+	sc := bufio.NewScanner(strings.NewReader(stdout))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var msg struct {
+			ID     int            `json:"id"`
+			Result map[string]any `json:"result"`
+			Error  map[string]any `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &msg), "non-JSON line on stdout: %q", sc.Text())
+		require.Nil(t, msg.Error, "request %d", msg.ID)
+		out[msg.ID] = msg.Result
+	}
+	require.Len(t, out, len(calls)+1)
+	return out
+}
+
+func toolCall(name, args string) string {
+	return fmt.Sprintf(`"method":"tools/call","params":{"name":%q,"arguments":%s}`, name, args)
+}
+
+func structured(t *testing.T, res map[string]any) map[string]any {
+	t.Helper()
+	require.NotEqual(t, true, res["isError"], "tool failed: %v", res["content"])
+	return res["structuredContent"].(map[string]any)
+}
+
+func TestE2E_MCP_HandshakeToolsAndResources(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	res := mcpSession(t, dir,
+		`"method":"tools/list"`,
+		`"method":"resources/read","params":{"uri":"mdsmith://rules/MDS001"}`,
+	)
+	assert.Equal(t, "2025-06-18", res[0]["protocolVersion"])
+	assert.Equal(t, "mdsmith", res[0]["serverInfo"].(map[string]any)["name"])
+
+	var names []string
+	for _, tool := range res[1]["tools"].([]any) {
+		tm := tool.(map[string]any)
+		names = append(names, tm["name"].(string))
+		assert.Equal(t, "object", tm["inputSchema"].(map[string]any)["type"])
+	}
+	assert.Equal(t, []string{
+		"check", "fix", "list_query", "deps", "list_backlinks", "kinds_resolve", "extract", "rename",
+	}, names)
+
+	contents := res[2]["contents"].([]any)[0].(map[string]any)
+	assert.Equal(t, "text/markdown", contents["mimeType"])
+	assert.Contains(t, contents["text"], "# MDS001")
+}
+
+func TestE2E_MCP_QueryTools(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	res := mcpSession(t, dir,
+		toolCall("check", `{"files":["readme.md"]}`),
+		toolCall("deps", `{"file":"readme.md"}`),
+		toolCall("list_backlinks", `{"target":"guide.md#debian"}`),
+		toolCall("kinds_resolve", `{"file":"plan.md"}`),
+		toolCall("list_query", `{"expr":"kinds: [\"plan\"]"}`),
+		toolCall("extract", `{"kind":"plan","file":"plan.md"}`),
+	)
+	check := structured(t, res[1])
+	assert.Equal(t, float64(1), check["filesChecked"])
+	assert.Equal(t, []any{}, check["diagnostics"])
+
+	edges := structured(t, res[2])["edges"].([]any)
+	require.Len(t, edges, 1)
+	assert.Equal(t, "guide.md#debian", edges[0].(map[string]any)["target"])
+
+	links := structured(t, res[3])["backlinks"].([]any)
+	require.Len(t, links, 1)
+	assert.Equal(t, "readme.md", links[0].(map[string]any)["source"])
+
+	kinds := structured(t, res[4])["kinds"].([]any)
+	assert.Equal(t, "plan", kinds[0].(map[string]any)["name"])
+
+	assert.Equal(t, []any{"plan.md"}, structured(t, res[5])["files"])
+
+	assert.NotNil(t, structured(t, res[6])["data"])
+}
+
+func TestE2E_MCP_FixDryRunWritesNothing(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	messy := "# Messy   \n\nText.\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "messy.md"), []byte(messy), 0o644))
+	res := mcpSession(t, dir, toolCall("fix", `{"files":["messy.md"],"dryRun":true}`))
+	out := structured(t, res[1])
+	changes := out["changes"].([]any)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "# Messy\n\nText.\n", changes[0].(map[string]any)["content"])
+	got, _ := os.ReadFile(filepath.Join(dir, "messy.md"))
+	assert.Equal(t, messy, string(got))
+}
+
+func TestE2E_MCP_RenameAndRefusedShift(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	res := mcpSession(t, dir,
+		toolCall("rename", `{"file":"guide.md","mode":"heading","old":"Debian","new":"Ubuntu"}`),
+		toolCall("rename", `{"file":"plan.md","mode":"shift-level","old":"Tasks","shift":1}`),
+	)
+	files := structured(t, res[1])["files"].([]any)
+	assert.Len(t, files, 2)
+	readme, _ := os.ReadFile(filepath.Join(dir, "readme.md"))
+	assert.Contains(t, string(readme), "(guide.md#ubuntu)")
+
+	refused := res[2]
+	assert.Equal(t, true, refused["isError"])
+	assert.Contains(t, refused["content"].([]any)[0].(map[string]any)["text"], "refusing to shift")
+	assert.NotEmpty(t, refused["structuredContent"].(map[string]any)["diagnostics"])
+	plan, _ := os.ReadFile(filepath.Join(dir, "plan.md"))
+	assert.Contains(t, string(plan), "\n## Tasks\n")
+}
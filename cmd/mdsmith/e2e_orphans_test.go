@@ -0,0 +1,85 @@
+package main_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupOrphansWorkspace builds a workspace with one page of each kind
+// of reachability and a few orphans. config is the .mdsmith.yml body.
+func setupOrphansWorkspace(t *testing.T, config string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	wf := func(rel, body string) {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(body), 0o644))
+	}
+	wf(".mdsmith.yml", config)
+	wf("README.md", "# Home\n\n[Guide](docs/guide.md) and the [API][api].\n\n"+
+		"[api]: docs/api.md\n")
+	wf("docs/guide.md", "# Guide\n\n<?catalog\nglob: \"topics/*.md\"\n?>\n<?/catalog?>\n")
+	wf("docs/api.md", "# API\n\n<?include\nfile: snippets/auth.md\n?>\n<?/include?>\n")
+	wf("docs/snippets/auth.md", "Use a token.\n")
+	wf("docs/topics/a.md", "# Topic A\n")
+	wf("docs/old.md", "---\ntitle: Old Notes\n---\n# Old\n\n[Home](../README.md)\n")
+	wf("landing/promo.md", "---\nkinds: [standalone]\n---\n# Promo\n")
+	return dir
+}
+
+const orphansConfig = "files:\n  - \"**/*.md\"\n" +
+	"kinds:\n  standalone:\n    rules:\n      orphaned-files: false\n"
+
+func TestE2E_ListOrphans_Text(t *testing.T) {
+	dir := setupOrphansWorkspace(t, orphansConfig)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "list", "orphans")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Equal(t, "docs/old.md\n", stdout)
+}
+
+func TestE2E_ListOrphans_JSONAndEntryPoints(t *testing.T) {
+	dir := setupOrphansWorkspace(t, orphansConfig)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "list", "orphans",
+		"-f", "json", "--entry-point", "docs/guide.md")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	var got []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(stdout), &got))
+	assert.Equal(t, []map[string]string{
+		{"file": "README.md"},
+		{"file": "docs/api.md"},
+		{"file": "docs/old.md", "title": "Old Notes"},
+		{"file": "docs/snippets/auth.md"},
+	}, got)
+}
+
+func TestE2E_ListOrphans_ConfiguredEntryPointsAndNone(t *testing.T) {
+	dir := setupOrphansWorkspace(t, orphansConfig+
+		"rules:\n  orphaned-files:\n    entry-points: [\"README.md\", \"docs/old.md\"]\n")
+	stdout, _, code := runBinaryInDir(t, dir, "", "list", "orphans", "-f", "json")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "[]\n", stdout)
+}
+
+func TestE2E_ListOrphans_RuleReportsSameFiles(t *testing.T) {
+	dir := setupOrphansWorkspace(t, orphansConfig+"rules:\n  orphaned-files: true\n")
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", "docs", "landing")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "docs/old.md:4:1 MDS076 file is not reachable from any entry point")
+	assert.NotContains(t, stderr, "promo.md")
+}
+
+func TestE2E_ListOrphans_BadArgs(t *testing.T) {
+	dir := setupOrphansWorkspace(t, orphansConfig)
+	_, stderr, code := runBinaryInDir(t, dir, "", "list", "orphans", "extra")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "takes no arguments")
+	_, stderr, code = runBinaryInDir(t, dir, "", "list", "orphans", "-f", "xml")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "unknown --format")
+}
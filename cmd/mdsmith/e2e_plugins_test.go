@@ -0,0 +1,190 @@
+package main_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	fakePluginOnce sync.Once
+	fakePluginPath string
+	fakePluginErr  error
+)
+
+// buildFakePlugin builds the test plugin from internal/plugin once per
+// test binary.
+func buildFakePlugin(t *testing.T) string {
+	t.Helper()
+	fakePluginOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "mdsmith-fakeplugin-*")
+		if err != nil {
+			fakePluginErr = err
+			return
+		}
+		fakePluginPath = filepath.Join(dir, "fakeplugin")
+		cmd := exec.Command("go", "build", "-o", fakePluginPath, "../../internal/plugin/testdata/fakeplugin")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			fakePluginErr = err
+			t.Log(string(out))
+		}
+	})
+	require.NoError(t, fakePluginErr)
+	return fakePluginPath
+}
+
+// setupPluginWorkspace copies the fake plugin into a workspace under
+// bin/ (so the relative command resolves against the config dir) and
+// writes a config declaring the given rules. It trusts command
+// plugins for the binaries the test runs.
+func setupPluginWorkspace(t *testing.T, rules, extra string) string {
+	t.Helper()
+	t.Setenv("MDSMITH_TRUST_PLUGINS", "1")
+	bin, err := os.ReadFile(buildFakePlugin(t))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "fake"), bin, 0o755))
+	cfg := "plugins:\n  - name: fake\n    command: [./bin/fake]\n    rules:\n" + rules + extra
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"), []byte(cfg), 0o644))
+	return dir
+}
+
+const noTodoRule = "      - id: FAKE001\n        name: no-todo\n        description: No TODO markers.\n"
+
+func TestE2E_Plugins_CheckAndFix(t *testing.T) {
+	dir := setupPluginWorkspace(t, noTodoRule, "rules:\n  no-todo:\n    replacement: Done\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"),
+		[]byte("---\ntitle: x\n---\n# Notes\n\nTODO write more.\n"), 0o644))
+
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", "doc.md")
+	require.Equal(t, 1, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Contains(t, stderr, "doc.md:6:1 FAKE001 TODO left in text")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "fix", "doc.md")
+	require.Equal(t, 0, code, stderr)
+	got, err := os.ReadFile(filepath.Join(dir, "doc.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: x\n---\n# Notes\n\nDone write more.\n", string(got))
+}
+
+func TestE2E_Plugins_UntrustedCommandDoesNotRun(t *testing.T) {
+	dir := setupPluginWorkspace(t, "      - id: FAKE001\n        name: pid\n", "")
+	t.Setenv("MDSMITH_TRUST_PLUGINS", "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc\n"), 0o644))
+
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", "doc.md")
+	require.Equal(t, 1, code, stderr)
+	assert.Contains(t, stderr, "doc.md:1:1 FAKE001 plugin fake: command plugins are not trusted; "+
+		"set MDSMITH_TRUST_PLUGINS=1 to run \"./bin/fake\"")
+	assert.NotContains(t, stderr, "pid ")
+}
+
+func TestE2E_Plugins_OneProcessPerRun(t *testing.T) {
+	dir := setupPluginWorkspace(t, "      - id: FAKE001\n        name: pid\n", "")
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("# Doc\n"), 0o644))
+	}
+
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", ".")
+	require.Equal(t, 1, code, stderr)
+	pids := map[string]bool{}
+	for _, m := range regexp.MustCompile(`FAKE001 pid (\d+) check`).FindAllStringSubmatch(stderr, -1) {
+		pids[m[1]] = true
+	}
+	assert.Len(t, pids, 1, "all files share one plugin process: %s", stderr)
+	assert.Contains(t, stderr, "check 3")
+}
+
+func TestE2E_Plugins_TimeoutIsReported(t *testing.T) {
+	dir := setupPluginWorkspace(t, "      - id: FAKE001\n        name: hang\n", "    timeout: 100ms\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc\n"), 0o644))
+
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", "doc.md")
+	require.Equal(t, 1, code, stderr)
+	assert.Contains(t, stderr, "doc.md:1:1 FAKE001 plugin fake: check: no response within 100ms")
+}
+
+var (
+	fakeWasmOnce sync.Once
+	fakeWasmPath string
+	fakeWasmErr  error
+)
+
+// buildFakeWasm builds the test WebAssembly plugin once per test
+// binary.
+func buildFakeWasm(t *testing.T) string {
+	t.Helper()
+	fakeWasmOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "mdsmith-fakewasm-*")
+		if err != nil {
+			fakeWasmErr = err
+			return
+		}
+		fakeWasmPath = filepath.Join(dir, "fake.wasm")
+		cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", fakeWasmPath,
+			"../../internal/plugin/testdata/fakewasm")
+		cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			fakeWasmErr = err
+			t.Log(string(out))
+		}
+	})
+	require.NoError(t, fakeWasmErr)
+	return fakeWasmPath
+}
+
+func TestE2E_Plugins_Wasm(t *testing.T) {
+	bin, err := os.ReadFile(buildFakeWasm(t))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fake.wasm"), bin, 0o644))
+	cfg := "plugins:\n  - name: fake\n    wasm: fake.wasm\n    memory: 32MB\n    rules:\n" + noTodoRule
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"), []byte(cfg), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Notes\n\nTODO write more.\n"), 0o644))
+
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "--no-color", "doc.md")
+	require.Equal(t, 1, code, stderr)
+	assert.Contains(t, stderr, "doc.md:3:1 FAKE001 TODO left in text")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "fix", "doc.md")
+	require.Equal(t, 0, code, stderr)
+	got, err := os.ReadFile(filepath.Join(dir, "doc.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n\nDONE write more.\n", string(got))
+
+	stdout, _, code := runBinaryInDir(t, dir, "", "help", "rule", "no-todo")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "- Module: `fake.wasm`")
+}
+
+func TestE2E_Plugins_Help(t *testing.T) {
+	dir := setupPluginWorkspace(t, noTodoRule, "")
+	stdout, _, code := runBinaryInDir(t, dir, "", "help", "rule")
+	require.Equal(t, 0, code)
+	assert.Regexp(t, `FAKE001\s+no-todo\s+plugin\s+No TODO markers\.`, stdout)
+
+	stdout, _, code = runBinaryInDir(t, dir, "", "help", "rule", "no-todo")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "- Plugin: fake")
+	assert.Contains(t, stdout, "- Timeout: 10s")
+}
+
+func TestE2E_Plugins_InvalidConfig(t *testing.T) {
+	dir := setupPluginWorkspace(t, "      - id: MDS999\n        name: mine\n", "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc\n"), 0o644))
+
+	_, stderr, code := runBinaryInDir(t, dir, "", "check", "doc.md")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `plugins[0] (fake): rule "mine": id "MDS999": the MDS prefix is reserved`)
+}
@@ -59,3 +59,19 @@ func TestE2E_Rename_ExitCodes(t *testing.T) {
 	_, _, code = runBinaryInDir(t, dir, "", "rename", "a.md", "Setup", "Install")
 	assert.Equal(t, 2, code)
 }
+
+func TestE2E_Fix_HeadingCaseKeepsAnchors(t *testing.T) {
+	dir := setupRenameWorkspace(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"),
+		[]byte("files:\n  - \"**/*.md\"\nrules:\n  heading-case: true\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.md"), []byte("# Setup The Tool\n\nBody.\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.md"),
+		[]byte("# Links\n\nSee [go](a.md#setup-the-tool).\n"), 0o644))
+	_, stderr, code := runBinaryInDir(t, dir, "", "fix", "--no-color", "a.md")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+
+	a, _ := os.ReadFile(filepath.Join(dir, "a.md"))
+	assert.Equal(t, "# Setup the tool\n\nBody.\n", string(a))
+	b, _ := os.ReadFile(filepath.Join(dir, "b.md"))
+	assert.Contains(t, string(b), "(a.md#setup-the-tool)")
+}
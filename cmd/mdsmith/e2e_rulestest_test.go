@@ -0,0 +1,159 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRulesTestWorkspace writes a config declaring the custom rules
+// of customRulesConfig plus the given fixtures under fixtures/.
+func setupRulesTestWorkspace(t *testing.T, fixtures map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fixtures"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mdsmith.yml"),
+		[]byte(customRulesConfig+"rules:\n  line-length:\n    max: 40\n"), 0o644))
+	for name, body := range fixtures {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fixtures", name), []byte(body), 0o644))
+	}
+	return dir
+}
+
+func TestE2E_RulesTest_Pass(t *testing.T) {
+	dir := setupRulesTestWorkspace(t, map[string]string{
+		"colour.md":       "# Notes\n\n<!-- expect: ACME001 -->\nThe colour wheel.\n",
+		"colour.fixed.md": "# Notes\n\n<!-- expect: ACME001 -->\nThe color wheel.\n",
+		"long.md":         "# Notes\n\nThis line runs past forty characters. <!-- expect: MDS001 -->\n",
+	})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "rules", "test", "fixtures")
+	assert.Equal(t, 0, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "rules test: 2 fixtures, 0 failed")
+}
+
+func TestE2E_RulesTest_ReportsMismatches(t *testing.T) {
+	dir := setupRulesTestWorkspace(t, map[string]string{
+		"colour.md":       "# Notes\n\nThe colour wheel.\n\n<!-- expect: prefer-color -->\nNo match here.\n",
+		"colour.fixed.md": "# Notes\n\nThe colour wheel.\n",
+	})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "rules", "test", "fixtures")
+	require.Equal(t, 1, code, stderr)
+	assert.Contains(t, stdout, "fixtures/colour.md:3:5: unexpected ACME001 use color, not colour\n")
+	assert.Contains(t, stdout, "fixtures/colour.md:6: missing ACME001\n")
+	assert.Contains(t, stdout, "fixtures/colour.fixed.md:3: differs from the fix output of fixtures/colour.md\n")
+	assert.Contains(t, stderr, "rules test: 1 fixtures, 1 failed")
+}
+
+func TestE2E_RulesTest_Update(t *testing.T) {
+	dir := setupRulesTestWorkspace(t, map[string]string{
+		"colour.md": "# Notes\n\nThe colour wheel. <!-- expect: ACME001 -->\n",
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "", "rules", "test", "--update", "fixtures")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "updated fixtures/colour.fixed.md")
+	got, err := os.ReadFile(filepath.Join(dir, "fixtures", "colour.fixed.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes\n\nThe color wheel. <!-- expect: ACME001 -->\n", string(got))
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "rules", "test", "fixtures")
+	assert.Equal(t, 0, code, stderr)
+}
+
+func TestE2E_RulesTest_RuleFlag(t *testing.T) {
+	dir := setupRulesTestWorkspace(t, map[string]string{
+		"clean.md": "# Notes\n\nThe colour wheel.\n",
+	})
+	_, stderr, code := runBinaryInDir(t, dir, "", "rules", "test", "fixtures")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "no rules to test")
+
+	stdout, _, code := runBinaryInDir(t, dir, "", "rules", "test", "--rule", "ACME001", "fixtures")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "unexpected ACME001")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "rules", "test", "--rule", "nope", "fixtures")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown rule "nope"`)
+}
+
+func TestE2E_RulesTest_EnablesDisabledRule(t *testing.T) {
+	dir := setupRulesTestWorkspace(t, map[string]string{
+		"tabs.md": "# Notes\n\n<!-- expect: MDS006 -->\nTrailing space \n",
+	})
+	cfg := filepath.Join(dir, ".mdsmith.yml")
+	data, err := os.ReadFile(cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cfg, append(data, "  no-trailing-spaces: false\n"...), 0o644))
+
+	_, stderr, code := runBinaryInDir(t, dir, "", "rules", "test", "fixtures")
+	assert.Equal(t, 0, code, stderr)
+}
+
+// setupRuleDirWorkspace writes the given files under rules/ in a
+// workspace whose config caps lines at 40 characters.
+func setupRuleDirWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := setupRulesTestWorkspace(t, nil)
+	for name, body := range files {
+		path := filepath.Join(dir, "rules", filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+	}
+	return dir
+}
+
+const shortLineBad = "---\nsettings:\n  max: 20\ndiagnostics:\n  - line: 3\n    column: 21\n" +
+	"    message: \"line too long (30 > 20)\"\n---\n# Title\n\nThis line is thirty chars long\n"
+
+const trailingBad = "---\ndiagnostics:\n  - line: 3\n    column: 15\n" +
+	"    message: \"trailing whitespace\"\n---\n# Title\n\nTrailing here. \n"
+
+func TestE2E_RulesTest_RuleDirectory(t *testing.T) {
+	dir := setupRuleDirWorkspace(t, map[string]string{
+		"MDS006-no-trailing-spaces/README.md":   "# MDS006\n",
+		"MDS006-no-trailing-spaces/good/a.md":   "# Title\n\nNo trailing spaces.\n",
+		"MDS006-no-trailing-spaces/bad/a.md":    trailingBad,
+		"MDS006-no-trailing-spaces/fixed/a.md":  "---\nnote: ignored\n---\n# Title\n\nTrailing here.\n",
+		"MDS001-line-length/good/default.md":    "# Title\n\nLonger than the forty characters the config allows.\n",
+		"MDS001-line-length/bad/short.md":       shortLineBad,
+		"MDS001-line-length/bad/data/unused.md": "Not a fixture.\n",
+	})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "rules", "test", "rules")
+	assert.Equal(t, 0, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Contains(t, stderr, "rules test: 4 fixtures, 0 failed")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "rules", "test", "--rule", "MDS006", "rules")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "rules test: 2 fixtures, 0 failed")
+}
+
+func TestE2E_RulesTest_RuleDirectoryMismatches(t *testing.T) {
+	dir := setupRuleDirWorkspace(t, map[string]string{
+		"MDS006-no-trailing-spaces/bad/a.md":   strings.Replace(trailingBad, "column: 15", "column: 9", 1),
+		"MDS006-no-trailing-spaces/fixed/a.md": "# Title\n\nTrailing there.\n",
+		"MDS006-no-trailing-spaces/bad/b.md":   "# Title\n\nTrailing here. \n",
+	})
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "rules", "test", "rules")
+	require.Equal(t, 1, code, stderr)
+	bad := "rules/MDS006-no-trailing-spaces/bad/"
+	assert.Contains(t, stdout, bad+"a.md:9:9: missing MDS006 trailing whitespace\n")
+	assert.Contains(t, stdout, bad+"a.md:9:15: unexpected MDS006 trailing whitespace\n")
+	assert.Contains(t, stdout,
+		"rules/MDS006-no-trailing-spaces/fixed/a.md:3: differs from the fix output of "+bad+"a.md\n")
+	assert.Contains(t, stdout, bad+"b.md: bad fixture lists no diagnostics in its front matter\n")
+	assert.Contains(t, stderr, "rules test: 2 fixtures, 2 failed")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "rules", "test", "--update", "rules")
+	require.Equal(t, 1, code, stderr)
+	got, err := os.ReadFile(filepath.Join(dir, "rules", "MDS006-no-trailing-spaces", "fixed", "a.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Title\n\nTrailing here.\n", string(got))
+	_, err = os.Stat(filepath.Join(dir, "rules", "MDS006-no-trailing-spaces", "fixed", "b.md"))
+	assert.True(t, os.IsNotExist(err), "update must not create fixed/b.md")
+}
@@ -0,0 +1,106 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupShiftWorkspace builds a workspace with a `plan` kind whose
+// schema pins `## Goal` and `## Tasks`, one plan file, and a guide
+// with a nested subtree to re-level.
+func setupShiftWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wf := func(rel, body string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, rel)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, rel), []byte(body), 0o644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	wf(".mdsmith.yml", "files:\n  - \"**/*.md\"\nignore:\n  - proto.md\n"+
+		"kinds:\n  plan:\n    rules:\n      required-structure:\n        schema: proto.md\n")
+	wf("proto.md", "# ?\n\n## Goal\n\n## Tasks\n")
+	wf("plan.md", "---\nkinds: [plan]\n---\n# Plan\n\n## Goal\n\nShip it.\n\n## Tasks\n\nDo it.\n")
+	wf("guide.md", "# Guide\n\n## Setup\n\nInstall.\n\n### Linux\n\nApt.\n\n#### Debian\n\nDeb.\n\n## Usage\n\nRun.\n")
+	wf("readme.md", "# Readme\n\nSee [debian](guide.md#debian).\n")
+	return dir
+}
+
+func TestE2E_ShiftLevel_PromotesSubtree(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "rename", "--shift-level", "-1", "guide.md:Linux")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Equal(t, "guide.md: 2 edit(s)\n", stdout)
+
+	got, _ := os.ReadFile(filepath.Join(dir, "guide.md"))
+	assert.Equal(t,
+		"# Guide\n\n## Setup\n\nInstall.\n\n## Linux\n\nApt.\n\n### Debian\n\nDeb.\n\n## Usage\n\nRun.\n",
+		string(got))
+	// Slugs do not depend on level, so the link is untouched.
+	readme, _ := os.ReadFile(filepath.Join(dir, "readme.md"))
+	assert.Contains(t, string(readme), "(guide.md#debian)")
+}
+
+func TestE2E_ShiftLevel_TwoArgFormJSON(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "",
+		"rename", "--shift-level=-1", "--format", "json", "guide.md", "Debian")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Contains(t, stdout, `"file": "guide.md"`)
+	assert.Contains(t, stdout, `"edits": 1`)
+}
+
+func TestE2E_ShiftLevel_RefusesSchemaBreak(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	before, _ := os.ReadFile(filepath.Join(dir, "plan.md"))
+	_, stderr, code := runBinaryInDir(t, dir, "", "rename", "--shift-level", "1", "plan.md:Tasks")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "MDS020")
+	assert.Contains(t, stderr, "refusing to shift")
+	after, _ := os.ReadFile(filepath.Join(dir, "plan.md"))
+	assert.Equal(t, string(before), string(after), "refused shift must not write")
+}
+
+func TestE2E_ShiftLevel_RefusesSecondH1UnderDefaultConfig(t *testing.T) {
+	// The workspace config leaves single-h1 at its default, off.
+	dir := setupShiftWorkspace(t)
+	before, _ := os.ReadFile(filepath.Join(dir, "guide.md"))
+	_, stderr, code := runBinaryInDir(t, dir, "", "rename", "--shift-level", "-1", "guide.md:Setup")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "MDS051 single-h1")
+	assert.Contains(t, stderr, "refusing to shift")
+	after, _ := os.ReadFile(filepath.Join(dir, "guide.md"))
+	assert.Equal(t, string(before), string(after), "refused shift must not write")
+}
+
+func TestE2E_ShiftLevel_KeepsExistingDuplicates(t *testing.T) {
+	// Collapsing the setext heading moves the duplicates up a line;
+	// they are not new, so the shift goes through.
+	dir := setupShiftWorkspace(t)
+	notes := "# Notes\n\n## Intro\n\nPart\n----\n\n## Dup\n\n## Dup\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte(notes), 0o644))
+	_, stderr, code := runBinaryInDir(t, dir, "", "rename", "--shift-level", "1", "notes.md:Part")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	got, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+	assert.Equal(t, "# Notes\n\n## Intro\n\n### Part\n\n## Dup\n\n## Dup\n", string(got))
+}
+
+func TestE2E_ShiftLevel_ExitCodes(t *testing.T) {
+	dir := setupShiftWorkspace(t)
+	// Unknown heading → exit 1.
+	_, _, code := runBinaryInDir(t, dir, "", "rename", "--shift-level", "1", "guide.md:Ghost")
+	assert.Equal(t, 1, code)
+	// Out of H1–H6 → exit 2.
+	_, stderr, code := runBinaryInDir(t, dir, "", "rename", "--shift-level", "3", "guide.md:Linux")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "want 1-6")
+	// Zero shift → exit 2.
+	_, _, code = runBinaryInDir(t, dir, "", "rename", "--shift-level", "0", "guide.md:Linux")
+	assert.Equal(t, 2, code)
+	// Combined with another mode → exit 2.
+	_, _, code = runBinaryInDir(t, dir, "", "rename", "--heading", "--shift-level", "1", "guide.md:Linux")
+	assert.Equal(t, 2, code)
+}
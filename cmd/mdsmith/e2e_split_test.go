@@ -0,0 +1,81 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSplitWorkspace builds a workspace where guide.md has two H2
+// sections and README.md links into both of them.
+func setupSplitWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wf := func(rel, body string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, rel)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, rel), []byte(body), 0o644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	wf(".mdsmith.yml", "files:\n  - \"**/*.md\"\n")
+	wf("docs/guide.md", "# Guide\n\nSee [setup](#setup).\n\n## Setup\n\nInstall.\n\n"+
+		"### Details\n\nMore.\n\n## Usage\n\nSee [details](#details).\n")
+	wf("README.md", "# Readme\n\nRead [use](docs/guide.md#usage) and [more](./docs/guide.md#details).\n")
+	return dir
+}
+
+func TestE2E_Split_Catalog(t *testing.T) {
+	dir := setupSplitWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "split", "docs/guide.md")
+	require.Equal(t, 0, code, "stdout=%q stderr=%q", stdout, stderr)
+	assert.Contains(t, stdout, "docs/setup.md: created")
+	assert.Contains(t, stdout, "README.md: 2 edit(s)")
+
+	setup, _ := os.ReadFile(filepath.Join(dir, "docs/setup.md"))
+	assert.Equal(t, "---\ntitle: Setup\nweight: 1\n---\n# Setup\n\nInstall.\n\n## Details\n\nMore.\n", string(setup))
+	usage, _ := os.ReadFile(filepath.Join(dir, "docs/usage.md"))
+	assert.Contains(t, string(usage), "See [details](setup.md#details).")
+	guide, _ := os.ReadFile(filepath.Join(dir, "docs/guide.md"))
+	assert.Contains(t, string(guide), "See [setup](setup.md#setup).")
+	assert.Contains(t, string(guide), "- [Setup](setup.md)\n- [Usage](usage.md)\n<?/catalog?>")
+	readme, _ := os.ReadFile(filepath.Join(dir, "README.md"))
+	assert.Contains(t, string(readme), "[use](docs/usage.md#usage) and [more](./docs/setup.md#details)")
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "check", ".")
+	assert.Equal(t, 0, code, "check after split: %s", stderr)
+}
+
+func TestE2E_Split_Include_JSON(t *testing.T) {
+	dir := setupSplitWorkspace(t)
+	stdout, stderr, code := runBinaryInDir(t, dir, "", "split",
+		"--index", "include", "--format", "json", "docs/guide.md")
+	require.Equal(t, 0, code, "stderr=%q", stderr)
+	assert.Contains(t, stdout, `"index": "docs/guide.md"`)
+	assert.Contains(t, stdout, `"docs/usage.md"`)
+
+	guide, _ := os.ReadFile(filepath.Join(dir, "docs/guide.md"))
+	assert.Contains(t, string(guide), "<?include\nfile: usage.md\nheading-level: absolute\n?>\n## Usage\n")
+	setup, _ := os.ReadFile(filepath.Join(dir, "docs/setup.md"))
+	assert.Equal(t, "# Setup\n\nInstall.\n\n## Details\n\nMore.\n", string(setup))
+
+	_, stderr, code = runBinaryInDir(t, dir, "", "check", ".")
+	assert.Equal(t, 0, code, "check after split: %s", stderr)
+}
+
+func TestE2E_Split_ExitCodes(t *testing.T) {
+	dir := setupSplitWorkspace(t)
+	// No heading at the level → exit 1.
+	_, _, code := runBinaryInDir(t, dir, "", "split", "--level", "4", "docs/guide.md")
+	assert.Equal(t, 1, code)
+	// Unknown index mode → exit 2.
+	_, _, code = runBinaryInDir(t, dir, "", "split", "--index", "list", "docs/guide.md")
+	assert.Equal(t, 2, code)
+	// An existing section file is never overwritten → exit 2.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs/usage.md"), []byte("# Mine\n"), 0o644))
+	_, _, code = runBinaryInDir(t, dir, "", "split", "docs/guide.md")
+	assert.Equal(t, 2, code)
+	mine, _ := os.ReadFile(filepath.Join(dir, "docs/usage.md"))
+	assert.Equal(t, "# Mine\n", string(mine))
+}
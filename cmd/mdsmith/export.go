@@ -158,7 +158,7 @@ func prepareExportFile(
 	// not responsible for those bytes.
 	f.GeneratedRanges = gensection.FindAllGeneratedRanges(f)
 
-	all := rule.All()
+	all := configuredRules(cfg)
 	effective, err := effectiveExportConfig(cfg, path, f.FrontMatter, all)
 	if err != nil {
 		return nil, nil, err
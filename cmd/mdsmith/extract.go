@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,7 +11,6 @@ import (
 	"github.com/jeduden/mdsmith/internal/extract"
 	"github.com/jeduden/mdsmith/internal/extract/encode"
 	"github.com/jeduden/mdsmith/internal/lint"
-	"github.com/jeduden/mdsmith/internal/rule"
 	"github.com/jeduden/mdsmith/internal/rules/requiredstructure"
 	"github.com/jeduden/mdsmith/internal/schema"
 	flag "github.com/spf13/pflag"
@@ -65,8 +65,8 @@ func runExtract(args []string) int {
 		return code
 	}
 	_, cfgPath, _ := loadConfig("")
-	if code := validateExtractKind(cfg, res, kindName, path); code != 0 {
-		return code
+	if err := validateExtractKind(cfg, res, kindName, path); err != nil {
+		return extractErr(2, "%v", err)
 	}
 
 	// resolveFileFromCLI already parsed and validated
@@ -77,22 +77,10 @@ func runExtract(args []string) int {
 		return code
 	}
 
-	f, source, code := loadExtractFile(cfg, cfgPath, path, maxBytes)
-	if code != 0 {
-		return code
-	}
-	sch, code := composedSchemaFor(f, res, kindName)
-	if code != 0 {
-		return code
-	}
-
-	docFM, code := decodeDocFrontMatter(cfg, source, path)
-	if code != 0 {
-		return code
+	data, diags, err := projectExtract(cfg, cfgPath, res, kindName, path, maxBytes)
+	if err != nil {
+		return extractErr(2, "%v", err)
 	}
-
-	mt := schema.BuildMatchTree(f, sch, docFM)
-	data, diags := extract.Extract(f, sch, mt)
 	if len(diags) > 0 {
 		formatDiagnostics(diags, "text", false)
 		return 1
@@ -101,6 +89,31 @@ func runExtract(args []string) int {
 	return emit(extractStdout, fmtEnum, data)
 }
 
+// projectExtract loads a file that already passed the check gate and
+// projects it onto its kind's composed schema. Diagnostics are
+// projection conflicts (the file conforms but cannot be mapped
+// losslessly); an error is a read, parse, or schema failure.
+func projectExtract(
+	cfg *config.Config, cfgPath string, res *config.FileResolution,
+	kindName, path string, maxBytes int64,
+) (any, []lint.Diagnostic, error) {
+	f, source, err := loadExtractFile(cfg, cfgPath, path, maxBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	sch, err := composedSchemaFor(f, res, kindName)
+	if err != nil {
+		return nil, nil, err
+	}
+	docFM, err := decodeDocFrontMatter(cfg, source, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	mt := schema.BuildMatchTree(f, sch, docFM)
+	data, diags := extract.Extract(f, sch, mt)
+	return data, diags, nil
+}
+
 // emit encodes data and writes it. Split out so its encode-error
 // and write-error returns are unit-testable directly (the real
 // projection always yields encodable data, and os.Stdout does not
@@ -157,14 +170,14 @@ func parseExtractArgs(
 func validateExtractKind(
 	cfg *config.Config, res *config.FileResolution,
 	kindName, path string,
-) int {
+) error {
 	if _, declared := cfg.Kinds[kindName]; !declared {
-		return extractErr(2, "unknown kind %q", kindName)
+		return fmt.Errorf("unknown kind %q", kindName)
 	}
 	if !kindAssigned(res.Kinds, kindName) {
-		return extractErr(2, "kind %q is not assigned to %s", kindName, path)
+		return fmt.Errorf("kind %q is not assigned to %s", kindName, path)
 	}
-	return 0
+	return nil
 }
 
 // gateExtractCheck runs the full check on the file and mirrors its
@@ -173,15 +186,22 @@ func validateExtractKind(
 func gateExtractCheck(
 	cfg *config.Config, cfgPath, path string, maxBytes int64,
 ) int {
+	return gateResultCode(runExtractGate(cfg, cfgPath, path, maxBytes))
+}
+
+// runExtractGate runs the full check extraction is gated on.
+func runExtractGate(
+	cfg *config.Config, cfgPath, path string, maxBytes int64,
+) *engine.Result {
 	runner := &engine.Runner{
 		Config:           cfg,
-		Rules:            rule.All(),
+		Rules:            configuredRules(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		RootDir:          rootDirFromConfig(cfgPath),
 		MaxInputBytes:    maxBytes,
 		ConfigPath:       cfgPath,
 	}
-	return gateResultCode(extractGateRun(runner, path))
+	return extractGateRun(runner, path)
 }
 
 // gateResultCode maps a check Result to extract's exit code,
@@ -207,31 +227,33 @@ func gateResultCode(result *engine.Result) int {
 		// Result. Projecting then would emit data for a file
 		// MDS020 never validated, breaking the gated-on-a-match
 		// contract.
-		return extractErr(2,
-			"file was not checked (excluded by ignore patterns?); "+
-				"cannot extract without a schema match")
+		return extractErr(2, "%v", errExtractUnchecked)
 	}
 	return 0
 }
 
+// errExtractUnchecked reports a gate run that skipped the file.
+var errExtractUnchecked = errors.New("file was not checked (excluded by ignore patterns?); " +
+	"cannot extract without a schema match")
+
 // loadExtractFile reads and parses the document the same way the
 // engine does so the match tree's line math lines up.
 func loadExtractFile(
 	cfg *config.Config, cfgPath, path string, maxBytes int64,
-) (*lint.File, []byte, int) {
+) (*lint.File, []byte, error) {
 	source, err := extractReadFile(path, maxBytes)
 	if err != nil {
-		return nil, nil, extractErr(2, "reading %s: %v", path, err)
+		return nil, nil, fmt.Errorf("reading %s: %w", path, err)
 	}
 	f, err := extractNewFile(path, source, frontMatterEnabled(cfg))
 	if err != nil {
-		return nil, nil, extractErr(2, "parsing %s: %v", path, err)
+		return nil, nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
 	f.MaxInputBytes = maxBytes
 	if rd := rootDirFromConfig(cfgPath); rd != "" {
 		f.SetRootDir(rd)
 	}
-	return f, source, 0
+	return f, source, nil
 }
 
 // decodeDocFrontMatter returns the document's decoded front-matter
@@ -242,19 +264,19 @@ func loadExtractFile(
 // hard error rather than a silently-empty object.
 func decodeDocFrontMatter(
 	cfg *config.Config, source []byte, path string,
-) (map[string]any, int) {
+) (map[string]any, error) {
 	if !frontMatterEnabled(cfg) {
-		return nil, 0
+		return nil, nil
 	}
 	prefix, _ := lint.StripFrontMatter(source)
 	if len(prefix) == 0 {
-		return nil, 0
+		return nil, nil
 	}
 	fm, err := lint.ParseFrontMatterFields(prefix)
 	if err != nil {
-		return nil, extractErr(2, "parsing front matter in %s: %v", path, err)
+		return nil, fmt.Errorf("parsing front matter in %s: %w", path, err)
 	}
-	return fm, 0
+	return fm, nil
 }
 
 // composedSchemaFor builds the same composed schema MDS020
@@ -262,32 +284,30 @@ func decodeDocFrontMatter(
 // config.
 func composedSchemaFor(
 	f *lint.File, res *config.FileResolution, kindName string,
-) (*schema.Schema, int) {
+) (*schema.Schema, error) {
 	rr, ok := res.Rules["required-structure"]
 	if !ok || !rr.Final.Enabled {
 		// gateExtractCheck runs the normal engine, which skips
 		// MDS020 when the rule is disabled. Projecting then would
 		// emit data for a never-validated file, breaking the
 		// "gated on a successful match" contract. Refuse instead.
-		return nil, extractErr(2,
-			"required-structure is disabled for %s; "+
-				"nothing to validate or extract against", f.Path)
+		return nil, fmt.Errorf("required-structure is disabled for %s; "+
+			"nothing to validate or extract against", f.Path)
 	}
 	rsRule := &requiredstructure.Rule{}
 	if rr.Final.Settings != nil {
 		if err := rsRule.ApplySettings(rr.Final.Settings); err != nil {
-			return nil, extractErr(2, "loading schema config: %v", err)
+			return nil, fmt.Errorf("loading schema config: %w", err)
 		}
 	}
 	sch, err := rsRule.ComposedSchema(f)
 	if err != nil {
-		return nil, extractErr(2, "%v", err)
+		return nil, err
 	}
 	if sch == nil || sch.IsEmpty() {
-		return nil, extractErr(2,
-			"kind %q declares no schema to extract against", kindName)
+		return nil, fmt.Errorf("kind %q declares no schema to extract against", kindName)
 	}
-	return sch, 0
+	return sch, nil
 }
 
 // kindAssigned reports whether name is one of the file's resolved
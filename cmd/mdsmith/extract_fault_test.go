@@ -72,8 +72,8 @@ func TestLoadExtractFile_ReadAndParseErrors(t *testing.T) {
 	extractReadFile = func(string, int64) ([]byte, error) {
 		return nil, errors.New("no such file")
 	}
-	_, _, code := loadExtractFile(cfg, "", "p.md", 1)
-	assert.Equal(t, 2, code)
+	_, _, err := loadExtractFile(cfg, "", "p.md", 1)
+	assert.ErrorContains(t, err, "reading p.md: no such file")
 	extractReadFile = origRead
 
 	// Read succeeds (seam returns bytes) so the parse branch is
@@ -85,34 +85,34 @@ func TestLoadExtractFile_ReadAndParseErrors(t *testing.T) {
 	extractNewFile = func(string, []byte, bool) (*lint.File, error) {
 		return nil, errors.New("parse fail")
 	}
-	_, _, code = loadExtractFile(cfg, "", "p.md", 1)
-	assert.Equal(t, 2, code)
+	_, _, err = loadExtractFile(cfg, "", "p.md", 1)
+	assert.ErrorContains(t, err, "parsing p.md: parse fail")
 	extractNewFile = origNew
 	extractReadFile = origRead
 }
 
 func TestDecodeDocFrontMatter(t *testing.T) {
 	disabled := false
-	fm, code := decodeDocFrontMatter(
+	fm, err := decodeDocFrontMatter(
 		&config.Config{FrontMatter: &disabled}, []byte("anything"), "p.md")
-	assert.Equal(t, 0, code)
+	assert.NoError(t, err)
 	assert.Nil(t, fm)
 
 	// Enabled, no front matter block.
-	fm, code = decodeDocFrontMatter(&config.Config{}, []byte("# T\n"), "p.md")
-	assert.Equal(t, 0, code)
+	fm, err = decodeDocFrontMatter(&config.Config{}, []byte("# T\n"), "p.md")
+	assert.NoError(t, err)
 	assert.Nil(t, fm)
 
 	// Enabled, valid mapping front matter.
-	fm, code = decodeDocFrontMatter(&config.Config{},
+	fm, err = decodeDocFrontMatter(&config.Config{},
 		[]byte("---\nid: x\n---\n# T\n"), "p.md")
-	assert.Equal(t, 0, code)
+	assert.NoError(t, err)
 	assert.Equal(t, map[string]any{"id": "x"}, fm)
 
 	// Enabled, non-mapping front matter is a hard error.
-	_, code = decodeDocFrontMatter(&config.Config{},
+	_, err = decodeDocFrontMatter(&config.Config{},
 		[]byte("---\n- a\n- b\n---\n# T\n"), "p.md")
-	assert.Equal(t, 2, code)
+	assert.ErrorContains(t, err, "parsing front matter in p.md")
 }
 
 func TestComposedSchemaFor_ApplySettingsAndComposeErrors(t *testing.T) {
@@ -133,8 +133,8 @@ func TestComposedSchemaFor_ApplySettingsAndComposeErrors(t *testing.T) {
 			}},
 		},
 	}
-	_, code := composedSchemaFor(f, badApply, "k")
-	assert.Equal(t, 2, code)
+	_, err = composedSchemaFor(f, badApply, "k")
+	assert.ErrorContains(t, err, "loading schema config")
 
 	// A schema-sources file that cannot be loaded makes
 	// ComposedSchema return an error.
@@ -150,6 +150,6 @@ func TestComposedSchemaFor_ApplySettingsAndComposeErrors(t *testing.T) {
 			}},
 		},
 	}
-	_, code = composedSchemaFor(f, missing, "k")
-	assert.Equal(t, 2, code)
+	_, err = composedSchemaFor(f, missing, "k")
+	assert.Error(t, err)
 }
@@ -272,16 +272,16 @@ func TestComposedSchemaFor_RefusesWhenRuleDisabled(t *testing.T) {
 	f, err := lint.NewFile("doc.md", []byte("# T\n"))
 	require.NoError(t, err)
 
-	_, code := composedSchemaFor(f, &config.FileResolution{}, "k")
-	assert.Equal(t, 2, code)
+	_, err = composedSchemaFor(f, &config.FileResolution{}, "k")
+	assert.ErrorContains(t, err, "required-structure is disabled")
 
 	res := &config.FileResolution{
 		Rules: map[string]config.RuleResolution{
 			"required-structure": {Final: config.RuleCfg{Enabled: false}},
 		},
 	}
-	_, code = composedSchemaFor(f, res, "k")
-	assert.Equal(t, 2, code)
+	_, err = composedSchemaFor(f, res, "k")
+	assert.ErrorContains(t, err, "required-structure is disabled")
 }
 
 func TestKindAssigned(t *testing.T) {
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/split"
+)
+
+// extractSectionOptions bundles the parsed CLI flags for
+// `extract-section`.
+type extractSectionOptions struct {
+	rename renameOptions
+	name   string
+}
+
+// parseExtractSectionFlags parses `mdsmith extract-section` flags and
+// returns the options plus the remaining positional arguments.
+func parseExtractSectionFlags(args []string) (extractSectionOptions, []string, error) {
+	fs := flag.NewFlagSet("extract-section", flag.ContinueOnError)
+	var (
+		opts                        extractSectionOptions
+		noGitignore, followSymlinks bool
+	)
+	fs.StringVar(&opts.name, "name", "", "File name for the section (default: <heading-slug>.md)")
+	addSectionFlags(fs, &opts.rename, &noGitignore, &followSymlinks)
+
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith extract-section [flags] <file> <heading>\n\n"+
+			"Move the section under <heading> into its own file next to <file>,\n"+
+			"replace it with an <?include?> of that file, and rewrite every\n"+
+			"workspace link into the moved section.\n\n"+
+			"  mdsmith extract-section docs/guide.md \"Setup\"\n"+
+			"  mdsmith extract-section --name install.md docs/guide.md \"Setup\"\n\n"+
+			"Exit codes: 0 extracted, 1 no such heading, 2 error or conflict\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	opts.rename.walk = walkCLI{
+		noGitignore:    noGitignore,
+		followSymlinks: followSymlinksOverride(fs, followSymlinks),
+	}
+	return opts, fs.Args(), nil
+}
+
+// runExtractSection implements the "extract-section" subcommand.
+func runExtractSection(args []string) int {
+	opts, posArgs, err := parseExtractSectionFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: extract-section"); code >= 0 {
+			return code
+		}
+	}
+	if len(posArgs) != 2 {
+		fmt.Fprint(os.Stderr, "mdsmith: extract-section requires <file> <heading>\n")
+		return 2
+	}
+	if code := checkSummaryFormat(opts.rename.format); code >= 0 {
+		return code
+	}
+	target := normalizeWorkspacePath(posArgs[0])
+	if !isWorkspaceRelativeTarget(target) {
+		fmt.Fprintf(os.Stderr, "mdsmith: target %q must be workspace-relative\n", target)
+		return 2
+	}
+
+	ws, src, code := buildRenameWorkspace(opts.rename, target)
+	if code >= 0 {
+		return code
+	}
+	line, ok := rename.FindHeadingLine(src, posArgs[1])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "mdsmith: no heading %q in %s\n", posArgs[1], target)
+		return 1
+	}
+	res, err := split.Extract(ws, target, line, opts.name)
+	if errors.Is(err, split.ErrNotSection) {
+		fmt.Fprintf(os.Stderr, "mdsmith: heading %q in %s is not a document-level section\n", posArgs[1], target)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	sections := []split.Section{res.Section}
+	if code := checkSectionTargets(ws, sections); code >= 0 {
+		return code
+	}
+	return writeSections(os.Stdout, ws, sectionWrite{
+		indexKey: res.IndexKey, index: res.Index, sections: sections, changes: res.Changes,
+	}, opts.rename)
+}
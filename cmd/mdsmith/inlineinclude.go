@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/split"
+)
+
+// parseInlineIncludeFlags parses `mdsmith inline-include` flags and
+// returns the options plus the remaining positional arguments.
+func parseInlineIncludeFlags(args []string) (renameOptions, []string, error) {
+	fs := flag.NewFlagSet("inline-include", flag.ContinueOnError)
+	var (
+		opts                        renameOptions
+		noGitignore, followSymlinks bool
+	)
+	addSectionFlags(fs, &opts, &noGitignore, &followSymlinks)
+
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith inline-include [flags] <file> <included>\n\n"+
+			"Replace the <?include?> of <included> in <file> with its generated\n"+
+			"body, drop the markers, and retarget every workspace link into the\n"+
+			"included file's headings at <file>. <included> is the directive's\n"+
+			"file parameter; the included file itself is left in place.\n\n"+
+			"  mdsmith inline-include docs/guide.md setup.md\n\n"+
+			"Exit codes: 0 inlined, 1 no such include, 2 error\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	opts.walk = walkCLI{
+		noGitignore:    noGitignore,
+		followSymlinks: followSymlinksOverride(fs, followSymlinks),
+	}
+	return opts, fs.Args(), nil
+}
+
+// runInlineInclude implements the "inline-include" subcommand.
+func runInlineInclude(args []string) int {
+	opts, posArgs, err := parseInlineIncludeFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: inline-include"); code >= 0 {
+			return code
+		}
+	}
+	if len(posArgs) != 2 {
+		fmt.Fprint(os.Stderr, "mdsmith: inline-include requires <file> <included>\n")
+		return 2
+	}
+	if code := checkSummaryFormat(opts.format); code >= 0 {
+		return code
+	}
+	target := normalizeWorkspacePath(posArgs[0])
+	if !isWorkspaceRelativeTarget(target) {
+		fmt.Fprintf(os.Stderr, "mdsmith: target %q must be workspace-relative\n", target)
+		return 2
+	}
+
+	ws, src, code := buildRenameWorkspace(opts, target)
+	if code >= 0 {
+		return code
+	}
+	line, ok := split.FindIncludeLine(target, src, posArgs[1])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "mdsmith: no include of %q in %s\n", posArgs[1], target)
+		return 1
+	}
+	res, err := split.Inline(ws, target, line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	return writeSections(os.Stdout, ws, sectionWrite{
+		indexKey: res.IndexKey, index: res.Index, changes: res.Changes, inlined: res.Included,
+	}, opts)
+}
@@ -289,41 +289,39 @@ func resolveFileFromCLI(path string) (*config.FileResolution, *config.Config, in
 	if code != 0 {
 		return nil, nil, code
 	}
+	res, err := resolveFileKinds(cfg, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil, nil, 2
+	}
+	return res, cfg, 0
+}
 
+// resolveFileKinds reads path's front matter and resolves its kinds
+// and merged rule config under cfg.
+func resolveFileKinds(cfg *config.Config, path string) (*config.FileResolution, error) {
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		return nil, err
+	}
 	var fmKinds []string
 	var fmFields map[string]any
 	if frontMatterEnabled(cfg) {
-		maxBytes, err := resolveMaxInputBytes(cfg, "")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-			return nil, nil, 2
-		}
 		fmKinds, fmFields, err = readFrontMatter(cfg, path, maxBytes)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: reading %s: %v\n", path, err)
-			return nil, nil, 2
+			return nil, fmt.Errorf("reading %s: %w", path, err)
 		}
 		if err := config.ValidateFrontMatterKinds(cfg, path, fmKinds); err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-			return nil, nil, 2
+			return nil, err
 		}
-	} else {
+	} else if _, err := lint.ReadFileLimited(path, maxBytes); err != nil {
 		// front-matter disabled: no kinds from front matter, but still
 		// attempt an open/read to mirror the engine's readability and
 		// max-input-size checks (os.Stat passes on directories and
 		// unreadable paths).
-		maxBytes, err := resolveMaxInputBytes(cfg, "")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-			return nil, nil, 2
-		}
-		if _, err := lint.ReadFileLimited(path, maxBytes); err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: reading %s: %v\n", path, err)
-			return nil, nil, 2
-		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
 	}
-
-	return config.ResolveFile(cfg, path, fmKinds, fmFields), cfg, 0
+	return config.ResolveFile(cfg, path, fmKinds, fmFields), nil
 }
 
 // runKindsResolve prints the resolved kind list and merged rule config
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/linkcheck"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+const linksUsage = `Usage: mdsmith links <subcommand> [flags] [files...]
+
+Subcommands:
+  check-external   Check external http(s) URLs and cache the results.
+
+Run 'mdsmith links <subcommand> --help' for its flags.
+`
+
+// runLinks dispatches the links subcommand.
+func runLinks(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, linksUsage)
+		return 0
+	}
+	switch args[0] {
+	case "--help", "-h":
+		fmt.Fprint(os.Stderr, linksUsage)
+		return 0
+	case "check-external":
+		return runLinksCheckExternal(os.Stdout, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr,
+			"mdsmith: links: unknown subcommand %q\n\n%s",
+			args[0], linksUsage)
+		return 2
+	}
+}
+
+// checkExternalOptions bundles the parsed CLI flags for
+// `links check-external`.
+type checkExternalOptions struct {
+	configPath   string
+	format       string
+	cachePath    string
+	ttl          time.Duration
+	offline      bool
+	exclude      []string
+	maxInputSize string
+	checker      linkcheck.Checker
+	walk         walkCLI
+}
+
+func parseCheckExternalFlags(args []string) (checkExternalOptions, []string, error) {
+	fs := flag.NewFlagSet("links check-external", flag.ContinueOnError)
+	var (
+		opts                        checkExternalOptions
+		noGitignore, followSymlinks bool
+	)
+	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
+	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json")
+	fs.StringVar(&opts.cachePath, "cache", linkcheck.DefaultCachePath,
+		"Result cache file, relative to the project root")
+	fs.DurationVar(&opts.ttl, "ttl", 24*time.Hour, "Re-check cached results older than this (0 = never)")
+	fs.BoolVar(&opts.offline, "offline", false, "Use cached results of any age and make no requests")
+	fs.StringArrayVar(&opts.exclude, "exclude", nil, "Skip URLs matching this regular expression (repeatable)")
+	fs.IntVar(&opts.checker.Concurrency, "concurrency", linkcheck.DefaultConcurrency, "Pages fetched at once")
+	fs.DurationVar(&opts.checker.HostInterval, "host-interval", linkcheck.DefaultHostInterval,
+		"Minimum time between requests to one host")
+	fs.IntVar(&opts.checker.Retries, "retries", linkcheck.DefaultRetries,
+		"Retries after a network error, 429, or 5xx")
+	fs.DurationVar(&opts.checker.Backoff, "backoff", linkcheck.DefaultBackoff,
+		"Wait before the first retry; doubles for each further one")
+	fs.DurationVar(&opts.checker.Timeout, "timeout", linkcheck.DefaultTimeout, "Per-request timeout")
+	fs.StringVar(&opts.checker.BaseURL, "base-url", "",
+		"Send every request to this base URL, keeping the original host in the Host header")
+	fs.BoolVar(&noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
+	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
+		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
+			"=false forces skip over any config opt-in")
+	fs.StringVar(&opts.maxInputSize, "max-input-size", "",
+		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith links check-external [flags] [files...]\n\n"+
+			"Check the http and https URLs of links, images, autolinks, reference\n"+
+			"definitions, and inline HTML. A URL with a #fragment also needs an\n"+
+			"element with that id on the fetched page. Results go to a cache file\n"+
+			"that later runs, --offline runs, and the external-links rule read.\n"+
+			"With no file arguments, discovers files from the config.\n\n"+
+			"Exit codes: 0 all reachable, 1 broken links, 2 error\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	if opts.checker.Concurrency < 1 {
+		return opts, nil, fmt.Errorf("--concurrency must be >= 1")
+	}
+	opts.checker.UserAgent = "mdsmith/" + versionString()
+	opts.walk = walkCLI{
+		noGitignore:    noGitignore,
+		followSymlinks: followSymlinksOverride(fs, followSymlinks),
+	}
+	return opts, fs.Args(), nil
+}
+
+// externalLink is one occurrence of an external URL, with its result.
+type externalLink struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	URL    string `json:"url"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// runLinksCheckExternal implements `mdsmith links check-external`.
+func runLinksCheckExternal(w io.Writer, args []string) int {
+	opts, paths, err := parseCheckExternalFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: links check-external"); code >= 0 {
+			return code
+		}
+	}
+	if opts.format != "text" && opts.format != "json" {
+		fmt.Fprintf(os.Stderr, "mdsmith: unknown --format %q (want text or json)\n", opts.format)
+		return 2
+	}
+	links, cachePath, err := collectExternalLinks(opts, paths)
+	if err != nil {
+		return printErr(err)
+	}
+	results, err := checkExternal(opts, cachePath, links)
+	if err != nil {
+		return printErr(err)
+	}
+	var broken []externalLink
+	for _, l := range links {
+		if r, ok := results[l.URL]; ok && !r.OK() {
+			l.Status, l.Error = r.Status, r.Reason()
+			broken = append(broken, l)
+		}
+	}
+	if err := writeExternalLinks(w, broken, opts.format); err != nil {
+		return printErr(err)
+	}
+	fmt.Fprintf(os.Stderr, "links: %d URLs checked, %d broken\n", len(results), len(broken))
+	if len(broken) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// collectExternalLinks returns the http and https URLs of the files,
+// in file and source order, and the absolute cache path.
+func collectExternalLinks(opts checkExternalOptions, paths []string) ([]externalLink, string, error) {
+	cfg, cfgPath, err := loadConfig(opts.configPath)
+	if err != nil {
+		return nil, "", err
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, opts.maxInputSize)
+	if err != nil {
+		return nil, "", err
+	}
+	excludes := make([]*regexp.Regexp, 0, len(opts.exclude))
+	for _, p := range opts.exclude {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid --exclude %q: %w", p, err)
+		}
+		excludes = append(excludes, re)
+	}
+	var files []string
+	if len(paths) == 0 {
+		files, err = discoverConfigFiles(cfg, opts.walk)
+	} else {
+		files, err = lint.ResolveFilesWithOpts(paths, resolveOpts(cfg, opts.walk))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	var links []externalLink
+	for _, path := range files {
+		src, err := lint.ReadFileLimited(path, maxBytes)
+		if err != nil {
+			return nil, "", err
+		}
+		f, err := lint.NewFileFromSource(path, src, true)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, ref := range linkgraph.CollectURLs(f) {
+			if isExternalURL(ref.URL) && !matchesAny(excludes, ref.URL) {
+				links = append(links, externalLink{
+					File:   path,
+					Line:   f.LineOfOffset(ref.Start) + f.LineOffset,
+					Column: f.ColumnOfOffset(ref.Start),
+					URL:    ref.URL,
+				})
+			}
+		}
+	}
+	cachePath := opts.cachePath
+	if !filepath.IsAbs(cachePath) {
+		cachePath = filepath.Join(rootDirFromConfig(cfgPath), cachePath)
+	}
+	return links, cachePath, nil
+}
+
+// isExternalURL reports whether u is an http or https URL.
+func isExternalURL(u string) bool {
+	lower := strings.ToLower(u)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkExternal returns a result for every distinct URL of links. Fresh
+// cached results are reused; the rest are fetched and written back to
+// the cache. Offline, cached results of any age are used, and URLs
+// without one are left out and counted on stderr.
+func checkExternal(
+	opts checkExternalOptions, cachePath string, links []externalLink,
+) (map[string]linkcheck.Result, error) {
+	cache, err := linkcheck.LoadCache(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	ttl := opts.ttl
+	if opts.offline {
+		ttl = 0
+	}
+	now := time.Now()
+	results := map[string]linkcheck.Result{}
+	seen := map[string]bool{}
+	var pending []string
+	for _, l := range links {
+		if seen[l.URL] {
+			continue
+		}
+		seen[l.URL] = true
+		if r, ok := cache.Lookup(l.URL, ttl, now); ok {
+			results[l.URL] = r
+		} else {
+			pending = append(pending, l.URL)
+		}
+	}
+	if opts.offline {
+		if len(pending) > 0 {
+			fmt.Fprintf(os.Stderr, "mdsmith: %d URLs not in the cache, skipped (offline)\n", len(pending))
+		}
+		return results, nil
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+	sort.Strings(pending)
+	checked, err := opts.checker.Check(context.Background(), pending)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range checked {
+		results[r.URL] = r
+		cache.Store(r)
+	}
+	return results, cache.Save(cachePath)
+}
+
+func writeExternalLinks(w io.Writer, links []externalLink, format string) error {
+	if format == "json" {
+		if links == nil {
+			links = []externalLink{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(links)
+	}
+	for _, l := range links {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s\n", l.File, l.Line, l.Column, l.URL, l.Error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ Subcommands:
                         satisfies a CUE expression.
   backlinks <target>    List workspace links that point at a file
                         (optionally scoped to an anchor).
+  orphans               List files that no entry point reaches.
 
 Run 'mdsmith list <subcommand> --help' for flags and exit codes.
 `
@@ -33,6 +34,8 @@ func runList(args []string) int {
 		return runQuery(args[1:])
 	case "backlinks":
 		return runBacklinks(args[1:])
+	case "orphans":
+		return runOrphans(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr,
 			"mdsmith: list: unknown subcommand %q\n\n%s",
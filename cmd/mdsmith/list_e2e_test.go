@@ -12,6 +12,7 @@ func TestE2E_List_NoArgs_PrintsUsage(t *testing.T) {
 	assert.Contains(t, stderr, "Usage: mdsmith list <subcommand>")
 	assert.Contains(t, stderr, "query")
 	assert.Contains(t, stderr, "backlinks")
+	assert.Contains(t, stderr, "orphans")
 }
 
 func TestE2E_List_HelpFlag(t *testing.T) {
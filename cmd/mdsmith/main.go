@@ -22,7 +22,6 @@ import (
 	"github.com/jeduden/mdsmith/internal/output"
 	"github.com/jeduden/mdsmith/internal/profiling"
 	"github.com/jeduden/mdsmith/internal/query"
-	"github.com/jeduden/mdsmith/internal/rule"
 	ruledocs "github.com/jeduden/mdsmith/internal/rules"
 	"github.com/jeduden/mdsmith/internal/yamlutil"
 
@@ -47,14 +46,21 @@ Commands:
   extract           Emit a kind-conformant file as a JSON/YAML/msgpack data tree
   list              Walk the workspace and emit matches (files or link records)
   deps              Show a file's dependency-graph edges (includes, links, …)
+  links             Check external links against the network or a cache
   rename            Rename a heading or link-ref label and rewrite dependents
+  split             Split a file into per-section files behind an index
+  extract-section   Move one section into its own file behind an include
+  inline-include    Replace an include directive with its body
   help              Show help for rules and topics
+  rules             Test rules against annotated fixture files
   metrics           Show and rank shared Markdown metrics
+  test              Run fenced code blocks marked {test} and check their output
   merge-driver      Git merge driver for regenerable sections
   pre-merge-commit  Install/manage pre-merge-commit hook
   kinds             Inspect declared kinds and resolve effective config per file
   init              Generate a default .mdsmith.yml config file
   lsp               Run the Language Server Protocol server on stdio
+  mcp               Run the Model Context Protocol server on stdio
   version           Print version and exit
 
 Global flags:
@@ -76,6 +82,7 @@ func run() int {
 	// MDSMITH_MEMPROFILE are set, profile the real end-to-end path
 	// so a tripped performance gate can be traced to a function.
 	defer profiling.Start()()
+	defer pluginPool.Close()
 
 	// No arguments or a global help flag: print usage and exit 0.
 	if len(os.Args) < 2 || os.Args[1] == "--help" || os.Args[1] == "-h" {
@@ -103,12 +110,32 @@ func dispatch(first string, args []string) int {
 		return runList(args)
 	case "deps":
 		return runDeps(args)
+	case "links":
+		return runLinks(args)
 	case "rename":
 		return runRename(args)
+	case "split":
+		return runSplit(args)
+	case "extract-section":
+		return runExtractSection(args)
+	case "inline-include":
+		return runInlineInclude(args)
 	case "help":
 		return runHelp(args)
+	case "rules":
+		return runRules(args)
 	case "metrics":
 		return runMetrics(args)
+	case "test":
+		return runTest(args)
+	}
+	return dispatchTooling(first, args)
+}
+
+// dispatchTooling routes the git-integration, setup, and server
+// subcommands; dispatch falls through to it.
+func dispatchTooling(first string, args []string) int {
+	switch first {
 	case "merge-driver":
 		return runMergeDriver(args)
 	case "pre-merge-commit":
@@ -119,6 +146,8 @@ func dispatch(first string, args []string) int {
 		return runInit(args)
 	case "lsp":
 		return runLSP(args)
+	case "mcp":
+		return runMCP(args)
 	case "version":
 		printVersion()
 		return 0
@@ -132,14 +161,19 @@ func dispatch(first string, args []string) int {
 var version string
 
 func printVersion() {
-	v := version
-	if v == "" {
-		v = "(devel)"
-		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
-			v = info.Main.Version
-		}
+	fmt.Printf("mdsmith %s\n", versionString())
+}
+
+// versionString is the ldflags version, else the module version from
+// the build info, else "(devel)".
+func versionString() string {
+	if version != "" {
+		return version
 	}
-	fmt.Printf("mdsmith %s\n", v)
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
 }
 
 // runCheck implements the "check" subcommand: lint files.
@@ -360,26 +394,39 @@ func runQuery(args []string) int {
 // queryFiles tests each file against matcher and writes matching paths
 // to stdout. Returns the number of matches.
 func queryFiles(matcher *query.Matcher, files []string, delim string, verbose bool, maxBytes int64) int {
-	matched := 0
+	var skip func(string, string)
+	if verbose {
+		skip = func(f, reason string) { fmt.Fprintf(os.Stderr, "skip %s: %s\n", f, reason) }
+	}
+	matched := matchQuery(matcher, files, maxBytes, skip)
+	for _, f := range matched {
+		_, _ = fmt.Fprintf(os.Stdout, "%s%s", f, delim)
+	}
+	return len(matched)
+}
+
+// matchQuery returns the files whose front matter satisfies matcher,
+// in input order. skip, when non-nil, is told why each other file
+// was passed over.
+func matchQuery(matcher *query.Matcher, files []string, maxBytes int64, skip func(file, reason string)) []string {
+	if skip == nil {
+		skip = func(string, string) {}
+	}
+	var matched []string
 	for _, f := range files {
 		fm, err := readFrontMatterRaw(f, maxBytes)
 		if err != nil {
-			if verbose {
-				fmt.Fprintf(os.Stderr, "skip %s: %v\n", f, err)
-			}
+			skip(f, err.Error())
 			continue
 		}
 		if fm == nil {
-			if verbose {
-				fmt.Fprintf(os.Stderr, "skip %s: no front matter\n", f)
-			}
+			skip(f, "no front matter")
 			continue
 		}
 		if matcher.Match(fm) {
-			_, _ = fmt.Fprintf(os.Stdout, "%s%s", f, delim)
-			matched++
-		} else if verbose {
-			fmt.Fprintf(os.Stderr, "skip %s: expression not satisfied\n", f)
+			matched = append(matched, f)
+		} else {
+			skip(f, "expression not satisfied")
 		}
 	}
 	return matched
@@ -527,7 +574,7 @@ func checkFiles(
 
 	runner := &engine.Runner{
 		Config:           cfg,
-		Rules:            rule.All(),
+		Rules:            configuredRules(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		Logger:           logger,
 		RootDir:          rootDirFromConfig(cfgPath),
@@ -575,12 +622,16 @@ func fixFiles(
 
 	fixer := &fixpkg.Fixer{
 		Config:           cfg,
-		Rules:            rule.All(),
+		Rules:            configuredRules(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		Logger:           logger,
 		RootDir:          rootDirFromConfig(cfgPath),
 		MaxInputBytes:    maxBytes,
 		Explain:          explain,
+		Headings: &fixHeadingWorkspace{
+			configPath: configPath, walk: walk, maxInputSize: maxInputSize,
+			rootDir: rootDirFromConfig(cfgPath),
+		},
 	}
 	fixResult := fixer.Fix(files)
 	printErrors(fixResult.Errors)
@@ -654,7 +705,7 @@ func checkStdin(format string, noColor, quiet, verbose bool, configPath, maxInpu
 
 	runner := &engine.Runner{
 		Config:           cfg,
-		Rules:            rule.All(),
+		Rules:            configuredRules(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		Logger:           logger,
 		RootDir:          rootDirFromConfig(cfgPath),
@@ -756,23 +807,32 @@ func discoverFiles(
 	if cfgPath != "" {
 		logger.Printf("config: %s", cfgPath)
 	}
-	if len(cfg.Files) == 0 {
+	files, err := discoverConfigFiles(cfg, walk)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil, "", nil, nil, 2
+	}
+	if len(files) == 0 {
 		return nil, "", nil, nil, 0
 	}
+	return cfg, cfgPath, logger, files, -1
+}
 
+// discoverConfigFiles walks the config's `files:` patterns. An empty
+// pattern list discovers nothing.
+func discoverConfigFiles(cfg *config.Config, walk walkCLI) ([]string, error) {
+	if len(cfg.Files) == 0 {
+		return nil, nil
+	}
 	files, err := discovery.Discover(discovery.Options{
 		Patterns:       cfg.Files,
 		UseGitignore:   !walk.noGitignore,
 		FollowSymlinks: resolveOpts(cfg, walk).FollowSymlinks,
 	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "mdsmith: discovering files: %v\n", err)
-		return nil, "", nil, nil, 2
+		return nil, fmt.Errorf("discovering files: %w", err)
 	}
-	if len(files) == 0 {
-		return nil, "", nil, nil, 0
-	}
-	return cfg, cfgPath, logger, files, -1
+	return files, nil
 }
 
 // checkDiscovered loads config, discovers files from config patterns,
@@ -795,7 +855,7 @@ func checkDiscovered(
 
 	runner := &engine.Runner{
 		Config:           cfg,
-		Rules:            rule.All(),
+		Rules:            configuredRules(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		Logger:           logger,
 		RootDir:          rootDirFromConfig(cfgPath),
@@ -848,12 +908,16 @@ func fixDiscovered(
 
 	fixer := &fixpkg.Fixer{
 		Config:           cfg,
-		Rules:            rule.All(),
+		Rules:            configuredRules(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		Logger:           logger,
 		RootDir:          rootDirFromConfig(cfgPath),
 		MaxInputBytes:    maxBytes,
 		Explain:          explain,
+		Headings: &fixHeadingWorkspace{
+			configPath: configPath, walk: walk, maxInputSize: maxInputSize,
+			rootDir: rootDirFromConfig(cfgPath),
+		},
 	}
 	fixResult := fixer.Fix(files)
 	printErrors(fixResult.Errors)
@@ -1283,7 +1347,7 @@ func listAllRules() int {
 		return 2
 	}
 
-	for _, r := range rules {
+	for _, r := range append(rules, customRuleInfos()...) {
 		fmt.Printf("%-6s %-40s %-10s %s\n", r.ID, r.Name, r.Status, r.Description)
 	}
 	return 0
@@ -1292,8 +1356,12 @@ func listAllRules() int {
 func showRule(query string) int {
 	info, err := ruledocs.LookupRuleInfo(query)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-		return 2
+		custom, ok := lookupCustomRuleInfo(query)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+			return 2
+		}
+		info = custom
 	}
 	content := ruledocs.StripFrontMatter(info.Content)
 	if m := info.Maintainability; m != nil {
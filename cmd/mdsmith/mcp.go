@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/mcp"
+)
+
+// runMCP implements the "mcp" subcommand: serve mdsmith's tools to a
+// coding agent over the Model Context Protocol on stdio. The protocol
+// lives in internal/mcp; the tools are in mcp_tools.go.
+func runMCP(args []string) int {
+	return runMCPWith(args, os.Stdin, os.Stdout, os.Stderr)
+}
+
+// runMCPWith is the testable variant of runMCP.
+func runMCPWith(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("mcp", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var configPath string
+	fs.StringVarP(&configPath, "config", "c", "", "Override config file path")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: mdsmith mcp [flags]\n\n"+
+			"Run a Model Context Protocol server over stdio. Designed to\n"+
+			"be spawned by a coding agent. Exposes check, fix, list_query,\n"+
+			"deps, list_backlinks, kinds_resolve, extract, and rename as\n"+
+			"tools, and every rule README as a mdsmith://rules/<ID>\n"+
+			"resource.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if code := reportFlagParseErr(err, stderr, "mdsmith: mcp"); code >= 0 {
+			return code
+		}
+	}
+	if fs.NArg() > 0 {
+		_, _ = fmt.Fprintf(stderr, "mdsmith: mcp takes no positional arguments\n")
+		return 2
+	}
+
+	resources, err := mcp.RuleResources()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "mdsmith: mcp: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	srv := mcp.New(mcp.Options{
+		Name:      "mdsmith",
+		Version:   versionString(),
+		Tools:     mcpTools(configPath),
+		Resources: resources,
+		Reader:    stdin,
+		Writer:    stdout,
+	})
+	// SIGINT/SIGTERM cancel ctx; that is a clean shutdown.
+	if err := srv.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		_, _ = fmt.Fprintf(stderr, "mdsmith: mcp: %v\n", err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,593 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/engine"
+	fixpkg "github.com/jeduden/mdsmith/internal/fix"
+	"github.com/jeduden/mdsmith/internal/kindsout"
+	"github.com/jeduden/mdsmith/internal/lint"
+	vlog "github.com/jeduden/mdsmith/internal/log"
+	"github.com/jeduden/mdsmith/internal/mcp"
+	"github.com/jeduden/mdsmith/internal/output"
+	"github.com/jeduden/mdsmith/internal/query"
+	"github.com/jeduden/mdsmith/internal/rename"
+)
+
+// mcpToolset holds what every MCP tool shares. Each call loads the
+// config afresh, so an edit to .mdsmith.yml applies to the next call
+// without restarting the server.
+type mcpToolset struct {
+	configPath string
+}
+
+// mcpTools returns the tools `mdsmith mcp` serves. Each one runs the
+// same packages as its CLI command in-process and returns the
+// command's JSON shape wrapped in an object.
+func mcpTools(configPath string) []mcp.Tool {
+	t := mcpToolset{configPath: configPath}
+	tools := t.lintTools()
+	tools = append(tools, t.graphTools()...)
+	return append(tools, t.structureTools()...)
+}
+
+// filesSchema is the optional `files` argument of check, fix, and
+// list_query.
+var filesSchema = map[string]any{
+	"type":  "array",
+	"items": map[string]any{"type": "string"},
+	"description": "Files or directories, relative to the server's working directory. " +
+		"Omit to use the config's files: patterns.",
+}
+
+func (t mcpToolset) lintTools() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "check",
+			Description: "Lint Markdown files and return diagnostics, like `mdsmith check --format json`.",
+			InputSchema: objectSchema(map[string]any{"files": filesSchema}),
+			Call:        t.check,
+		},
+		{
+			Name: "fix",
+			Description: "Auto-fix Markdown files and return the remaining diagnostics. " +
+				"With dryRun, write nothing and return each changed file's fixed content.",
+			InputSchema: objectSchema(map[string]any{
+				"files":  filesSchema,
+				"dryRun": boolSchema("Report fixes without writing files."),
+			}),
+			Call: t.fix,
+		},
+	}
+}
+
+func (t mcpToolset) graphTools() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "list_query",
+			Description: "List files whose front matter satisfies a CUE expression, like `mdsmith list query`.",
+			InputSchema: objectSchema(map[string]any{
+				"expr": stringSchema("CUE expression matched against each file's front matter, " +
+					`e.g. 'status: "draft"'.`),
+				"files": filesSchema,
+			}, "expr"),
+			Call: t.listQuery,
+		},
+		{
+			Name: "deps",
+			Description: "List a file's outgoing (or, with incoming, inbound) links and " +
+				"directive references, like `mdsmith deps`.",
+			InputSchema: objectSchema(map[string]any{
+				"file":     stringSchema("Workspace-relative Markdown file."),
+				"incoming": boolSchema("List edges pointing at file instead of out of it."),
+			}, "file"),
+			Call: t.deps,
+		},
+		{
+			Name:        "list_backlinks",
+			Description: "List every workspace link to a file or heading, like `mdsmith list backlinks`.",
+			InputSchema: objectSchema(map[string]any{
+				"target": stringSchema("Workspace-relative file, optionally with #anchor."),
+				"include": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Only scan sources matching these globs.",
+				},
+			}, "target"),
+			Call: t.listBacklinks,
+		},
+	}
+}
+
+func (t mcpToolset) structureTools() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name: "kinds_resolve",
+			Description: "Resolve a file's kinds and merged rule config with provenance, " +
+				"like `mdsmith kinds resolve --json`.",
+			InputSchema: objectSchema(map[string]any{"file": stringSchema("Markdown file to resolve.")}, "file"),
+			Call:        t.kindsResolve,
+		},
+		{
+			Name: "extract",
+			Description: "Project a file that passes its kind's schema into structured data, " +
+				"like `mdsmith extract`. A file that fails check returns its diagnostics as an error.",
+			InputSchema: objectSchema(map[string]any{
+				"kind": stringSchema("Kind assigned to the file whose schema drives the projection."),
+				"file": stringSchema("Markdown file to extract."),
+			}, "kind", "file"),
+			Call: t.extract,
+		},
+		{
+			Name: "rename",
+			Description: "Rename a heading or link-reference label, or shift a section's heading level, " +
+				"rewriting every dependent link, like `mdsmith rename`. Writes the files.",
+			InputSchema: objectSchema(map[string]any{
+				"file": stringSchema("Workspace-relative file holding the heading or label."),
+				"mode": map[string]any{"type": "string", "enum": []string{"heading", "link-ref", "shift-level"}},
+				"old":  stringSchema("Current heading text or label."),
+				"new":  stringSchema("New heading text or label (heading and link-ref modes)."),
+				"shift": map[string]any{
+					"type":        "integer",
+					"description": "Levels to move the section; negative promotes (shift-level mode).",
+				},
+			}, "file", "mode", "old"),
+			Call: t.rename,
+		},
+	}
+}
+
+// objectSchema is the JSON Schema of an arguments object.
+func objectSchema(props map[string]any, required ...string) map[string]any {
+	s := map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func stringSchema(desc string) map[string]any {
+	return map[string]any{"type": "string", "description": desc}
+}
+
+func boolSchema(desc string) map[string]any {
+	return map[string]any{"type": "boolean", "description": desc}
+}
+
+// mcpLintResult is what check and fix return.
+type mcpLintResult struct {
+	FilesChecked int             `json:"filesChecked"`
+	Failures     int             `json:"failures,omitempty"`
+	Modified     []string        `json:"modified,omitempty"`
+	Changes      []mcpFileChange `json:"changes,omitempty"`
+	Diagnostics  json.RawMessage `json:"diagnostics"`
+	Errors       []string        `json:"errors,omitempty"`
+}
+
+// mcpFileChange is one file a dry-run fix would rewrite.
+type mcpFileChange struct {
+	File    string `json:"file"`
+	Content string `json:"content"`
+}
+
+// lintTarget is the config and file set a check or fix call runs on.
+type lintTarget struct {
+	cfg      *config.Config
+	cfgPath  string
+	files    []string
+	maxBytes int64
+}
+
+// resolveLintTarget loads the config and resolves files the way
+// check and fix do: the config's `files:` patterns when none are
+// given, else the given files and directories.
+func (t mcpToolset) resolveLintTarget(fileArgs []string) (lintTarget, error) {
+	cfg, cfgPath, err := loadConfig(t.configPath)
+	if err != nil {
+		return lintTarget{}, err
+	}
+	var files []string
+	if len(fileArgs) == 0 {
+		files, err = discoverConfigFiles(cfg, walkCLI{})
+	} else if slices.Contains(fileArgs, "-") {
+		// stdin is the protocol stream.
+		return lintTarget{}, &mcp.ToolError{Message: `"-" (stdin) is not supported over MCP`}
+	} else {
+		files, err = lint.ResolveFilesWithOpts(fileArgs, resolveOpts(cfg, walkCLI{}))
+	}
+	if err != nil {
+		return lintTarget{}, err
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		return lintTarget{}, err
+	}
+	return lintTarget{cfg: cfg, cfgPath: cfgPath, files: files, maxBytes: maxBytes}, nil
+}
+
+func (lt lintTarget) runner() *engine.Runner {
+	return &engine.Runner{
+		Config:           lt.cfg,
+		Rules:            configuredRules(lt.cfg),
+		StripFrontMatter: frontMatterEnabled(lt.cfg),
+		RootDir:          rootDirFromConfig(lt.cfgPath),
+		MaxInputBytes:    lt.maxBytes,
+		ConfigPath:       lt.cfgPath,
+	}
+}
+
+type mcpFilesArgs struct {
+	Files []string `json:"files"`
+}
+
+func (t mcpToolset) check(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpFilesArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	lt, err := t.resolveLintTarget(args.Files)
+	if err != nil {
+		return nil, err
+	}
+	res := lt.runner().Run(lt.files)
+	return lintResult(res.FilesChecked, res.Diagnostics, res.Errors)
+}
+
+type mcpFixArgs struct {
+	Files  []string `json:"files"`
+	DryRun bool     `json:"dryRun"`
+}
+
+func (t mcpToolset) fix(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpFixArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	lt, err := t.resolveLintTarget(args.Files)
+	if err != nil {
+		return nil, err
+	}
+	if args.DryRun {
+		return fixDryRun(lt)
+	}
+	fixer := &fixpkg.Fixer{
+		Config:           lt.cfg,
+		Rules:            configuredRules(lt.cfg),
+		StripFrontMatter: frontMatterEnabled(lt.cfg),
+		Logger:           &vlog.Logger{},
+		RootDir:          rootDirFromConfig(lt.cfgPath),
+		MaxInputBytes:    lt.maxBytes,
+	}
+	res := fixer.Fix(lt.files)
+	out, err := lintResult(res.FilesChecked, res.Diagnostics, res.Errors)
+	if err != nil {
+		return nil, err
+	}
+	out.Failures = res.Failures
+	out.Modified = res.Modified
+	return out, nil
+}
+
+// fixDryRun fixes each file in memory and lints the result, so the
+// reported diagnostics are the ones a real fix would leave behind.
+func fixDryRun(lt lintTarget) (*mcpLintResult, error) {
+	runner := lt.runner()
+	var (
+		changes []mcpFileChange
+		diags   []lint.Diagnostic
+		errs    []error
+		checked int
+	)
+	for _, path := range lt.files {
+		if config.IsIgnored(lt.cfg.Ignore, path) {
+			continue
+		}
+		checked++
+		src, err := lint.ReadFileLimited(path, lt.maxBytes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading %q: %w", path, err))
+			continue
+		}
+		fixed, err := fixpkg.Source(fixpkg.SourceOptions{
+			Config:           lt.cfg,
+			Rules:            configuredRules(lt.cfg),
+			Path:             path,
+			Source:           src,
+			RootDir:          rootDirFromConfig(lt.cfgPath),
+			StripFrontMatter: frontMatterEnabled(lt.cfg),
+			MaxInputBytes:    lt.maxBytes,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fixing %q: %w", path, err))
+			continue
+		}
+		if !bytes.Equal(fixed, src) {
+			changes = append(changes, mcpFileChange{File: path, Content: string(fixed)})
+		}
+		res := runner.RunSource(path, fixed)
+		diags = append(diags, res.Diagnostics...)
+		errs = append(errs, res.Errors...)
+	}
+	out, err := lintResult(checked, engine.DedupeDiagnostics(diags), errs)
+	if err != nil {
+		return nil, err
+	}
+	out.Changes = changes
+	return out, nil
+}
+
+// lintResult renders diagnostics in the `--format json` shape.
+func lintResult(checked int, diags []lint.Diagnostic, errs []error) (*mcpLintResult, error) {
+	var buf bytes.Buffer
+	if err := (&output.JSONFormatter{}).Format(&buf, diags); err != nil {
+		return nil, err
+	}
+	out := &mcpLintResult{FilesChecked: checked, Diagnostics: buf.Bytes()}
+	for _, e := range errs {
+		out.Errors = append(out.Errors, e.Error())
+	}
+	return out, nil
+}
+
+type mcpQueryArgs struct {
+	Expr  string   `json:"expr"`
+	Files []string `json:"files"`
+}
+
+func (t mcpToolset) listQuery(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpQueryArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	matcher, err := query.Compile(args.Expr)
+	if err != nil {
+		return nil, &mcp.ToolError{Message: err.Error()}
+	}
+	if len(args.Files) == 0 {
+		args.Files = []string{"."}
+	}
+	files, err := lint.ResolveFilesWithOpts(args.Files, lint.ResolveOpts{})
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := loadConfig(t.configPath)
+	if err != nil {
+		return nil, err
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	matched := matchQuery(matcher, files, maxBytes, nil)
+	if matched == nil {
+		matched = []string{}
+	}
+	return map[string]any{"files": matched}, nil
+}
+
+type mcpDepsArgs struct {
+	File     string `json:"file"`
+	Incoming bool   `json:"incoming"`
+}
+
+func (t mcpToolset) deps(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpDepsArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	target, err := workspaceTarget(args.File)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := loadWorkspace(t.configPath, walkCLI{}, "")
+	if err != nil {
+		return nil, err
+	}
+	recs := []depRecord{}
+	if ws.idx != nil {
+		recs = append(recs, collectDeps(ws.idx, target, args.Incoming)...)
+	}
+	return map[string]any{"edges": recs}, nil
+}
+
+// workspaceTarget normalizes a workspace-relative file argument.
+func workspaceTarget(file string) (string, error) {
+	target := normalizeWorkspacePath(file)
+	if file == "" || !isWorkspaceRelativeTarget(target) {
+		return "", &mcp.ToolError{Message: fmt.Sprintf("target %q must be workspace-relative", file)}
+	}
+	return target, nil
+}
+
+type mcpBacklinksArgs struct {
+	Target  string   `json:"target"`
+	Include []string `json:"include"`
+}
+
+func (t mcpToolset) listBacklinks(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpBacklinksArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	targetPath, targetAnchor, err := parseBacklinksTarget(args.Target)
+	if err != nil {
+		return nil, &mcp.ToolError{Message: err.Error()}
+	}
+	if err := validateIncludePatterns(args.Include); err != nil {
+		return nil, &mcp.ToolError{Message: err.Error()}
+	}
+	lt, err := t.resolveLintTarget(nil)
+	if err != nil {
+		return nil, err
+	}
+	records, errs := collectBacklinks(
+		lt.files, rootDirFromConfig(lt.cfgPath), normalizeWorkspacePath(targetPath), targetAnchor,
+		args.Include, lt.cfg.Ignore, lt.maxBytes, frontMatterEnabled(lt.cfg),
+	)
+	if records == nil {
+		records = []backlinkRecord{}
+	}
+	out := map[string]any{"backlinks": records}
+	if len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, e := range errs {
+			msgs = append(msgs, e.Error())
+		}
+		out["errors"] = msgs
+	}
+	return out, nil
+}
+
+type mcpFileArgs struct {
+	File string `json:"file"`
+}
+
+func (t mcpToolset) kindsResolve(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpFileArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	cfg, _, err := loadConfig(t.configPath)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resolveFileKinds(cfg, args.File)
+	if err != nil {
+		return nil, err
+	}
+	return kindsout.FileResolution(res), nil
+}
+
+type mcpExtractArgs struct {
+	Kind string `json:"kind"`
+	File string `json:"file"`
+}
+
+// extract mirrors runExtract: the file must pass a full check before
+// it is projected. Check diagnostics and projection conflicts come
+// back as a tool error carrying the diagnostics.
+func (t mcpToolset) extract(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpExtractArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	cfg, cfgPath, err := loadConfig(t.configPath)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resolveFileKinds(cfg, args.File)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateExtractKind(cfg, res, args.Kind, args.File); err != nil {
+		return nil, err
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	gate := runExtractGate(cfg, cfgPath, args.File, maxBytes)
+	if len(gate.Diagnostics) > 0 {
+		return nil, diagnosticsError(fmt.Sprintf("%s does not pass check", args.File), gate.Diagnostics)
+	}
+	if len(gate.Errors) > 0 {
+		return nil, errors.Join(gate.Errors...)
+	}
+	if gate.FilesChecked == 0 {
+		return nil, errExtractUnchecked
+	}
+	data, diags, err := projectExtract(cfg, cfgPath, res, args.Kind, args.File, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(diags) > 0 {
+		return nil, diagnosticsError(fmt.Sprintf("cannot project %s onto its schema", args.File), diags)
+	}
+	return map[string]any{"data": data}, nil
+}
+
+// diagnosticsError is a tool error whose structured content is the
+// diagnostics in the `--format json` shape.
+func diagnosticsError(msg string, diags []lint.Diagnostic) error {
+	out, err := lintResult(1, diags, nil)
+	if err != nil {
+		return err
+	}
+	return &mcp.ToolError{Message: msg, Data: map[string]any{"diagnostics": out.Diagnostics}}
+}
+
+type mcpRenameArgs struct {
+	File  string `json:"file"`
+	Mode  string `json:"mode"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+	Shift int    `json:"shift"`
+}
+
+// rename mirrors runRename and runShiftLevel, including the refusal
+// to shift a section when it would break the file's structure.
+func (t mcpToolset) rename(_ context.Context, raw json.RawMessage) (any, error) {
+	var args mcpRenameArgs
+	if err := mcp.DecodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	target, err := workspaceTarget(args.File)
+	if err != nil {
+		return nil, err
+	}
+	switch args.Mode {
+	case "heading", "link-ref":
+		if args.New == "" {
+			return nil, &mcp.ToolError{Message: "new is required for " + args.Mode + " mode"}
+		}
+	case "shift-level":
+		if args.Shift == 0 {
+			return nil, &mcp.ToolError{Message: "shift must be non-zero"}
+		}
+	default:
+		return nil, &mcp.ToolError{
+			Message: fmt.Sprintf("unknown mode %q (want heading, link-ref, or shift-level)", args.Mode),
+		}
+	}
+	ws, src, err := openRenameWorkspace(t.configPath, walkCLI{}, "", target)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []renameSummary
+	if args.Mode == "shift-level" {
+		summaries, err = t.shift(ws, target, src, args.Old, args.Shift)
+	} else {
+		var changes map[string][]rename.Edit
+		changes, err = renameChanges(ws, target, src, args.Old, args.New, args.Mode == "heading")
+		if err == nil {
+			summaries, err = writeChanges(ws, changes)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"files": summaries}, nil
+}
+
+func (t mcpToolset) shift(
+	ws cliRenameWorkspace, target string, src []byte, heading string, delta int,
+) ([]renameSummary, error) {
+	res, err := shiftHeading(ws, target, src, heading, delta)
+	if err != nil {
+		return nil, err
+	}
+	introduced, err := shiftIntroduced(t.configPath, target, res.Baseline, res.Source)
+	if err != nil {
+		return nil, err
+	}
+	if len(introduced) > 0 {
+		return nil, diagnosticsError("refusing to shift: the result breaks the file's structure", introduced)
+	}
+	return writeShifted(ws, res, t.configPath)
+}
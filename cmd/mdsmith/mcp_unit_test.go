@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunMCPRejectsPositionalArgs(t *testing.T) {
+	t.Parallel()
+	var out, errBuf bytes.Buffer
+	code := runMCPWith([]string{"unexpected"}, strings.NewReader(""), &out, &errBuf)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errBuf.String(), "takes no positional arguments")
+}
+
+func TestRunMCPRejectsUnknownFlag(t *testing.T) {
+	t.Parallel()
+	var out, errBuf bytes.Buffer
+	code := runMCPWith([]string{"--no-such-flag"}, strings.NewReader(""), &out, &errBuf)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errBuf.String(), "mdsmith: mcp:")
+}
+
+func TestRunMCPHelpFlag(t *testing.T) {
+	t.Parallel()
+	var out, errBuf bytes.Buffer
+	code := runMCPWith([]string{"-h"}, strings.NewReader(""), &out, &errBuf)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, errBuf.String(), "Usage: mdsmith mcp")
+	assert.Empty(t, out.String(), "stdout is the protocol stream")
+}
+
+func TestRunMCPRunFailurePrintsStderr(t *testing.T) {
+	t.Parallel()
+	var stdout, stderr bytes.Buffer
+	code := runMCPWith(nil, failingReader{}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "mdsmith: mcp:")
+}
+
+func TestMCPToolsRejectBadArguments(t *testing.T) {
+	tools := mcpTools("")
+	byName := map[string]int{}
+	for i, tool := range tools {
+		byName[tool.Name] = i
+	}
+	call := func(name, args string) error {
+		_, err := tools[byName[name]].Call(t.Context(), []byte(args))
+		return err
+	}
+	assert.ErrorContains(t, call("check", `{"files":["-"]}`), "stdin")
+	assert.ErrorContains(t, call("deps", `{"file":"../x.md"}`), "workspace-relative")
+	assert.ErrorContains(t, call("list_backlinks", `{"target":"#anchor"}`), "must include a file path")
+	assert.ErrorContains(t, call("rename", `{"file":"a.md","mode":"bogus","old":"x"}`), "unknown mode")
+	assert.ErrorContains(t, call("rename", `{"file":"a.md","mode":"heading","old":"x"}`), "new is required")
+	assert.ErrorContains(t, call("rename", `{"file":"a.md","mode":"shift-level","old":"x"}`), "non-zero")
+	assert.Error(t, call("list_query", `{"expr":"((("}`))
+	assert.ErrorContains(t, call("fix", `{"dry":true}`), "unknown field")
+}
@@ -337,7 +337,7 @@ func fixFileInPlace(path string, maxBytes int64) error {
 
 	fixer := &fixpkg.Fixer{
 		Config:           cfg,
-		Rules:            rule.All(),
+		Rules:            configuredRules(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		Logger:           &vlog.Logger{},
 		MaxInputBytes:    maxBytes,
@@ -374,11 +374,16 @@ func regenDirectiveNames() []string {
 // The ======= separator is only stripped when it appears between
 // <<<<<<< and >>>>>>> to avoid false positives with Markdown
 // setext heading underlines.
+//
+// Conflicts in a directive's parameters, up to its closing ?>, are
+// left for the user: two branches that change `lines:` or `section:`
+// select different content, and fix cannot pick one.
 func stripSectionConflicts(content []byte) []byte {
 	names := regenDirectiveNames()
 	lines := bytes.Split(content, []byte("\n"))
 	var out [][]byte
 	inSection := false
+	inHeader := false
 	inConflict := false
 
 	for _, line := range lines {
@@ -386,9 +391,12 @@ func stripSectionConflicts(content []byte) []byte {
 
 		if matchesAnyStart(trimmed, names) {
 			inSection = true
+			inHeader = !bytes.HasSuffix(trimmed, []byte("?>"))
+		} else if inHeader && bytes.HasSuffix(trimmed, []byte("?>")) {
+			inHeader = false
 		}
 
-		if inSection {
+		if inSection && !inHeader {
 			if isConflictOpen(trimmed) {
 				inConflict = true
 				continue
@@ -409,6 +417,7 @@ func stripSectionConflicts(content []byte) []byte {
 
 		if matchesAnyEnd(trimmed, names) {
 			inSection = false
+			inHeader = false
 			inConflict = false
 		}
 	}
@@ -40,6 +40,34 @@ func TestStripSectionConflicts_Diff3CatalogConflict(t *testing.T) {
 	assert.NotContains(t, result, ">>>>>>>", "expected >>>>>>> marker stripped")
 }
 
+func TestStripSectionConflicts_DirectiveParamsPreserved(t *testing.T) {
+	// Both branches moved the include's line range. The body conflict
+	// is regenerated by fix, but the parameter conflict decides what
+	// fix regenerates, so it must reach the user.
+	input := "# Doc\n\n" +
+		"<?include\nfile: example_test.go\n" +
+		"<<<<<<< ours\n" +
+		"lines: 10-20\n" +
+		"=======\n" +
+		"lines: 12-22\n" +
+		">>>>>>> theirs\n" +
+		"?>\n" +
+		"<<<<<<< ours\n" +
+		"ours body\n" +
+		"=======\n" +
+		"theirs body\n" +
+		">>>>>>> theirs\n" +
+		"<?/include?>\n"
+
+	result := string(stripSectionConflicts([]byte(input)))
+
+	assert.Equal(t, "# Doc\n\n"+
+		"<?include\nfile: example_test.go\n"+
+		"<<<<<<< ours\nlines: 10-20\n=======\nlines: 12-22\n>>>>>>> theirs\n"+
+		"?>\nours body\ntheirs body\n<?/include?>\n", result)
+	assert.True(t, hasConflictMarkers([]byte(result)))
+}
+
 func TestStripSectionConflicts_Diff3OutsideSection_Preserved(t *testing.T) {
 	// diff3 conflict markers outside regenerable sections must be
 	// preserved so the user can resolve them manually.
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/engine"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/orphanedfiles"
+)
+
+// orphanRecord is one file no entry point reaches.
+type orphanRecord struct {
+	File  string `json:"file"`
+	Title string `json:"title,omitempty"`
+}
+
+// orphansOptions bundles the parsed CLI flags for `list orphans`.
+type orphansOptions struct {
+	configPath   string
+	format       string
+	maxInputSize string
+	entryPoints  []string
+	walk         walkCLI
+}
+
+func parseOrphansFlags(args []string) (orphansOptions, []string, error) {
+	fs := flag.NewFlagSet("orphans", flag.ContinueOnError)
+	var (
+		opts                        orphansOptions
+		noGitignore, followSymlinks bool
+	)
+	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
+	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json")
+	fs.StringArrayVar(&opts.entryPoints, "entry-point", nil,
+		"Entry-point glob, replacing the orphaned-files setting (repeatable)")
+	fs.BoolVar(&noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
+	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
+		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
+			"=false forces skip over any config opt-in")
+	fs.StringVar(&opts.maxInputSize, "max-input-size", "",
+		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith list orphans [flags]\n\n"+
+			"List workspace files that no entry point reaches through links,\n"+
+			"includes, or catalogs. Entry points come from the orphaned-files\n"+
+			"rule's entry-points setting. Files where that rule is turned off,\n"+
+			"by kind or override, are left out.\n\n"+
+			"Exit codes: 0 found, 1 none, 2 error\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	opts.walk = walkCLI{
+		noGitignore:    noGitignore,
+		followSymlinks: followSymlinksOverride(fs, followSymlinks),
+	}
+	return opts, fs.Args(), nil
+}
+
+// runOrphans implements `mdsmith list orphans`.
+func runOrphans(args []string) int {
+	opts, posArgs, err := parseOrphansFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: list orphans"); code >= 0 {
+			return code
+		}
+	}
+	if len(posArgs) > 0 {
+		fmt.Fprintf(os.Stderr, "mdsmith: list orphans takes no arguments, got %d\n", len(posArgs))
+		return 2
+	}
+	records, err := collectOrphans(opts)
+	if err != nil {
+		return printErr(err)
+	}
+	return emitOrphans(os.Stdout, records, opts.format)
+}
+
+// collectOrphans returns the unreachable workspace files, minus those
+// where the orphaned-files rule is explicitly disabled.
+func collectOrphans(opts orphansOptions) ([]orphanRecord, error) {
+	cfg, _, err := loadConfig(opts.configPath)
+	if err != nil {
+		return nil, err
+	}
+	entryPoints := opts.entryPoints
+	if entryPoints == nil {
+		if entryPoints, err = configuredEntryPoints(cfg); err != nil {
+			return nil, err
+		}
+	}
+	ws, err := loadWorkspace(opts.configPath, opts.walk, opts.maxInputSize)
+	if err != nil {
+		return nil, err
+	}
+	name := (&orphanedfiles.Rule{}).Name()
+	var out []orphanRecord
+	for _, p := range ws.idx.Unreachable(entryPoints) {
+		fe, _ := ws.idx.File(p)
+		effective, _, explicit := config.EffectiveAll(cfg, p, fe.Kinds, nil)
+		if explicit[name] && !effective[name].Enabled {
+			continue
+		}
+		out = append(out, orphanRecord{File: p, Title: fe.Title})
+	}
+	return out, nil
+}
+
+// configuredEntryPoints returns the entry points of the top-level
+// orphaned-files settings, or the defaults.
+func configuredEntryPoints(cfg *config.Config) ([]string, error) {
+	base := rule.ByName((&orphanedfiles.Rule{}).Name())
+	if base == nil {
+		return orphanedfiles.DefaultEntryPoints, nil
+	}
+	configured, err := engine.ConfigureRule(base, cfg.Rules[base.Name()])
+	if err != nil {
+		return nil, err
+	}
+	return configured.(*orphanedfiles.Rule).EntryPoints, nil
+}
+
+// emitOrphans writes records to w. Exit code: 0 when records were
+// emitted, 1 when none were found, 2 on a bad format or write error.
+func emitOrphans(w io.Writer, records []orphanRecord, format string) int {
+	switch format {
+	case "json":
+		out := records
+		if out == nil {
+			out = []orphanRecord{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: writing json: %v\n", err)
+			return 2
+		}
+	case "text", "":
+		for _, r := range records {
+			if _, err := fmt.Fprintln(w, r.File); err != nil {
+				fmt.Fprintf(os.Stderr, "mdsmith: writing output: %v\n", err)
+				return 2
+			}
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "mdsmith: unknown --format %q (want text or json)\n", format)
+		return 2
+	}
+	if len(records) == 0 {
+		return 1
+	}
+	return 0
+}
@@ -13,7 +13,6 @@ import (
 
 	"github.com/jeduden/mdsmith/internal/index"
 	"github.com/jeduden/mdsmith/internal/lint"
-	"github.com/jeduden/mdsmith/internal/mdtext"
 	"github.com/jeduden/mdsmith/internal/rename"
 )
 
@@ -24,13 +23,22 @@ type renameOptions struct {
 	maxInputSize string
 	heading      bool
 	linkRef      bool
+	shift        bool
+	shiftLevel   int
 	walk         walkCLI
 }
 
 // renameSummary is one rewritten file's record for `--format json`.
 type renameSummary struct {
-	File  string `json:"file"`
-	Edits int    `json:"edits"`
+	File  string      `json:"file"`
+	Edits int         `json:"edits"`
+	Slugs []slugMoved `json:"slugs,omitempty"`
+}
+
+// slugMoved is one anchor of a rewritten file that changed.
+type slugMoved struct {
+	Old string `json:"old"`
+	New string `json:"new"`
 }
 
 // cliRenameWorkspace backs the rename engine's Workspace seam with a
@@ -67,6 +75,76 @@ func (w cliRenameWorkspace) Resolve(file string) (string, []byte, bool) {
 	return rel, src, true
 }
 
+// fixHeadingWorkspace backs `mdsmith fix`'s heading renames with the
+// rename workspace. The link index is built on first use, so fix runs
+// that rename no heading skip the workspace walk.
+type fixHeadingWorkspace struct {
+	configPath   string
+	walk         walkCLI
+	maxInputSize string
+	rootDir      string
+
+	loaded bool
+	ws     cliRenameWorkspace
+	err    error
+}
+
+func (w *fixHeadingWorkspace) load() (cliRenameWorkspace, error) {
+	if !w.loaded {
+		w.ws, w.err = loadWorkspace(w.configPath, w.walk, w.maxInputSize)
+		w.loaded = true
+	}
+	return w.ws, w.err
+}
+
+func (w *fixHeadingWorkspace) IncomingAnchorEdges(file, slug string) []index.Edge {
+	ws, err := w.load()
+	if err != nil || ws.idx == nil {
+		return nil
+	}
+	return ws.IncomingAnchorEdges(file, slug)
+}
+
+func (w *fixHeadingWorkspace) Files() []string {
+	ws, err := w.load()
+	if err != nil || ws.idx == nil {
+		return nil
+	}
+	return ws.Files()
+}
+
+func (w *fixHeadingWorkspace) Resolve(file string) (string, []byte, bool) {
+	ws, err := w.load()
+	if err != nil {
+		return "", nil, false
+	}
+	if ws.idx == nil {
+		ws.rootDir = w.rootDir
+	}
+	return ws.Resolve(file)
+}
+
+// Rel loads the workspace so a broken config or walk surfaces as the
+// fix error before any heading is rewritten.
+func (w *fixHeadingWorkspace) Rel(path string) (string, error) {
+	if _, err := w.load(); err != nil {
+		return "", err
+	}
+	return index.NormalizePath(workspaceRelativePath(path, w.rootDir)), nil
+}
+
+func (w *fixHeadingWorkspace) Write(key string, data []byte) (string, error) {
+	ws, err := w.load()
+	if err != nil {
+		return "", err
+	}
+	if ws.idx == nil {
+		ws.rootDir = w.rootDir
+	}
+	path := ws.absPath(key)
+	return path, writeFilePreservingMode(path, data)
+}
+
 // parseRenameFlags parses `mdsmith rename` flags and returns the
 // options plus the remaining positional arguments.
 func parseRenameFlags(args []string) (renameOptions, []string, error) {
@@ -79,6 +157,8 @@ func parseRenameFlags(args []string) (renameOptions, []string, error) {
 	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json")
 	fs.BoolVar(&opts.heading, "heading", false, "Rename a heading and every workspace anchor that targets it")
 	fs.BoolVar(&opts.linkRef, "link-ref", false, "Rename a link-reference label: the def and every use in the file")
+	fs.IntVar(&opts.shiftLevel, "shift-level", 0,
+		"Move a heading and its subsections N levels (negative promotes, positive demotes)")
 	fs.BoolVar(&noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
 	fs.BoolVar(&followSymlinks, "follow-symlinks", false,
 		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
@@ -87,12 +167,15 @@ func parseRenameFlags(args []string) (renameOptions, []string, error) {
 		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
 
 	fs.Usage = func() {
-		fmt.Fprint(os.Stderr, "Usage: mdsmith rename [flags] <file> <old> <new>\n\n"+
-			"Rename a heading or a link-reference label, rewriting every\n"+
-			"dependent edit across the workspace in place. Exactly one of\n"+
-			"--heading or --link-ref is required.\n\n"+
+		fmt.Fprint(os.Stderr, "Usage: mdsmith rename [flags] <file> <old> <new>\n"+
+			"       mdsmith rename --shift-level N <file>:<heading>\n\n"+
+			"Rename a heading or a link-reference label, or re-level a\n"+
+			"heading's subtree, rewriting every dependent edit across the\n"+
+			"workspace in place. Exactly one of --heading, --link-ref, or\n"+
+			"--shift-level is required.\n\n"+
 			"  mdsmith rename docs/a.md --heading \"Old Title\" \"New Title\"\n"+
-			"  mdsmith rename docs/a.md --link-ref oldlabel newlabel\n\n"+
+			"  mdsmith rename docs/a.md --link-ref oldlabel newlabel\n"+
+			"  mdsmith rename --shift-level -1 \"docs/a.md:Deep Section\"\n\n"+
 			"Exit codes: 0 rewritten, 1 no match, 2 error or conflict\n\nFlags:\n")
 		fs.PrintDefaults()
 	}
@@ -100,6 +183,7 @@ func parseRenameFlags(args []string) (renameOptions, []string, error) {
 	if err := fs.Parse(args); err != nil {
 		return opts, nil, err
 	}
+	opts.shift = fs.Changed("shift-level")
 	opts.walk = walkCLI{
 		noGitignore:    noGitignore,
 		followSymlinks: followSymlinksOverride(fs, followSymlinks),
@@ -116,10 +200,19 @@ func runRename(args []string) int {
 			return code
 		}
 	}
-	if opts.heading == opts.linkRef {
-		fmt.Fprint(os.Stderr, "mdsmith: rename requires exactly one of --heading or --link-ref\n")
+	modes := 0
+	for _, on := range []bool{opts.heading, opts.linkRef, opts.shift} {
+		if on {
+			modes++
+		}
+	}
+	if modes != 1 {
+		fmt.Fprint(os.Stderr, "mdsmith: rename requires exactly one of --heading, --link-ref, or --shift-level\n")
 		return 2
 	}
+	if opts.shift {
+		return runShiftLevel(opts, posArgs)
+	}
 	if len(posArgs) != 3 {
 		fmt.Fprint(os.Stderr, "mdsmith: rename requires <file> <old> <new>\n")
 		return 2
@@ -145,21 +238,57 @@ func runRename(args []string) int {
 
 // buildRenameWorkspace discovers the workspace, builds the transient
 // index, and reads the target file's bytes. A non-negative return
-// code means stop (0 = empty workspace, 2 = error); src is the target
+// code means stop (1 = empty workspace, 2 = error); src is the target
 // source on the success path.
 func buildRenameWorkspace(opts renameOptions, target string) (cliRenameWorkspace, []byte, int) {
-	cfg, cfgPath, _, files, code := discoverFiles(opts.configPath, false, opts.walk)
-	if code >= 0 {
-		if code == 0 {
-			fmt.Fprint(os.Stderr, "mdsmith: no Markdown files in workspace\n")
+	ws, src, err := openRenameWorkspace(opts.configPath, opts.walk, opts.maxInputSize, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		if errors.Is(err, errEmptyWorkspace) {
 			return cliRenameWorkspace{}, nil, 1
 		}
-		return cliRenameWorkspace{}, nil, code
+		return cliRenameWorkspace{}, nil, 2
 	}
-	maxBytes, err := resolveMaxInputBytes(cfg, opts.maxInputSize)
+	return ws, src, -1
+}
+
+// openRenameWorkspace loads the workspace and reads target from it.
+func openRenameWorkspace(
+	configPath string, walk walkCLI, maxInputSize, target string,
+) (cliRenameWorkspace, []byte, error) {
+	ws, err := loadWorkspace(configPath, walk, maxInputSize)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-		return cliRenameWorkspace{}, nil, 2
+		return cliRenameWorkspace{}, nil, err
+	}
+	if ws.idx == nil {
+		return cliRenameWorkspace{}, nil, errEmptyWorkspace
+	}
+	_, src, ok := ws.Resolve(target)
+	if !ok {
+		return cliRenameWorkspace{}, nil, fmt.Errorf("cannot read %q", target)
+	}
+	return ws, src, nil
+}
+
+// errEmptyWorkspace reports a workspace whose `files:` patterns
+// matched nothing.
+var errEmptyWorkspace = errors.New("no Markdown files in workspace")
+
+// loadWorkspace discovers the config's files and builds the transient
+// link index over them. An empty workspace yields a zero
+// cliRenameWorkspace (nil idx) and no error.
+func loadWorkspace(configPath string, walk walkCLI, maxInputSize string) (cliRenameWorkspace, error) {
+	cfg, cfgPath, err := loadConfig(configPath)
+	if err != nil {
+		return cliRenameWorkspace{}, err
+	}
+	files, err := discoverConfigFiles(cfg, walk)
+	if err != nil || len(files) == 0 {
+		return cliRenameWorkspace{}, err
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, maxInputSize)
+	if err != nil {
+		return cliRenameWorkspace{}, err
 	}
 	rootDir := rootDirFromConfig(cfgPath)
 	relToAbs := make(map[string]string, len(files))
@@ -171,15 +300,11 @@ func buildRenameWorkspace(opts renameOptions, target string) (cliRenameWorkspace
 	}
 	idx := index.New(rootDir)
 	idx.BuildSerial(rels, func(rel string) ([]byte, error) {
+		// rel always comes from rels, and rels is built in
+		// lockstep with relToAbs, so the lookup never misses.
 		return lint.ReadFileLimited(relToAbs[rel], maxBytes)
 	})
-	ws := cliRenameWorkspace{idx: idx, relToAbs: relToAbs, rootDir: rootDir, maxBytes: maxBytes}
-	_, src, ok := ws.Resolve(target)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "mdsmith: cannot read %q\n", target)
-		return cliRenameWorkspace{}, nil, 2
-	}
-	return ws, src, -1
+	return cliRenameWorkspace{idx: idx, relToAbs: relToAbs, rootDir: rootDir, maxBytes: maxBytes}, nil
 }
 
 // computeRenameChanges runs the rename engine for the requested mode
@@ -189,65 +314,107 @@ func computeRenameChanges(
 	ws cliRenameWorkspace, target string, src []byte,
 	oldName, newName string, isHeading bool,
 ) (map[string][]rename.Edit, int) {
+	changes, err := renameChanges(ws, target, src, oldName, newName, isHeading)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		if errors.Is(err, errNothingToRename) {
+			return nil, 1
+		}
+		return nil, 2
+	}
+	return changes, -1
+}
+
+// errNothingToRename wraps the "no such heading / label" outcomes,
+// which the CLI reports as exit 1 rather than an error.
+var errNothingToRename = errors.New("nothing to rename")
+
+// renameChanges runs the rename engine for the requested mode. An
+// error wrapping errNothingToRename means nothing matched oldName.
+func renameChanges(
+	ws cliRenameWorkspace, target string, src []byte,
+	oldName, newName string, isHeading bool,
+) (map[string][]rename.Edit, error) {
 	if isHeading {
 		line, ok := rename.FindHeadingLine(src, oldName)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "mdsmith: no heading %q in %s\n", oldName, target)
-			return nil, 1
+			return nil, noRenameMatch("no heading %q in %s", oldName, target)
 		}
 		changes, err := rename.Heading(ws, target, target, src, line, oldName, newName)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-			return nil, 2
+			return nil, err
 		}
 		if len(changes) == 0 {
-			fmt.Fprintf(os.Stderr, "mdsmith: nothing to rename for heading %q\n", oldName)
-			return nil, 1
+			return nil, noRenameMatch("nothing to rename for heading %q", oldName)
 		}
-		return changes, -1
+		return changes, nil
 	}
 	edits, err := rename.LinkRef(src, rename.NormalizeLabel(oldName), newName)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
-		return nil, 2
+		return nil, err
 	}
 	if len(edits) == 0 {
-		fmt.Fprintf(os.Stderr, "mdsmith: no link reference %q in %s\n", oldName, target)
-		return nil, 1
+		return nil, noRenameMatch("no link reference %q in %s", oldName, target)
 	}
-	return map[string][]rename.Edit{target: edits}, -1
+	return map[string][]rename.Edit{target: edits}, nil
+}
+
+// noRenameMatch formats a message that still matches
+// errNothingToRename under errors.Is.
+func noRenameMatch(format string, args ...any) error {
+	return noMatchError{msg: fmt.Sprintf(format, args...)}
 }
 
+type noMatchError struct{ msg string }
+
+func (e noMatchError) Error() string        { return e.msg }
+func (e noMatchError) Is(target error) bool { return target == errNothingToRename }
+
 // applyAndReport writes every change to disk and prints the per-file
 // summary. Returns 0 on success, 2 on a write or render failure.
 func applyAndReport(
 	w io.Writer, ws cliRenameWorkspace,
 	changes map[string][]rename.Edit, format string,
 ) int {
+	summaries, code := applyChanges(ws, changes)
+	if code >= 0 {
+		return code
+	}
+	return emitRenameSummary(w, summaries, format)
+}
+
+// applyChanges writes every change to disk and returns the per-file
+// records sorted by path. A non-negative code (2) means a read or
+// write failed.
+func applyChanges(ws cliRenameWorkspace, changes map[string][]rename.Edit) ([]renameSummary, int) {
+	summaries, err := writeChanges(ws, changes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return nil, 2
+	}
+	return summaries, -1
+}
+
+// writeChanges applies every file's edits and writes the result,
+// returning one summary per file sorted by path.
+func writeChanges(ws cliRenameWorkspace, changes map[string][]rename.Edit) ([]renameSummary, error) {
 	summaries := make([]renameSummary, 0, len(changes))
 	for rel, edits := range changes {
 		_, src, ok := ws.Resolve(rel)
 		if !ok {
-			fmt.Fprintf(os.Stderr, "mdsmith: cannot read %q to apply edits\n", rel)
-			return 2
+			return nil, fmt.Errorf("cannot read %q to apply edits", rel)
 		}
-		out, err := applyEdits(src, edits)
+		out, err := rename.ApplyEdits(src, edits)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: %s: %v\n", rel, err)
-			return 2
+			return nil, fmt.Errorf("%s: %w", rel, err)
 		}
-		abs, ok := ws.relToAbs[rel]
-		if !ok {
-			abs = filepath.Join(ws.rootDir, filepath.FromSlash(rel))
-		}
-		if err := writeFilePreservingMode(abs, out); err != nil {
-			fmt.Fprintf(os.Stderr, "mdsmith: writing %s: %v\n", rel, err)
-			return 2
+		if err := writeFilePreservingMode(ws.absPath(rel), out); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", rel, err)
 		}
 		summaries = append(summaries, renameSummary{File: rel, Edits: len(edits)})
 	}
 	sort.Slice(summaries, func(i, j int) bool { return summaries[i].File < summaries[j].File })
-	return emitRenameSummary(w, summaries, format)
+	return summaries, nil
 }
 
 // emitRenameSummary renders the rewritten-file list. Exit code: 0 on
@@ -263,9 +430,15 @@ func emitRenameSummary(w io.Writer, summaries []renameSummary, format string) in
 		}
 	case "text", "":
 		for _, s := range summaries {
-			if _, err := fmt.Fprintf(w, "%s: %d edit(s)\n", s.File, s.Edits); err != nil {
-				fmt.Fprintf(os.Stderr, "mdsmith: writing output: %v\n", err)
-				return 2
+			lines := []string{fmt.Sprintf("%s: %d edit(s)", s.File, s.Edits)}
+			for _, m := range s.Slugs {
+				lines = append(lines, fmt.Sprintf("  #%s -> #%s", m.Old, m.New))
+			}
+			for _, l := range lines {
+				if _, err := fmt.Fprintln(w, l); err != nil {
+					fmt.Fprintf(os.Stderr, "mdsmith: writing output: %v\n", err)
+					return 2
+				}
 			}
 		}
 	default:
@@ -275,82 +448,6 @@ func emitRenameSummary(w io.Writer, summaries []renameSummary, format string) in
 	return 0
 }
 
-// applyEdits splices every edit into src and returns the rewritten
-// bytes. Each edit is single-line (heading text, label, or fragment).
-// Edits on the same line are applied right-to-left so a left edit's
-// byte offsets — computed against the original row — stay valid while
-// the bytes to its right are rewritten. A trailing `\r` is preserved
-// so CRLF files round-trip.
-func applyEdits(src []byte, edits []rename.Edit) ([]byte, error) {
-	segs := splitKeepCR(src)
-	byLine := map[int][]rename.Edit{}
-	for _, e := range edits {
-		if e.Range.Start.Line != e.Range.End.Line {
-			return nil, errors.New("multi-line edit is not supported")
-		}
-		byLine[e.Range.Start.Line] = append(byLine[e.Range.Start.Line], e)
-	}
-	for line, es := range byLine {
-		if line < 0 || line >= len(segs) {
-			return nil, fmt.Errorf("edit line %d out of range", line+1)
-		}
-		seg := segs[line]
-		cr := len(seg) > 0 && seg[len(seg)-1] == '\r'
-		row := seg
-		if cr {
-			row = seg[:len(seg)-1]
-		}
-		sort.SliceStable(es, func(i, j int) bool {
-			return es[i].Range.Start.Character > es[j].Range.Start.Character
-		})
-		buf := append([]byte(nil), row...)
-		for _, e := range es {
-			s := mdtext.UTF16ToByteOffset(row, e.Range.Start.Character)
-			en := mdtext.UTF16ToByteOffset(row, e.Range.End.Character)
-			if s < 0 || en < 0 || s > len(buf) || en > len(buf) || s > en {
-				return nil, fmt.Errorf("edit offset [%d,%d) out of range on line %d", s, en, line+1)
-			}
-			next := make([]byte, 0, len(buf)-(en-s)+len(e.NewText))
-			next = append(next, buf[:s]...)
-			next = append(next, e.NewText...)
-			next = append(next, buf[en:]...)
-			buf = next
-		}
-		if cr {
-			buf = append(buf, '\r')
-		}
-		segs[line] = buf
-	}
-	return joinLF(segs), nil
-}
-
-// splitKeepCR splits src on `\n`, keeping any trailing `\r` on each
-// segment so CRLF endings survive a round-trip.
-func splitKeepCR(src []byte) [][]byte {
-	var segs [][]byte
-	start := 0
-	for i := 0; i < len(src); i++ {
-		if src[i] == '\n' {
-			segs = append(segs, src[start:i])
-			start = i + 1
-		}
-	}
-	segs = append(segs, src[start:])
-	return segs
-}
-
-// joinLF rejoins segments with `\n`, the inverse of splitKeepCR.
-func joinLF(segs [][]byte) []byte {
-	var out []byte
-	for i, s := range segs {
-		if i > 0 {
-			out = append(out, '\n')
-		}
-		out = append(out, s...)
-	}
-	return out
-}
-
 // writeFilePreservingMode overwrites path with data, keeping the
 // file's existing permission bits.
 func writeFilePreservingMode(path string, data []byte) error {
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const rulesUsage = `Usage: mdsmith rules <subcommand> [flags] [args]
+
+Subcommands:
+  test <path>...        Run annotated fixtures and rule directories
+                        against their rules and compare fix output.
+
+Run 'mdsmith rules <subcommand> --help' for flags and exit codes.
+`
+
+// runRules dispatches the rules subcommand to its children. Rule
+// documentation lives under `mdsmith help rule`; this parent groups
+// the commands that exercise rules.
+func runRules(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, rulesUsage)
+		return 0
+	}
+	switch args[0] {
+	case "--help", "-h":
+		fmt.Fprint(os.Stderr, rulesUsage)
+		return 0
+	case "test":
+		return runRulesTest(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr,
+			"mdsmith: rules: unknown subcommand %q\n\n%s",
+			args[0], rulesUsage)
+		return 2
+	}
+}
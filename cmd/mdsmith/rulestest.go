@@ -0,0 +1,458 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/engine"
+	fixpkg "github.com/jeduden/mdsmith/internal/fix"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// expectRe matches one expectation annotation. Its body lists rule
+// IDs or names separated by commas or spaces.
+var expectRe = regexp.MustCompile(`<!--\s*expect:\s*(.*?)\s*-->`)
+
+// fixedInfix marks expected fix output: a.md is fixed into a.fixed.md.
+const fixedInfix = ".fixed"
+
+type rulesTestOptions struct {
+	rules      []string
+	update     bool
+	configPath string
+}
+
+// rulesTest holds the state shared by every fixture of one run.
+type rulesTest struct {
+	cfg     *config.Config
+	cfgPath string
+	rules   []rule.Rule
+	tested  map[string]bool // IDs of the rules under test
+	// annotated holds the IDs annotated fixtures are checked against.
+	annotated map[string]bool
+	maxBytes  int64
+	update    bool
+	out       io.Writer
+}
+
+// fixture is one Markdown file to test. An annotated fixture has
+// expect, mapping a line to the rule references annotated for it, as
+// written. A fixture from a rule directory has rule instead, with the
+// settings and, for a bad fixture, the diagnostics of its front
+// matter; its source starts after that front matter, lineOffset lines
+// into the file.
+type fixture struct {
+	path       string
+	source     []byte
+	expect     map[int][]string
+	golden     string // expected fix output, checked when it exists
+	lineOffset int
+	rule       string
+	settings   map[string]any
+	want       []expectedDiag
+	bad        bool
+}
+
+// testFailure is one mismatch, sorted by line for output.
+type testFailure struct {
+	line int
+	text string
+}
+
+func parseRulesTestFlags(args []string) (rulesTestOptions, []string, error) {
+	fs := flag.NewFlagSet("rules test", flag.ContinueOnError)
+	var opts rulesTestOptions
+	fs.StringArrayVar(&opts.rules, "rule", nil,
+		"Rule ID or name to test (repeatable); default: every annotated rule and rule directory")
+	fs.BoolVar(&opts.update, "update", false, "Write fix output to the .fixed.md siblings and existing fixed/ files")
+	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith rules test [flags] <path>...\n\n"+
+			"Lint annotated fixture files and compare the diagnostics of the rules\n"+
+			"under test with their <!-- expect: ID --> annotations. A fixture's\n"+
+			"fix output must match its .fixed.md sibling when one exists.\n\n"+
+			"Rule directories such as MDS001-line-length/ are tested the way the\n"+
+			"fixture suite tests them: good/ reports nothing, bad/ reports the\n"+
+			"diagnostics: of its front matter, and fixed/ holds the fix of bad/.\n\n"+
+			"Exit codes: 0 pass, 1 failures, 2 error\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	return opts, fs.Args(), nil
+}
+
+// runRulesTest implements `mdsmith rules test`.
+func runRulesTest(args []string) int {
+	opts, paths, err := parseRulesTestFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: rules test"); code >= 0 {
+			return code
+		}
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "mdsmith: rules test requires at least one path")
+		return 2
+	}
+	cfg, cfgPath, err := loadConfig(opts.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	maxBytes, err := resolveMaxInputBytes(cfg, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	fixtures, err := loadFixtures(paths, maxBytes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	rt := &rulesTest{
+		cfg: cfg, cfgPath: cfgPath, rules: configuredRules(cfg),
+		maxBytes: maxBytes, update: opts.update, out: os.Stdout,
+	}
+	if err := rt.selectRules(opts.rules, fixtures); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: rules test: %v\n", err)
+		return 2
+	}
+	return rt.run(fixtures)
+}
+
+// loadFixtures resolves paths to Markdown files, skipping expected
+// fix output, and parses each file's annotations or, in a rule
+// directory, its front matter.
+func loadFixtures(paths []string, maxBytes int64) ([]fixture, error) {
+	files, err := lint.ResolveFilesWithOpts(paths, lint.ResolveOpts{})
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []fixture
+	for _, path := range files {
+		place, inRuleDir := placeInLayout(path)
+		if (inRuleDir && place.kind == "") || (!inRuleDir && isFixedOutput(path)) {
+			continue
+		}
+		src, err := lint.ReadFileLimited(path, maxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("reading %q: %w", path, err)
+		}
+		if inRuleDir {
+			fx, err := layoutFixture(path, place, src)
+			if err != nil {
+				return nil, err
+			}
+			fixtures = append(fixtures, fx)
+			continue
+		}
+		fixtures = append(fixtures, fixture{
+			path: path, source: src, expect: parseExpectations(src), golden: fixedPath(path),
+		})
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixture files found in %s", strings.Join(paths, ", "))
+	}
+	return fixtures, nil
+}
+
+// parseExpectations maps each line to the rules annotated for it. An
+// annotation after content applies to its own line; a line holding
+// only annotations applies them to the next line.
+func parseExpectations(src []byte) map[int][]string {
+	expect := map[int][]string{}
+	var pending []string
+	for i, line := range strings.Split(string(src), "\n") {
+		var refs []string
+		for _, m := range expectRe.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, strings.FieldsFunc(m[1], func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})...)
+		}
+		if len(refs) > 0 && strings.TrimSpace(expectRe.ReplaceAllString(line, "")) == "" {
+			pending = append(pending, refs...)
+			continue
+		}
+		if refs = append(pending, refs...); len(refs) > 0 {
+			expect[i+1] = refs
+		}
+		pending = nil
+	}
+	return expect
+}
+
+// fixedPath returns the expected fix output for a fixture.
+func fixedPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + fixedInfix + ext
+}
+
+func isFixedOutput(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, filepath.Ext(path)), fixedInfix)
+}
+
+// lookup finds a configured rule by ID (case-insensitive) or name.
+func (rt *rulesTest) lookup(ref string) (rule.Rule, bool) {
+	for _, r := range rt.rules {
+		if strings.EqualFold(r.ID(), ref) || r.Name() == ref {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// selectRules decides which rules are under test: those named with
+// --rule, else every rule some fixture annotates or has a rule
+// directory. Annotated fixtures are checked against the rules named
+// with --rule or annotated, not those only a rule directory brings
+// in. Each one is enabled even when the config turns it off, keeping
+// its settings.
+func (rt *rulesTest) selectRules(flagged []string, fixtures []fixture) error {
+	var annotated, dirs []string
+	for _, fx := range fixtures {
+		if fx.rule != "" {
+			dirs = append(dirs, fx.rule)
+		}
+		for _, line := range fx.expect {
+			annotated = append(annotated, line...)
+		}
+	}
+	if len(flagged) > 0 {
+		annotated, dirs = flagged, nil
+	}
+	rt.tested, rt.annotated = map[string]bool{}, map[string]bool{}
+	for i, ref := range slices.Concat(annotated, dirs) {
+		r, ok := rt.lookup(ref)
+		if !ok {
+			return fmt.Errorf("unknown rule %q", ref)
+		}
+		rt.tested[r.ID()] = true
+		if i < len(annotated) {
+			rt.annotated[r.ID()] = true
+		}
+		rc := rt.cfg.Rules[r.Name()]
+		rt.cfg.Rules[r.Name()] = config.RuleCfg{Enabled: true, Settings: rc.Settings}
+		if rt.cfg.ExplicitRules == nil {
+			rt.cfg.ExplicitRules = map[string]bool{}
+		}
+		rt.cfg.ExplicitRules[r.Name()] = true
+	}
+	if len(rt.tested) == 0 {
+		return fmt.Errorf("no rules to test: annotate fixtures with <!-- expect: ID -->, " +
+			"point at a rule directory, or pass --rule")
+	}
+	return nil
+}
+
+// annotatedNames returns the names of the rules annotated fixtures
+// are checked against, for the fixer.
+func (rt *rulesTest) annotatedNames() []string {
+	var names []string
+	for _, r := range rt.rules {
+		if rt.annotated[r.ID()] {
+			names = append(names, r.Name())
+		}
+	}
+	return names
+}
+
+// run tests every fixture and prints a summary. A rule directory
+// fixture whose rule --rule leaves out is skipped, and so is an
+// annotated fixture when no rule is annotated or named.
+func (rt *rulesTest) run(fixtures []fixture) int {
+	tested, failed, errored := 0, 0, false
+	for _, fx := range fixtures {
+		if fx.rule != "" && !rt.tested[fx.rule] || fx.rule == "" && len(rt.annotated) == 0 {
+			continue
+		}
+		tested++
+		failures, err := rt.testFixture(fx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+			errored = true
+			continue
+		}
+		if len(failures) > 0 {
+			failed++
+		}
+		for _, f := range failures {
+			if _, err := fmt.Fprintln(rt.out, f.text); err != nil {
+				return printErr(err)
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "rules test: %d fixtures, %d failed\n", tested, failed)
+	switch {
+	case errored:
+		return 2
+	case failed > 0:
+		return 1
+	}
+	return 0
+}
+
+// testFixture compares one fixture's diagnostics with its
+// expectations and its fix output with its expected output.
+func (rt *rulesTest) testFixture(fx fixture) ([]testFailure, error) {
+	cfg, rules, names := rt.cfg, rt.rules, rt.annotatedNames()
+	path, root := fx.path, rootDirFromConfig(rt.cfgPath)
+	if fx.rule != "" {
+		var err error
+		if path, root, err = layoutPaths(fx); err != nil {
+			return nil, err
+		}
+		r, _ := rt.lookup(fx.rule)
+		if cfg, err = rt.layoutConfig(fx, r); err != nil {
+			return nil, err
+		}
+		rules, names = []rule.Rule{r}, []string{r.Name()}
+	}
+	dirFS := os.DirFS(filepath.Dir(fx.path))
+	runner := &engine.Runner{
+		Config:            cfg,
+		Rules:             rules,
+		StripFrontMatter:  frontMatterEnabled(rt.cfg),
+		RootDir:           root,
+		MaxInputBytes:     rt.maxBytes,
+		SkipSourceContext: true,
+		SourceFS:          dirFS,
+	}
+	res := runner.RunSource(path, fx.source)
+	if len(res.Errors) > 0 {
+		return nil, res.Errors[0]
+	}
+	var failures []testFailure
+	if fx.rule != "" {
+		failures = rt.compareLayout(fx, res.Diagnostics)
+	} else {
+		failures = rt.compare(fx, res.Diagnostics)
+	}
+
+	fixed, err := fixpkg.SourceWithRules(fixpkg.SourceOptions{
+		Config:           cfg,
+		Rules:            rules,
+		Path:             path,
+		Source:           fx.source,
+		RootDir:          root,
+		StripFrontMatter: frontMatterEnabled(rt.cfg),
+		MaxInputBytes:    rt.maxBytes,
+		SourceFS:         dirFS,
+	}, names)
+	if err != nil {
+		return nil, fmt.Errorf("fixing %q: %w", fx.path, err)
+	}
+	failure, err := rt.compareFixed(fx, fixed)
+	if err != nil {
+		return nil, err
+	}
+	if failure != nil {
+		failures = append(failures, *failure)
+	}
+	sortFailures(failures)
+	return failures, nil
+}
+
+// compare matches the diagnostics of the rules under test against the
+// annotations line by line.
+func (rt *rulesTest) compare(fx fixture, diags []lint.Diagnostic) []testFailure {
+	type key struct {
+		line int
+		id   string
+	}
+	want := map[key]int{}
+	for line, refs := range fx.expect {
+		for _, ref := range refs {
+			if r, ok := rt.lookup(ref); ok && rt.annotated[r.ID()] {
+				want[key{line, r.ID()}]++
+			}
+		}
+	}
+	var failures []testFailure
+	for _, d := range diags {
+		if !rt.annotated[d.RuleID] {
+			continue
+		}
+		k := key{d.Line, d.RuleID}
+		if want[k] > 0 {
+			want[k]--
+			continue
+		}
+		failures = append(failures, testFailure{d.Line, fmt.Sprintf("%s:%d:%d: unexpected %s %s",
+			fx.path, d.Line, d.Column, d.RuleID, d.Message)})
+	}
+	for k, n := range want {
+		for ; n > 0; n-- {
+			failures = append(failures, testFailure{k.line, fmt.Sprintf("%s:%d: missing %s", fx.path, k.line, k.id)})
+		}
+	}
+	return failures
+}
+
+// sortFailures orders failures by line, expected fix output last.
+func sortFailures(failures []testFailure) {
+	sort.SliceStable(failures, func(i, j int) bool {
+		if failures[i].line != failures[j].line {
+			return failures[i].line < failures[j].line
+		}
+		return failures[i].text < failures[j].text
+	})
+}
+
+// compareFixed checks fixed against the fixture's expected fix
+// output: its .fixed.md sibling, or for a rule directory fixture the
+// body of its fixed/ file. In update mode it writes that file instead
+// whenever it exists or, for an annotated fixture, fixing changes it.
+func (rt *rulesTest) compareFixed(fx fixture, fixed []byte) (*testFailure, error) {
+	golden := fx.golden
+	if golden == "" {
+		return nil, nil
+	}
+	want, err := os.ReadFile(golden)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %q: %w", golden, err)
+	}
+	var prefix []byte
+	if fx.rule != "" {
+		prefix, want = lint.StripFrontMatter(want)
+	}
+	if rt.update {
+		create := fx.rule == "" && !bytes.Equal(fixed, fx.source)
+		if (exists || create) && !bytes.Equal(fixed, want) {
+			if err := os.WriteFile(golden, slices.Concat(prefix, fixed), 0o644); err != nil {
+				return nil, fmt.Errorf("writing %q: %w", golden, err)
+			}
+			fmt.Fprintf(os.Stderr, "rules test: updated %s\n", golden)
+		}
+		return nil, nil
+	}
+	if !exists || bytes.Equal(fixed, want) {
+		return nil, nil
+	}
+	line := firstDifferingLine(want, fixed) + lint.CountLines(prefix)
+	return &testFailure{text: fmt.Sprintf("%s:%d: differs from the fix output of %s", golden, line, fx.path)}, nil
+}
+
+// firstDifferingLine returns the 1-based line where a and b first
+// differ.
+func firstDifferingLine(a, b []byte) int {
+	al, bl := strings.Split(string(a), "\n"), strings.Split(string(b), "\n")
+	for i := range min(len(al), len(bl)) {
+		if al[i] != bl[i] {
+			return i + 1
+		}
+	}
+	return min(len(al), len(bl)) + 1
+}
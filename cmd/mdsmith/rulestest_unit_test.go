@@ -0,0 +1,100 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpectations(t *testing.T) {
+	src := "# Title\n" +
+		"long line <!-- expect: MDS001 -->\n" +
+		"<!-- expect: MDS006, line-length -->\n" +
+		"<!-- expect: MDS001 -->\n" +
+		"target\n" +
+		"plain\n" +
+		"<!-- not: an annotation -->\n"
+	assert.Equal(t, map[int][]string{
+		2: {"MDS001"},
+		5: {"MDS006", "line-length", "MDS001"},
+	}, parseExpectations([]byte(src)))
+}
+
+func TestFixedPath(t *testing.T) {
+	assert.Equal(t, "dir/a.fixed.md", fixedPath("dir/a.md"))
+	assert.True(t, isFixedOutput("dir/a.fixed.md"))
+	assert.False(t, isFixedOutput("dir/a.md"))
+	assert.False(t, isFixedOutput("dir/fixed.md"))
+}
+
+func TestFirstDifferingLine(t *testing.T) {
+	assert.Equal(t, 2, firstDifferingLine([]byte("a\nb\n"), []byte("a\nc\n")))
+	assert.Equal(t, 3, firstDifferingLine([]byte("a\nb"), []byte("a\nb\nc")))
+}
+
+func TestPlaceInLayout(t *testing.T) {
+	tests := []struct {
+		path string
+		want layoutPlace
+		ok   bool
+	}{
+		{"rules/MDS001-line-length/good/a.md", layoutPlace{id: "MDS001", kind: "good"}, true},
+		{"rules/MDS001-line-length/bad/a.md",
+			layoutPlace{id: "MDS001", kind: "bad", golden: "rules/MDS001-line-length/fixed/a.md"}, true},
+		{"rules/MDS001-line-length/fixed/a.md", layoutPlace{id: "MDS001"}, true},
+		{"rules/MDS001-line-length/README.md", layoutPlace{id: "MDS001"}, true},
+		{"rules/MDS023-paragraph-readability/good.md", layoutPlace{id: "MDS023", kind: "good"}, true},
+		{"rules/MDS023-paragraph-readability/bad.md",
+			layoutPlace{id: "MDS023", kind: "bad", golden: "rules/MDS023-paragraph-readability/fixed.md"}, true},
+		{"rules/MDS021-include/bad/data/part.md", layoutPlace{}, true},
+		{"docs/guide.md", layoutPlace{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := placeInLayout(filepath.FromSlash(tt.path))
+		tt.want.golden = filepath.FromSlash(tt.want.golden)
+		assert.Equal(t, tt.ok, ok, tt.path)
+		assert.Equal(t, tt.want, got, tt.path)
+	}
+}
+
+func TestLayoutFixture(t *testing.T) {
+	src := "---\nsettings:\n  max: 20\ndiagnostics:\n  - line: 3\n    column: 21\n" +
+		"    message: \"line too long (30 > 20)\"\n---\n# Title\n\nThis line is thirty chars long\n"
+	fx, err := layoutFixture("bad/a.md", layoutPlace{id: "MDS001", kind: "bad", golden: "fixed/a.md"}, []byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, "# Title\n\nThis line is thirty chars long\n", string(fx.source))
+	assert.Equal(t, 8, fx.lineOffset)
+	assert.Equal(t, map[string]any{"max": 20}, fx.settings)
+	assert.Equal(t, []expectedDiag{{3, 21, "line too long (30 > 20)"}}, fx.want)
+	assert.True(t, fx.bad)
+	assert.Equal(t, "fixed/a.md", fx.golden)
+
+	fx, err = layoutFixture("good/a.md", layoutPlace{id: "MDS001", kind: "good"}, []byte("# Title\n"))
+	require.NoError(t, err)
+	assert.False(t, fx.bad)
+	assert.Zero(t, fx.lineOffset)
+
+	_, err = layoutFixture("bad/b.md", layoutPlace{id: "MDS001", kind: "bad"}, []byte("---\n[\n---\n# T\n"))
+	assert.ErrorContains(t, err, `parsing front matter of "bad/b.md"`)
+}
+
+func TestLayoutPaths(t *testing.T) {
+	dir, err := filepath.Abs("rules/MDS019-catalog/good")
+	require.NoError(t, err)
+	path, root, err := layoutPaths(fixture{path: "rules/MDS019-catalog/good/a.md", rule: "MDS019"})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "a.md"), path)
+	assert.Equal(t, dir, root)
+
+	path, root, err = layoutPaths(fixture{path: "rules/MDS060-spelling/good/a.md", rule: "MDS060"})
+	require.NoError(t, err)
+	assert.Equal(t, "a.md", path)
+	assert.True(t, filepath.IsAbs(root))
+
+	path, root, err = layoutPaths(fixture{path: "rules/MDS001-line-length/good/a.md", rule: "MDS001"})
+	require.NoError(t, err)
+	assert.Equal(t, "a.md", path)
+	assert.Empty(t, root)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+)
+
+// ruleDirRe matches a rule's fixture directory, e.g.
+// MDS001-line-length, and captures the rule ID.
+var ruleDirRe = regexp.MustCompile(`^(MDS\d+)-`)
+
+// expectedDiag is one entry of a bad fixture's diagnostics: list.
+type expectedDiag struct {
+	Line    int    `yaml:"line"`
+	Column  int    `yaml:"column"`
+	Message string `yaml:"message"`
+}
+
+// layoutFrontMatter is the front matter of a rule directory fixture.
+type layoutFrontMatter struct {
+	Settings    map[string]any `yaml:"settings"`
+	Diagnostics []expectedDiag `yaml:"diagnostics"`
+}
+
+// layoutPlace is where a file sits in a rule directory: id is the
+// rule, kind is "good" or "bad" for a fixture and "" for anything
+// else there, and golden is a bad fixture's expected fix output.
+type layoutPlace struct {
+	id, kind, golden string
+}
+
+// placeInLayout locates path in the fixture layout of the rule
+// directories: MDS001-line-length/good/*.md and bad/*.md, with the
+// fix output of bad/a.md in fixed/a.md, or the older good.md, bad.md,
+// and fixed.md beside the README. ok is false for a file outside any
+// rule directory. READMEs, fix output, and the data files fixtures
+// read are in a rule directory but have no kind.
+func placeInLayout(path string) (layoutPlace, bool) {
+	dir, base := filepath.Split(filepath.Clean(path))
+	dir = filepath.Clean(dir)
+	if m := ruleDirRe.FindStringSubmatch(filepath.Base(dir)); m != nil {
+		p := layoutPlace{id: m[1]}
+		switch base {
+		case "good.md":
+			p.kind = "good"
+		case "bad.md":
+			p.kind, p.golden = "bad", filepath.Join(dir, "fixed.md")
+		}
+		return p, true
+	}
+	ruleDir := filepath.Dir(dir)
+	if m := ruleDirRe.FindStringSubmatch(filepath.Base(ruleDir)); m != nil {
+		p := layoutPlace{id: m[1]}
+		switch filepath.Base(dir) {
+		case "good":
+			p.kind = "good"
+		case "bad":
+			p.kind, p.golden = "bad", filepath.Join(ruleDir, "fixed", base)
+		}
+		return p, true
+	}
+	for d := filepath.Dir(ruleDir); d != filepath.Dir(d); d = filepath.Dir(d) {
+		if ruleDirRe.MatchString(filepath.Base(d)) {
+			return layoutPlace{}, true
+		}
+	}
+	return layoutPlace{}, false
+}
+
+// layoutFixture builds a fixture from a rule directory file. Its
+// front matter is cut off, as the fixture suite does, so diagnostic
+// lines count from the first line after it.
+func layoutFixture(path string, p layoutPlace, src []byte) (fixture, error) {
+	prefix, content := lint.StripFrontMatter(src)
+	var fm layoutFrontMatter
+	if body := frontMatterBody(prefix); len(bytes.TrimSpace(body)) > 0 {
+		if err := yamlutil.UnmarshalSafe(body, &fm); err != nil {
+			return fixture{}, fmt.Errorf("parsing front matter of %q: %w", path, err)
+		}
+	}
+	fx := fixture{
+		path:       path,
+		source:     content,
+		lineOffset: lint.CountLines(prefix),
+		golden:     p.golden,
+		rule:       p.id,
+		settings:   fm.Settings,
+	}
+	if p.kind == "bad" {
+		fx.want = fm.Diagnostics
+		fx.bad = true
+	}
+	return fx, nil
+}
+
+// frontMatterBody returns the YAML between the --- delimiters.
+func frontMatterBody(prefix []byte) []byte {
+	delim := []byte("---\n")
+	return bytes.TrimSuffix(bytes.TrimPrefix(prefix, delim), delim)
+}
+
+// layoutConfig returns the config a rule directory fixture runs
+// under: the rule's default settings with the fixture's on top,
+// whatever the project config sets, as in the fixture suite.
+func (rt *rulesTest) layoutConfig(fx fixture, r rule.Rule) (*config.Config, error) {
+	var settings map[string]any
+	if c, ok := r.(rule.Configurable); ok {
+		settings = maps.Clone(c.DefaultSettings())
+		if settings == nil {
+			settings = map[string]any{}
+		}
+		maps.Copy(settings, fx.settings)
+	} else if len(fx.settings) > 0 {
+		return nil, fmt.Errorf("%s: fixture sets settings but rule %s has none", fx.path, r.ID())
+	}
+	cfg := *rt.cfg
+	cfg.Rules = maps.Clone(rt.cfg.Rules)
+	cfg.Rules[r.Name()] = config.RuleCfg{Enabled: true, Settings: settings}
+	return &cfg, nil
+}
+
+// compareLayout matches the diagnostics of a rule directory fixture's
+// rule against its diagnostics: list. A good fixture expects none.
+func (rt *rulesTest) compareLayout(fx fixture, diags []lint.Diagnostic) []testFailure {
+	if fx.bad && len(fx.want) == 0 {
+		return []testFailure{{0, fmt.Sprintf("%s: bad fixture lists no diagnostics in its front matter", fx.path)}}
+	}
+	want := map[expectedDiag]int{}
+	for _, d := range fx.want {
+		want[d]++
+	}
+	var failures []testFailure
+	for _, d := range diags {
+		if d.RuleID != fx.rule {
+			continue
+		}
+		k := expectedDiag{d.Line, d.Column, d.Message}
+		if want[k] > 0 {
+			want[k]--
+			continue
+		}
+		line := d.Line + fx.lineOffset
+		failures = append(failures, testFailure{line, fmt.Sprintf("%s:%d:%d: unexpected %s %s",
+			fx.path, line, d.Column, d.RuleID, d.Message)})
+	}
+	for _, d := range fx.want {
+		if want[d] == 0 {
+			continue
+		}
+		want[d]--
+		line := d.Line + fx.lineOffset
+		failures = append(failures, testFailure{line, fmt.Sprintf("%s:%d:%d: missing %s %s",
+			fx.path, line, d.Column, fx.rule, d.Message)})
+	}
+	return failures
+}
+
+// layoutPaths returns the path and project root a rule directory
+// fixture runs under, as the fixture suite picks them. Rules that
+// resolve files against the project root (MDS019, MDS020, MDS076,
+// MDS077) see the fixture's absolute path with its directory as the
+// root. MDS060 and MDS075 see that root with the base name. Every
+// other rule sees the base name alone, so messages never depend on
+// where the command runs from.
+func layoutPaths(fx fixture) (path, root string, err error) {
+	dir, err := filepath.Abs(filepath.Dir(fx.path))
+	if err != nil {
+		return "", "", err
+	}
+	base := filepath.Base(fx.path)
+	switch fx.rule {
+	case "MDS019", "MDS020", "MDS076", "MDS077":
+		return filepath.Join(dir, base), dir, nil
+	case "MDS060", "MDS075":
+		return base, dir, nil
+	}
+	return base, "", nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/engine"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// runShiftLevel implements `rename --shift-level N`: re-level a
+// heading and its subsections, refusing when the result breaks the
+// file's heading outline or kind schema.
+func runShiftLevel(opts renameOptions, posArgs []string) int {
+	target, heading, ok := parseShiftTarget(posArgs)
+	if !ok {
+		fmt.Fprint(os.Stderr, "mdsmith: rename --shift-level requires <file>:<heading> or <file> <heading>\n")
+		return 2
+	}
+	if opts.shiftLevel == 0 {
+		fmt.Fprint(os.Stderr, "mdsmith: --shift-level must be non-zero\n")
+		return 2
+	}
+	target = normalizeWorkspacePath(target)
+	if !isWorkspaceRelativeTarget(target) {
+		fmt.Fprintf(os.Stderr, "mdsmith: target %q must be workspace-relative\n", target)
+		return 2
+	}
+	ws, src, code := buildRenameWorkspace(opts, target)
+	if code >= 0 {
+		return code
+	}
+	res, err := shiftHeading(ws, target, src, heading, opts.shiftLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		if errors.Is(err, errNothingToRename) {
+			return 1
+		}
+		return 2
+	}
+	if code := checkShiftStructure(opts, target, res.Baseline, res.Source); code >= 0 {
+		return code
+	}
+	return writeShift(ws, res, opts)
+}
+
+// shiftHeading re-levels the section headed by heading in target. An
+// error wrapping errNothingToRename means no such heading exists.
+func shiftHeading(ws cliRenameWorkspace, target string, src []byte, heading string, delta int) (rename.Shifted, error) {
+	line, ok := rename.FindHeadingLine(src, heading)
+	if !ok {
+		return rename.Shifted{}, noRenameMatch("no heading %q in %s", heading, target)
+	}
+	res, err := rename.ShiftSection(ws, target, line, delta)
+	if errors.Is(err, rename.ErrNotSectionHeading) {
+		return rename.Shifted{}, fmt.Errorf("heading %q in %s is nested in a block and has no subtree", heading, target)
+	}
+	return res, err
+}
+
+// parseShiftTarget accepts the heading as `<file>:<heading>` (split
+// at the first colon) or as two arguments.
+func parseShiftTarget(posArgs []string) (string, string, bool) {
+	switch len(posArgs) {
+	case 1:
+		file, heading, ok := strings.Cut(posArgs[0], ":")
+		return file, heading, ok && file != "" && heading != ""
+	case 2:
+		return posArgs[0], posArgs[1], posArgs[0] != "" && posArgs[1] != ""
+	}
+	return "", "", false
+}
+
+// checkShiftStructure lints the file before and after the shift with
+// rename.ShiftCheckRules, enabled whatever the config says, and
+// refuses the shift (exit 2) when it would introduce a diagnostic.
+func checkShiftStructure(opts renameOptions, target string, before, after []byte) int {
+	introduced, err := shiftIntroduced(opts.configPath, target, before, after)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: loading config: %v\n", err)
+		return 2
+	}
+	if len(introduced) == 0 {
+		return -1
+	}
+	for _, d := range introduced {
+		fmt.Fprintf(os.Stderr, "mdsmith: %s:%d: %s %s: %s\n", target, d.Line, d.RuleID, d.RuleName, d.Message)
+	}
+	fmt.Fprintf(os.Stderr, "mdsmith: refusing to shift: %d new structure diagnostic(s)\n", len(introduced))
+	return 2
+}
+
+// shiftIntroduced returns the rename.ShiftCheckRules diagnostics
+// after has and before did not.
+func shiftIntroduced(configPath, target string, before, after []byte) ([]lint.Diagnostic, error) {
+	cfg, cfgPath, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var rules []rule.Rule
+	for _, r := range rule.All() {
+		if slices.Contains(rename.ShiftCheckRules, r.Name()) {
+			rules = append(rules, r)
+		}
+	}
+	runner := &engine.Runner{
+		Config:            rename.ShiftCheckConfig(cfg),
+		Rules:             rules,
+		StripFrontMatter:  frontMatterEnabled(cfg),
+		RootDir:           rootDirFromConfig(cfgPath),
+		SkipSourceContext: true,
+	}
+	return lint.Introduced(
+		runner.RunSource(target, before).Diagnostics,
+		runner.RunSource(target, after).Diagnostics,
+	), nil
+}
+
+// writeShift writes the re-levelled file and any retargeted anchor
+// links and prints the summary.
+func writeShift(ws cliRenameWorkspace, res rename.Shifted, opts renameOptions) int {
+	summaries, err := writeShifted(ws, res, opts.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	return emitRenameSummary(os.Stdout, summaries, opts.format)
+}
+
+// writeShifted writes the re-levelled file and any retargeted anchor
+// links, then refreshes the file's directive bodies (an absolute-level
+// include below a moved heading follows it). The summaries are sorted
+// by path.
+func writeShifted(ws cliRenameWorkspace, res rename.Shifted, configPath string) ([]renameSummary, error) {
+	summaries, err := writeChanges(ws, res.Changes)
+	if err != nil {
+		return nil, err
+	}
+	path := ws.absPath(res.Key)
+	if err := writeFilePreservingMode(path, res.Source); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", res.Key, err)
+	}
+	if err := regenerateDirectives(path, configPath, ws.maxBytes); err != nil {
+		return nil, fmt.Errorf("regenerating %s: %w", res.Key, err)
+	}
+	own := renameSummary{File: res.Key, Edits: res.Headings}
+	for _, s := range res.Slugs {
+		own.Slugs = append(own.Slugs, slugMoved{Old: s.Old, New: s.New})
+	}
+	summaries = append(summaries, own)
+	slices.SortFunc(summaries, func(a, b renameSummary) int { return strings.Compare(a.File, b.File) })
+	return summaries, nil
+}
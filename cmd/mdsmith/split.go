@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	fixpkg "github.com/jeduden/mdsmith/internal/fix"
+	vlog "github.com/jeduden/mdsmith/internal/log"
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/split"
+)
+
+// splitOptions bundles the parsed CLI flags for `split`.
+type splitOptions struct {
+	rename renameOptions
+	level  int
+	index  string
+}
+
+// splitSummary is the `--format json` record of a split, section
+// extract, or include inline.
+type splitSummary struct {
+	Index    string          `json:"index"`
+	Sections []string        `json:"sections,omitempty"`
+	Inlined  string          `json:"inlined,omitempty"`
+	Rewrites []renameSummary `json:"rewrites"`
+}
+
+// sectionWrite is everything a section refactoring writes: the
+// rewritten original, any new section files, and the anchor edits
+// for the rest of the workspace.
+type sectionWrite struct {
+	indexKey string
+	index    []byte
+	sections []split.Section
+	changes  map[string][]rename.Edit
+	inlined  string
+}
+
+// parseSplitFlags parses `mdsmith split` flags and returns the
+// options plus the remaining positional arguments.
+func parseSplitFlags(args []string) (splitOptions, []string, error) {
+	fs := flag.NewFlagSet("split", flag.ContinueOnError)
+	var (
+		opts                        splitOptions
+		noGitignore, followSymlinks bool
+	)
+	fs.IntVar(&opts.level, "level", 2, "Heading level that starts a section (1-6)")
+	fs.StringVar(&opts.index, "index", "catalog", "What replaces the sections: catalog, include, toc")
+	addSectionFlags(fs, &opts.rename, &noGitignore, &followSymlinks)
+
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: mdsmith split [flags] <file>\n\n"+
+			"Move each section at --level into its own file named by the\n"+
+			"heading's slug, turn <file> into an index over the new files,\n"+
+			"and rewrite every workspace link into a moved section.\n\n"+
+			"  mdsmith split docs/guide.md\n"+
+			"  mdsmith split docs/guide.md --level 3 --index include\n\n"+
+			"Exit codes: 0 split, 1 no section at --level, 2 error or conflict\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, err
+	}
+	opts.rename.walk = walkCLI{
+		noGitignore:    noGitignore,
+		followSymlinks: followSymlinksOverride(fs, followSymlinks),
+	}
+	return opts, fs.Args(), nil
+}
+
+// addSectionFlags registers the config, format, and walk flags the
+// section refactorings (split, extract-section, inline-include) share.
+func addSectionFlags(fs *flag.FlagSet, opts *renameOptions, noGitignore, followSymlinks *bool) {
+	fs.StringVarP(&opts.configPath, "config", "c", "", "Override config file path")
+	fs.StringVarP(&opts.format, "format", "f", "text", "Output format: text, json")
+	fs.BoolVar(noGitignore, "no-gitignore", false, "Disable .gitignore filtering when walking directories")
+	fs.BoolVar(followSymlinks, "follow-symlinks", false,
+		"Follow symlinks; omitted defers to follow-symlinks config (default skip); "+
+			"=false forces skip over any config opt-in")
+	fs.StringVar(&opts.maxInputSize, "max-input-size", "",
+		"Maximum file size to process (e.g. 2MB, 500KB, 0=unlimited)")
+}
+
+// runSplit implements the "split" subcommand: carve a file into one
+// file per section and rewrite every dependent anchor link in place.
+func runSplit(args []string) int {
+	opts, posArgs, err := parseSplitFlags(args)
+	if err != nil {
+		if code := reportFlagParseErr(err, os.Stderr, "mdsmith: split"); code >= 0 {
+			return code
+		}
+	}
+	if len(posArgs) != 1 {
+		fmt.Fprint(os.Stderr, "mdsmith: split requires exactly one <file>\n")
+		return 2
+	}
+	if code := checkSummaryFormat(opts.rename.format); code >= 0 {
+		return code
+	}
+	mode, err := split.ParseMode(opts.index)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	target := normalizeWorkspacePath(posArgs[0])
+	if !isWorkspaceRelativeTarget(target) {
+		fmt.Fprintf(os.Stderr, "mdsmith: target %q must be workspace-relative\n", target)
+		return 2
+	}
+
+	ws, _, code := buildRenameWorkspace(opts.rename, target)
+	if code >= 0 {
+		return code
+	}
+	res, err := split.Split(ws, target, split.Options{Level: opts.level, Index: mode})
+	if errors.Is(err, split.ErrNoSections) {
+		fmt.Fprintf(os.Stderr, "mdsmith: no level-%d heading to split in %s\n", opts.level, target)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: %v\n", err)
+		return 2
+	}
+	if code := checkSectionTargets(ws, res.Sections); code >= 0 {
+		return code
+	}
+	return writeSections(os.Stdout, ws, sectionWrite{
+		indexKey: res.IndexKey, index: res.Index, sections: res.Sections, changes: res.Changes,
+	}, opts.rename)
+}
+
+// checkSummaryFormat rejects an unknown --format before anything is
+// written, since the summary is only rendered after the writes.
+func checkSummaryFormat(format string) int {
+	switch format {
+	case "text", "json", "":
+		return -1
+	}
+	fmt.Fprintf(os.Stderr, "mdsmith: unknown --format %q (want text or json)\n", format)
+	return 2
+}
+
+// checkSectionTargets refuses a refactoring whose section files
+// already exist, so nothing is written over an unrelated document.
+func checkSectionTargets(ws cliRenameWorkspace, sections []split.Section) int {
+	for _, s := range sections {
+		if _, err := os.Lstat(ws.absPath(s.File)); err == nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: %s already exists; refusing to overwrite\n", s.File)
+			return 2
+		}
+	}
+	return -1
+}
+
+// writeSections rewrites dependent files, creates the section files,
+// rewrites the index, and regenerates its directive bodies. Returns
+// 0 on success, 2 on a write failure.
+func writeSections(w io.Writer, ws cliRenameWorkspace, sw sectionWrite, opts renameOptions) int {
+	rewrites, code := applyChanges(ws, sw.changes)
+	if code >= 0 {
+		return code
+	}
+	summary := splitSummary{Index: sw.indexKey, Inlined: sw.inlined, Rewrites: rewrites}
+	for _, s := range sw.sections {
+		if err := os.WriteFile(ws.absPath(s.File), s.Source, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: writing %s: %v\n", s.File, err)
+			return 2
+		}
+		summary.Sections = append(summary.Sections, s.File)
+	}
+	indexPath := ws.absPath(sw.indexKey)
+	if err := writeFilePreservingMode(indexPath, sw.index); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: writing %s: %v\n", sw.indexKey, err)
+		return 2
+	}
+	if err := regenerateDirectives(indexPath, opts.configPath, ws.maxBytes); err != nil {
+		fmt.Fprintf(os.Stderr, "mdsmith: regenerating %s: %v\n", sw.indexKey, err)
+		return 2
+	}
+	return emitSplitSummary(w, summary, opts.format)
+}
+
+// absPath maps a workspace-relative key to its on-disk path, falling
+// back to the root for files the walk did not discover (such as the
+// section files split is about to create).
+func (w cliRenameWorkspace) absPath(rel string) string {
+	if abs, ok := w.relToAbs[rel]; ok {
+		return abs
+	}
+	return filepath.Join(w.rootDir, filepath.FromSlash(rel))
+}
+
+// regenerateDirectives runs only the generated-section rules over
+// path so the empty directive bodies split writes are filled in
+// without touching anything else in the file.
+func regenerateDirectives(path, configPath string, maxBytes int64) error {
+	cfg, cfgPath, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	var rules []rule.Rule
+	for _, r := range rule.All() {
+		if _, ok := r.(gensection.Directive); ok {
+			rules = append(rules, r)
+		}
+	}
+	fixer := &fixpkg.Fixer{
+		Config:           cfg,
+		Rules:            rules,
+		StripFrontMatter: frontMatterEnabled(cfg),
+		Logger:           &vlog.Logger{},
+		RootDir:          rootDirFromConfig(cfgPath),
+		MaxInputBytes:    maxBytes,
+	}
+	if result := fixer.Fix([]string{path}); len(result.Errors) > 0 {
+		return result.Errors[0]
+	}
+	return nil
+}
+
+// emitSplitSummary renders a section refactoring's result. Exit code: 0 on
+// success, 2 on unknown format or write error.
+func emitSplitSummary(w io.Writer, s splitSummary, format string) int {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s); err != nil {
+			fmt.Fprintf(os.Stderr, "mdsmith: writing json: %v\n", err)
+			return 2
+		}
+	case "text", "":
+		var lines []string
+		for _, f := range s.Sections {
+			lines = append(lines, fmt.Sprintf("%s: created", f))
+		}
+		if s.Inlined != "" {
+			lines = append(lines, fmt.Sprintf("%s: inlined", s.Inlined))
+		}
+		lines = append(lines, fmt.Sprintf("%s: index", s.Index))
+		for _, r := range s.Rewrites {
+			lines = append(lines, fmt.Sprintf("%s: %d edit(s)", r.File, r.Edits))
+		}
+		for _, l := range lines {
+			if _, err := fmt.Fprintln(w, l); err != nil {
+				fmt.Fprintf(os.Stderr, "mdsmith: writing output: %v\n", err)
+				return 2
+			}
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "mdsmith: unknown --format %q (want text or json)\n", format)
+		return 2
+	}
+	return 0
+}
@@ -8,16 +8,16 @@ import (
 
 // generatedDirectiveNames are the directives whose generated bodies must
 // be excluded from host-file diagnostics and host-file metric counts.
-var generatedDirectiveNames = []string{"include", "catalog"}
+var generatedDirectiveNames = []string{"include", "catalog", "snippet", "table"}
 
 // directiveMarkers are the byte prefixes used for the quick pre-check in
 // AuthoredSource. Kept in sync with generatedDirectiveNames.
-var directiveMarkers = [][]byte{[]byte("<?include"), []byte("<?catalog")}
+var directiveMarkers = [][]byte{[]byte("<?include"), []byte("<?catalog"), []byte("<?snippet"), []byte("<?table")}
 
 // FindAllGeneratedRanges returns the content line ranges for all
-// include/catalog generated sections in f. Lines are 1-based and
-// relative to f.Source (i.e. post-front-matter when the file was
-// created with NewFileFromSource).
+// include/catalog/snippet/table generated sections in f. Lines are
+// 1-based and relative to f.Source (i.e. post-front-matter when the file
+// was created with NewFileFromSource).
 //
 // If FindMarkerPairs returns any diagnostics for a directive (indicating
 // malformed markers), that directive's ranges are omitted entirely so the
@@ -38,8 +38,8 @@ func FindAllGeneratedRanges(f *lint.File) []lint.LineRange {
 	return ranges
 }
 
-// AuthoredSource returns source with the bodies of all include/catalog
-// generated sections removed (the opening and closing markers are kept).
+// AuthoredSource returns source with the bodies of all
+// include/catalog/snippet/table generated sections removed (the opening and closing markers are kept).
 // This gives the "authored bytes" — what the file author wrote, excluding
 // fragments pulled in by directives. Used by the metrics pipeline so that
 // a host file's metric values count only its own content.
@@ -58,6 +58,45 @@ func TestFindAllGeneratedRanges_CatalogSection(t *testing.T) {
 	assert.Equal(t, 6, ranges[0].To)
 }
 
+func TestFindAllGeneratedRanges_SnippetSection(t *testing.T) {
+	// Lines:
+	// 1: # Guide
+	// 2: (empty)
+	// 3: <?snippet
+	// 4: file: run.go
+	// 5: symbol: Run
+	// 6: ?>
+	// 7-9: fenced go block
+	// 10: <?/snippet?>
+	src := "# Guide\n\n<?snippet\nfile: run.go\nsymbol: Run\n?>\n```go\nfunc Run() {}\n```\n<?/snippet?>\n"
+	f := mustNewFile(t, "guide.md", src)
+
+	ranges := FindAllGeneratedRanges(f)
+	require.Len(t, ranges, 1)
+	assert.Equal(t, 7, ranges[0].From)
+	assert.Equal(t, 9, ranges[0].To)
+}
+
+func TestFindAllGeneratedRanges_TableSection(t *testing.T) {
+	// Lines:
+	// 1: # Matrix
+	// 2: (empty)
+	// 3: <?table
+	// 4: file: features.csv
+	// 5: ?>
+	// 6: (empty)
+	// 7-9: table
+	// 10: (empty)
+	// 11: <?/table?>
+	src := "# Matrix\n\n<?table\nfile: features.csv\n?>\n\n| a |\n| - |\n| 1 |\n\n<?/table?>\n"
+	f := mustNewFile(t, "matrix.md", src)
+
+	ranges := FindAllGeneratedRanges(f)
+	require.Len(t, ranges, 1)
+	assert.Equal(t, 6, ranges[0].From)
+	assert.Equal(t, 10, ranges[0].To)
+}
+
 func TestFindAllGeneratedRanges_EmptyBody(t *testing.T) {
 	// No content between markers: ContentFrom > ContentTo → no range recorded.
 	src := "# Host\n\n<?include\nfile: frag.md\n?>\n<?/include?>\n"
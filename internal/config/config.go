@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 
+	"github.com/jeduden/mdsmith/internal/customrule"
+	"github.com/jeduden/mdsmith/internal/plugin"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +18,7 @@ var ValidCategories = []string{
 	"link",
 	"list",
 	"prose",
+	"security",
 	"structural",
 	"table",
 	"whitespace",
@@ -65,6 +68,19 @@ type Config struct {
 	// built-in conventions ("portable", "github", "plain").
 	Conventions map[string]UserConvention `yaml:"conventions,omitempty"`
 
+	// CustomRules declares user-defined rules under the top-level
+	// `custom-rules:` key. Load validates them; Merge enables each
+	// one that `rules:` does not mention. Callers build the rules
+	// with customrule.With. See docs/guides/custom-rules.md.
+	CustomRules []customrule.Def `yaml:"custom-rules,omitempty"`
+
+	// Plugins declares out-of-process rule plugins under the
+	// top-level `plugins:` key. Load validates them and records the
+	// config directory in each Spec; Merge enables their rules like
+	// custom rules. Callers build the rules with plugin.Rules. See
+	// docs/reference/plugin-protocol.md.
+	Plugins []plugin.Spec `yaml:"plugins,omitempty"`
+
 	// LegacyNoFollowSymlinks captures the removed `no-follow-symlinks`
 	// key. Its presence surfaces a deprecation warning via
 	// Deprecations; its contents are otherwise ignored now that
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jeduden/mdsmith/internal/customrule"
+	"github.com/jeduden/mdsmith/internal/plugin"
 	"github.com/jeduden/mdsmith/internal/rule"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -617,6 +619,42 @@ func TestEffectiveGlobPatternMatch(t *testing.T) {
 	assert.True(t, eff2["line-length"].Enabled, "line-length should remain enabled for src/main.md")
 }
 
+func TestEffectiveHeadingCaseVocabulary(t *testing.T) {
+	cfg := Defaults()
+	cfg.Rules["heading-case"] = RuleCfg{Enabled: true, Settings: map[string]any{"style": "title"}}
+	cfg.Rules["proper-names"] = RuleCfg{Enabled: true, Settings: map[string]any{"names": []any{"GitHub"}}}
+	cfg.Kinds = map[string]KindBody{"guide": {Schema: map[string]any{
+		"acronyms": map[string]any{"known-safe": []any{"OAuth"}},
+	}}}
+
+	eff := Effective(cfg, "guide.md", []string{"guide"}, nil)
+	hc := eff["heading-case"].Settings
+	assert.Equal(t, "title", hc["style"])
+	assert.Equal(t, []any{"GitHub"}, hc["proper-names"])
+	assert.Equal(t, eff["required-structure"].Settings["schema-sources"], hc["schema-sources"])
+	assert.NotContains(t, cfg.Rules["heading-case"].Settings, "proper-names",
+		"the copy must not leak into the loaded config")
+
+	cfg.Rules["heading-case"] = RuleCfg{Enabled: false}
+	eff = Effective(cfg, "guide.md", []string{"guide"}, nil)
+	assert.NotContains(t, eff["heading-case"].Settings, "proper-names")
+}
+
+func TestEffectiveParagraphWrapWidth(t *testing.T) {
+	cfg := Defaults()
+	cfg.Rules["paragraph-wrap"] = RuleCfg{Enabled: true, Settings: map[string]any{"mode": "wrap"}}
+	cfg.Rules["line-length"] = RuleCfg{Enabled: true, Settings: map[string]any{"max": 100}}
+
+	eff := Effective(cfg, "doc.md", nil, nil)
+	assert.Equal(t, 100, eff["paragraph-wrap"].Settings["max"])
+	assert.NotContains(t, cfg.Rules["paragraph-wrap"].Settings, "max",
+		"the copy must not leak into the loaded config")
+
+	cfg.Rules["paragraph-wrap"] = RuleCfg{Enabled: true, Settings: map[string]any{"max": 72}}
+	eff = Effective(cfg, "doc.md", nil, nil)
+	assert.Equal(t, 72, eff["paragraph-wrap"].Settings["max"])
+}
+
 // --- MarshalYAML tests ---
 
 func TestMarshalYAML_DisabledRule(t *testing.T) {
@@ -1759,3 +1797,148 @@ kind-assignment:
 	assert.Equal(t, []string{"plan/*.md"}, cfg.KindAssignment[0].Files)
 	assert.Equal(t, []string{"plan/*.md"}, cfg.KindAssignment[0].Patterns())
 }
+
+// --- Custom rules tests ---
+
+func TestLoadCustomRules(t *testing.T) {
+	yml := `
+custom-rules:
+  - id: ACME001
+    name: no-draft-title
+    message: "title says {match}"
+    select: front-matter
+    field: title
+    pattern: (?i)draft
+rules:
+  no-draft-title:
+    severity: error
+`
+	cfgPath := filepath.Join(t.TempDir(), ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(yml), 0o644))
+
+	cfg, err := Load(cfgPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.CustomRules, 1)
+	assert.Equal(t, "title", cfg.CustomRules[0].Field)
+
+	merged := Merge(Defaults(), cfg)
+	require.Len(t, merged.CustomRules, 1)
+	assert.Equal(t, RuleCfg{Enabled: true, Settings: map[string]any{"severity": "error"}},
+		merged.Rules["no-draft-title"])
+	assert.True(t, merged.ExplicitRules["no-draft-title"])
+}
+
+func TestMergeEnablesUnmentionedCustomRules(t *testing.T) {
+	loaded := &Config{
+		CustomRules: []customrule.Def{{Name: "on"}, {Name: "off"}},
+		Rules:       map[string]RuleCfg{"off": {Enabled: false}},
+	}
+	merged := Merge(Defaults(), loaded)
+	assert.Equal(t, RuleCfg{Enabled: true}, merged.Rules["on"])
+	assert.False(t, merged.Rules["off"].Enabled)
+	assert.False(t, merged.ExplicitRules["on"], "implicit enablement yields to a disabled category")
+}
+
+func TestLoadRejectsInvalidCustomRules(t *testing.T) {
+	yml := `
+custom-rules:
+  - id: ACME001
+    name: no-foo
+    message: avoid foo
+    select: list-item
+    pattern: foo
+`
+	cfgPath := filepath.Join(t.TempDir(), ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(yml), 0o644))
+
+	_, err := Load(cfgPath)
+	assert.ErrorContains(t, err, `validating config: custom-rules[0] (no-foo): select "list-item"`)
+}
+
+// --- Plugin tests ---
+
+func TestLoadPlugins(t *testing.T) {
+	yml := `
+plugins:
+  - name: api-names
+    command: [./tools/api-names, --strict]
+    timeout: 5s
+    rules:
+      - id: API001
+        name: known-api-names
+rules:
+  known-api-names:
+    symbols: build/symbols.txt
+`
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(yml), 0o644))
+
+	cfg, err := Load(cfgPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Plugins, 1)
+	assert.Equal(t, []string{"./tools/api-names", "--strict"}, cfg.Plugins[0].Command)
+	assert.Equal(t, dir, cfg.Plugins[0].Dir)
+
+	merged := Merge(Defaults(), cfg)
+	require.Len(t, merged.Plugins, 1)
+	assert.Equal(t, dir, merged.Plugins[0].Dir)
+	assert.True(t, merged.Rules["known-api-names"].Enabled)
+	assert.Equal(t, "build/symbols.txt", merged.Rules["known-api-names"].Settings["symbols"])
+}
+
+func TestMergeEnablesUnmentionedPluginRules(t *testing.T) {
+	loaded := &Config{
+		Plugins: []plugin.Spec{{Name: "p", Rules: []plugin.RuleDef{{Name: "on"}, {Name: "off"}}}},
+		Rules:   map[string]RuleCfg{"off": {Enabled: false}},
+	}
+	merged := Merge(Defaults(), loaded)
+	assert.Equal(t, RuleCfg{Enabled: true}, merged.Rules["on"])
+	assert.False(t, merged.Rules["off"].Enabled)
+}
+
+func TestLoadRejectsPluginRuleClashingWithCustomRule(t *testing.T) {
+	yml := `
+custom-rules:
+  - id: ACME001
+    name: no-foo
+    message: avoid foo
+    select: paragraph
+    pattern: foo
+plugins:
+  - name: acme
+    command: [acme]
+    rules:
+      - id: acme001
+        name: acme-names
+`
+	cfgPath := filepath.Join(t.TempDir(), ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(yml), 0o644))
+
+	_, err := Load(cfgPath)
+	assert.ErrorContains(t, err,
+		`validating config: plugins[0] (acme): rule "acme-names": id "acme001" is already in use`)
+}
+
+func TestLoadWasmPluginMemory(t *testing.T) {
+	yml := `
+plugins:
+  - name: api-names
+    wasm: plugins/api-names.wasm
+    memory: 16MB
+    rules:
+      - id: API001
+        name: known-api-names
+`
+	cfgPath := filepath.Join(t.TempDir(), ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(yml), 0o644))
+
+	cfg, err := Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(16*1024*1024), cfg.Plugins[0].MemoryLimit)
+
+	bad := strings.Replace(yml, "16MB", "lots", 1)
+	require.NoError(t, os.WriteFile(cfgPath, []byte(bad), 0o644))
+	_, err = Load(cfgPath)
+	assert.ErrorContains(t, err, `validating config: plugins[0] (api-names): memory "lots" must be a positive size`)
+}
@@ -5,7 +5,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/jeduden/mdsmith/internal/customrule"
+	"github.com/jeduden/mdsmith/internal/plugin"
 	"github.com/jeduden/mdsmith/internal/rule"
 	"github.com/jeduden/mdsmith/internal/yamlutil"
 	"gopkg.in/yaml.v3"
@@ -69,6 +72,14 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("validating config: %w", err)
 	}
 
+	if _, err := customrule.Build(cfg.CustomRules); err != nil {
+		return nil, fmt.Errorf("validating config: %w", err)
+	}
+
+	if err := validatePlugins(&cfg, filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("validating config: %w", err)
+	}
+
 	if err := applyConvention(&cfg); err != nil {
 		return nil, fmt.Errorf("applying convention: %w", err)
 	}
@@ -76,6 +87,37 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// validatePlugins validates the `plugins:` entries, parses their
+// memory limits, and points each one at dir, the absolute directory
+// of the config file, so relative commands and modules resolve there. Plugin rule IDs and names must not collide
+// with custom rules either.
+func validatePlugins(cfg *Config, dir string) error {
+	if len(cfg.Plugins) == 0 {
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("resolving config directory: %w", err)
+	}
+	taken := map[string]bool{}
+	for _, d := range cfg.CustomRules {
+		taken[strings.ToUpper(d.ID)], taken[d.Name] = true, true
+	}
+	for i := range cfg.Plugins {
+		p := &cfg.Plugins[i]
+		p.Dir = abs
+		if p.Memory == "" {
+			continue
+		}
+		n, err := ParseSize(p.Memory)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("plugins[%d] (%s): memory %q must be a positive size", i, p.Name, p.Memory)
+		}
+		p.MemoryLimit = n
+	}
+	return plugin.Validate(cfg.Plugins, taken)
+}
+
 // topLevelKeySet returns the set of top-level YAML mapping keys
 // present in data, or an empty set on parse error. It rejects
 // anchor/alias usage for the same reason yamlHasKey does.
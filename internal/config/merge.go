@@ -1,10 +1,16 @@
 package config
 
-import "github.com/jeduden/mdsmith/internal/rule"
+import (
+	"github.com/jeduden/mdsmith/internal/customrule"
+	"github.com/jeduden/mdsmith/internal/plugin"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
 
 // Merge merges a loaded config on top of defaults. The loaded config's rules
 // override the defaults; any rule not mentioned in loaded keeps its default
-// value. Ignore and Overrides come from the loaded config only.
+// value. Ignore, Overrides, CustomRules, and Plugins come from the loaded
+// config only; each custom or plugin rule not mentioned in loaded starts
+// enabled.
 // Categories from the loaded config are merged on top of defaults; any
 // category not mentioned in loaded keeps its default value (true).
 func Merge(defaults, loaded *Config) *Config {
@@ -51,6 +57,8 @@ func Merge(defaults, loaded *Config) *Config {
 		Convention:             loaded.Convention,
 		Conventions:            copyUserConventions(loaded.Conventions),
 		ConventionPreset:       copyConventionPreset(loaded.ConventionPreset),
+		CustomRules:            copyCustomRules(loaded.CustomRules),
+		Plugins:                copyPlugins(loaded.Plugins),
 	}
 }
 
@@ -62,6 +70,14 @@ func mergeRules(defaults, loaded *Config) (map[string]RuleCfg, map[string]bool)
 	for k, v := range defaults.Rules {
 		rules[k] = v
 	}
+	for _, d := range loaded.CustomRules {
+		rules[d.Name] = RuleCfg{Enabled: true}
+	}
+	for _, p := range loaded.Plugins {
+		for _, d := range p.Rules {
+			rules[d.Name] = RuleCfg{Enabled: true}
+		}
+	}
 	for k, v := range loaded.Rules {
 		rules[k] = v
 	}
@@ -104,7 +120,27 @@ func copyConfig(cfg *Config) *Config {
 		Convention:             cfg.Convention,
 		Conventions:            copyUserConventions(cfg.Conventions),
 		ConventionPreset:       copyConventionPreset(cfg.ConventionPreset),
+		CustomRules:            copyCustomRules(cfg.CustomRules),
+		Plugins:                copyPlugins(cfg.Plugins),
+	}
+}
+
+// copyCustomRules returns a copy of the custom rule definitions.
+// Returns nil when the input is nil.
+func copyCustomRules(defs []customrule.Def) []customrule.Def {
+	if defs == nil {
+		return nil
 	}
+	return append([]customrule.Def(nil), defs...)
+}
+
+// copyPlugins returns a copy of the plugin specs. Returns nil when
+// the input is nil.
+func copyPlugins(specs []plugin.Spec) []plugin.Spec {
+	if specs == nil {
+		return nil
+	}
+	return append([]plugin.Spec(nil), specs...)
 }
 
 // copyUserConventions returns a deep copy of a user-defined
@@ -391,9 +427,56 @@ func effectiveRules(cfg *Config, filePath string, kinds []string) map[string]Rul
 			}
 		}
 	}
+	applyHeadingCaseVocabulary(result)
+	applyParagraphWrapWidth(result)
 	return result
 }
 
+// applyHeadingCaseVocabulary hands heading-case the words whose casing
+// other rules already own: the proper-names `names` list and the
+// required-structure `schema-sources`, whose schemas carry the
+// known-safe acronyms. Copying them after every layer has merged means
+// the file sees the same vocabulary those rules check it against.
+func applyHeadingCaseVocabulary(result map[string]RuleCfg) {
+	hc, ok := result["heading-case"]
+	if !ok || !hc.Enabled {
+		return
+	}
+	settings := cloneSettings(hc.Settings)
+	if settings == nil {
+		settings = map[string]any{}
+	}
+	if pn, ok := result["proper-names"]; ok && pn.Settings["names"] != nil {
+		settings["proper-names"] = pn.Settings["names"]
+	}
+	if rs, ok := result["required-structure"]; ok && rs.Settings["schema-sources"] != nil {
+		settings["schema-sources"] = rs.Settings["schema-sources"]
+	}
+	hc.Settings = settings
+	result["heading-case"] = hc
+}
+
+// applyParagraphWrapWidth hands paragraph-wrap the line-length max
+// as its wrap width, unless paragraph-wrap sets its own max, so the
+// fixer wraps at the width line-length checks.
+func applyParagraphWrapWidth(result map[string]RuleCfg) {
+	pw, ok := result["paragraph-wrap"]
+	if !ok || !pw.Enabled || pw.Settings["max"] != nil {
+		return
+	}
+	ll, ok := result["line-length"]
+	if !ok || ll.Settings["max"] == nil {
+		return
+	}
+	settings := cloneSettings(pw.Settings)
+	if settings == nil {
+		settings = map[string]any{}
+	}
+	settings["max"] = ll.Settings["max"]
+	pw.Settings = settings
+	result["paragraph-wrap"] = pw
+}
+
 // translateLayerSettings applies a rule's rule.SettingsTranslator
 // (when it implements one) to a single config layer's settings
 // before deep-merge. Rules without the interface — or rules not
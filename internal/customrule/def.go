@@ -0,0 +1,187 @@
+// Package customrule builds lint rules from the `custom-rules:` block
+// of .mdsmith.yml. Each entry selects one kind of Markdown node (or a
+// front-matter field), tests it with a regular expression or a CUE
+// expression, and reports a fixed message; a `replace:` template makes
+// a regex rule fixable. The resulting rules implement rule.Rule, so the
+// engine treats them like built-ins: they are enabled, disabled, and
+// tuned by name under `rules:`, kinds, and overrides, and they honour
+// categories.
+package customrule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// DefaultCategory is the category of a custom rule that names none.
+const DefaultCategory = "custom"
+
+// Selectors a Def may name under `select:`.
+const (
+	SelectHeading     = "heading"
+	SelectParagraph   = "paragraph"
+	SelectLink        = "link"
+	SelectCodeBlock   = "code-block"
+	SelectTableCell   = "table-cell"
+	SelectFrontMatter = "front-matter"
+)
+
+var selectors = []string{
+	SelectHeading, SelectParagraph, SelectLink,
+	SelectCodeBlock, SelectTableCell, SelectFrontMatter,
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Def is one `custom-rules:` entry as written in .mdsmith.yml.
+type Def struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Category    string `yaml:"category,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Message     string `yaml:"message"`
+	Severity    string `yaml:"severity,omitempty"`
+	// Select names the node kind the condition tests.
+	Select string `yaml:"select"`
+	// Field is the CUE path of the front-matter field to test;
+	// required for, and only valid with, select: front-matter.
+	Field string `yaml:"field,omitempty"`
+	// Pattern fires on every match of this regular expression.
+	Pattern string `yaml:"pattern,omitempty"`
+	// CUE fires on every value that satisfies this CUE expression.
+	CUE string `yaml:"cue,omitempty"`
+	// Replace, when set, rewrites each Pattern match with this
+	// template ($1, ${name}) and makes the rule fixable.
+	Replace *string `yaml:"replace,omitempty"`
+}
+
+// Build compiles defs into rules, in order. It rejects the whole set
+// on the first invalid entry so a broken config never half-applies.
+// IDs and names must be unique and must not collide with a registered
+// built-in rule; the MDS prefix is reserved for built-ins.
+func Build(defs []Def) ([]rule.Rule, error) {
+	ids := map[string]bool{}
+	names := map[string]bool{}
+	out := make([]rule.Rule, 0, len(defs))
+	for i, d := range defs {
+		r, err := compile(d)
+		if err == nil {
+			err = checkUnique(d, ids, names)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("custom-rules[%d] %s: %w", i, label(d), err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// With returns base followed by the rules built from defs. Callers
+// use it on a config whose custom rules config.Load already
+// validated; an invalid set therefore cannot occur and yields base.
+func With(base []rule.Rule, defs []Def) []rule.Rule {
+	if len(defs) == 0 {
+		return base
+	}
+	custom, err := Build(defs)
+	if err != nil {
+		return base
+	}
+	out := make([]rule.Rule, 0, len(base)+len(custom))
+	out = append(out, base...)
+	return append(out, custom...)
+}
+
+func label(d Def) string {
+	if d.Name != "" {
+		return fmt.Sprintf("(%s)", d.Name)
+	}
+	return fmt.Sprintf("(%s)", d.ID)
+}
+
+func checkUnique(d Def, ids, names map[string]bool) error {
+	id := strings.ToUpper(d.ID)
+	switch {
+	case ids[id] || rule.ByID(id) != nil:
+		return fmt.Errorf("id %q is already in use", d.ID)
+	case names[d.Name] || rule.ByName(d.Name) != nil:
+		return fmt.Errorf("name %q is already in use", d.Name)
+	}
+	ids[id], names[d.Name] = true, true
+	return nil
+}
+
+// compile validates d and returns its rule.
+func compile(d Def) (rule.Rule, error) {
+	if err := validateShape(d); err != nil {
+		return nil, err
+	}
+	r := &Rule{def: d, severity: lint.Warning, message: d.Message}
+	if d.Severity != "" {
+		r.severity = lint.Severity(d.Severity)
+	}
+	if d.Select == SelectFrontMatter {
+		r.field = fieldinterp.ParseCUEPath(d.Field)
+		if r.field == nil {
+			return nil, fmt.Errorf("field %q is not a valid CUE path", d.Field)
+		}
+	}
+	if d.Pattern != "" {
+		re, err := regexp.Compile(d.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+		r.re = re
+	} else {
+		c, err := compileCond(d.CUE)
+		if err != nil {
+			return nil, err
+		}
+		r.cond = c
+	}
+	if d.Replace != nil {
+		return &FixRule{Rule: r}, nil
+	}
+	return r, nil
+}
+
+// validateShape checks the fields that need no compilation.
+func validateShape(d Def) error {
+	switch {
+	case d.ID == "":
+		return fmt.Errorf("id is required")
+	case strings.HasPrefix(strings.ToUpper(d.ID), "MDS"):
+		return fmt.Errorf("id %q: the MDS prefix is reserved for built-in rules", d.ID)
+	case !namePattern.MatchString(d.Name):
+		return fmt.Errorf("name %q must be lower-case kebab-case", d.Name)
+	case d.Message == "":
+		return fmt.Errorf("message is required")
+	case d.Severity != "" && d.Severity != string(lint.Error) && d.Severity != string(lint.Warning):
+		return fmt.Errorf("severity %q must be %q or %q", d.Severity, lint.Error, lint.Warning)
+	case !validSelector(d.Select):
+		return fmt.Errorf("select %q must be one of %s", d.Select, strings.Join(selectors, ", "))
+	case (d.Select == SelectFrontMatter) != (d.Field != ""):
+		return fmt.Errorf("field is required with, and only valid with, select: %s", SelectFrontMatter)
+	case (d.Pattern == "") == (d.CUE == ""):
+		return fmt.Errorf("exactly one of pattern and cue is required")
+	case d.Replace != nil && d.Pattern == "":
+		return fmt.Errorf("replace requires pattern")
+	case d.Replace != nil && d.Select == SelectFrontMatter:
+		return fmt.Errorf("replace is not supported with select: %s", SelectFrontMatter)
+	}
+	return nil
+}
+
+func validSelector(s string) bool {
+	for _, v := range selectors {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
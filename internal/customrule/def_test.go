@@ -0,0 +1,103 @@
+package customrule
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registered stands in for a built-in rule in the registry.
+type registered struct{}
+
+func (registered) ID() string                           { return "TEST001" }
+func (registered) Name() string                         { return "registered" }
+func (registered) Category() string                     { return "test" }
+func (registered) Check(_ *lint.File) []lint.Diagnostic { return nil }
+
+func init() { rule.Register(registered{}) }
+
+func strPtr(s string) *string { return &s }
+
+func validDef() Def {
+	return Def{
+		ID: "ACME001", Name: "no-foo", Message: "avoid foo",
+		Select: SelectParagraph, Pattern: `foo`,
+	}
+}
+
+func TestBuild_CompilesInOrder(t *testing.T) {
+	fix := validDef()
+	fix.ID, fix.Name, fix.Replace = "ACME002", "no-bar", strPtr("bar")
+	rules, err := Build([]Def{validDef(), fix})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.IsType(t, &Rule{}, rules[0])
+	assert.IsType(t, &FixRule{}, rules[1])
+	assert.Equal(t, "no-foo", rules[0].Name())
+	assert.Equal(t, DefaultCategory, rules[0].Category())
+}
+
+func TestBuild_RejectsInvalidDefs(t *testing.T) {
+	for name, edit := range map[string]func(*Def){
+		"missing id":          func(d *Def) { d.ID = "" },
+		"reserved prefix":     func(d *Def) { d.ID = "mds900" },
+		"built-in id":         func(d *Def) { d.ID = "test001" },
+		"built-in name":       func(d *Def) { d.Name = "registered" },
+		"bad name":            func(d *Def) { d.Name = "No Foo" },
+		"missing message":     func(d *Def) { d.Message = "" },
+		"bad severity":        func(d *Def) { d.Severity = "info" },
+		"bad selector":        func(d *Def) { d.Select = "list-item" },
+		"field off fm":        func(d *Def) { d.Field = "title" },
+		"fm without field":    func(d *Def) { d.Select = SelectFrontMatter },
+		"bad field":           func(d *Def) { d.Select, d.Field = SelectFrontMatter, "a..b" },
+		"no condition":        func(d *Def) { d.Pattern = "" },
+		"both conditions":     func(d *Def) { d.CUE = `string` },
+		"bad pattern":         func(d *Def) { d.Pattern = `(` },
+		"bad cue":             func(d *Def) { d.Pattern, d.CUE = "", `{{` },
+		"replace with cue":    func(d *Def) { d.Pattern, d.CUE, d.Replace = "", `string`, strPtr("x") },
+		"replace on fm field": func(d *Def) { d.Select, d.Field, d.Replace = SelectFrontMatter, "title", strPtr("x") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := validDef()
+			edit(&d)
+			_, err := Build([]Def{d})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBuild_RejectsDuplicates(t *testing.T) {
+	byID := validDef()
+	byID.Name = "other"
+	_, err := Build([]Def{validDef(), byID})
+	assert.ErrorContains(t, err, `custom-rules[1] (other): id "ACME001" is already in use`)
+
+	byName := validDef()
+	byName.ID = "ACME002"
+	_, err = Build([]Def{validDef(), byName})
+	assert.ErrorContains(t, err, `name "no-foo" is already in use`)
+}
+
+func TestWith(t *testing.T) {
+	base := []rule.Rule{registered{}}
+	assert.Equal(t, base, With(base, nil))
+	assert.Len(t, With(base, []Def{validDef()}), 2)
+	assert.Equal(t, base, With(base, []Def{{ID: "X"}}))
+}
+
+func TestInfo(t *testing.T) {
+	d := validDef()
+	d.Replace = strPtr("bar")
+	info := Info(d)
+	assert.Equal(t, "ACME001", info.ID)
+	assert.Equal(t, Status, info.Status)
+	assert.Equal(t, "avoid foo", info.Description)
+	assert.Contains(t, info.Content, "# ACME001: no-foo")
+	assert.Contains(t, info.Content, "- Replace: `bar` (fixable)")
+
+	d.Description = "Flags foo."
+	assert.Equal(t, "Flags foo.", Info(d).Description)
+}
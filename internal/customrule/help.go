@@ -0,0 +1,64 @@
+package customrule
+
+import (
+	"fmt"
+	"strings"
+
+	ruledocs "github.com/jeduden/mdsmith/internal/rules"
+)
+
+// Status is the status `mdsmith help rule` lists for custom rules.
+const Status = "custom"
+
+// Info describes d the way a built-in rule's README describes it, so
+// `mdsmith help rule` can list and show custom rules next to
+// built-ins. Content carries no front matter.
+func Info(d Def) ruledocs.RuleInfo {
+	return ruledocs.RuleInfo{
+		ID:          d.ID,
+		Name:        d.Name,
+		Status:      Status,
+		Description: description(d),
+		Content:     content(d),
+	}
+}
+
+func description(d Def) string {
+	if d.Description != "" {
+		return d.Description
+	}
+	return d.Message
+}
+
+func content(d Def) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", d.ID, d.Name)
+	fmt.Fprintf(&b, "%s\n\n", description(d))
+	b.WriteString("Defined under `custom-rules:` in the config file.\n\n")
+	b.WriteString("## Definition\n\n")
+	category := d.Category
+	if category == "" {
+		category = DefaultCategory
+	}
+	severity := d.Severity
+	if severity == "" {
+		severity = "warning"
+	}
+	fmt.Fprintf(&b, "- Category: %s\n", category)
+	fmt.Fprintf(&b, "- Severity: %s\n", severity)
+	fmt.Fprintf(&b, "- Select: %s\n", d.Select)
+	if d.Field != "" {
+		fmt.Fprintf(&b, "- Field: `%s`\n", d.Field)
+	}
+	if d.Pattern != "" {
+		fmt.Fprintf(&b, "- Pattern: `%s`\n", d.Pattern)
+	}
+	if d.CUE != "" {
+		fmt.Fprintf(&b, "- CUE: `%s`\n", d.CUE)
+	}
+	fmt.Fprintf(&b, "- Message: %s\n", d.Message)
+	if d.Replace != nil {
+		fmt.Fprintf(&b, "- Replace: `%s` (fixable)\n", *d.Replace)
+	}
+	return b.String()
+}
@@ -0,0 +1,230 @@
+package customrule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/jeduden/mdsmith/internal/fieldinterp"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// matchPlaceholder in a message is replaced by the matched text.
+const matchPlaceholder = "{match}"
+
+// Rule is a compiled custom rule. Severity and message are settings,
+// so kinds and overrides can tune them like any built-in setting.
+type Rule struct {
+	def      Def
+	severity lint.Severity
+	message  string
+	field    []string
+	re       *regexp.Regexp
+	cond     *cueCond
+}
+
+// FixRule is a custom rule with a replace template.
+type FixRule struct {
+	*Rule
+}
+
+var (
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Cloner       = (*Rule)(nil)
+	_ rule.FixableRule  = (*FixRule)(nil)
+	_ rule.Cloner       = (*FixRule)(nil)
+)
+
+// cueCond is a compiled CUE expression. CUE values are not safe for
+// concurrent use, and clones of one rule share the value across
+// engine workers, so evaluation is serialised.
+type cueCond struct {
+	mu  sync.Mutex
+	ctx *cue.Context
+	val cue.Value
+}
+
+func compileCond(expr string) (*cueCond, error) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(expr)
+	if err := v.Err(); err != nil {
+		return nil, fmt.Errorf("cue: %w", err)
+	}
+	return &cueCond{ctx: ctx, val: v}, nil
+}
+
+// satisfied reports whether value unifies with the expression into a
+// concrete, error-free value.
+func (c *cueCond) satisfied(value any) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v := c.ctx.Encode(value)
+	if v.Err() != nil {
+		return false
+	}
+	return c.val.Unify(v).Validate(cue.Concrete(true)) == nil
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return r.def.ID }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return r.def.Name }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string {
+	if r.def.Category != "" {
+		return r.def.Category
+	}
+	return DefaultCategory
+}
+
+// Def returns the definition the rule was built from.
+func (r *Rule) Def() Def { return r.def }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if r.def.Select == SelectFrontMatter {
+		return r.checkFrontMatter(f)
+	}
+	var diags []lint.Diagnostic
+	for _, n := range nodes(f, r.def.Select) {
+		if r.cond != nil {
+			if text := n.text(f.Source); r.cond.satisfied(text) {
+				diags = append(diags, r.diagAt(f, n[0].start, text))
+			}
+			continue
+		}
+		for _, s := range n {
+			line := f.Source[s.start:s.stop]
+			for _, m := range r.re.FindAllIndex(line, -1) {
+				diags = append(diags, r.diagAt(f, s.start+m[0], string(line[m[0]:m[1]])))
+			}
+		}
+	}
+	return diags
+}
+
+// checkFrontMatter tests the configured front-matter field. A
+// missing field never fires; a pattern only sees scalar values.
+func (r *Rule) checkFrontMatter(f *lint.File) []lint.Diagnostic {
+	fields, err := lint.ParseFrontMatterFields(f.FrontMatter)
+	if err != nil || fields == nil {
+		return nil
+	}
+	value, ok := lookup(fields, r.field)
+	if !ok {
+		return nil
+	}
+	line := fieldLine(f.FrontMatter, r.field) - f.LineOffset
+	if r.cond != nil {
+		if !r.cond.satisfied(value) {
+			return nil
+		}
+		return []lint.Diagnostic{r.diag(f, line, 1, fieldinterp.Stringify(value))}
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return nil
+	}
+	text := fieldinterp.Stringify(value)
+	loc := r.re.FindStringIndex(text)
+	if loc == nil {
+		return nil
+	}
+	return []lint.Diagnostic{r.diag(f, line, 1, text[loc[0]:loc[1]])}
+}
+
+func (r *Rule) diagAt(f *lint.File, off int, match string) lint.Diagnostic {
+	return r.diag(f, f.LineOfOffset(off), f.ColumnOfOffset(off), match)
+}
+
+func (r *Rule) diag(f *lint.File, line, col int, match string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     f.Path,
+		Line:     line,
+		Column:   col,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: r.severity,
+		Message:  strings.ReplaceAll(r.message, matchPlaceholder, match),
+	}
+}
+
+// Fix implements rule.FixableRule: each selected node's matches are
+// rewritten with the replace template. Nodes inside generated
+// sections are left to the directive that owns them.
+func (r *FixRule) Fix(f *lint.File) []byte {
+	tmpl := []byte(*r.def.Replace)
+	var out []byte
+	last := 0
+	for _, n := range nodes(f, r.def.Select) {
+		if inGenerated(f, f.LineOfOffset(n[0].start)) {
+			continue
+		}
+		for _, s := range n {
+			out = append(out, f.Source[last:s.start]...)
+			out = append(out, r.re.ReplaceAll(f.Source[s.start:s.stop], tmpl)...)
+			last = s.stop
+		}
+	}
+	return append(out, f.Source[last:]...)
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: %s must be a string, got %T", r.Name(), k, v)
+		}
+		switch k {
+		case "severity":
+			if str != string(lint.Error) && str != string(lint.Warning) {
+				return fmt.Errorf("%s: severity %q must be %q or %q", r.Name(), str, lint.Error, lint.Warning)
+			}
+			r.severity = lint.Severity(str)
+		case "message":
+			r.message = str
+		default:
+			return fmt.Errorf("%s: unknown setting %q", r.Name(), k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	severity := string(lint.Warning)
+	if r.def.Severity != "" {
+		severity = r.def.Severity
+	}
+	return map[string]any{"severity": severity, "message": r.def.Message}
+}
+
+// Clone implements rule.Cloner. A zero Rule has no definition, so the
+// reflective clones in package rule cannot rebuild one; a reset clone
+// restores the definition's severity and message.
+func (r *Rule) Clone(reset bool) rule.Rule {
+	return r.clone(reset)
+}
+
+func (r *Rule) clone(reset bool) *Rule {
+	c := *r
+	if reset {
+		c.severity, c.message = lint.Warning, r.def.Message
+		if r.def.Severity != "" {
+			c.severity = lint.Severity(r.def.Severity)
+		}
+	}
+	return &c
+}
+
+// Clone implements rule.Cloner, keeping the fixable variant.
+func (r *FixRule) Clone(reset bool) rule.Rule {
+	return &FixRule{Rule: r.clone(reset)}
+}
@@ -0,0 +1,135 @@
+package customrule
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func build(t *testing.T, d Def) rule.Rule {
+	t.Helper()
+	if d.ID == "" {
+		d.ID, d.Name, d.Message = "ACME001", "acme", "found {match}"
+	}
+	rules, err := Build([]Def{d})
+	require.NoError(t, err)
+	return rules[0]
+}
+
+func check(t *testing.T, r rule.Rule, src string) []lint.Diagnostic {
+	t.Helper()
+	f, err := lint.NewFileFromSource("doc.md", []byte(src), true)
+	require.NoError(t, err)
+	diags := r.Check(f)
+	f.AdjustDiagnostics(diags)
+	return diags
+}
+
+type pos struct {
+	Line, Col int
+	Msg       string
+}
+
+func positions(diags []lint.Diagnostic) []pos {
+	out := make([]pos, 0, len(diags))
+	for _, d := range diags {
+		out = append(out, pos{d.Line, d.Column, d.Message})
+	}
+	return out
+}
+
+const doc = `# Foo title
+
+A foo paragraph
+with foo twice.
+
+| Name | Note |
+| ---- | ---- |
+| foo  | a\|foo |
+
+See [foo](https://foo.example/x) and [ref][r].
+
+` + "```text\nfoo in code\n```\n" + `
+[r]: https://foo.example/ref
+`
+
+func TestCheck_Selectors(t *testing.T) {
+	for sel, want := range map[string][]pos{
+		SelectHeading:   {{1, 3, "found Foo"}},
+		SelectParagraph: {{3, 3, "found foo"}, {4, 6, "found foo"}, {10, 6, "found foo"}, {10, 19, "found foo"}},
+		SelectTableCell: {{8, 3, "found foo"}, {8, 13, "found foo"}},
+		SelectLink:      {{10, 19, "found foo"}},
+		SelectCodeBlock: {{13, 1, "found foo"}},
+	} {
+		t.Run(sel, func(t *testing.T) {
+			r := build(t, Def{Select: sel, Pattern: `(?i)foo`})
+			assert.Equal(t, want, positions(check(t, r, doc)))
+		})
+	}
+}
+
+func TestCheck_CUEMatchesWholeNode(t *testing.T) {
+	r := build(t, Def{Select: SelectHeading, CUE: "import \"strings\"\nstrings.MaxRunes(5)"})
+	assert.Equal(t, []pos{{3, 4, "found Tiny"}}, positions(check(t, r, "# Long heading\n\n## Tiny\n")))
+}
+
+func TestCheck_FrontMatterField(t *testing.T) {
+	src := "---\ntitle: Draft notes\nmeta:\n  status: draft\n  n: 3\n---\n# Doc\n"
+
+	r := build(t, Def{Select: SelectFrontMatter, Field: "title", Pattern: `(?i)draft`})
+	assert.Equal(t, []pos{{2, 1, "found Draft"}}, positions(check(t, r, src)))
+
+	r = build(t, Def{Select: SelectFrontMatter, Field: "meta.status", CUE: `"draft"`})
+	assert.Equal(t, []pos{{4, 1, "found draft"}}, positions(check(t, r, src)))
+
+	r = build(t, Def{Select: SelectFrontMatter, Field: "meta.n", CUE: `>2`})
+	assert.Len(t, check(t, r, src), 1)
+
+	r = build(t, Def{Select: SelectFrontMatter, Field: "meta", Pattern: `draft`})
+	assert.Empty(t, check(t, r, src), "patterns skip non-scalar values")
+
+	r = build(t, Def{Select: SelectFrontMatter, Field: "missing", CUE: `_`})
+	assert.Empty(t, check(t, r, src))
+}
+
+func TestFix_RewritesSelectedNodesOnly(t *testing.T) {
+	r := build(t, Def{Select: SelectParagraph, Pattern: `\bcolour\b`, Replace: strPtr("color")})
+	fr, ok := r.(rule.FixableRule)
+	require.True(t, ok)
+	src := "# The colour\n\nA colour and a colour.\n\n```\ncolour\n```\n"
+	f, err := lint.NewFile("doc.md", []byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, "# The colour\n\nA color and a color.\n\n```\ncolour\n```\n", string(fr.Fix(f)))
+}
+
+func TestFix_ExpandsTemplateInLinks(t *testing.T) {
+	r := build(t, Def{Select: SelectLink, Pattern: `^http://(.*)$`, Replace: strPtr("https://$1")})
+	f, err := lint.NewFile("doc.md", []byte("Go [a](http://a.example) and [b](<http://b.example>).\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Go [a](https://a.example) and [b](<https://b.example>).\n",
+		string(r.(rule.FixableRule).Fix(f)))
+}
+
+func TestSettings_AndClone(t *testing.T) {
+	r := build(t, Def{Select: SelectHeading, Pattern: `x`, Severity: "error"}).(*Rule)
+	assert.Equal(t, map[string]any{"severity": "error", "message": "found {match}"}, r.DefaultSettings())
+
+	require.NoError(t, r.ApplySettings(map[string]any{"severity": "warning", "message": "no {match}"}))
+	assert.Equal(t, []lint.Diagnostic{{
+		File: "doc.md", Line: 1, Column: 3, RuleID: "ACME001", RuleName: "acme",
+		Severity: lint.Warning, Message: "no x",
+	}}, check(t, r, "# x\n"))
+
+	inst := rule.CloneInstance(r)
+	assert.Equal(t, lint.Warning, check(t, inst, "# x\n")[0].Severity)
+	fresh := rule.CloneRule(r)
+	assert.Equal(t, lint.Error, check(t, fresh, "# x\n")[0].Severity)
+	assert.Equal(t, "found x", check(t, fresh, "# x\n")[0].Message)
+
+	assert.Error(t, r.ApplySettings(map[string]any{"severity": "info"}))
+	assert.Error(t, r.ApplySettings(map[string]any{"severity": 1}))
+	assert.Error(t, r.ApplySettings(map[string]any{"other": "x"}))
+}
@@ -0,0 +1,228 @@
+package customrule
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rules/astutil"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"gopkg.in/yaml.v3"
+)
+
+// segment is a byte range of f.Source, at most one line long.
+type segment struct {
+	start, stop int
+}
+
+// node is one selected Markdown node as the source lines it spans.
+// Patterns match within a single line; CUE sees the lines joined.
+type node []segment
+
+func (n node) text(src []byte) string {
+	parts := make([]string, len(n))
+	for i, s := range n {
+		parts[i] = string(src[s.start:s.stop])
+	}
+	return strings.Join(parts, "\n")
+}
+
+// nodes returns the nodes of f that sel selects, in source order.
+func nodes(f *lint.File, sel string) []node {
+	var out []node
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.Heading:
+			if sel == SelectHeading {
+				out = appendNode(out, lineSegments(f, v.Lines()))
+			}
+		case *ast.Paragraph:
+			out = appendParagraph(out, f, v, sel)
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if sel == SelectCodeBlock {
+				out = appendNode(out, lineSegments(f, v.Lines()))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Link:
+			if sel == SelectLink {
+				if s, ok := destination(f, v); ok {
+					out = append(out, node{s})
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+func appendNode(out []node, n node) []node {
+	if len(n) == 0 {
+		return out
+	}
+	return append(out, n)
+}
+
+func appendParagraph(out []node, f *lint.File, p *ast.Paragraph, sel string) []node {
+	table := astutil.IsTable(p, f)
+	switch {
+	case sel == SelectParagraph && !table:
+		return appendNode(out, lineSegments(f, p.Lines()))
+	case sel == SelectTableCell && table:
+		for i, s := range lineSegments(f, p.Lines()) {
+			if i == 1 {
+				continue // delimiter row
+			}
+			for _, c := range cells(f.Source, s) {
+				out = append(out, node{c})
+			}
+		}
+	}
+	return out
+}
+
+// lineSegments converts goldmark line segments, dropping the line
+// terminator and any trailing whitespace.
+func lineSegments(f *lint.File, lines *text.Segments) node {
+	var out node
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		stop := seg.Stop
+		for stop > seg.Start && isSpace(f.Source[stop-1]) {
+			stop--
+		}
+		out = append(out, segment{seg.Start, stop})
+	}
+	return out
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// cells splits one table row on unescaped pipes and trims each cell.
+// Empty cells are skipped: there is nothing to match or replace.
+func cells(src []byte, row segment) []segment {
+	var out []segment
+	start := row.start
+	flush := func(stop int) {
+		for start < stop && isSpace(src[start]) {
+			start++
+		}
+		for stop > start && isSpace(src[stop-1]) {
+			stop--
+		}
+		if stop > start {
+			out = append(out, segment{start, stop})
+		}
+	}
+	for i := row.start; i < row.stop; i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '|':
+			flush(i)
+			start = i + 1
+		}
+	}
+	flush(row.stop)
+	return out
+}
+
+// destination locates the destination of an inline link in the
+// source. goldmark keeps no offsets for it, so the search starts at
+// the end of the link text and expects `](`, an optional `<`, and
+// the destination bytes. Reference links and links with empty text
+// have no such span and are skipped.
+func destination(f *lint.File, l *ast.Link) (segment, bool) {
+	end := -1
+	_ = ast.Walk(l, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering && t.Segment.Stop > end {
+			end = t.Segment.Stop
+		}
+		return ast.WalkContinue, nil
+	})
+	if end < 0 || len(l.Destination) == 0 {
+		return segment{}, false
+	}
+	rest := f.Source[end:]
+	i := bytes.Index(rest, []byte("]("))
+	if i < 0 || bytes.IndexByte(rest[:i], '\n') >= 0 {
+		return segment{}, false
+	}
+	start := end + i + 2
+	if start < len(f.Source) && f.Source[start] == '<' {
+		start++
+	}
+	if !bytes.HasPrefix(f.Source[start:], l.Destination) {
+		return segment{}, false
+	}
+	return segment{start, start + len(l.Destination)}, true
+}
+
+// inGenerated reports whether line lies in a generated section.
+func inGenerated(f *lint.File, line int) bool {
+	for _, r := range f.GeneratedRanges {
+		if r.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup walks a front-matter map along path.
+func lookup(fields map[string]any, path []string) (any, bool) {
+	var cur any = fields
+	for _, key := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// fieldLine returns the 1-based file line of the key at path in the
+// front-matter block fm (delimiters included), or 1 when unknown.
+func fieldLine(fm []byte, path []string) int {
+	delim := []byte("---\n")
+	body := bytes.TrimSuffix(bytes.TrimPrefix(fm, delim), delim)
+	doc, err := yamlutil.UnmarshalNodeSafe(body)
+	if err != nil || len(doc.Content) == 0 {
+		return 1
+	}
+	cur := doc.Content[0]
+	line := 1
+	for _, key := range path {
+		next := mappingValue(cur, key)
+		if next == nil {
+			return line
+		}
+		line = next.key.Line + 1
+		cur = next.value
+	}
+	return line
+}
+
+type pair struct {
+	key, value *yaml.Node
+}
+
+func mappingValue(n *yaml.Node, key string) *pair {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return &pair{n.Content[i], n.Content[i+1]}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+// Package doctest runs the fenced code blocks of a Markdown file that
+// are marked {test} in their info string and compares what they print
+// with the output block that follows them.
+//
+// A runnable block is a sh, bash, or shell block, run one command per
+// line without a shell, or a go block, run with `go run`. The output
+// block is the fenced block right after it whose language is output;
+// its {regex} or {ellipsis} attribute loosens the comparison.
+package doctest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+
+	"github.com/jeduden/mdsmith/internal/fenceinfo"
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// Info-string words the package reads.
+const (
+	testAttr    = "test"
+	timeoutAttr = "timeout"
+	outputLang  = "output"
+)
+
+// Output match modes.
+const (
+	MatchExact    = "exact"    // byte-for-byte, ignoring trailing newlines
+	MatchRegex    = "regex"    // the block is a regular expression
+	MatchEllipsis = "ellipsis" // "..." matches any text
+)
+
+// runners maps each runnable language to the runner for it.
+var runners = map[string]string{
+	"sh":     runShell,
+	"bash":   runShell,
+	"shell":  runShell,
+	"go":     runGo,
+	"golang": runGo,
+}
+
+// Block is one fenced code block marked {test}.
+type Block struct {
+	Line    int           // 1-based line of the opening fence
+	Lang    string        // language from the info string
+	Code    string        // the block body
+	Timeout time.Duration // from {timeout=...}; 0 uses the run default
+	Want    *Output       // the following output block, or nil
+}
+
+// Output is the expected-output block that follows a runnable block.
+type Output struct {
+	Line  int    // 1-based line of the opening fence
+	Text  string // the block body
+	Match string // MatchExact, MatchRegex, or MatchEllipsis
+
+	// start and stop delimit the body's lines in the full source,
+	// from the start of the first line to the end of the last;
+	// indent is the width of the text before the opening fence.
+	// Update uses them.
+	start, stop int
+	indent      string
+}
+
+// Parse returns the runnable blocks of a Markdown document in source
+// order. The document may start with front matter. A malformed
+// attribute is an error naming its line.
+func Parse(src []byte) ([]Block, error) {
+	f, err := lint.NewFileFromSource("", src, true)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{f: f, base: len(src) - len(f.Source)}
+	var blocks []Block
+	err = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		b, ok, err := p.block(n)
+		if ok {
+			blocks = append(blocks, b)
+		}
+		return ast.WalkContinue, err
+	})
+	return blocks, err
+}
+
+// parser maps AST positions back to the full source.
+type parser struct {
+	f    *lint.File
+	base int // length of the front matter stripped from f.Source
+}
+
+func (p *parser) line(offset int) int {
+	return p.f.LineOfOffset(offset) + p.f.LineOffset
+}
+
+// block returns n as a runnable block when it is a fenced code block
+// marked {test}. Its output block is its next sibling, so both must
+// sit in the same list item or block quote.
+func (p *parser) block(n ast.Node) (Block, bool, error) {
+	fcb, ok := n.(*ast.FencedCodeBlock)
+	if !ok || fcb.Info == nil {
+		return Block{}, false, nil
+	}
+	info := fenceinfo.Parse(string(fcb.Info.Segment.Value(p.f.Source)))
+	if !info.Has(testAttr) {
+		return Block{}, false, nil
+	}
+	b := Block{
+		Line: p.line(fcb.Info.Segment.Start),
+		Lang: info.Lang,
+		Code: body(p.f.Source, fcb),
+	}
+	if _, ok := runners[b.Lang]; !ok {
+		return b, false, fmt.Errorf("line %d: no runner for language %q; want sh, bash, shell, or go", b.Line, b.Lang)
+	}
+	if v, ok := info.Get(timeoutAttr); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return b, false, fmt.Errorf("line %d: timeout must be a positive duration, got %q", b.Line, v)
+		}
+		b.Timeout = d
+	}
+	want, err := p.output(fcb.NextSibling())
+	if err != nil {
+		return b, false, err
+	}
+	b.Want = want
+	return b, true, nil
+}
+
+// output returns n as an expected-output block, or nil when n is not
+// a fenced code block in the output language.
+func (p *parser) output(n ast.Node) (*Output, error) {
+	fcb, ok := n.(*ast.FencedCodeBlock)
+	if !ok || fcb.Info == nil {
+		return nil, nil
+	}
+	src := p.f.Source
+	info := fenceinfo.Parse(string(fcb.Info.Segment.Value(src)))
+	if info.Lang != outputLang {
+		return nil, nil
+	}
+	o := &Output{
+		Line:  p.line(fcb.Info.Segment.Start),
+		Text:  body(src, fcb),
+		Match: MatchExact,
+	}
+	switch {
+	case info.Has(MatchRegex) && info.Has(MatchEllipsis):
+		return nil, fmt.Errorf("line %d: output block cannot be both regex and ellipsis", o.Line)
+	case info.Has(MatchRegex):
+		o.Match = MatchRegex
+	case info.Has(MatchEllipsis):
+		o.Match = MatchEllipsis
+	}
+	o.indent = fenceIndent(src, fcb.Info.Segment.Start)
+	if lines := fcb.Lines(); lines.Len() > 0 {
+		o.start = lineStart(src, lines.At(0).Start)
+		o.stop = lines.At(lines.Len() - 1).Stop
+	} else {
+		o.start = lineEnd(src, fcb.Info.Segment.Stop)
+		o.stop = o.start
+	}
+	o.start += p.base
+	o.stop += p.base
+	return o, nil
+}
+
+// body joins the lines of a fenced code block.
+func body(src []byte, fcb *ast.FencedCodeBlock) string {
+	var sb strings.Builder
+	lines := fcb.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		sb.Write(seg.Value(src))
+	}
+	return sb.String()
+}
+
+// lineStart returns the offset of the start of the line holding off.
+func lineStart(src []byte, off int) int {
+	for off > 0 && src[off-1] != '\n' {
+		off--
+	}
+	return off
+}
+
+// lineEnd returns the offset just past the newline that ends the line
+// holding off, or len(src) on the last line.
+func lineEnd(src []byte, off int) int {
+	for off < len(src) {
+		off++
+		if src[off-1] == '\n' {
+			break
+		}
+	}
+	return off
+}
+
+// fenceIndent returns spaces as wide as the text before the fence on
+// the line holding the info string at off, so rewritten body lines
+// stay inside a list item.
+func fenceIndent(src []byte, off int) string {
+	start := lineStart(src, off)
+	i := start
+	for i < off && src[i] != '`' && src[i] != '~' {
+		i++
+	}
+	return strings.Repeat(" ", i-start)
+}
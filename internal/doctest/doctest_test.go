@@ -0,0 +1,204 @@
+package doctest
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const doc = "---\ntitle: x\n---\n# Demo\n\n" +
+	"```sh {test}\necho hello\n```\n\n" +
+	"```output\nhello\n```\n\n" +
+	"```sh\necho not run\n```\n\n" +
+	"- item\n\n  ```sh {test timeout=2s}\n  echo a b\n  ```\n  ```output {ellipsis}\n  a...\n  ```\n"
+
+func TestParse(t *testing.T) {
+	blocks, err := Parse([]byte(doc))
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+
+	b := blocks[0]
+	assert.Equal(t, 6, b.Line)
+	assert.Equal(t, "sh", b.Lang)
+	assert.Equal(t, "echo hello\n", b.Code)
+	require.NotNil(t, b.Want)
+	assert.Equal(t, 10, b.Want.Line)
+	assert.Equal(t, "hello\n", b.Want.Text)
+	assert.Equal(t, MatchExact, b.Want.Match)
+
+	b = blocks[1]
+	assert.Equal(t, 2*time.Second, b.Timeout)
+	require.NotNil(t, b.Want)
+	assert.Equal(t, MatchEllipsis, b.Want.Match)
+	assert.Equal(t, "  ", b.Want.indent)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"```python {test}\nprint(1)\n```\n", `line 1: no runner for language "python"`},
+		{"```sh {test timeout=soon}\necho\n```\n", `line 1: timeout must be a positive duration, got "soon"`},
+		{"```sh {test}\necho\n```\n```output {regex ellipsis}\nx\n```\n", "line 4: output block cannot be both"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.src))
+		assert.ErrorContains(t, err, tt.want, tt.src)
+	}
+}
+
+func TestParse_NoOutputBlock(t *testing.T) {
+	blocks, err := Parse([]byte("```sh {test}\ntrue\n```\n\nText.\n\n```output\nx\n```\n"))
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Nil(t, blocks[0].Want)
+}
+
+func TestCommands(t *testing.T) {
+	b := Block{Line: 3, Code: "# comment\n\n$ echo 'a b' \"c\"\nprintf \\\n  x\n"}
+	cmds, err := commands(b)
+	require.NoError(t, err)
+	require.Len(t, cmds, 2)
+	assert.Equal(t, command{line: 6, argv: []string{"echo", "a b", "c"}}, cmds[0])
+	assert.Equal(t, command{line: 7, argv: []string{"printf", "x"}}, cmds[1])
+
+	_, err = commands(Block{Line: 1, Code: "echo 'open\n"})
+	assert.ErrorContains(t, err, "line 2: unterminated ' quote")
+	_, err = commands(Block{Line: 1, Code: "echo \\\n"})
+	assert.ErrorContains(t, err, "line continuation")
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		mode, want, got string
+		ok              bool
+	}{
+		{MatchExact, "a\nb\n", "a\nb", true},
+		{MatchExact, "a\n", "a\r\n\n", true},
+		{MatchExact, "a\n", "b\n", false},
+		{MatchEllipsis, "start ...\nend\n", "start 1\n2\nend\n", true},
+		{MatchEllipsis, "a.b\n", "axb\n", false},
+		{MatchRegex, `v\d+\.\d+`, "v1.20\n", true},
+		{MatchRegex, `v\d+`, "v1.2\n", false},
+	}
+	for _, tt := range tests {
+		ok, err := matches(&Output{Text: tt.want, Match: tt.mode}, tt.got)
+		require.NoError(t, err)
+		assert.Equal(t, tt.ok, ok, "%s %q vs %q", tt.mode, tt.want, tt.got)
+	}
+	_, err := matches(&Output{Text: "(", Match: MatchRegex}, "x")
+	assert.ErrorContains(t, err, "invalid output regex")
+}
+
+func requireEcho(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not in PATH")
+	}
+}
+
+func TestRun_Shell(t *testing.T) {
+	requireEcho(t)
+	blocks, err := Parse([]byte(doc))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	for _, b := range blocks {
+		res := Run(context.Background(), b, dir, 0)
+		assert.True(t, res.Passed(), res.Failure)
+	}
+
+	b := blocks[0]
+	b.Want = &Output{Text: "bye\n", Match: MatchExact}
+	res := Run(context.Background(), b, dir, 0)
+	assert.Equal(t, "output does not match", res.Failure)
+	assert.True(t, res.Mismatch())
+	assert.Equal(t, "hello\n", res.Got)
+}
+
+func TestRun_Failures(t *testing.T) {
+	requireEcho(t)
+	dir := t.TempDir()
+	tests := []struct {
+		code, want string
+	}{
+		{"echo a | wc -l\n", `line 2: command contains shell operator "|"`},
+		{"bash -c true\n", `line 2: command uses shell interpreter "bash"`},
+		{"mdsmith-no-such-command\n", "line 2: mdsmith-no-such-command:"},
+	}
+	for _, tt := range tests {
+		res := Run(context.Background(), Block{Line: 1, Lang: "sh", Code: tt.code}, dir, 0)
+		assert.Contains(t, res.Failure, tt.want)
+		assert.False(t, res.Mismatch())
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not in PATH")
+	}
+	b := Block{Line: 1, Lang: "sh", Code: "sleep 5\n", Timeout: 50 * time.Millisecond}
+	res := Run(context.Background(), b, t.TempDir(), time.Minute)
+	assert.Equal(t, "timed out after 50ms", res.Failure)
+}
+
+func TestRun_Go(t *testing.T) {
+	if testing.Short() {
+		t.Skip("go run is slow")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not in PATH")
+	}
+	b := Block{Line: 1, Lang: "go", Code: "import \"fmt\"\n\nfunc main() { fmt.Println(6 * 7) }\n",
+		Want: &Output{Text: "42\n", Match: MatchExact}}
+	res := Run(context.Background(), b, t.TempDir(), time.Minute)
+	assert.True(t, res.Passed(), res.Failure)
+}
+
+func TestGoProgram(t *testing.T) {
+	assert.Equal(t, "package x\n", goProgram("package x\n"))
+	assert.Equal(t, "package main\n\n// doc\nfunc f() {}\n", goProgram("// doc\nfunc f() {}\n"))
+	assert.Equal(t, "package main\n\nfunc main() {\nprintln(1)\n}\n", goProgram("println(1)\n"))
+}
+
+func TestUpdate(t *testing.T) {
+	src := "# T\n\n```sh {test}\necho new\n```\n\n```output\nold\nlines\n```\n\n" +
+		"- item\n\n  ```sh {test}\n  echo x\n  ```\n  ```output\n  ```\n\n" +
+		"```sh {test}\necho y\n```\n```output {regex}\nz\n```\n"
+	blocks, err := Parse([]byte(src))
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	results := []Result{
+		{Block: blocks[0], Got: "new\n", mismatch: true},
+		{Block: blocks[1], Got: "one\n\ntwo\n", mismatch: true},
+		{Block: blocks[2], Got: "y\n", mismatch: true},
+	}
+	want := "# T\n\n```sh {test}\necho new\n```\n\n```output\nnew\n```\n\n" +
+		"- item\n\n  ```sh {test}\n  echo x\n  ```\n  ```output\n  one\n\n  two\n  ```\n\n" +
+		"```sh {test}\necho y\n```\n```output {regex}\nz\n```\n"
+	assert.Equal(t, want, string(Update([]byte(src), results)))
+}
+
+func TestUpdate_FrontMatter(t *testing.T) {
+	blocks, err := Parse([]byte(doc))
+	require.NoError(t, err)
+	out := Update([]byte(doc), []Result{{Block: blocks[0], Got: "hi\n", mismatch: true}})
+	assert.Equal(t, strings.Replace(doc, "```output\nhello\n", "```output\nhi\n", 1), string(out))
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJUnit(&buf, []Suite{{Path: "a.md", Results: []Result{
+		{Block: Block{Line: 3, Lang: "sh"}, Elapsed: time.Second},
+		{Block: Block{Line: 9, Lang: "go"}, Failure: "output does not match", Got: "x <y>"},
+	}}})
+	require.NoError(t, err)
+	out := buf.String()
+	assert.Contains(t, out, `<testsuites tests="2" failures="1" time="1.000">`)
+	assert.Contains(t, out, `<testsuite name="a.md" tests="2" failures="1" time="1.000">`)
+	assert.Contains(t, out, `<testcase name="a.md:3 (sh)" classname="a.md" time="1.000"></testcase>`)
+	assert.Contains(t, out, `<failure message="output does not match">x &lt;y&gt;</failure>`)
+}
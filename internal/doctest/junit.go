@@ -0,0 +1,87 @@
+package doctest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Suite is the results of one file, reported as a JUnit test suite.
+type Suite struct {
+	Path    string
+	Results []Result
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes suites as a JUnit XML report: one test suite per
+// file and one test case per block, named by its line and language.
+// A failure's body holds the block's stdout.
+func WriteJUnit(w io.Writer, suites []Suite) error {
+	var doc junitSuites
+	var total time.Duration
+	for _, s := range suites {
+		js := junitSuite{Name: s.Path, Tests: len(s.Results)}
+		var elapsed time.Duration
+		for _, r := range s.Results {
+			jc := junitCase{
+				Name:      fmt.Sprintf("%s:%d (%s)", s.Path, r.Block.Line, r.Block.Lang),
+				ClassName: s.Path,
+				Time:      seconds(r.Elapsed),
+			}
+			if !r.Passed() {
+				jc.Failure = &junitFailure{Message: r.Failure, Body: r.Got}
+				js.Failures++
+			}
+			elapsed += r.Elapsed
+			js.Cases = append(js.Cases, jc)
+		}
+		js.Time = seconds(elapsed)
+		doc.Tests += js.Tests
+		doc.Failures += js.Failures
+		total += elapsed
+		doc.Suites = append(doc.Suites, js)
+	}
+	doc.Time = seconds(total)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,77 @@
+package doctest
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ellipsis stands for any text in an ellipsis-mode output block.
+const ellipsis = "..."
+
+// matches reports whether got satisfies want. Line endings are
+// normalized and trailing newlines on either side are ignored.
+func matches(want *Output, got string) (bool, error) {
+	w, g := normalize(want.Text), normalize(got)
+	switch want.Match {
+	case MatchRegex:
+		re, err := regexp.Compile(`\A(?:` + w + `)\z`)
+		if err != nil {
+			return false, fmt.Errorf("invalid output regex: %w", err)
+		}
+		return re.MatchString(g), nil
+	case MatchEllipsis:
+		parts := strings.Split(w, ellipsis)
+		for i, p := range parts {
+			parts[i] = regexp.QuoteMeta(p)
+		}
+		re := regexp.MustCompile(`\A` + strings.Join(parts, `(?s:.*)`) + `\z`)
+		return re.MatchString(g), nil
+	}
+	return w == g, nil
+}
+
+func normalize(s string) string {
+	return strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// Update rewrites the exact-mode output blocks of the mismatched
+// results in src with what their blocks printed. Regex and ellipsis
+// blocks are patterns, so they are left for a person to edit. It
+// returns src unchanged when nothing needs rewriting.
+func Update(src []byte, results []Result) []byte {
+	var outs []*Output
+	got := map[*Output]string{}
+	for _, r := range results {
+		if w := r.Block.Want; r.mismatch && w.Match == MatchExact {
+			outs = append(outs, w)
+			got[w] = r.Got
+		}
+	}
+	// Rewrite from the end so earlier offsets stay valid.
+	slices.SortFunc(outs, func(a, b *Output) int { return b.start - a.start })
+	out := slices.Clone(src)
+	for _, o := range outs {
+		out = slices.Concat(out[:o.start], []byte(indented(got[o], o.indent)), out[o.stop:])
+	}
+	return out
+}
+
+// indented returns text as fenced-block lines under indent, each
+// ending in a newline.
+func indented(text, indent string) string {
+	text = normalize(text)
+	if text == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" {
+			sb.WriteString(indent)
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
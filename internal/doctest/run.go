@@ -0,0 +1,269 @@
+package doctest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jeduden/mdsmith/internal/rules/recipesafety"
+)
+
+// Runner names.
+const (
+	runShell = "shell"
+	runGo    = "go"
+)
+
+// DefaultTimeout bounds one block when neither the run nor the block
+// sets a timeout, matching the build recipe default.
+const DefaultTimeout = 30 * time.Second
+
+// waitDelay bounds the wait for a killed command's output pipes, for
+// children (such as the binary `go run` builds) that outlive it.
+const waitDelay = time.Second
+
+// Result is the outcome of running one block.
+type Result struct {
+	Block   Block
+	Got     string        // captured stdout
+	Failure string        // why the block failed; empty when it passed
+	Elapsed time.Duration // wall time of the run
+
+	mismatch bool
+}
+
+// Passed reports whether the block ran cleanly and matched its output.
+func (r Result) Passed() bool { return r.Failure == "" }
+
+// Mismatch reports whether the block ran cleanly but printed
+// something other than its output block allows.
+func (r Result) Mismatch() bool { return r.mismatch }
+
+// Run runs b in dir and compares its stdout with b.Want. Blocks of one
+// file share dir, so a block sees the files earlier blocks wrote.
+// timeout applies unless the block sets its own; zero means
+// DefaultTimeout.
+func Run(ctx context.Context, b Block, dir string, timeout time.Duration) Result {
+	if b.Timeout > 0 {
+		timeout = b.Timeout
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var got string
+	var err error
+	switch runners[b.Lang] {
+	case runShell:
+		got, err = runShellBlock(ctx, b, dir)
+	case runGo:
+		got, err = runGoBlock(ctx, b, dir)
+	default:
+		err = fmt.Errorf("no runner for language %q", b.Lang)
+	}
+	res := Result{Block: b, Got: got, Elapsed: time.Since(start)}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		res.Failure = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil:
+		res.Failure = err.Error()
+	case b.Want != nil:
+		ok, err := matches(b.Want, got)
+		switch {
+		case err != nil:
+			res.Failure = fmt.Sprintf("line %d: %v", b.Want.Line, err)
+		case !ok:
+			res.Failure = "output does not match"
+			res.mismatch = true
+		}
+	}
+	return res
+}
+
+// command is one shell-block command and the file line it starts on.
+type command struct {
+	line int
+	argv []string
+}
+
+// runShellBlock runs each command of a shell block in turn, without a
+// shell, and returns their joined stdout. Every command passes the
+// recipe-safety checks before any runs.
+func runShellBlock(ctx context.Context, b Block, dir string) (string, error) {
+	cmds, err := commands(b)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cmds {
+		if problems := recipesafety.ArgvProblems(c.argv); len(problems) > 0 {
+			return "", fmt.Errorf("line %d: %s", c.line, problems[0])
+		}
+	}
+	var stdout bytes.Buffer
+	for _, c := range cmds {
+		cmd := exec.CommandContext(ctx, c.argv[0], c.argv[1:]...) // #nosec G204 -- argv passed recipe-safety
+		if err := execute(cmd, dir, &stdout); err != nil {
+			return stdout.String(), fmt.Errorf("line %d: %s: %w", c.line, c.argv[0], err)
+		}
+	}
+	return stdout.String(), nil
+}
+
+// commands splits a shell block into commands. Blank lines and #
+// comments are skipped, a leading "$ " prompt is dropped, and a line
+// ending in a backslash continues on the next.
+func commands(b Block) ([]command, error) {
+	var cmds []command
+	var pending string
+	pendingLine := 0
+	for i, line := range strings.Split(strings.TrimSuffix(b.Code, "\n"), "\n") {
+		lineNo := b.Line + 1 + i
+		if pending == "" {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			line = strings.TrimPrefix(line, "$ ")
+			pendingLine = lineNo
+		}
+		if cont, ok := strings.CutSuffix(line, `\`); ok {
+			pending += cont + " "
+			continue
+		}
+		argv, err := splitArgs(pending + line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", pendingLine, err)
+		}
+		pending = ""
+		if len(argv) > 0 {
+			cmds = append(cmds, command{line: pendingLine, argv: argv})
+		}
+	}
+	if pending != "" {
+		return nil, fmt.Errorf("line %d: command ends in a line continuation", pendingLine)
+	}
+	return cmds, nil
+}
+
+// splitArgs splits a command line on whitespace. Single or double
+// quotes group words; nothing is expanded or escaped.
+func splitArgs(s string) ([]string, error) {
+	var argv []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				argv = append(argv, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		argv = append(argv, cur.String())
+	}
+	return argv, nil
+}
+
+// runGoBlock writes a Go block to a scratch directory as main.go and
+// runs it with `go run` from dir. A block without a package clause
+// gets `package main`; one without declarations becomes main's body.
+func runGoBlock(ctx context.Context, b Block, dir string) (string, error) {
+	src, err := os.MkdirTemp("", "mdsmith-test-go-")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(src) }()
+	main := filepath.Join(src, "main.go")
+	if err := os.WriteFile(main, []byte(goProgram(b.Code)), 0o600); err != nil {
+		return "", err
+	}
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", "run", main) // #nosec G204 -- fixed tool, generated file
+	if err := execute(cmd, dir, &stdout); err != nil {
+		return stdout.String(), fmt.Errorf("go run: %w", err)
+	}
+	return stdout.String(), nil
+}
+
+// declPrefixes start the first code line of Go declarations.
+var declPrefixes = []string{"import ", "import(", "func ", "type ", "var ", "var(", "const ", "const("}
+
+// goProgram turns a Go block into a main package.
+func goProgram(code string) string {
+	first := ""
+	for _, line := range strings.Split(code, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "//") {
+			first = line
+			break
+		}
+	}
+	switch {
+	case strings.HasPrefix(first, "package "):
+		return code
+	case hasAnyPrefix(first, declPrefixes):
+		return "package main\n\n" + code
+	}
+	return "package main\n\nfunc main() {\n" + strings.TrimSuffix(code, "\n") + "\n}\n"
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// execute runs cmd in dir, appending its stdout to stdout. A failure
+// carries the exit status and the last line of stderr.
+func execute(cmd *exec.Cmd, dir string, stdout *bytes.Buffer) error {
+	var stderr bytes.Buffer
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if detail := lastLine(stderr.String()); detail != "" {
+		msg += ": " + detail
+	}
+	return errors.New(msg)
+}
+
+func lastLine(s string) string {
+	s = strings.TrimRight(s, "\n")
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(s)
+}
@@ -112,6 +112,36 @@ func TestLintOnce_CatalogHost(t *testing.T) {
 	}
 }
 
+// TestLintOnce_IncludeSelectionHost verifies that an <?include?> which
+// embeds only part of its file is treated like a whole-file include:
+// the selected body belongs to the fragment, not the host.
+func TestLintOnce_IncludeSelectionHost(t *testing.T) {
+	dir := t.TempDir()
+	host := "# Host\n\n" +
+		"<?include\nfile: guide.md\nsection: Usage\n?>\n" +
+		"## Usage\n\nRun it.   \n" +
+		"<?/include?>\n\n" +
+		"<?include\nfile: example_test.go\nlines: 3-4\nwrap: go\n?>\n" +
+		"\n```go\nx := 1   \n```\n\n" +
+		"<?/include?>\n"
+	hostPath := filepath.Join(dir, "host.md")
+	require.NoError(t, os.WriteFile(hostPath, []byte(host), 0o644))
+
+	runner := &Runner{
+		Config:  makeTrailingSpacesConfig(),
+		Rules:   rule.All(),
+		RootDir: dir,
+	}
+	result := runner.Run([]string{hostPath})
+	require.Empty(t, result.Errors, "unexpected errors: %v", result.Errors)
+	for _, d := range result.Diagnostics {
+		if d.RuleName == trailingSpacesRuleName {
+			t.Errorf("host must not surface trailing-spaces from a selected include body: line %d: %s",
+				d.Line, d.Message)
+		}
+	}
+}
+
 // TestLintOnce_HostOwnedDiagnosticsPreserved verifies that diagnostics in
 // host-authored content (outside generated sections) are not suppressed.
 func TestLintOnce_HostOwnedDiagnosticsPreserved(t *testing.T) {
@@ -0,0 +1,94 @@
+// Package fenceinfo parses the info string of a fenced code block:
+// the language word and an optional attribute block in braces, as in
+//
+//	```json {skip-validate}
+//	```go {wrap=func}
+//
+// Attributes are space-separated. Each is a bare flag (skip-validate),
+// a key=value pair, or key="quoted value". Pandoc-style .class and
+// #id entries are kept as flags under their full spelling.
+package fenceinfo
+
+import (
+	"strings"
+)
+
+// Info is a parsed info string.
+type Info struct {
+	// Lang is the first word, lower-cased; empty when the info string
+	// starts with the attribute block or is blank.
+	Lang string
+	// Attrs maps attribute names to values; flags map to "".
+	Attrs map[string]string
+}
+
+// Parse splits info into its language and attributes. Text after the
+// language that is not in braces is ignored, as CommonMark does.
+func Parse(info string) Info {
+	info = strings.TrimSpace(info)
+	out := Info{Attrs: map[string]string{}}
+	open := strings.IndexByte(info, '{')
+	head := info
+	if open >= 0 {
+		head = info[:open]
+		end := strings.LastIndexByte(info, '}')
+		if end < open {
+			end = len(info)
+		}
+		out.Attrs = parseAttrs(info[open+1 : end])
+	}
+	if f := strings.Fields(head); len(f) > 0 {
+		out.Lang = strings.ToLower(f[0])
+	}
+	return out
+}
+
+// Has reports whether the attribute name is present.
+func (i Info) Has(name string) bool {
+	_, ok := i.Attrs[name]
+	return ok
+}
+
+// Get returns the value of the attribute name.
+func (i Info) Get(name string) (string, bool) {
+	v, ok := i.Attrs[name]
+	return v, ok
+}
+
+// parseAttrs reads space-separated flags and key=value pairs. A value
+// in double quotes may contain spaces.
+func parseAttrs(s string) map[string]string {
+	attrs := map[string]string{}
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		end := strings.IndexAny(s, " \t=")
+		if end < 0 {
+			attrs[s] = ""
+			break
+		}
+		key := s[:end]
+		if s[end] != '=' {
+			attrs[key] = ""
+			s = s[end:]
+			continue
+		}
+		val, rest := value(s[end+1:])
+		attrs[key] = val
+		s = rest
+	}
+	return attrs
+}
+
+// value reads one attribute value from the start of s and returns it
+// with the remaining text.
+func value(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		if end := strings.IndexByte(s[1:], '"'); end >= 0 {
+			return s[1 : end+1], s[end+2:]
+		}
+		return s[1:], ""
+	}
+	if end := strings.IndexAny(s, " \t"); end >= 0 {
+		return s[:end], s[end:]
+	}
+	return s, ""
+}
@@ -0,0 +1,43 @@
+package fenceinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		info  string
+		lang  string
+		attrs map[string]string
+	}{
+		{"", "", map[string]string{}},
+		{"go", "go", map[string]string{}},
+		{"JSON title", "json", map[string]string{}},
+		{"json {skip-validate}", "json", map[string]string{"skip-validate": ""}},
+		{"go{wrap=func}", "go", map[string]string{"wrap": "func"}},
+		{
+			`sh {.cmd #run title="a b" exit=1 test}`, "sh",
+			map[string]string{".cmd": "", "#run": "", "title": "a b", "exit": "1", "test": ""},
+		},
+		{"{skip-validate}", "", map[string]string{"skip-validate": ""}},
+		{`yaml {title="unclosed`, "yaml", map[string]string{"title": "unclosed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.info, func(t *testing.T) {
+			got := Parse(tt.info)
+			assert.Equal(t, tt.lang, got.Lang)
+			assert.Equal(t, tt.attrs, got.Attrs)
+		})
+	}
+}
+
+func TestHasAndGet(t *testing.T) {
+	i := Parse("go {wrap=func skip-validate}")
+	assert.True(t, i.Has("skip-validate"))
+	assert.False(t, i.Has("nope"))
+	v, ok := i.Get("wrap")
+	assert.True(t, ok)
+	assert.Equal(t, "func", v)
+}
@@ -71,6 +71,19 @@ func ContainsField(text string) bool {
 	return len(Fields(text)) > 0
 }
 
+// FieldSpans returns the byte ranges [start, end) of the {field}
+// placeholders in text. Escaped braces are skipped; offsets refer to
+// text unchanged.
+func FieldSpans(text string) [][2]int {
+	s := strings.ReplaceAll(text, "{{", "\x00\x00")
+	s = strings.ReplaceAll(s, "}}", "\x00\x00")
+	var spans [][2]int
+	for _, m := range fieldPattern.FindAllStringIndex(s, -1) {
+		spans = append(spans, [2]int{m[0], m[1]})
+	}
+	return spans
+}
+
 // SplitOnFields splits text on {field} placeholders and returns the literal
 // parts between them. Escaped braces are treated as literals.
 // For "{id}: {name}" it returns ["", ": ", ""].
@@ -184,3 +184,9 @@ func TestParseCUEPath_MixedQuotedAndIdent(t *testing.T) {
 	segments := ParseCUEPath(`params."my-key"`)
 	assert.Equal(t, []string{"params", "my-key"}, segments)
 }
+
+func TestFieldSpans(t *testing.T) {
+	assert.Equal(t, [][2]int{{6, 12}, {14, 19}}, FieldSpans("Hello {name}, {a.b}"))
+	assert.Equal(t, [][2]int{{10, 17}}, FieldSpans("{{lit}} x {title}"))
+	assert.Nil(t, FieldSpans("no fields"))
+}
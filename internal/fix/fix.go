@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 
 	"github.com/jeduden/mdsmith/internal/archetype/gensection"
@@ -42,6 +43,15 @@ type Fixer struct {
 	// leaves this nil and continues to derive dirFS from each file's
 	// absolute path.
 	SourceFS fs.FS
+	// Headings, when non-nil, routes the heading edits of rules that
+	// implement rule.HeadingRenamer through the rename flow so anchors
+	// in other workspace files follow a changed slug. Nil keeps those
+	// fixes file-local.
+	Headings HeadingWorkspace
+
+	// retargeted lists the files written by heading renames because
+	// their anchors pointed at a changed slug.
+	retargeted []string
 
 	// gitignoreCache caches GitignoreMatchers by directory so the
 	// matcher tree is walked once per directory across a fix run,
@@ -95,6 +105,7 @@ type Result struct {
 // containing remaining diagnostics, modified file paths, and any errors.
 func (f *Fixer) Fix(paths []string) *Result {
 	res := &Result{}
+	f.retargeted = nil
 
 	// Aggregate `before` diagnostics across files so the Failures
 	// count can be deduped after the loop. Repo-level rules
@@ -118,6 +129,11 @@ func (f *Fixer) Fix(paths []string) *Result {
 		res.Errors = append(res.Errors, errs...)
 	}
 	res.Failures = len(engine.DedupeDiagnostics(allBefore))
+	for _, p := range f.retargeted {
+		if !slices.Contains(res.Modified, p) {
+			res.Modified = append(res.Modified, p)
+		}
+	}
 
 	res.Diagnostics = engine.DedupeDiagnostics(res.Diagnostics)
 	sort.Slice(res.Diagnostics, func(i, j int) bool {
@@ -164,10 +180,15 @@ func (f *Fixer) fixFile(path string) ([]lint.Diagnostic, []lint.Diagnostic, stri
 	beforeDiags, checkErrs := engine.CheckRules(lf, f.Rules, effective)
 	errs = append(errs, append(settingsErrs, checkErrs...)...)
 
+	renamed := false
+	if f.Headings != nil {
+		lf, dirFS, fixable, renamed = f.applyHeadingRenames(path, lf, dirFS, fixable, &errs)
+	}
+
 	current := f.applyFixPasses(path, lf.Source, fixable, lf, dirFS, &errs)
 
 	var modified string
-	if !bytes.Equal(lf.Source, current) {
+	if renamed || !bytes.Equal(lf.Source, current) {
 		out := lf.FullSource(current)
 		if err := atomicWriteFile(path, out, info.Mode()); err != nil {
 			errs = append(errs, fmt.Errorf("writing %q: %w", path, err))
@@ -0,0 +1,135 @@
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// HeadingWorkspace is the workspace a Fixer routes heading renames
+// through. Besides the rename engine's questions it answers the two
+// the fixer needs to apply the resulting edits.
+type HeadingWorkspace interface {
+	rename.Workspace
+	// Rel maps a path passed to Fix to its workspace-relative path.
+	Rel(path string) (string, error)
+	// Write replaces the content of the file whose edits group under
+	// key and returns the path it wrote.
+	Write(key string, data []byte) (string, error)
+}
+
+// applyHeadingRenames runs renameHeadings on the file and, when a
+// heading changed, re-prepares the file from its new content. It
+// returns the file, its dirFS, the rules left to fix, and whether the
+// content changed.
+func (f *Fixer) applyHeadingRenames(
+	path string, lf *lint.File, dirFS fs.FS, fixable []rule.FixableRule, errs *[]error,
+) (*lint.File, fs.FS, []rule.FixableRule, bool) {
+	rest, source := f.renameHeadings(path, lf, fixable, errs)
+	if source == nil {
+		return lf, dirFS, rest, false
+	}
+	renamed, renamedFS, _, _, err := f.prepareFile(path, source)
+	if err != nil {
+		*errs = append(*errs, err)
+		return lf, dirFS, fixable, false
+	}
+	renamed.GeneratedRanges = gensection.FindAllGeneratedRanges(renamed)
+	return renamed, renamedFS, rest, true
+}
+
+// renameHeadings routes the heading edits of every rule.HeadingRenamer
+// in fixable through the rename flow. Files whose anchors point at a
+// changed slug are rewritten at once; the heading file's new content
+// is returned, or nil when no heading changed. The returned rules are
+// fixable without the renamers, which then have nothing left to fix.
+func (f *Fixer) renameHeadings(
+	path string, lf *lint.File, fixable []rule.FixableRule, errs *[]error,
+) ([]rule.FixableRule, []byte) {
+	var rest []rule.FixableRule
+	var renamers []rule.HeadingRenamer
+	for _, fr := range fixable {
+		if hr, ok := fr.(rule.HeadingRenamer); ok {
+			renamers = append(renamers, hr)
+			continue
+		}
+		rest = append(rest, fr)
+	}
+	if len(renamers) == 0 {
+		return fixable, nil
+	}
+	orig := lf.FullSource(lf.Source)
+	source := orig
+	rel := ""
+	for _, hr := range renamers {
+		cur := lf
+		var err error
+		if !bytes.Equal(source, orig) {
+			if cur, _, _, _, err = f.prepareFile(path, source); err != nil {
+				*errs = append(*errs, err)
+				break
+			}
+		}
+		renames := hr.HeadingRenames(cur)
+		if len(renames) > 0 && rel == "" {
+			// Resolved only now so files with nothing to rename never
+			// make the workspace load.
+			if rel, err = f.Headings.Rel(path); err != nil {
+				*errs = append(*errs, err)
+				return fixable, nil
+			}
+		}
+		for _, rn := range renames {
+			source = f.renameHeading(path, rel, source, cur.LineOffset, rn, errs)
+		}
+	}
+	if bytes.Equal(source, orig) {
+		return rest, nil
+	}
+	return rest, source
+}
+
+// renameHeading applies one heading rename to source, the heading
+// file's full content, and writes the anchor edits it implies for
+// other files. It returns the new content of the heading file.
+func (f *Fixer) renameHeading(
+	path, rel string, source []byte, lineOffset int, rn rule.HeadingRename, errs *[]error,
+) []byte {
+	line := rn.Line + lineOffset
+	changes, err := rename.HeadingSource(f.Headings, rel, rel, source, line, rn.Old, rn.New, rn.Source)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s:%d: renaming heading: %w", path, line, err))
+		return source
+	}
+	for key, edits := range changes {
+		src := source
+		if key != rel {
+			var ok bool
+			if _, src, ok = f.Headings.Resolve(key); !ok {
+				*errs = append(*errs, fmt.Errorf("cannot read %q to retarget anchors", key))
+				continue
+			}
+		}
+		out, err := rename.ApplyEdits(src, edits)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if key == rel {
+			source = out
+			continue
+		}
+		written, err := f.Headings.Write(key, out)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("writing %q: %w", key, err))
+			continue
+		}
+		f.retargeted = append(f.retargeted, written)
+	}
+	return source
+}
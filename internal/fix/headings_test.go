@@ -0,0 +1,135 @@
+package fix
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRenamer renames every "# Setup" heading to "# Install".
+type mockRenamer struct{}
+
+func (r *mockRenamer) ID() string       { return "MDS101" }
+func (r *mockRenamer) Name() string     { return "mock-renamer" }
+func (r *mockRenamer) Category() string { return "test" }
+
+func (r *mockRenamer) Check(f *lint.File) []lint.Diagnostic {
+	var diags []lint.Diagnostic
+	for _, rn := range r.HeadingRenames(f) {
+		diags = append(diags, lint.Diagnostic{
+			File: f.Path, Line: rn.Line, RuleID: r.ID(), RuleName: r.Name(), Message: "rename",
+		})
+	}
+	return diags
+}
+
+func (r *mockRenamer) Fix(f *lint.File) []byte {
+	return bytes.ReplaceAll(f.Source, []byte("# Setup\n"), []byte("# Install\n"))
+}
+
+func (r *mockRenamer) HeadingRenames(f *lint.File) []rule.HeadingRename {
+	var out []rule.HeadingRename
+	for i, line := range f.Lines {
+		if string(line) == "# Setup" {
+			out = append(out, rule.HeadingRename{Line: i + 1, Old: "Setup", New: "Install", Source: "Install"})
+		}
+	}
+	return out
+}
+
+var _ rule.HeadingRenamer = (*mockRenamer)(nil)
+
+// diskWorkspace is a HeadingWorkspace over the Markdown files of dir.
+type diskWorkspace struct {
+	dir string
+	idx *index.Index
+}
+
+func newDiskWorkspace(t *testing.T, dir string, rels ...string) *diskWorkspace {
+	t.Helper()
+	idx := index.New(dir)
+	idx.BuildSerial(rels, func(rel string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, rel))
+	})
+	return &diskWorkspace{dir: dir, idx: idx}
+}
+
+func (w *diskWorkspace) IncomingAnchorEdges(file, slug string) []index.Edge {
+	return w.idx.IncomingEdges(file, slug)
+}
+
+func (w *diskWorkspace) Files() []string { return w.idx.Files() }
+
+func (w *diskWorkspace) Resolve(file string) (string, []byte, bool) {
+	b, err := os.ReadFile(filepath.Join(w.dir, file))
+	return file, b, err == nil
+}
+
+func (w *diskWorkspace) Rel(path string) (string, error) {
+	rel, err := filepath.Rel(w.dir, path)
+	return filepath.ToSlash(rel), err
+}
+
+func (w *diskWorkspace) Write(key string, data []byte) (string, error) {
+	path := filepath.Join(w.dir, key)
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644))
+	}
+}
+
+func TestFix_HeadingRenamesRetargetAnchors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.md": "---\ntitle: A\n---\n# Setup\n\nSee [below](#setup).\n",
+		"b.md": "Read [setup](a.md#setup).\n",
+	})
+	fixer := &Fixer{
+		Config:           &config.Config{Rules: map[string]config.RuleCfg{"mock-renamer": {Enabled: true}}},
+		Rules:            []rule.Rule{&mockRenamer{}},
+		StripFrontMatter: true,
+		Headings:         newDiskWorkspace(t, dir, "a.md", "b.md"),
+	}
+	result := fixer.Fix([]string{filepath.Join(dir, "a.md")})
+	require.Empty(t, result.Errors)
+	assert.Equal(t, 1, result.Failures)
+	assert.Empty(t, result.Diagnostics)
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")}, result.Modified)
+
+	a, err := os.ReadFile(filepath.Join(dir, "a.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: A\n---\n# Install\n\nSee [below](#install).\n", string(a))
+	b, err := os.ReadFile(filepath.Join(dir, "b.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "Read [setup](a.md#install).\n", string(b))
+}
+
+func TestFix_HeadingRenamesFileLocalWithoutWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.md": "# Setup\n",
+		"b.md": "Read [setup](a.md#setup).\n",
+	})
+	fixer := &Fixer{
+		Config: &config.Config{Rules: map[string]config.RuleCfg{"mock-renamer": {Enabled: true}}},
+		Rules:  []rule.Rule{&mockRenamer{}},
+	}
+	result := fixer.Fix([]string{filepath.Join(dir, "a.md")})
+	require.Empty(t, result.Errors)
+	assert.Equal(t, []string{filepath.Join(dir, "a.md")}, result.Modified)
+	b, err := os.ReadFile(filepath.Join(dir, "b.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "Read [setup](a.md#setup).\n", string(b))
+}
@@ -0,0 +1,271 @@
+package hunspell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// flag modes of the FLAG directive.
+const (
+	flagChar = iota // one character per flag (the default)
+	flagLong        // two characters per flag
+	flagNum         // comma-separated decimal numbers
+)
+
+// affix is one PFX or SFX entry: strip is removed from the stem and
+// add appended (or prepended) when cond matches the stem.
+type affix struct {
+	flag   string
+	prefix bool
+	cross  bool
+	strip  string
+	add    string
+	cond   []charClass
+}
+
+// charClass is one position of an affix condition: any character, a
+// set, or a negated set.
+type charClass struct {
+	any bool
+	neg bool
+	set string
+}
+
+func (c charClass) match(r rune) bool {
+	if c.any {
+		return true
+	}
+	return strings.ContainsRune(c.set, r) != c.neg
+}
+
+// matches reports whether the condition holds at the start (prefix)
+// or the end (suffix) of stem.
+func (a *affix) matches(stem string) bool {
+	if len(a.cond) == 0 {
+		return true
+	}
+	runes := []rune(stem)
+	if len(runes) < len(a.cond) {
+		return false
+	}
+	if !a.prefix {
+		runes = runes[len(runes)-len(a.cond):]
+	}
+	for i, c := range a.cond {
+		if !c.match(runes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCondition parses an affix condition such as "[^aeiou]y".
+func parseCondition(s string) ([]charClass, error) {
+	if s == "." {
+		return nil, nil
+	}
+	var cond []charClass
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '.':
+			cond = append(cond, charClass{any: true})
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("condition %q: unclosed [", s)
+			}
+			set := runes[i+1 : end]
+			neg := len(set) > 0 && set[0] == '^'
+			if neg {
+				set = set[1:]
+			}
+			cond = append(cond, charClass{neg: neg, set: string(set)})
+			i = end
+		default:
+			cond = append(cond, charClass{set: string(runes[i])})
+		}
+	}
+	return cond, nil
+}
+
+// affParser holds the state of reading an .aff file.
+type affParser struct {
+	d       *Dictionary
+	mode    int
+	aliases [][]string
+	pending int // table rows still expected
+	table   string
+	header  []string
+}
+
+// parse reads the directives of an .aff file.
+func (p *affParser) parse(text string) error {
+	for n, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := p.line(fields); err != nil {
+			return fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	return nil
+}
+
+// line handles one directive, or one row of the table in progress.
+func (p *affParser) line(f []string) error {
+	if p.pending > 0 && f[0] == p.table {
+		p.pending--
+		return p.row(f)
+	}
+	p.pending = 0
+	switch f[0] {
+	case "FLAG":
+		return p.flagMode(f)
+	case "TRY":
+		p.d.try = []rune(arg(f))
+	case "KEY":
+		p.d.key = strings.Split(arg(f), "|")
+	case "WORDCHARS":
+		p.d.wordChars = arg(f)
+	case "NOSUGGEST":
+		p.d.noSuggest = arg(f)
+	case "FORBIDDENWORD":
+		p.d.forbidden = arg(f)
+	case "NEEDAFFIX", "PSEUDOROOT":
+		p.d.needAffix = arg(f)
+	case "KEEPCASE":
+		p.d.keepCase = arg(f)
+	case "ONLYINCOMPOUND":
+		p.d.onlyInCompound = arg(f)
+	case "REP", "AF":
+		return p.startTable(f, 2)
+	case "PFX", "SFX":
+		return p.startTable(f, 4)
+	}
+	return nil
+}
+
+func arg(f []string) string {
+	if len(f) < 2 {
+		return ""
+	}
+	return f[1]
+}
+
+func (p *affParser) flagMode(f []string) error {
+	switch arg(f) {
+	case "long":
+		p.mode = flagLong
+	case "num":
+		p.mode = flagNum
+	case "UTF-8":
+		p.mode = flagChar
+	default:
+		return fmt.Errorf("unsupported FLAG %q", arg(f))
+	}
+	return nil
+}
+
+// startTable reads a table header: "REP 3", "AF 5", or "SFX A Y 4".
+func (p *affParser) startTable(f []string, want int) error {
+	if len(f) < want {
+		return fmt.Errorf("%s header needs %d fields", f[0], want)
+	}
+	n, err := strconv.Atoi(f[want-1])
+	if err != nil {
+		return fmt.Errorf("%s header: bad count %q", f[0], f[want-1])
+	}
+	p.table, p.pending, p.header = f[0], n, f
+	return nil
+}
+
+func (p *affParser) row(f []string) error {
+	switch p.table {
+	case "REP":
+		if len(f) < 3 {
+			return fmt.Errorf("REP row needs 3 fields")
+		}
+		from := strings.ReplaceAll(f[1], "_", " ")
+		to := strings.ReplaceAll(f[2], "_", " ")
+		p.d.rep = append(p.d.rep, [2]string{from, to})
+	case "AF":
+		p.aliases = append(p.aliases, p.splitFlags(arg(f)))
+	default:
+		return p.affixRow(f)
+	}
+	return nil
+}
+
+// affixRow reads "SFX A strip add[/flags] cond".
+func (p *affParser) affixRow(f []string) error {
+	if len(f) < 4 {
+		return fmt.Errorf("%s row needs at least 4 fields", f[0])
+	}
+	if f[1] != p.header[1] {
+		return fmt.Errorf("%s row flag %q does not match header %q", f[0], f[1], p.header[1])
+	}
+	a := &affix{
+		flag:   f[1],
+		prefix: f[0] == "PFX",
+		cross:  p.header[2] == "Y",
+		strip:  zero(f[2]),
+		add:    zero(strings.SplitN(f[3], "/", 2)[0]),
+	}
+	cond := "."
+	if len(f) > 4 {
+		cond = f[4]
+	}
+	var err error
+	if a.cond, err = parseCondition(cond); err != nil {
+		return err
+	}
+	p.d.addAffix(a)
+	return nil
+}
+
+func zero(s string) string {
+	if s == "0" {
+		return ""
+	}
+	return s
+}
+
+// parseFlags splits the flag field of a word into flags, resolving
+// an AF alias number.
+func (p *affParser) parseFlags(s string) []string {
+	if len(p.aliases) > 0 {
+		if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= len(p.aliases) {
+			return p.aliases[n-1]
+		}
+	}
+	return p.splitFlags(s)
+}
+
+// splitFlags splits a flag field according to the FLAG mode.
+func (p *affParser) splitFlags(s string) []string {
+	var flags []string
+	switch p.mode {
+	case flagNum:
+		for _, part := range strings.Split(s, ",") {
+			if part != "" {
+				flags = append(flags, part)
+			}
+		}
+	case flagLong:
+		runes := []rune(s)
+		for i := 0; i+1 < len(runes); i += 2 {
+			flags = append(flags, string(runes[i:i+2]))
+		}
+	default:
+		for _, r := range s {
+			flags = append(flags, string(r))
+		}
+	}
+	return flags
+}
@@ -0,0 +1,161 @@
+package hunspell
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// casing classifies the capitalisation of a word.
+type casing int
+
+const (
+	caseLower casing = iota // all lower case, or no cased letters
+	caseTitle               // first letter upper, the rest lower
+	caseUpper               // all cased letters upper
+	caseMixed               // anything else, like "iPhone"
+)
+
+func caseOf(word string) casing {
+	upper, lower, firstUpper := 0, 0, false
+	for i, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+			if i == 0 {
+				firstUpper = true
+			}
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	switch {
+	case upper == 0:
+		return caseLower
+	case lower == 0:
+		return caseUpper
+	case upper == 1 && firstUpper:
+		return caseTitle
+	}
+	return caseMixed
+}
+
+// title returns word with its first letter upper case and the rest
+// lower case.
+func title(word string) string {
+	r, n := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + strings.ToLower(word[n:])
+}
+
+// Check reports whether word is spelled correctly. A capitalised or
+// upper-case word is also accepted when its lower-case form is, and
+// an upper-case word when its capitalised form is, unless the entry
+// carries the KEEPCASE flag.
+func (d *Dictionary) Check(word string) bool {
+	word = strings.ReplaceAll(word, "’", "'")
+	if word == "" || d.isForbidden(word) {
+		return false
+	}
+	if d.lookup(word, true) {
+		return true
+	}
+	switch caseOf(word) {
+	case caseTitle:
+		return d.lookup(strings.ToLower(word), false)
+	case caseUpper:
+		return d.lookup(strings.ToLower(word), false) || d.lookup(title(word), false)
+	}
+	return false
+}
+
+func (d *Dictionary) isForbidden(word string) bool {
+	if d.forbidden == "" {
+		return false
+	}
+	for _, flags := range d.words[word] {
+		if slices.Contains(flags, d.forbidden) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup reports whether word is a stem or a stem with one prefix,
+// one suffix, or a cross-product pair of both. exact is false when
+// word was recased, so KEEPCASE stems do not match.
+func (d *Dictionary) lookup(word string, exact bool) bool {
+	if d.hasStem(word, exact, "", "") {
+		return true
+	}
+	if d.stripSuffix(word, exact, "", false) {
+		return true
+	}
+	for _, n := range d.preLens {
+		if n >= len(word) {
+			break
+		}
+		for _, p := range d.prefixes[word[:n]] {
+			stem := p.strip + word[n:]
+			if !p.matches(stem) {
+				continue
+			}
+			if d.hasStem(stem, exact, p.flag, "") {
+				return true
+			}
+			if p.cross && d.stripSuffix(stem, exact, p.flag, true) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripSuffix reports whether word is a stem plus one suffix. With
+// crossOnly, only cross-product suffixes apply, and the stem must
+// also carry prefix.
+func (d *Dictionary) stripSuffix(word string, exact bool, prefix string, crossOnly bool) bool {
+	for _, n := range d.sufLens {
+		if n >= len(word) {
+			break // the remaining stem must not be empty
+		}
+		for _, s := range d.suffixes[word[len(word)-n:]] {
+			if crossOnly && !s.cross {
+				continue
+			}
+			stem := word[:len(word)-n] + s.strip
+			if !s.matches(stem) {
+				continue
+			}
+			if d.hasStem(stem, exact, s.flag, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasStem reports whether some homonym of stem carries every flag
+// given. A bare stem (no flags asked for) must not need an affix.
+func (d *Dictionary) hasStem(stem string, exact bool, flag1, flag2 string) bool {
+	for _, flags := range d.words[stem] {
+		switch {
+		case d.has(flags, d.forbidden), d.has(flags, d.onlyInCompound):
+			continue
+		case flag1 == "" && d.has(flags, d.needAffix):
+			continue
+		case !exact && d.has(flags, d.keepCase):
+			continue
+		}
+		if (flag1 == "" || slices.Contains(flags, flag1)) && (flag2 == "" || slices.Contains(flags, flag2)) {
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether flags contains the special flag f, if the
+// dictionary defines f at all.
+func (d *Dictionary) has(flags []string, f string) bool {
+	return f != "" && slices.Contains(flags, f)
+}
@@ -0,0 +1,176 @@
+// Package hunspell reads Hunspell dictionaries (a .dic word list with
+// its .aff affix file) and checks and suggests words with them.
+//
+// It implements the subset most dictionaries rely on: PFX and SFX
+// rules with conditions and cross products, FLAG long/num/UTF-8, AF
+// flag aliases, TRY, KEY, and REP for suggestions, and the
+// NOSUGGEST, FORBIDDENWORD, NEEDAFFIX, KEEPCASE, and ONLYINCOMPOUND
+// flags. Compounding, twofold affixes, and morphology are not
+// supported: words that only a compound rule accepts are reported.
+package hunspell
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Dictionary is a loaded Hunspell dictionary. It is safe for
+// concurrent use.
+type Dictionary struct {
+	words map[string][][]string // stem → flag sets of its homonyms
+
+	prefixes map[string][]*affix // by add string
+	suffixes map[string][]*affix
+	preLens  []int // distinct byte lengths of prefix add strings
+	sufLens  []int
+
+	try       []rune
+	key       []string
+	rep       [][2]string
+	wordChars string
+
+	noSuggest, forbidden, needAffix, keepCase, onlyInCompound string
+
+	mu      sync.Mutex
+	suggest map[string][]string // memoized Suggest results
+}
+
+// charsets maps SET names to decoders. UTF-8 needs no decoding.
+var charsets = map[string]*charmap.Charmap{
+	"ISO8859-1":        charmap.ISO8859_1,
+	"ISO8859-2":        charmap.ISO8859_2,
+	"ISO8859-3":        charmap.ISO8859_3,
+	"ISO8859-4":        charmap.ISO8859_4,
+	"ISO8859-5":        charmap.ISO8859_5,
+	"ISO8859-6":        charmap.ISO8859_6,
+	"ISO8859-7":        charmap.ISO8859_7,
+	"ISO8859-8":        charmap.ISO8859_8,
+	"ISO8859-9":        charmap.ISO8859_9,
+	"ISO8859-10":       charmap.ISO8859_10,
+	"ISO8859-13":       charmap.ISO8859_13,
+	"ISO8859-14":       charmap.ISO8859_14,
+	"ISO8859-15":       charmap.ISO8859_15,
+	"KOI8-R":           charmap.KOI8R,
+	"KOI8-U":           charmap.KOI8U,
+	"microsoft-cp1251": charmap.Windows1251,
+}
+
+// Load reads the dictionary at dicPath and the .aff file beside it.
+func Load(dicPath string) (*Dictionary, error) {
+	affPath := strings.TrimSuffix(dicPath, ".dic") + ".aff"
+	aff, err := os.ReadFile(affPath)
+	if err != nil {
+		return nil, err
+	}
+	dic, err := os.ReadFile(dicPath)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(aff, dic)
+}
+
+// Parse builds a dictionary from the contents of an .aff and a .dic
+// file. The SET directive of the .aff file names the encoding of
+// both; without one they are read as ISO8859-1, as Hunspell does.
+func Parse(aff, dic []byte) (*Dictionary, error) {
+	enc := charset(aff)
+	affText, err := decode(aff, enc)
+	if err != nil {
+		return nil, err
+	}
+	dicText, err := decode(dic, enc)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dictionary{
+		words:    map[string][][]string{},
+		prefixes: map[string][]*affix{},
+		suffixes: map[string][]*affix{},
+		suggest:  map[string][]string{},
+	}
+	p := &affParser{d: d}
+	if err := p.parse(affText); err != nil {
+		return nil, fmt.Errorf("affix file: %w", err)
+	}
+	p.parseDic(dicText)
+	return d, nil
+}
+
+// charset returns the SET of an .aff file.
+func charset(aff []byte) string {
+	for _, line := range bytes.Split(aff, []byte("\n")) {
+		f := strings.Fields(string(line))
+		if len(f) >= 2 && f[0] == "SET" {
+			return f[1]
+		}
+	}
+	return "ISO8859-1"
+}
+
+func decode(b []byte, enc string) (string, error) {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	if strings.EqualFold(enc, "UTF-8") {
+		return string(b), nil
+	}
+	cm, ok := charsets[enc]
+	if !ok {
+		return "", fmt.Errorf("unsupported encoding %q", enc)
+	}
+	out, err := cm.NewDecoder().Bytes(b)
+	return string(out), err
+}
+
+// parseDic reads the word list. The first line holds the approximate
+// word count; each other line is word[/flags], optionally followed
+// by morphological fields, which are ignored.
+func (p *affParser) parseDic(text string) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if i == 0 || line == "" || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		entry := strings.Fields(line)[0]
+		word, flags := splitEntry(entry)
+		p.d.words[word] = append(p.d.words[word], p.parseFlags(flags))
+	}
+}
+
+// splitEntry splits "word/flags" at the first unescaped slash.
+func splitEntry(entry string) (word, flags string) {
+	for i := 0; i < len(entry); i++ {
+		switch entry[i] {
+		case '\\':
+			i++
+		case '/':
+			return strings.ReplaceAll(entry[:i], `\/`, "/"), entry[i+1:]
+		}
+	}
+	return strings.ReplaceAll(entry, `\/`, "/"), ""
+}
+
+func (d *Dictionary) addAffix(a *affix) {
+	index, lens := d.suffixes, &d.sufLens
+	if a.prefix {
+		index, lens = d.prefixes, &d.preLens
+	}
+	if _, ok := index[a.add]; !ok {
+		n := len(a.add)
+		if i := sort.SearchInts(*lens, n); i == len(*lens) || (*lens)[i] != n {
+			*lens = append(*lens, 0)
+			copy((*lens)[i+1:], (*lens)[i:])
+			(*lens)[i] = n
+		}
+	}
+	index[a.add] = append(index[a.add], a)
+}
+
+// WordChars returns the characters besides letters that the
+// dictionary counts as part of a word (its WORDCHARS).
+func (d *Dictionary) WordChars() string { return d.wordChars }
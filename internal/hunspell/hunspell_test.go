@@ -0,0 +1,110 @@
+package hunspell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestDict(t *testing.T, name string) *Dictionary {
+	t.Helper()
+	d, err := Load("testdata/" + name + ".dic")
+	require.NoError(t, err)
+	return d
+}
+
+func TestCheck(t *testing.T) {
+	d := loadTestDict(t, "en")
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"the", true},
+		{"The", true},
+		{"THE", true},
+		{"cats", true},
+		{"cat's", true},
+		{"cat’s", true},
+		{"boxes", true},
+		{"tries", true},
+		{"tried", true},
+		{"unhappy", true},
+		{"unlocked", true},
+		{"Paris", true},
+		{"PARIS", true},
+		{"paris", false},
+		{"OpenSSL", true},
+		{"openssl", false},
+		{"OPENSSL", false},
+		{"colour", false},
+		{"pseudo", false},
+		{"teh", false},
+		{"boxs", false},
+		{"trys", false},
+		{"unthe", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, d.Check(tt.word), tt.word)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	d := loadTestDict(t, "en")
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"teh", "the"},
+		{"Teh", "The"},
+		{"paris", "Paris"},
+		{"fone", "phone"},
+		{"alot", "a lot"},
+		{"boxs", "box"},
+		{"cst", "cat"},
+		{"thecat", "the cat"},
+	}
+	for _, tt := range tests {
+		got := d.Suggest(tt.word, 5)
+		require.NotEmpty(t, got, tt.word)
+		assert.Equal(t, tt.want, got[0], "%s: %v", tt.word, got)
+	}
+	assert.NotContains(t, d.Suggest("dam", 10), "damn", "NOSUGGEST words are not offered")
+	assert.Equal(t, []string{"box", "boxes"}, d.Suggest("boxs", 5), "edits rank in Hunspell's order")
+	assert.Len(t, d.Suggest("teh", 1), 1)
+}
+
+func TestSuggestNgram(t *testing.T) {
+	d := loadTestDict(t, "en")
+	assert.Equal(t, []string{"phone"}, d.Suggest("phonnee", 5))
+	assert.Empty(t, d.Suggest("qqqq", 5))
+}
+
+func TestEncodingsAndFlagModes(t *testing.T) {
+	d := loadTestDict(t, "latin1")
+	assert.True(t, d.Check("café"))
+	assert.True(t, d.Check("cafés"))
+
+	d = loadTestDict(t, "num")
+	assert.True(t, d.Check("redos"))
+	assert.True(t, d.Check("works"))
+	assert.False(t, d.Check("reworks"))
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte("SET EBCDIC\n"), []byte("0\n"))
+	assert.ErrorContains(t, err, `unsupported encoding "EBCDIC"`)
+
+	_, err = Parse([]byte("SFX A Y 1\nSFX A 0 s [ab\n"), []byte("0\n"))
+	assert.ErrorContains(t, err, "line 2: condition \"[ab\": unclosed [")
+
+	_, err = Load("testdata/missing.dic")
+	assert.Error(t, err)
+}
+
+func TestSplitEntry(t *testing.T) {
+	w, f := splitEntry(`and\/or/AB`)
+	assert.Equal(t, "and/or", w)
+	assert.Equal(t, "AB", f)
+}
@@ -0,0 +1,259 @@
+package hunspell
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns up to limit corrections for word, best first.
+//
+// Candidates come in the order Hunspell tries them: a case fix, the
+// REP table, keyboard neighbours (KEY), swapped letters, an extra
+// letter, a missing or wrong letter (TRY), and a split into two
+// words. Only when none of these is a word does Suggest rank the
+// dictionary's stems by n-gram similarity. Results keep the
+// capitalisation of word and are memoized per dictionary.
+func (d *Dictionary) Suggest(word string, limit int) []string {
+	d.mu.Lock()
+	cached, ok := d.suggest[word]
+	d.mu.Unlock()
+	if !ok {
+		cached = d.suggestUncached(word)
+		d.mu.Lock()
+		d.suggest[word] = cached
+		d.mu.Unlock()
+	}
+	if len(cached) > limit {
+		cached = cached[:limit]
+	}
+	return append([]string(nil), cached...)
+}
+
+// maxSuggestions bounds the memoized list; Suggest trims it further.
+const maxSuggestions = 10
+
+func (d *Dictionary) suggestUncached(word string) []string {
+	word = strings.ReplaceAll(word, "’", "'")
+	c := caseOf(word)
+	s := &suggester{d: d, seen: map[string]bool{word: true}}
+	s.add(strings.ToLower(word))
+	s.add(title(word))
+	base := word
+	if c == caseTitle || c == caseUpper {
+		base = strings.ToLower(word)
+	}
+	s.edits(base)
+	if len(s.out) == 0 {
+		s.out = d.ngram(base)
+	}
+	for i, w := range s.out {
+		s.out[i] = recase(w, c)
+	}
+	return dedupe(s.out)
+}
+
+// suggester collects distinct, correctly spelled candidates.
+type suggester struct {
+	d    *Dictionary
+	seen map[string]bool
+	out  []string
+}
+
+func (s *suggester) add(cand string) {
+	if len(s.out) >= maxSuggestions || s.seen[cand] {
+		return
+	}
+	s.seen[cand] = true
+	for _, w := range strings.Split(cand, " ") {
+		if !s.d.Check(w) || s.noSuggest(w) {
+			return
+		}
+	}
+	s.out = append(s.out, cand)
+}
+
+// noSuggest reports whether every entry of cand is NOSUGGEST.
+func (s *suggester) noSuggest(cand string) bool {
+	entries := s.d.words[cand]
+	if len(entries) == 0 || s.d.noSuggest == "" {
+		return false
+	}
+	for _, flags := range entries {
+		if !s.d.has(flags, s.d.noSuggest) {
+			return false
+		}
+	}
+	return true
+}
+
+// edits tries every single-edit candidate of w.
+func (s *suggester) edits(w string) {
+	for _, r := range s.d.rep {
+		s.replace(w, r[0], r[1])
+	}
+	runes := []rune(w)
+	s.keyNeighbours(runes)
+	for i := 0; i+1 < len(runes); i++ {
+		s.add(string(swap(runes, i)))
+	}
+	for i := range runes {
+		s.add(string(runes[:i]) + string(runes[i+1:]))
+	}
+	for i := 0; i <= len(runes); i++ {
+		for _, t := range s.d.try {
+			s.add(string(runes[:i]) + string(t) + string(runes[i:]))
+		}
+	}
+	for i := range runes {
+		for _, t := range s.d.try {
+			if t != runes[i] {
+				s.add(string(runes[:i]) + string(t) + string(runes[i+1:]))
+			}
+		}
+	}
+	for i := 1; i < len(runes); i++ {
+		s.add(string(runes[:i]) + " " + string(runes[i:]))
+	}
+}
+
+// replace tries a REP entry at each place from occurs in w. A
+// leading ^ or trailing $ anchors from to the start or end.
+func (s *suggester) replace(w, from, to string) {
+	start, end := strings.HasPrefix(from, "^"), strings.HasSuffix(from, "$")
+	from = strings.TrimSuffix(strings.TrimPrefix(from, "^"), "$")
+	if from == "" {
+		return
+	}
+	for i := 0; i+len(from) <= len(w); i++ {
+		if !strings.HasPrefix(w[i:], from) ||
+			(start && i != 0) || (end && i+len(from) != len(w)) {
+			continue
+		}
+		s.add(w[:i] + to + w[i+len(from):])
+	}
+}
+
+// keyNeighbours replaces each letter with the keys next to it on
+// the keyboard rows the KEY directive lists.
+func (s *suggester) keyNeighbours(runes []rune) {
+	for i, r := range runes {
+		for _, row := range s.d.key {
+			keys := []rune(row)
+			for j, k := range keys {
+				if k != r {
+					continue
+				}
+				for _, n := range []int{j - 1, j + 1} {
+					if n >= 0 && n < len(keys) {
+						s.add(string(runes[:i]) + string(keys[n]) + string(runes[i+1:]))
+					}
+				}
+			}
+		}
+	}
+}
+
+func swap(runes []rune, i int) []rune {
+	out := append([]rune(nil), runes...)
+	out[i], out[i+1] = out[i+1], out[i]
+	return out
+}
+
+// ngram ranks the dictionary's stems by shared 1- to 3-grams with w,
+// penalising length differences.
+func (d *Dictionary) ngram(w string) []string {
+	if len([]rune(w)) < 3 {
+		return nil
+	}
+	type scored struct {
+		word  string
+		score int
+	}
+	var best []scored
+	for stem, entries := range d.words {
+		if !d.suggestible(stem, entries) {
+			continue
+		}
+		sc := similarity(w, strings.ToLower(stem))
+		if sc <= 0 {
+			continue
+		}
+		best = append(best, scored{stem, sc})
+	}
+	sort.Slice(best, func(i, j int) bool {
+		if best[i].score != best[j].score {
+			return best[i].score > best[j].score
+		}
+		return best[i].word < best[j].word
+	})
+	var out []string
+	for _, b := range best {
+		if len(out) == maxSuggestions {
+			break
+		}
+		out = append(out, b.word)
+	}
+	return out
+}
+
+// suggestible reports whether stem may be offered as it stands.
+func (d *Dictionary) suggestible(stem string, entries [][]string) bool {
+	for _, flags := range entries {
+		if !d.has(flags, d.noSuggest) && !d.has(flags, d.forbidden) &&
+			!d.has(flags, d.needAffix) && !d.has(flags, d.onlyInCompound) {
+			return true
+		}
+	}
+	return false
+}
+
+// similarity counts the 1-, 2-, and 3-grams of a found in b, less
+// twice the length difference. A score no higher than a's length
+// counts as no similarity.
+func similarity(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	score := 0
+	for n := 1; n <= 3; n++ {
+		for i := 0; i+n <= len(ar); i++ {
+			if strings.Contains(b, string(ar[i:i+n])) {
+				score++
+			}
+		}
+	}
+	diff := len(ar) - len(br)
+	if diff < 0 {
+		diff = -diff
+	}
+	score -= 2 * diff
+	if score <= len(ar) {
+		return 0
+	}
+	return score
+}
+
+// recase gives cand the capitalisation class c of the misspelling.
+// Candidates with their own capitals, like proper names, keep them.
+func recase(cand string, c casing) string {
+	if caseOf(cand) != caseLower {
+		return cand
+	}
+	switch c {
+	case caseTitle:
+		return title(cand)
+	case caseUpper:
+		return strings.ToUpper(cand)
+	}
+	return cand
+}
+
+func dedupe(words []string) []string {
+	seen := map[string]bool{}
+	out := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
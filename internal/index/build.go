@@ -3,10 +3,12 @@ package index
 import (
 	"bytes"
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
 	"sync"
 
+	"github.com/jeduden/mdsmith/internal/globpath"
 	"github.com/jeduden/mdsmith/internal/linkgraph"
 	"github.com/jeduden/mdsmith/internal/lint"
 	"github.com/jeduden/mdsmith/internal/mdtext"
@@ -545,7 +547,8 @@ func piLineRange(pi *lint.ProcessingInstruction, source []byte, fmOffset int) (i
 // collectLinkEdges emits one Edge per Markdown link in f. Inline
 // links produce EdgeAnchorLink (`[x](#sec)`) or EdgeFileLink
 // (`[x](./other.md)`); reference-style links (`[x][label]`) produce
-// EdgeRefLink. Extraction routes through linkgraph so MDS027, the
+// EdgeRefLink, with TargetFile set when the label's definition points
+// at another file. Extraction routes through linkgraph so MDS027, the
 // backlinks CLI, and this index walk the same parser.
 //
 // Absolute / escapes-the-root file targets are dropped (linkgraph's
@@ -588,25 +591,36 @@ func collectLinkEdges(filePath string, f *lint.File, fmOffset int) []Edge {
 		})
 	}
 	for _, r := range linkgraph.ExtractRefLinks(f) {
-		out = append(out, Edge{
+		e := Edge{
 			SourceFile:  filePath,
 			SourceLine:  r.Line + fmOffset,
 			SourceCol:   r.Column,
 			TargetLabel: r.Label,
 			Kind:        EdgeRefLink,
-		})
+		}
+		// A definition that points at another file makes the use a
+		// cross-file citation, so it carries the file like an inline
+		// link would.
+		if r.Target.Path != "" {
+			e.TargetFile = linkgraph.ResolveRelTarget(filePath, r.Target.Path)
+			if e.TargetFile != "" {
+				e.TargetAnchor = linkgraph.NormalizeAnchor(r.Target.Anchor)
+			}
+		}
+		out = append(out, e)
 	}
 	return out
 }
 
 // collectDirectiveEdges emits one Edge per `<?include?>`,
-// `<?catalog?>`, and `<?build?>` directive whose body specifies a
-// usable target. Include and build edges carry a workspace-relative
-// TargetFile. Catalog edges are emitted with Unresolved=true and an
+// `<?catalog?>`, `<?build?>`, `<?table?>`, and `<?snippet?>`
+// directive whose body specifies a usable target. Include, build,
+// table, and snippet edges carry a workspace-relative TargetFile. Catalog edges are emitted with Unresolved=true and an
 // empty TargetFile — the glob list isn't expanded inside the
-// per-file extractor (see linkgraph.ExpandCatalog for callers that
-// need the concrete list), and IncomingEdges skips unresolved edges
-// so catalog hosts don't appear as phantom self-backlinks.
+// per-file extractor (TargetGlobs feeds linkgraph.ExpandCatalog for
+// callers that need the concrete list), and IncomingEdges skips
+// unresolved edges so catalog hosts don't appear as phantom
+// self-backlinks.
 //
 // Targets that are absolute or escape the workspace are dropped
 // silently; dedicated lint rules report those as diagnostics.
@@ -639,15 +653,69 @@ func collectDirectiveEdges(filePath string, f *lint.File, fmOffset int) []Edge {
 				TargetFile: tgt,
 				Kind:       EdgeBuild,
 			})
-		case linkgraph.DirectiveCatalog:
+		case linkgraph.DirectiveTable:
+			tgt := linkgraph.ResolveRelTarget(filePath, d.Path)
+			if tgt == "" {
+				continue
+			}
 			out = append(out, Edge{
 				SourceFile: filePath,
 				SourceLine: line,
 				SourceCol:  d.Col,
-				Kind:       EdgeCatalog,
-				Unresolved: true,
+				TargetFile: tgt,
+				Kind:       EdgeTable,
 			})
+		case linkgraph.DirectiveSnippet:
+			tgt := linkgraph.ResolveRelTarget(filePath, d.Path)
+			if tgt == "" {
+				continue
+			}
+			out = append(out, Edge{
+				SourceFile: filePath,
+				SourceLine: line,
+				SourceCol:  d.Col,
+				TargetFile: tgt,
+				Kind:       EdgeSnippet,
+			})
+		case linkgraph.DirectiveCatalog:
+			out = append(out, Edge{
+				SourceFile:  filePath,
+				SourceLine:  line,
+				SourceCol:   d.Col,
+				TargetGlobs: CatalogGlobs(filePath, d),
+				Kind:        EdgeCatalog,
+				Unresolved:  true,
+			})
+		}
+	}
+	return out
+}
+
+// CatalogGlobs rewrites a catalog directive's patterns relative to
+// the workspace root, the way the catalog rule resolves them: against
+// source-dir when set, otherwise against the host file's directory.
+// A `!` prefix is kept. Patterns that escape the root are dropped, as
+// is everything when source-dir itself escapes.
+func CatalogGlobs(filePath string, d linkgraph.DirectiveEdge) []string {
+	base := path.Dir(filePath)
+	if d.SourceDir != "" {
+		dir, escapes := globpath.ResolveAgainstRoot("", d.SourceDir)
+		if escapes || path.IsAbs(d.SourceDir) {
+			return nil
+		}
+		base = dir
+	}
+	var out []string
+	for _, g := range d.Globs {
+		neg := strings.HasPrefix(g, "!")
+		resolved, escapes := globpath.ResolveAgainstRoot(base, strings.TrimPrefix(g, "!"))
+		if escapes || resolved == "" {
+			continue
+		}
+		if neg {
+			resolved = "!" + resolved
 		}
+		out = append(out, resolved)
 	}
 	return out
 }
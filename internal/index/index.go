@@ -86,19 +86,26 @@ const (
 	EdgeCatalog
 	// EdgeBuild is a `<?build source: …?>` directive.
 	EdgeBuild
+	// EdgeTable is a `<?table file: …?>` directive.
+	EdgeTable
+	// EdgeSnippet is a `<?snippet file: …?>` directive.
+	EdgeSnippet
 )
 
 // Edge records one reference from a source position to a target.
 //
-// Empty TargetFile means "same file as Source" (used for anchor and
-// reference-style links). Empty TargetAnchor means the reference
-// targets the file as a whole (e.g. `[text](./other.md)`).
+// Empty TargetFile means "same file as Source" (used for anchor links
+// and for reference-style links whose definition does not point at
+// another file). Empty TargetAnchor means the reference targets the
+// file as a whole (e.g. `[text](./other.md)`).
 //
 // Unresolved is set on edges whose target shape is a glob pattern
 // (catalog directives) rather than a single file. Reverse-edge
 // queries (IncomingEdges / BacklinksFor) skip unresolved edges so
 // catalog directives don't surface as phantom self-backlinks the way
-// empty-TargetFile placeholders did before plan 153.
+// empty-TargetFile placeholders did before plan 153. TargetGlobs
+// carries those patterns rewritten relative to the workspace root,
+// ready for linkgraph.ExpandCatalog.
 type Edge struct {
 	SourceFile   string
 	SourceLine   int // 1-based
@@ -106,6 +113,7 @@ type Edge struct {
 	TargetFile   string
 	TargetAnchor string
 	TargetLabel  string
+	TargetGlobs  []string
 	Kind         EdgeKind
 	Unresolved   bool
 }
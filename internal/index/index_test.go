@@ -117,6 +117,38 @@ func TestOutgoingEdgesIncludeAndBuild(t *testing.T) {
 	assert.True(t, bld, "missing build edge: %+v", fe.Outgoing)
 }
 
+func TestOutgoingEdgesTable(t *testing.T) {
+	t.Parallel()
+	idx := New("/root")
+	src := "# T\n\n<?table\nfile: data/os.yaml\n?>\n<?/table?>\n"
+	idx.Update("docs/p.md", []byte(src))
+	fe, ok := idx.File("docs/p.md")
+	require.True(t, ok)
+	require.Len(t, fe.Outgoing, 1)
+	assert.Equal(t, EdgeTable, fe.Outgoing[0].Kind)
+	assert.Equal(t, "docs/data/os.yaml", fe.Outgoing[0].TargetFile)
+
+	in := idx.BacklinksFor("docs/data/os.yaml")
+	require.Len(t, in, 1)
+	assert.Equal(t, "docs/p.md", in[0].SourceFile)
+}
+
+func TestOutgoingEdgesSnippet(t *testing.T) {
+	t.Parallel()
+	idx := New("/root")
+	src := "# T\n\n<?snippet\nfile: ../run/runner.go\nsymbol: Run\n?>\n<?/snippet?>\n"
+	idx.Update("docs/p.md", []byte(src))
+	fe, ok := idx.File("docs/p.md")
+	require.True(t, ok)
+	require.Len(t, fe.Outgoing, 1)
+	assert.Equal(t, EdgeSnippet, fe.Outgoing[0].Kind)
+	assert.Equal(t, "run/runner.go", fe.Outgoing[0].TargetFile)
+
+	in := idx.BacklinksFor("run/runner.go")
+	require.Len(t, in, 1)
+	assert.Equal(t, "docs/p.md", in[0].SourceFile)
+}
+
 func TestIncomingEdgesAcrossFiles(t *testing.T) {
 	t.Parallel()
 	idx := New("/root")
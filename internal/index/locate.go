@@ -89,7 +89,7 @@ type LocateResult struct {
 	DirectiveArg string
 	// Directive argument value (raw, untrimmed).
 	DirectiveValue string
-	// DirectiveTargetFile is the raw `file:` (for include) or
+	// DirectiveTargetFile is the raw `file:` (for include, table, or snippet) or
 	// `source:` (for build) value the cursor sits on, copied
 	// verbatim from the directive body. It is *not* resolved
 	// against the host file's directory — the LSP layer pipes it
@@ -427,7 +427,7 @@ func piToLocate(pi *lint.ProcessingInstruction, source []byte, lines [][]byte, l
 		res.DirectiveArg = m[1]
 		res.DirectiveValue = strings.Trim(strings.TrimSpace(m[2]), `"'`)
 	}
-	if (pi.Name == "include" && res.DirectiveArg == "file") ||
+	if ((pi.Name == "include" || pi.Name == "table" || pi.Name == "snippet") && res.DirectiveArg == "file") ||
 		(pi.Name == "build" && res.DirectiveArg == "source") {
 		res.DirectiveTargetFile = res.DirectiveValue
 	}
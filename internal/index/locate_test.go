@@ -145,6 +145,24 @@ func TestLocateBuildDirectiveSourceArg(t *testing.T) {
 	assert.Equal(t, "x.md", res.DirectiveTargetFile)
 }
 
+func TestLocateTableDirectiveFileArg(t *testing.T) {
+	t.Parallel()
+	src := "# Top\n\n<?table\nfile: data/os.csv\n?>\n<?/table?>\n"
+	res := Locator{Path: "a.md"}.Locate([]byte(src), 4, 8)
+	assert.Equal(t, TokenDirectiveArg, res.Tag)
+	assert.Equal(t, "table", res.DirectiveName)
+	assert.Equal(t, "data/os.csv", res.DirectiveTargetFile)
+}
+
+func TestLocateSnippetDirectiveFileArg(t *testing.T) {
+	t.Parallel()
+	src := "# Top\n\n<?snippet\nfile: run/runner.go\nsymbol: Run\n?>\n<?/snippet?>\n"
+	res := Locator{Path: "a.md"}.Locate([]byte(src), 4, 8)
+	assert.Equal(t, TokenDirectiveArg, res.Tag)
+	assert.Equal(t, "snippet", res.DirectiveName)
+	assert.Equal(t, "run/runner.go", res.DirectiveTargetFile)
+}
+
 func TestLocateFileLinkResolvesAgainstSourceDir(t *testing.T) {
 	t.Parallel()
 	// Source file lives in `docs/`; the relative link `./b.md`
@@ -0,0 +1,68 @@
+package index
+
+import (
+	"sort"
+
+	"github.com/jeduden/mdsmith/internal/globpath"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+)
+
+// Unreachable returns the indexed files that no entry point reaches,
+// sorted. A file is an entry point when it matches entryPoints (globpath
+// patterns; `!` excludes). Reachability follows file links, resolved
+// reference-style links, include and build directives, and catalog
+// globs expanded against the indexed files. Anchor-only links never
+// leave their file, so they are ignored.
+func (i *Index) Unreachable(entryPoints []string) []string {
+	files := i.Files()
+	sort.Strings(files)
+	reached := make(map[string]bool, len(files))
+	var queue []string
+	visit := func(p string) {
+		if !reached[p] {
+			reached[p] = true
+			queue = append(queue, p)
+		}
+	}
+	for _, f := range files {
+		if globpath.MatchAny(entryPoints, f) {
+			visit(f)
+		}
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, e := range i.OutgoingEdges(cur) {
+			for _, t := range i.EdgeTargets(e, files) {
+				visit(t)
+			}
+		}
+	}
+	var out []string
+	for _, f := range files {
+		if !reached[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// EdgeTargets returns the indexed files e points at, from the
+// workspace file list files: the target file of a link, include,
+// build, table, or snippet, or the files a catalog's globs match. Anchor links, reference
+// links whose definition is not a file, and targets missing from the
+// index give none.
+func (i *Index) EdgeTargets(e Edge, files []string) []string {
+	switch e.Kind {
+	case EdgeFileLink, EdgeRefLink, EdgeInclude, EdgeBuild, EdgeTable, EdgeSnippet:
+		if e.TargetFile == "" {
+			return nil
+		}
+		if _, ok := i.File(e.TargetFile); ok {
+			return []string{NormalizePath(e.TargetFile)}
+		}
+	case EdgeCatalog:
+		return linkgraph.ExpandCatalog(e.TargetGlobs, files)
+	}
+	return nil
+}
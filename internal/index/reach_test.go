@@ -0,0 +1,56 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnreachableFollowsEveryEdgeKind(t *testing.T) {
+	t.Parallel()
+	idx := New("/root")
+	idx.Update("README.md", []byte("# Home\n\n[Guide](docs/guide.md) and [ref][r].\n\n"+
+		"[r]: docs/ref.md#usage\n\n<?include\nfile: docs/part.md\n?>\n<?/include?>\n"))
+	idx.Update("docs/guide.md", []byte("# Guide\n\n<?catalog\nglob: \"cli/*.md\"\n?>\n<?/catalog?>\n"))
+	idx.Update("docs/ref.md", []byte("# Ref\n"))
+	idx.Update("docs/part.md", []byte("Part.\n"))
+	idx.Update("docs/cli/check.md", []byte("# Check\n\n[Back](#check)\n"))
+	idx.Update("docs/cli/fix.md", []byte("# Fix\n"))
+	idx.Update("docs/lonely.md", []byte("# Lonely\n\n[Home](../README.md)\n"))
+	idx.Update("notes/a.md", []byte("# A\n\n[B](b.md)\n"))
+	idx.Update("notes/b.md", []byte("# B\n\n[A](a.md)\n"))
+
+	assert.Equal(t, []string{"docs/lonely.md", "notes/a.md", "notes/b.md"},
+		idx.Unreachable([]string{"README.md"}))
+	assert.Equal(t, []string{"docs/lonely.md"},
+		idx.Unreachable([]string{"README.md", "notes/a.md"}))
+	assert.Empty(t, idx.Unreachable([]string{"**/*.md"}))
+	assert.Len(t, idx.Unreachable([]string{"**/*.md", "!**/*.md"}), 9)
+}
+
+func TestRefLinkEdgeCarriesDefinitionFile(t *testing.T) {
+	t.Parallel()
+	idx := New("/root")
+	idx.Update("docs/a.md", []byte("[x][b] [y][web] [z][top]\n\n"+
+		"[b]: b.md#Intro\n[web]: https://example.com/\n[top]: #a\n"))
+	edges := idx.OutgoingEdges("docs/a.md")
+	require.Len(t, edges, 3)
+	assert.Equal(t, "docs/b.md", edges[0].TargetFile)
+	assert.Equal(t, "intro", edges[0].TargetAnchor)
+	assert.Empty(t, edges[1].TargetFile)
+	assert.Empty(t, edges[2].TargetFile)
+	assert.Len(t, idx.BacklinksFor("docs/b.md"), 1)
+}
+
+func TestCatalogEdgeCarriesRootRelativeGlobs(t *testing.T) {
+	t.Parallel()
+	idx := New("/root")
+	idx.Update("docs/index.md", []byte("<?catalog\nglob:\n  - \"guides/*.md\"\n  - \"!guides/draft.md\"\n"+
+		"  - \"../../escape/*.md\"\n?>\n<?/catalog?>\n\n"+
+		"<?catalog\nglob: \"*.md\"\nsource-dir: notes\n?>\n<?/catalog?>\n"))
+	edges := idx.OutgoingEdges("docs/index.md")
+	require.Len(t, edges, 2)
+	assert.Equal(t, []string{"docs/guides/*.md", "!docs/guides/draft.md"}, edges[0].TargetGlobs)
+	assert.Equal(t, []string{"notes/*.md"}, edges[1].TargetGlobs)
+}
@@ -0,0 +1,92 @@
+package index
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+// ScanFS indexes every Markdown file under rootFS, the filesystem of
+// the project root rootDir. `.git`, `node_modules`, and gitignored
+// paths are skipped, and files over maxBytes (0 means no limit) are
+// left out of the graph.
+func ScanFS(rootFS fs.FS, rootDir string, maxBytes int64) *Index {
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		absRoot = rootDir
+	}
+	gi := lint.NewGitignoreMatcher(absRoot)
+	var files []string
+	_ = fs.WalkDir(rootFS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == "." {
+			return nil
+		}
+		ignored := gi.IsIgnored(filepath.Join(absRoot, filepath.FromSlash(p)), d.IsDir())
+		if d.IsDir() {
+			if ignored || d.Name() == ".git" || d.Name() == "node_modules" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !ignored && isMarkdownPath(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	idx := New(rootDir)
+	idx.BuildSerial(files, func(p string) ([]byte, error) {
+		return lint.ReadFSFileLimited(rootFS, p, maxBytes)
+	})
+	return idx
+}
+
+// ForFile returns the index of f's project root and f's path within
+// it. Rules that look across files use it; the index is scanned once
+// per run when the engine supplies a RunCache. ok is false when f has
+// no project root (stdin, or no config) or lies outside it.
+func ForFile(f *lint.File) (idx *Index, rel string, ok bool) {
+	if f.RootFS == nil || f.RootDir == "" {
+		return nil, "", false
+	}
+	rel, ok = rootRelative(f.RootDir, f.Path)
+	if !ok {
+		return nil, "", false
+	}
+	build := func() any { return ScanFS(f.RootFS, f.RootDir, f.MaxInputBytes) }
+	if f.RunCache == nil {
+		return build().(*Index), rel, true
+	}
+	key := fmt.Sprintf("index\x00%s\x00%d", f.RootDir, f.MaxInputBytes)
+	return f.RunCache.Workspace(key, build).(*Index), rel, true
+}
+
+func isMarkdownPath(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// rootRelative returns p relative to rootDir with forward slashes, or
+// ok=false when p lies outside rootDir.
+func rootRelative(rootDir, p string) (string, bool) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absRoot, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
@@ -27,21 +27,26 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/concisenessscoring"
 	_ "github.com/jeduden/mdsmith/internal/rules/crossfilereferenceintegrity"
 	_ "github.com/jeduden/mdsmith/internal/rules/descriptivelinktext"
+	_ "github.com/jeduden/mdsmith/internal/rules/directivegraph"
 	"github.com/jeduden/mdsmith/internal/rules/directorystructure"
 	_ "github.com/jeduden/mdsmith/internal/rules/duplicatedcontent"
 	_ "github.com/jeduden/mdsmith/internal/rules/emphasisstyle"
 	_ "github.com/jeduden/mdsmith/internal/rules/emptysectionbody"
+	_ "github.com/jeduden/mdsmith/internal/rules/externallinks"
 	_ "github.com/jeduden/mdsmith/internal/rules/fencedcodelanguage"
 	_ "github.com/jeduden/mdsmith/internal/rules/fencedcodestyle"
+	_ "github.com/jeduden/mdsmith/internal/rules/fencedcodesyntax"
 	_ "github.com/jeduden/mdsmith/internal/rules/firstlineheading"
 	_ "github.com/jeduden/mdsmith/internal/rules/forbiddenparagraphstarts"
 	_ "github.com/jeduden/mdsmith/internal/rules/forbiddentext"
 	_ "github.com/jeduden/mdsmith/internal/rules/githooksync"
+	_ "github.com/jeduden/mdsmith/internal/rules/headingcase"
 	_ "github.com/jeduden/mdsmith/internal/rules/headingincrement"
 	_ "github.com/jeduden/mdsmith/internal/rules/headingstyle"
 	_ "github.com/jeduden/mdsmith/internal/rules/horizontalrulestyle"
 	_ "github.com/jeduden/mdsmith/internal/rules/include"
 	_ "github.com/jeduden/mdsmith/internal/rules/linelength"
+	_ "github.com/jeduden/mdsmith/internal/rules/linkpolicy"
 	_ "github.com/jeduden/mdsmith/internal/rules/linkvalidity"
 	_ "github.com/jeduden/mdsmith/internal/rules/listindent"
 	_ "github.com/jeduden/mdsmith/internal/rules/listmarkerspace"
@@ -57,6 +62,7 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/noinlinehtml"
 	_ "github.com/jeduden/mdsmith/internal/rules/nomultipleblanks"
 	_ "github.com/jeduden/mdsmith/internal/rules/noreferencestyle"
+	_ "github.com/jeduden/mdsmith/internal/rules/nosecrets"
 	_ "github.com/jeduden/mdsmith/internal/rules/nospaceincodespans"
 	_ "github.com/jeduden/mdsmith/internal/rules/nospaceinlinktext"
 	_ "github.com/jeduden/mdsmith/internal/rules/notrailingpunctuation"
@@ -64,8 +70,10 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/noundefinedreferencelabels"
 	_ "github.com/jeduden/mdsmith/internal/rules/nounusedlinkdefinitions"
 	_ "github.com/jeduden/mdsmith/internal/rules/orderedlistnumbering"
+	_ "github.com/jeduden/mdsmith/internal/rules/orphanedfiles"
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphreadability"
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphstructure"
+	_ "github.com/jeduden/mdsmith/internal/rules/paragraphwrap"
 	_ "github.com/jeduden/mdsmith/internal/rules/propernames"
 	_ "github.com/jeduden/mdsmith/internal/rules/recipesafety"
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredmentions"
@@ -73,11 +81,16 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredtextpatterns"
 	_ "github.com/jeduden/mdsmith/internal/rules/singleh1"
 	_ "github.com/jeduden/mdsmith/internal/rules/singletrailingnewline"
+	_ "github.com/jeduden/mdsmith/internal/rules/snippet"
+	_ "github.com/jeduden/mdsmith/internal/rules/spelling"
+	_ "github.com/jeduden/mdsmith/internal/rules/table"
 	_ "github.com/jeduden/mdsmith/internal/rules/tableformat"
 	_ "github.com/jeduden/mdsmith/internal/rules/tablereadability"
+	_ "github.com/jeduden/mdsmith/internal/rules/terminology"
 	_ "github.com/jeduden/mdsmith/internal/rules/toc"
 	_ "github.com/jeduden/mdsmith/internal/rules/tocdirective"
 	_ "github.com/jeduden/mdsmith/internal/rules/tokenbudget"
+	_ "github.com/jeduden/mdsmith/internal/rules/typography"
 	_ "github.com/jeduden/mdsmith/internal/rules/unclosedcodeblock"
 
 	"github.com/stretchr/testify/assert"
@@ -293,19 +306,25 @@ func runGoodFolderFile(
 
 // attachFixtureFS scopes a fixture's lint.File to a directory on disk.
 // For rules that resolve cross-tree paths against a project root
-// (currently MDS019 catalog) it also pins RootFS and RootDir to the
-// same directory so the rule can resolve ".." segments and anchor
-// gitignore lookups the way it does in a real workspace. For all
-// other rules, RootFS/RootDir are left nil to preserve their existing
-// fixture semantics — notably MDS020, whose schema reader switches
-// resolution strategy based on whether RootFS is set.
+// (currently MDS019 catalog, MDS020 required-structure, MDS076
+// orphaned-files, and MDS077 directive-graph) it also pins RootFS and
+// RootDir to the same directory so the rule can resolve ".." segments,
+// schema paths, and gitignore lookups the way it does in a real
+// workspace. MDS060 spelling and MDS075 external-links get RootDir
+// alone, so fixture dictionary and cache paths resolve against the
+// fixture directory. For all other rules, RootFS/RootDir are left nil
+// to preserve their existing fixture semantics.
 func attachFixtureFS(f *lint.File, dir string, r rule.Rule) {
 	fsys := os.DirFS(dir)
 	f.FS = fsys
-	if r != nil && r.ID() == "MDS019" {
+	if r != nil && (r.ID() == "MDS019" || r.ID() == "MDS020" || r.ID() == "MDS076" ||
+		r.ID() == "MDS077") {
 		f.RootFS = fsys
 		f.RootDir = dir
 	}
+	if r != nil && (r.ID() == "MDS060" || r.ID() == "MDS075") {
+		f.RootDir = dir
+	}
 }
 
 // fixtureFilePath returns the value to use as f.Path when running a
@@ -313,9 +332,10 @@ func attachFixtureFS(f *lint.File, dir string, r rule.Rule) {
 // MDS048), it returns a path inside a fresh non-repo tempdir so the
 // fixture cannot fail based on the contributor's local git config or
 // installed hooks. For rules that resolve paths against the project
-// root (currently MDS019), it returns the fixture's absolute path so
-// projectRelFileDir-style logic computes the same root-relative
-// directory it would for a real `mdsmith check <abs-path>` invocation.
+// root (currently MDS019, MDS020, MDS076, and MDS077), it returns the
+// fixture's absolute path so projectRelFileDir-style logic computes
+// the same root-relative directory it would for a real
+// `mdsmith check <abs-path>` invocation.
 // For all other rules it returns the basename so existing tests are
 // unaffected.
 func fixtureFilePath(t *testing.T, r rule.Rule, filePath string) string {
@@ -323,7 +343,8 @@ func fixtureFilePath(t *testing.T, r rule.Rule, filePath string) string {
 	if r != nil && r.ID() == "MDS048" {
 		return filepath.Join(t.TempDir(), filepath.Base(filePath))
 	}
-	if r != nil && r.ID() == "MDS019" {
+	if r != nil && (r.ID() == "MDS019" || r.ID() == "MDS020" || r.ID() == "MDS076" ||
+		r.ID() == "MDS077") {
 		abs, err := filepath.Abs(filePath)
 		require.NoError(t, err)
 		return abs
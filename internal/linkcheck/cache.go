@@ -0,0 +1,93 @@
+package linkcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCachePath is where `links check-external` keeps its results,
+// relative to the project root.
+const DefaultCachePath = ".mdsmith-cache/links.json"
+
+// cacheVersion is bumped when the file layout changes; a file with
+// another version is read as empty.
+const cacheVersion = 1
+
+// Cache holds the results of earlier checks keyed by URL, fragment
+// included. It is not safe for concurrent use.
+type Cache struct {
+	Version int               `json:"version"`
+	Entries map[string]Result `json:"entries"`
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{Version: cacheVersion, Entries: map[string]Result{}}
+}
+
+// LoadCache reads the cache at path. A missing file, or one written by
+// another version, gives an empty cache.
+func LoadCache(path string) (*Cache, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewCache(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse link cache %s: %w", path, err)
+	}
+	if c.Version != cacheVersion || c.Entries == nil {
+		return NewCache(), nil
+	}
+	return &c, nil
+}
+
+// Save writes the cache to path through a temporary file, so a
+// reader never sees a partial file.
+func (c *Cache) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".links-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Lookup returns the result for u. With ttl above zero, a result
+// checked longer than ttl before now is treated as missing.
+func (c *Cache) Lookup(u string, ttl time.Duration, now time.Time) (Result, bool) {
+	r, ok := c.Entries[u]
+	if !ok || (ttl > 0 && now.Sub(r.Checked) > ttl) {
+		return Result{}, false
+	}
+	r.URL = u
+	return r, true
+}
+
+// Store records r under its URL.
+func (c *Cache) Store(r Result) {
+	c.Entries[r.URL] = r
+}
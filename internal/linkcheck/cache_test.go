@@ -0,0 +1,47 @@
+package linkcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_RoundTripAndTTL(t *testing.T) {
+	path := t.TempDir() + "/sub/links.json"
+	c, err := LoadCache(path)
+	require.NoError(t, err)
+	assert.Empty(t, c.Entries)
+
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Store(Result{URL: "https://a.test/", Status: 200, Checked: old})
+	c.Store(Result{URL: "https://a.test/#x", Status: 200, Error: "fragment", Checked: old})
+	require.NoError(t, c.Save(path))
+
+	c, err = LoadCache(path)
+	require.NoError(t, err)
+	r, ok := c.Lookup("https://a.test/", 0, old.Add(48*time.Hour))
+	require.True(t, ok)
+	assert.Equal(t, "https://a.test/", r.URL)
+	assert.True(t, r.OK())
+	_, ok = c.Lookup("https://a.test/", 24*time.Hour, old.Add(48*time.Hour))
+	assert.False(t, ok, "expired")
+	_, ok = c.Lookup("https://b.test/", 0, old)
+	assert.False(t, ok)
+}
+
+func TestLoadCache_OtherVersionAndCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "links.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "entries": {"u": {}}}`), 0o644))
+	c, err := LoadCache(path)
+	require.NoError(t, err)
+	assert.Empty(t, c.Entries)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err = LoadCache(path)
+	assert.ErrorContains(t, err, "parse link cache")
+}
@@ -0,0 +1,306 @@
+// Package linkcheck checks external URLs over HTTP for the
+// `links check-external` command and keeps the results in an on-disk
+// cache that the external-links rule (MDS075) reads.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the zero values of the Checker fields.
+const (
+	DefaultConcurrency  = 8
+	DefaultHostInterval = 100 * time.Millisecond
+	DefaultRetries      = 2
+	DefaultBackoff      = 500 * time.Millisecond
+	DefaultTimeout      = 15 * time.Second
+)
+
+// maxBodyBytes caps how much of a page is read to find fragment ids.
+const maxBodyBytes = 10 << 20
+
+// Result is the outcome of checking one URL.
+type Result struct {
+	URL string `json:"-"`
+	// Status is the final HTTP status code, 0 when no response came.
+	Status int `json:"status,omitempty"`
+	// Error explains a failure that Status alone does not: a network
+	// error or a missing fragment.
+	Error   string    `json:"error,omitempty"`
+	Checked time.Time `json:"checked"`
+}
+
+// OK reports whether the URL resolved, fragment included.
+func (r Result) OK() bool {
+	return r.Error == "" && r.Status >= 200 && r.Status < 400
+}
+
+// Reason describes why the URL failed.
+func (r Result) Reason() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return fmt.Sprintf("HTTP %d", r.Status)
+}
+
+// Checker checks URLs. The zero value uses the defaults above and
+// http.DefaultTransport.
+type Checker struct {
+	// Transport sends the requests. Tests plug in a stand-in here.
+	Transport http.RoundTripper
+	// BaseURL, when set, replaces the scheme and host of every request
+	// and prefixes its path; the original host goes in the Host
+	// header. This points the checker at a local stand-in server.
+	BaseURL string
+	// Concurrency is the number of pages fetched at once. Zero or a
+	// negative value uses DefaultConcurrency.
+	Concurrency int
+	// HostInterval is the minimum time between two requests to the
+	// same host. A negative value turns the limit off.
+	HostInterval time.Duration
+	// Retries is how often a network error, 429, or 5xx is retried.
+	// A negative value turns retries off.
+	Retries int
+	// Backoff is the wait before the first retry; it doubles for each
+	// further one.
+	Backoff time.Duration
+	// Timeout bounds each request.
+	Timeout time.Duration
+	// UserAgent is sent with every request when set.
+	UserAgent string
+}
+
+// Check fetches each distinct page once and returns one result per
+// URL, in the order given. A URL with a fragment fails when the
+// fetched HTML has no element with that id or name.
+func (c *Checker) Check(ctx context.Context, urls []string) ([]Result, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	pages := map[string]bool{} // page URL → its body is needed
+	var order []string
+	for _, u := range urls {
+		page, frag := splitFragment(u)
+		if _, seen := pages[page]; !seen {
+			order = append(order, page)
+		}
+		pages[page] = pages[page] || frag != ""
+	}
+
+	type fetched struct {
+		status int
+		ids    map[string]bool
+		err    error
+	}
+	got := make(map[string]fetched, len(order))
+	var mu sync.Mutex
+	lim := &hostLimiter{interval: pick(c.HostInterval, DefaultHostInterval), next: map[string]time.Time{}}
+	workers := c.Concurrency
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, page := range order {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			status, ids, err := c.fetch(ctx, client, lim, page, pages[page])
+			mu.Lock()
+			got[page] = fetched{status, ids, err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now().UTC()
+	out := make([]Result, len(urls))
+	for i, u := range urls {
+		page, frag := splitFragment(u)
+		f := got[page]
+		r := Result{URL: u, Status: f.status, Checked: now}
+		switch {
+		case f.err != nil:
+			r.Error = f.err.Error()
+		case frag != "" && r.OK() && !hasFragment(f.ids, frag):
+			r.Error = fmt.Sprintf("fragment %q not found", "#"+frag)
+		}
+		out[i] = r
+	}
+	return out, nil
+}
+
+func (c *Checker) client() (*http.Client, error) {
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if c.BaseURL != "" {
+		base, err := url.Parse(c.BaseURL)
+		if err != nil || base.Scheme == "" || base.Host == "" {
+			return nil, fmt.Errorf("invalid base URL %q", c.BaseURL)
+		}
+		rt = &baseTransport{base: base, next: rt}
+	}
+	return &http.Client{Transport: rt, Timeout: pick(c.Timeout, DefaultTimeout)}, nil
+}
+
+// fetch requests page and returns its status and, when withBody is
+// set and the page is HTML, the ids it defines. It tries HEAD first
+// when no body is needed and falls back to GET when HEAD fails, since
+// many servers answer HEAD wrongly.
+func (c *Checker) fetch(
+	ctx context.Context, client *http.Client, lim *hostLimiter, page string, withBody bool,
+) (int, map[string]bool, error) {
+	method := http.MethodGet
+	if !withBody {
+		method = http.MethodHead
+	}
+	retries := pick(c.Retries, DefaultRetries)
+	backoff := pick(c.Backoff, DefaultBackoff)
+	for attempt := 0; ; attempt++ {
+		status, ids, err := c.do(ctx, client, lim, method, page)
+		if method == http.MethodHead && (err != nil || status >= 400) {
+			method = http.MethodGet
+			status, ids, err = c.do(ctx, client, lim, method, page)
+		}
+		if !retryable(status, err) || attempt >= retries || ctx.Err() != nil {
+			return status, ids, err
+		}
+		select {
+		case <-time.After(backoff << attempt):
+		case <-ctx.Done():
+			return status, ids, err
+		}
+	}
+}
+
+func (c *Checker) do(
+	ctx context.Context, client *http.Client, lim *hostLimiter, method, page string,
+) (int, map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, page, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if err := lim.wait(ctx, req.URL.Host); err != nil {
+		return 0, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var ids map[string]bool
+	if method == http.MethodGet && strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		if err != nil {
+			return resp.StatusCode, nil, err
+		}
+		ids = htmlIDs(body)
+	}
+	return resp.StatusCode, ids, nil
+}
+
+// retryable reports whether a response may succeed when repeated.
+func retryable(status int, err error) bool {
+	return err != nil || status == http.StatusTooManyRequests || status >= 500
+}
+
+// splitFragment splits u at its first '#'.
+func splitFragment(u string) (page, frag string) {
+	page, frag, _ = strings.Cut(u, "#")
+	return page, frag
+}
+
+// idRe matches an id or name attribute; one of groups 1 to 3 holds
+// the value.
+var idRe = regexp.MustCompile(`(?i)\s(?:id|name)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+func htmlIDs(body []byte) map[string]bool {
+	ids := map[string]bool{}
+	for _, m := range idRe.FindAllSubmatch(body, -1) {
+		ids[string(m[1])+string(m[2])+string(m[3])] = true
+	}
+	return ids
+}
+
+// hasFragment reports whether frag names an element of the page.
+// "top" always exists, and GitHub prefixes the ids of rendered
+// Markdown with "user-content-".
+func hasFragment(ids map[string]bool, frag string) bool {
+	if decoded, err := url.PathUnescape(frag); err == nil {
+		frag = decoded
+	}
+	return frag == "top" || ids[frag] || ids["user-content-"+frag]
+}
+
+// pick returns v, or def when v is zero. Negative values become
+// zero, so callers can tell "off" from "default".
+func pick[T int | time.Duration](v, def T) T {
+	if v == 0 {
+		return def
+	}
+	return max(v, 0)
+}
+
+// hostLimiter spaces out requests to the same host.
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+// wait blocks until host may receive another request.
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+	if d := at.Sub(now); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// baseTransport sends every request to base instead of its own host,
+// keeping the original host in the Host header. Redirects go through
+// it too, so a stand-in server can redirect to any host.
+type baseTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *baseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Host = req.URL.Host
+	out.URL.Scheme = t.base.Scheme
+	out.URL.Host = t.base.Host
+	out.URL.Path = strings.TrimRight(t.base.Path, "/") + req.URL.Path
+	out.URL.RawPath = ""
+	return t.next.RoundTrip(out)
+}
@@ -0,0 +1,160 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standIn serves a few fixed pages for any host and counts requests.
+func standIn(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	var flaky atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<h2 id="install">Install</h2><a name='legacy'></a>` +
+			`<div id=user-content-usage></div>`))
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte("plain"))
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if flaky.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "https://other.test/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/host", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Host != "docs.example.com" {
+			http.Error(w, r.Host, http.StatusBadRequest)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newChecker(base string) *Checker {
+	return &Checker{BaseURL: base, HostInterval: -1, Backoff: time.Millisecond}
+}
+
+func TestCheck_StatusesAndFragments(t *testing.T) {
+	var hits atomic.Int32
+	srv := standIn(t, &hits)
+	c := newChecker(srv.URL)
+	urls := []string{
+		"https://docs.example.com/ok",
+		"https://docs.example.com/ok#install",
+		"https://docs.example.com/ok#legacy",
+		"https://docs.example.com/ok#usage",
+		"https://docs.example.com/ok#missing",
+		"https://docs.example.com/gone",
+		"https://docs.example.com/no-head",
+		"https://docs.example.com/moved",
+		"https://docs.example.com/host",
+	}
+	res, err := c.Check(context.Background(), urls)
+	require.NoError(t, err)
+	require.Len(t, res, len(urls))
+	for i, r := range res {
+		assert.Equal(t, urls[i], r.URL)
+	}
+	assert.True(t, res[0].OK())
+	assert.True(t, res[1].OK())
+	assert.True(t, res[2].OK())
+	assert.True(t, res[3].OK(), "GitHub user-content- prefix")
+	assert.False(t, res[4].OK())
+	assert.Equal(t, `fragment "#missing" not found`, res[4].Reason())
+	assert.False(t, res[5].OK())
+	assert.Equal(t, "HTTP 404", res[5].Reason())
+	assert.True(t, res[6].OK(), "GET fallback after 405 on HEAD")
+	assert.True(t, res[7].OK(), "redirect followed through the stand-in")
+	assert.True(t, res[8].OK(), "original host sent in the Host header")
+}
+
+func TestCheck_RetriesServerErrors(t *testing.T) {
+	var hits atomic.Int32
+	srv := standIn(t, &hits)
+	res, err := newChecker(srv.URL).Check(context.Background(), []string{"https://x.test/flaky"})
+	require.NoError(t, err)
+	assert.True(t, res[0].OK())
+
+	c := newChecker(srv.URL)
+	c.Retries = -1
+	res, err = c.Check(context.Background(), []string{"https://x.test/gone"})
+	require.NoError(t, err)
+	assert.Equal(t, 404, res[0].Status)
+}
+
+func TestCheck_FetchesEachPageOnce(t *testing.T) {
+	var hits atomic.Int32
+	srv := standIn(t, &hits)
+	_, err := newChecker(srv.URL).Check(context.Background(), []string{
+		"https://x.test/ok#install", "https://x.test/ok#legacy", "https://x.test/ok",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestCheck_NegativeConcurrencyUsesDefault(t *testing.T) {
+	var hits atomic.Int32
+	srv := standIn(t, &hits)
+	c := newChecker(srv.URL)
+	c.Concurrency = -1
+	res, err := c.Check(context.Background(), []string{"https://x.test/ok", "https://x.test/gone"})
+	require.NoError(t, err)
+	assert.True(t, res[0].OK())
+	assert.Equal(t, 404, res[1].Status)
+}
+
+func TestCheck_NetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	base := srv.URL
+	srv.Close()
+	c := newChecker(base)
+	c.Retries = -1
+	res, err := c.Check(context.Background(), []string{"https://x.test/a"})
+	require.NoError(t, err)
+	assert.False(t, res[0].OK())
+	assert.Equal(t, 0, res[0].Status)
+	assert.NotEmpty(t, res[0].Error)
+}
+
+func TestCheck_InvalidBaseURL(t *testing.T) {
+	_, err := newChecker("localhost").Check(context.Background(), nil)
+	assert.ErrorContains(t, err, "invalid base URL")
+}
+
+func TestHostLimiter_SpacesRequests(t *testing.T) {
+	l := &hostLimiter{interval: 20 * time.Millisecond, next: map[string]time.Time{}}
+	start := time.Now()
+	for range 3 {
+		require.NoError(t, l.wait(context.Background(), "a.test"))
+	}
+	require.NoError(t, l.wait(context.Background(), "b.test"))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
@@ -3,6 +3,8 @@ package linkgraph
 import (
 	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
+
 	"github.com/jeduden/mdsmith/internal/archetype/gensection"
 	"github.com/jeduden/mdsmith/internal/globpath"
 	"github.com/jeduden/mdsmith/internal/lint"
@@ -21,6 +23,10 @@ const (
 	// targets are glob patterns; concrete files are produced by
 	// ExpandCatalog against a workspace file list.
 	DirectiveCatalog
+	// DirectiveTable is a `<?table file: …?>` directive.
+	DirectiveTable
+	// DirectiveSnippet is a `<?snippet file: …?>` directive.
+	DirectiveSnippet
 )
 
 // DirectiveEdge is one directive's parsed target.
@@ -29,21 +35,25 @@ const (
 // convention as Link.Line/Column. Callers needing file-relative
 // coordinates must add f.LineOffset themselves.
 //
-// For DirectiveInclude and DirectiveBuild, Path carries the raw
-// directive value (file: for include, source: for build) verbatim
+// For DirectiveInclude, DirectiveBuild, DirectiveTable, and
+// DirectiveSnippet, Path carries the raw directive value (file: for
+// include, table, and snippet, source: for build) verbatim
 // from the directive body. Path is the un-resolved string — callers
 // resolve it against the host file's directory using ResolveRelTarget.
 //
-// For DirectiveCatalog, Globs carries the raw glob pattern list.
-// Path is empty. The IsUnresolved method returns true for catalog
+// For DirectiveCatalog, Globs carries the raw glob pattern list and
+// SourceDir the raw `source-dir:` value, which roots the globs at a
+// project-relative directory instead of the host file's. Path is
+// empty. The IsUnresolved method returns true for catalog
 // edges so reverse-edge queries skip them generically — see the index
 // layer for the corresponding Unresolved flag.
 type DirectiveEdge struct {
-	Line  int
-	Col   int
-	Kind  DirectiveKind
-	Path  string
-	Globs []string
+	Line      int
+	Col       int
+	Kind      DirectiveKind
+	Path      string
+	Globs     []string
+	SourceDir string
 }
 
 // IsUnresolved reports whether this directive points at glob patterns
@@ -54,9 +64,9 @@ func (d DirectiveEdge) IsUnresolved() bool {
 }
 
 // ExtractDirectives walks f.AST top-level for processing-instruction
-// nodes whose name is "include", "build", or "catalog", parses each
-// one's YAML body, and returns one DirectiveEdge per directive that
-// carries a usable target. Directives with malformed YAML or empty
+// nodes whose name is "include", "build", "catalog", "table", or "snippet",
+// parses each one's YAML body, and returns one DirectiveEdge per
+// directive that carries a usable target. Directives with malformed YAML or empty
 // required parameters are skipped silently — the dedicated lint rules
 // surface those as diagnostics; this extractor only contributes to the
 // link graph.
@@ -78,7 +88,7 @@ func ExtractDirectives(f *lint.File) []DirectiveEdge {
 			continue
 		}
 		switch pi.Name {
-		case "include", "build", "catalog":
+		case "include", "build", "catalog", "table", "snippet":
 		default:
 			continue
 		}
@@ -113,10 +123,33 @@ func ExtractDirectives(f *lint.File) []DirectiveEdge {
 		case "catalog":
 			globs := splitCatalogGlobs(params["glob"])
 			out = append(out, DirectiveEdge{
-				Line:  line,
-				Col:   1,
-				Kind:  DirectiveCatalog,
-				Globs: globs,
+				Line:      line,
+				Col:       1,
+				Kind:      DirectiveCatalog,
+				Globs:     globs,
+				SourceDir: strings.TrimSpace(params["source-dir"]),
+			})
+		case "table":
+			file := strings.TrimSpace(params["file"])
+			if file == "" {
+				continue
+			}
+			out = append(out, DirectiveEdge{
+				Line: line,
+				Col:  1,
+				Kind: DirectiveTable,
+				Path: file,
+			})
+		case "snippet":
+			file := strings.TrimSpace(params["file"])
+			if file == "" {
+				continue
+			}
+			out = append(out, DirectiveEdge{
+				Line: line,
+				Col:  1,
+				Kind: DirectiveSnippet,
+				Path: file,
 			})
 		}
 	}
@@ -195,9 +228,8 @@ func splitCatalogGlobs(raw string) []string {
 	return out
 }
 
-// ExpandCatalog returns the subset of files that match any of the
-// given glob patterns. Patterns prefixed with `!` are exclusion
-// patterns — see globpath.MatchAny for the precise semantics.
+// ExpandCatalog returns the subset of files that match the given
+// glob patterns, as MatchCatalog decides.
 //
 // The function does not walk the filesystem; the caller is
 // responsible for supplying the candidate file list (typically the
@@ -209,13 +241,32 @@ func ExpandCatalog(globs, files []string) []string {
 	}
 	out := make([]string, 0, len(files))
 	for _, f := range files {
-		if globpath.MatchAny(globs, f) {
+		if MatchCatalog(globs, f) {
 			out = append(out, f)
 		}
 	}
 	return out
 }
 
+// MatchCatalog reports whether file matches a catalog's globs the way
+// the catalog directive matches them: against the full path, so a
+// pattern without a slash stays in its own directory. Patterns
+// prefixed with `!` exclude, and an exclusion wins over any include.
+func MatchCatalog(globs []string, file string) bool {
+	includes, excludes := globpath.SplitIncludeExclude(globs)
+	for _, g := range excludes {
+		if ok, _ := doublestar.Match(g, file); ok {
+			return false
+		}
+	}
+	for _, g := range includes {
+		if ok, _ := doublestar.Match(g, file); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // lineOfOffset is a body-local 1-based line index for a byte offset.
 // Used for marker-pair start lines where a *lint.File is not
 // available (e.g. inside parsePIParams' YAML body diagnostic path).
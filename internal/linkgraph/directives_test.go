@@ -28,6 +28,26 @@ func TestExtractDirectives_Build(t *testing.T) {
 	assert.Equal(t, "src.md", edges[0].Path)
 }
 
+func TestExtractDirectives_Table(t *testing.T) {
+	src := "# Top\n\n<?table\nfile: data/features.csv\nsort: name\n?>\n<?/table?>\n"
+	f := newFile(t, src)
+	edges := ExtractDirectives(f)
+	require.Len(t, edges, 1)
+	assert.Equal(t, DirectiveTable, edges[0].Kind)
+	assert.Equal(t, "data/features.csv", edges[0].Path)
+	assert.False(t, edges[0].IsUnresolved())
+}
+
+func TestExtractDirectives_Snippet(t *testing.T) {
+	src := "# Top\n\n<?snippet\nfile: ../run/runner.go\nsymbol: Runner.Run\n?>\n<?/snippet?>\n"
+	f := newFile(t, src)
+	edges := ExtractDirectives(f)
+	require.Len(t, edges, 1)
+	assert.Equal(t, DirectiveSnippet, edges[0].Kind)
+	assert.Equal(t, "../run/runner.go", edges[0].Path)
+	assert.False(t, edges[0].IsUnresolved())
+}
+
 func TestExtractDirectives_Catalog(t *testing.T) {
 	src := "# Top\n\n<?catalog\nglob:\n  - \"docs/*.md\"\n  - \"!docs/internal/*.md\"\n?>\n<?/catalog?>\n"
 	f := newFile(t, src)
@@ -118,6 +138,11 @@ func TestExpandCatalog(t *testing.T) {
 			globs: []string{"docs/**/*.md", "!docs/internal/**"},
 			want:  []string{"docs/intro.md", "docs/api.md"},
 		},
+		{
+			name:  "pattern without a slash stays in its directory",
+			globs: []string{"*.md"},
+			want:  []string{},
+		},
 		{
 			name:  "no globs",
 			globs: nil,
@@ -26,6 +26,10 @@ type RefLink struct {
 	// collapsed). Use this when keying into the parser-context ref
 	// table or matching against a `[label]: url` definition.
 	Label string
+	// Target is the parsed destination of the label's definition. It
+	// is the zero Target when the destination is not a local target
+	// (an external URL, for instance).
+	Target Target
 }
 
 // ExtractRefLinkTargets walks f.AST and returns every reference-style
@@ -80,11 +84,13 @@ func ExtractRefLinks(f *lint.File) []RefLink {
 			return ast.WalkContinue, nil
 		}
 		line, col := linkPosition(f, l)
+		target, _ := ParseTarget(string(l.Destination))
 		out = append(out, RefLink{
 			Line:   line,
 			Column: col,
 			Text:   linkText(l, f.Source),
 			Label:  string(util.ToLinkReference(l.Reference.Value)),
+			Target: target,
 		})
 		return ast.WalkContinue, nil
 	})
@@ -0,0 +1,139 @@
+package linkgraph
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// URLRef is one URL in a file. Start and End bound its literal text in
+// f.Source; End is -1 when the source spells the URL differently, e.g.
+// with escapes, so it can be reported but not rewritten.
+type URLRef struct {
+	URL        string
+	Start, End int
+}
+
+// CollectURLs returns the URLs of inline links, images, autolinks,
+// link reference definitions, and the href and src attributes of raw
+// <a> and <img> tags, in source order.
+//
+// goldmark keeps no position for link destinations, so the walk moves
+// a cursor past each text node and looks for a destination's literal
+// text between the cursor and the end of the enclosing block.
+func CollectURLs(f *lint.File) []URLRef {
+	var out []URLRef
+	cursor, blockEnd := 0, 0
+	find := func(url, literal string) {
+		ref := URLRef{URL: url, Start: cursor, End: -1}
+		if i := bytes.Index(f.Source[cursor:max(cursor, blockEnd)], []byte(literal)); i >= 0 {
+			ref.Start = cursor + i
+			ref.End = ref.Start + len(literal)
+			cursor = ref.End
+		}
+		out = append(out, ref)
+	}
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			// A destination follows the link text or image alt text.
+			switch v := n.(type) {
+			case *ast.Link:
+				if v.Reference == nil {
+					find(string(v.Destination), string(v.Destination))
+				}
+			case *ast.Image:
+				if v.Reference == nil {
+					find(string(v.Destination), string(v.Destination))
+				}
+			}
+			return ast.WalkContinue, nil
+		}
+		if n.Type() == ast.TypeBlock {
+			if lines := n.Lines(); lines.Len() > 0 {
+				cursor = max(cursor, lines.At(0).Start)
+				blockEnd = lines.At(lines.Len() - 1).Stop
+			}
+		}
+		switch v := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock:
+			out = append(out, htmlURLs(f.Source, v.Lines())...)
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			out = append(out, htmlURLs(f.Source, v.Segments)...)
+			if v.Segments.Len() > 0 {
+				cursor = max(cursor, v.Segments.At(v.Segments.Len()-1).Stop)
+			}
+		case *ast.Text:
+			cursor = max(cursor, v.Segment.Stop)
+		case *ast.AutoLink:
+			find(string(v.URL(f.Source)), string(v.Label(f.Source)))
+		}
+		return ast.WalkContinue, nil
+	})
+	out = append(out, definitionURLs(f)...)
+	slices.SortStableFunc(out, func(a, b URLRef) int { return a.Start - b.Start })
+	return out
+}
+
+// htmlAttrRe matches the href of an <a> tag or the src of an <img>
+// tag; one of groups 1 to 3 holds the value.
+var htmlAttrRe = regexp.MustCompile(
+	`(?i)<(?:a|img)\b[^>]*?\s(?:href|src)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+func htmlURLs(source []byte, segs *text.Segments) []URLRef {
+	var out []URLRef
+	for i := 0; i < segs.Len(); i++ {
+		seg := segs.At(i)
+		for _, m := range htmlAttrRe.FindAllSubmatchIndex(seg.Value(source), -1) {
+			for g := 2; g < 8; g += 2 {
+				if m[g] >= 0 {
+					start, end := seg.Start+m[g], seg.Start+m[g+1]
+					out = append(out, URLRef{URL: string(source[start:end]), Start: start, End: end})
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+// refDefRE matches a link reference definition; group 1 is the label
+// and group 2 the destination, which may start on the next line.
+var refDefRE = regexp.MustCompile(`(?m)^[ ]{0,3}\[([^\]\n]+)\]:[ \t]*\n?[ \t]*<?([^\s>]+)`)
+
+// definitionURLs returns the destinations of the link reference
+// definitions goldmark accepted. Reference-style links and images
+// point at these, so each URL is checked once, where it is written.
+func definitionURLs(f *lint.File) []URLRef {
+	ctx := parser.NewContext()
+	lint.NewParser().Parse(text.NewReader(f.Source), parser.WithContext(ctx))
+	dests := map[string]string{}
+	for _, ref := range ctx.References() {
+		dests[string(ref.Label())] = string(ref.Destination())
+	}
+	if len(dests) == 0 {
+		return nil
+	}
+	codeLines := lint.CollectCodeBlockLines(f)
+	var out []URLRef
+	for _, m := range refDefRE.FindAllSubmatchIndex(f.Source, -1) {
+		dest, ok := dests[util.ToLinkReference(f.Source[m[2]:m[3]])]
+		if !ok || codeLines[f.LineOfOffset(m[2])] {
+			continue
+		}
+		ref := URLRef{URL: dest, Start: m[4], End: -1}
+		if string(f.Source[m[4]:m[5]]) == dest {
+			ref.End = m[5]
+		}
+		out = append(out, ref)
+	}
+	return out
+}
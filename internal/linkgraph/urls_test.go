@@ -0,0 +1,40 @@
+package linkgraph
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectURLs(t *testing.T) {
+	src := "[a](https://a.test/x) ![b](img.png) <https://c.test/>\n\n" +
+		`<a href="https://d.test/">d</a>` + "\n\n" +
+		"[e][ref] `[f](https://code.test/)`\n\n" +
+		"[ref]: https://e.test/\n\n" +
+		"```\n[g](https://fenced.test/)\n```\n"
+	f, err := lint.NewFile("t.md", []byte(src))
+	require.NoError(t, err)
+	refs := CollectURLs(f)
+	var urls []string
+	for _, r := range refs {
+		urls = append(urls, r.URL)
+		if r.End >= 0 {
+			assert.Equal(t, r.URL, src[r.Start:r.End])
+		}
+	}
+	assert.Equal(t, []string{
+		"https://a.test/x", "img.png", "https://c.test/", "https://d.test/", "https://e.test/",
+	}, urls)
+}
+
+func TestCollectURLs_KeepsSourceSpelling(t *testing.T) {
+	src := `[a](https://a.test/\(x\))` + "\n"
+	f, err := lint.NewFile("t.md", []byte(src))
+	require.NoError(t, err)
+	refs := CollectURLs(f)
+	require.Len(t, refs, 1)
+	assert.Equal(t, `https://a.test/\(x\)`, refs[0].URL)
+	assert.Equal(t, refs[0].URL, src[refs[0].Start:refs[0].End])
+}
@@ -31,6 +31,13 @@ type Diagnostic struct {
 	Message         string
 	SourceLines     []string // context lines around the diagnostic; empty if unavailable
 	SourceStartLine int      // 1-based line number of first entry in SourceLines
+	// EndColumn, when non-zero, is the 1-based byte column just past
+	// the flagged text on Line, so editors can underline only that
+	// span. Zero leaves the extent to the consumer.
+	EndColumn int
+	// Suggestions are replacements for the text between Column and
+	// EndColumn, best first. Editors offer each as a quick fix.
+	Suggestions []string
 	// Explanation, when non-nil, attaches per-leaf provenance for the
 	// rule that fired. Populated by the CLI when --explain is on.
 	Explanation *Explanation
@@ -52,3 +59,25 @@ type ExplanationLeaf struct {
 	Value  any
 	Source string
 }
+
+// Introduced returns the diagnostics in after that have no
+// counterpart in before. Diagnostics are matched on rule and message,
+// not position, so a finding an edit merely moved is not reported as
+// new; duplicates are matched one for one.
+func Introduced(before, after []Diagnostic) []Diagnostic {
+	type key struct{ rule, msg string }
+	seen := make(map[key]int, len(before))
+	for _, d := range before {
+		seen[key{d.RuleID, d.Message}]++
+	}
+	var out []Diagnostic
+	for _, d := range after {
+		k := key{d.RuleID, d.Message}
+		if seen[k] > 0 {
+			seen[k]--
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
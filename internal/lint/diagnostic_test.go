@@ -39,3 +39,19 @@ func TestLineRange_Contains(t *testing.T) {
 	assert.False(t, r.Contains(4), "before range")
 	assert.False(t, r.Contains(9), "after range")
 }
+
+func TestIntroduced(t *testing.T) {
+	before := []Diagnostic{
+		{Line: 3, RuleID: "MDS003", Message: "skip"},
+		{Line: 9, RuleID: "MDS003", Message: "skip"},
+	}
+	after := []Diagnostic{
+		{Line: 4, RuleID: "MDS003", Message: "skip"},
+		{Line: 8, RuleID: "MDS003", Message: "skip"},
+		{Line: 12, RuleID: "MDS003", Message: "skip"},
+		{Line: 1, RuleID: "MDS051", Message: "two h1"},
+	}
+	got := Introduced(before, after)
+	assert.Equal(t, []Diagnostic{after[2], after[3]}, got)
+	assert.Empty(t, Introduced(after, before))
+}
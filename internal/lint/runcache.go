@@ -18,6 +18,7 @@ import "sync"
 type RunCache struct {
 	frontMatter sync.Map // string (absPath) -> *runCacheEntry
 	includes    sync.Map // string (absPath) -> *runCacheEntry
+	workspace   sync.Map // string (caller key) -> *runCacheEntry
 }
 
 // runCacheEntry guards a single cache slot so build runs exactly once
@@ -53,12 +54,23 @@ func (c *RunCache) Includes(absPath string, build func() []string) []string {
 	return v.([]string)
 }
 
+// Workspace returns build's result for key, computed at most once per
+// key in this cache's lifetime. It holds values derived from the whole
+// workspace (every file's links, say), so rules that need one can
+// build it once per run instead of once per checked file.
+func (c *RunCache) Workspace(key string, build func() any) any {
+	return load(&c.workspace, key, build)
+}
+
 // Invalidate drops the front-matter and include entries for absPath.
-// The LSP calls this from didChange / didSave / didChangeWatchedFiles
-// so the next Check that crosses absPath re-reads from disk.
+// Workspace entries may depend on any file, so all of them are
+// dropped. The LSP calls this from didChange / didSave /
+// didChangeWatchedFiles so the next Check that crosses absPath
+// re-reads from disk.
 func (c *RunCache) Invalidate(absPath string) {
 	c.frontMatter.Delete(absPath)
 	c.includes.Delete(absPath)
+	c.workspace.Clear()
 }
 
 // load is the shared LoadOrStore + sync.Once primitive for both maps.
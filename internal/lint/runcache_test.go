@@ -136,3 +136,18 @@ func TestRunCache_ConcurrentSingleBuild(t *testing.T) {
 	assert.Equal(t, int32(1), atomic.LoadInt32(&calls),
 		"build must run exactly once under concurrent access")
 }
+
+// TestRunCache_InvalidateDropsWorkspaceEntries pins that any file
+// event drops every workspace entry: a workspace value may depend on
+// the file that changed, whatever its key.
+func TestRunCache_InvalidateDropsWorkspaceEntries(t *testing.T) {
+	c := NewRunCache()
+
+	var calls int32
+	build := func() any { return atomic.AddInt32(&calls, 1) }
+	assert.Equal(t, int32(1), c.Workspace("graph", build))
+	assert.Equal(t, int32(1), c.Workspace("graph", build))
+
+	c.Invalidate("/abs/unrelated.md")
+	assert.Equal(t, int32(2), c.Workspace("graph", build))
+}
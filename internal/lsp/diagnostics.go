@@ -11,9 +11,9 @@ import (
 // toLSP converts an mdsmith diagnostic to the LSP wire shape.
 //
 // Coordinates flip from 1-based (mdsmith) to 0-based (LSP). The end
-// column is set to the line's UTF-16 length so the squiggle covers
-// the remainder of the line; rules can later widen this with their
-// own per-rule end column once LSP-aware spans land in the engine.
+// column is the rule's EndColumn when it sets one, so the squiggle
+// covers just the flagged text; otherwise it is the line's UTF-16
+// length and the squiggle covers the remainder of the line.
 //
 // LSP positions count UTF-16 code units. mdsmith's
 // `lint.Diagnostic.Column` is a 1-based UTF-8 byte column (see
@@ -28,7 +28,7 @@ import (
 // Both startCol and endCol come from mdtext.UTF16FromByteOffset/utf16Length
 // on the same line, which clamps every input to [0, line's UTF-16
 // length], so endCol is always >= startCol — no end-before-start
-// guard is needed.
+// guard is needed. An EndColumn before Column is ignored.
 func toLSP(d lint.Diagnostic, lines [][]byte) Diagnostic {
 	startLine := d.Line - 1
 	if startLine < 0 {
@@ -37,6 +37,9 @@ func toLSP(d lint.Diagnostic, lines [][]byte) Diagnostic {
 	line := currentLineBytes(lines, d.Line)
 	startCol := mdtext.UTF16FromByteOffset(line, d.Column-1)
 	endCol := utf16Length(line)
+	if d.EndColumn > d.Column {
+		endCol = mdtext.UTF16FromByteOffset(line, d.EndColumn-1)
+	}
 	return Diagnostic{
 		Range: Range{
 			Start: Position{Line: startLine, Character: startCol},
@@ -46,7 +49,7 @@ func toLSP(d lint.Diagnostic, lines [][]byte) Diagnostic {
 		Code:     d.RuleID,
 		Source:   "mdsmith",
 		Message:  d.Message,
-		Data:     &diagnosticData{RuleName: d.RuleName},
+		Data:     &diagnosticData{RuleName: d.RuleName, Suggestions: d.Suggestions},
 	}
 }
 
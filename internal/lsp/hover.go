@@ -21,6 +21,8 @@ import (
 var directiveToDocFile = map[string]string{
 	"catalog":             "generating-content.md",
 	"include":             "generating-content.md",
+	"snippet":             "generating-content.md",
+	"table":               "generating-content.md",
 	"build":               "build.md",
 	"allow-empty-section": "enforcing-structure.md",
 	"require":             "enforcing-structure.md",
@@ -176,8 +176,12 @@ type Diagnostic struct {
 // LSP allows arbitrary `data` on diagnostics; clients echo it back on
 // codeAction requests, which is exactly what we need to know which
 // rule's fix to run for a given diagnostic.
+//
+// Suggestions echo lint.Diagnostic.Suggestions so the code-action
+// handler can offer each replacement without re-running the rule.
 type diagnosticData struct {
-	RuleName string `json:"rule"`
+	RuleName    string   `json:"rule"`
+	Suggestions []string `json:"suggestions,omitempty"`
 }
 
 // publishDiagnosticsParams is LSP §3.18.6 PublishDiagnosticsParams.
@@ -237,9 +241,12 @@ type codeActionContext struct {
 
 // Code action kinds — match the strings VS Code expects.
 const (
-	kindQuickFix       = "quickfix"
-	kindSourceFixAll   = "source.fixAll.mdsmith"
-	titleFixAllMdsmith = "Fix all mdsmith issues"
+	kindQuickFix        = "quickfix"
+	kindSourceFixAll    = "source.fixAll.mdsmith"
+	kindRefactorExtract = "refactor.extract"
+	kindRefactorInline  = "refactor.inline"
+	kindRefactorRewrite = "refactor.rewrite"
+	titleFixAllMdsmith  = "Fix all mdsmith issues"
 )
 
 // codeAction is what the server returns from textDocument/codeAction.
@@ -252,6 +259,28 @@ type codeAction struct {
 
 type workspaceEdit struct {
 	Changes map[string][]textEdit `json:"changes"`
+	// DocumentChanges carries edits that must create a file first
+	// (the extract refactoring). Clients that support it prefer it
+	// over Changes, which is then left empty.
+	DocumentChanges []documentChange `json:"documentChanges,omitempty"`
+}
+
+// documentChange is one WorkspaceEdit.documentChanges entry: a
+// CreateFile operation when Kind is "create" (URI set), otherwise a
+// TextDocumentEdit (TextDocument and Edits set).
+type documentChange struct {
+	Kind         string                                   `json:"kind,omitempty"`
+	URI          string                                   `json:"uri,omitempty"`
+	TextDocument *optionalVersionedTextDocumentIdentifier `json:"textDocument,omitempty"`
+	Edits        []textEdit                               `json:"edits,omitempty"`
+}
+
+// optionalVersionedTextDocumentIdentifier names the document a
+// TextDocumentEdit applies to. Version is always sent; null means
+// "whatever the client currently holds".
+type optionalVersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version *int   `json:"version"`
 }
 
 type textEdit struct {
@@ -0,0 +1,189 @@
+package lsp
+
+import (
+	"path"
+	"slices"
+	"sort"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/engine"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/split"
+)
+
+// refactorActions returns the section refactorings available at the
+// start of the request range: "extract section" and "promote/demote
+// section" on a document-level heading line, and "inline include"
+// inside an `<?include?>` block. The engines in internal/split and
+// internal/rename do the work; this adapter turns their result into a
+// WorkspaceEdit.
+//
+// Every engine rejects a line that is not theirs before walking the
+// workspace, so a request on ordinary text stays cheap.
+func (s *Server) refactorActions(p codeActionParams, doc *document) []codeAction {
+	wantExtract := wantsKind(p.Context.Only, kindRefactorExtract)
+	wantInline := wantsKind(p.Context.Only, kindRefactorInline)
+	wantRewrite := wantsKind(p.Context.Only, kindRefactorRewrite)
+	if !wantExtract && !wantInline && !wantRewrite {
+		return nil
+	}
+	_, rel, ok := s.docTextOrFile(p.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+	ws := lspRenameWorkspace{s: s, idx: s.ensureIndex()}
+	line := p.Range.Start.Line + 1
+	var actions []codeAction
+	if wantExtract {
+		if a, ok := s.extractSectionAction(ws, rel, line, doc.text); ok {
+			actions = append(actions, a)
+		}
+	}
+	if wantInline {
+		if a, ok := s.inlineIncludeAction(ws, rel, line, doc.text); ok {
+			actions = append(actions, a)
+		}
+	}
+	if wantRewrite {
+		for _, delta := range []int{-1, 1} {
+			if a, ok := s.shiftSectionAction(ws, rel, line, delta, doc.text); ok {
+				actions = append(actions, a)
+			}
+		}
+	}
+	return actions
+}
+
+// extractSectionAction builds the "extract section" action, or
+// ok=false when line is not a section heading or the new file would
+// overwrite an existing one.
+func (s *Server) extractSectionAction(
+	ws lspRenameWorkspace, rel string, line int, before []byte,
+) (codeAction, bool) {
+	res, err := split.Extract(ws, rel, line, "")
+	if err != nil {
+		return codeAction{}, false
+	}
+	if _, _, exists := ws.Resolve(res.Section.File); exists {
+		return codeAction{}, false
+	}
+	newURI := s.workspaceURI(res.Section.File)
+	if newURI == "" {
+		return codeAction{}, false
+	}
+	changes := []documentChange{
+		{Kind: "create", URI: newURI},
+		{
+			TextDocument: &optionalVersionedTextDocumentIdentifier{URI: newURI},
+			Edits:        []textEdit{{NewText: string(res.Section.Source)}},
+		},
+	}
+	changes = append(changes, wholeDocumentChange(res.IndexKey, before, res.Index))
+	return codeAction{
+		Title: "Extract section to " + path.Base(res.Section.File),
+		Kind:  kindRefactorExtract,
+		Edit: &workspaceEdit{
+			Changes:         map[string][]textEdit{},
+			DocumentChanges: append(changes, editDocumentChanges(res.Changes)...),
+		},
+	}, true
+}
+
+// inlineIncludeAction builds the "inline include" action, or
+// ok=false when line is not inside an include with a generated body.
+func (s *Server) inlineIncludeAction(
+	ws lspRenameWorkspace, rel string, line int, before []byte,
+) (codeAction, bool) {
+	res, err := split.Inline(ws, rel, line)
+	if err != nil {
+		return codeAction{}, false
+	}
+	changes := toLSPChanges(res.Changes)
+	changes[res.IndexKey] = fullFileEdit(res.IndexKey, before, res.Index).Changes[res.IndexKey]
+	return codeAction{
+		Title: "Inline include of " + path.Base(res.Included),
+		Kind:  kindRefactorInline,
+		Edit:  &workspaceEdit{Changes: changes},
+	}, true
+}
+
+// shiftSectionAction builds "promote section" (delta -1) or "demote
+// section" (delta +1), or ok=false when line is not a section heading,
+// the subtree would leave H1–H6, or the result would introduce a
+// rename.ShiftCheckRules diagnostic.
+func (s *Server) shiftSectionAction(
+	ws lspRenameWorkspace, rel string, line, delta int, before []byte,
+) (codeAction, bool) {
+	res, err := rename.ShiftSection(ws, rel, line, delta)
+	if err != nil || s.shiftBreaksStructure(rel, res.Baseline, res.Source) {
+		return codeAction{}, false
+	}
+	changes := toLSPChanges(res.Changes)
+	changes[res.Key] = fullFileEdit(res.Key, before, res.Source).Changes[res.Key]
+	title := "Demote section"
+	if delta < 0 {
+		title = "Promote section"
+	}
+	return codeAction{
+		Title: title,
+		Kind:  kindRefactorRewrite,
+		Edit:  &workspaceEdit{Changes: changes},
+	}, true
+}
+
+// shiftBreaksStructure lints rel before and after a level shift with
+// rename.ShiftCheckRules, enabled whatever the workspace config says,
+// and reports whether the shift introduces a diagnostic.
+func (s *Server) shiftBreaksStructure(rel string, before, after []byte) bool {
+	cfg, _, root := s.snapshotConfig()
+	if cfg == nil {
+		cfg = config.Merge(config.Defaults(), nil)
+	}
+	var rules []rule.Rule
+	for _, r := range s.rules {
+		if slices.Contains(rename.ShiftCheckRules, r.Name()) {
+			rules = append(rules, r)
+		}
+	}
+	r := &engine.Runner{
+		Config:            rename.ShiftCheckConfig(cfg),
+		Rules:             rules,
+		StripFrontMatter:  frontMatterEnabled(cfg),
+		RootDir:           root,
+		SkipSourceContext: true,
+	}
+	return len(lint.Introduced(
+		r.RunSource(rel, before).Diagnostics,
+		r.RunSource(rel, after).Diagnostics,
+	)) > 0
+}
+
+// wholeDocumentChange is fullFileEdit in documentChanges form.
+func wholeDocumentChange(uri string, before, after []byte) documentChange {
+	return documentChange{
+		TextDocument: &optionalVersionedTextDocumentIdentifier{URI: uri},
+		Edits:        fullFileEdit(uri, before, after).Changes[uri],
+	}
+}
+
+// editDocumentChanges converts per-key engine edits into
+// TextDocumentEdits, sorted by URI so the reply is deterministic.
+func editDocumentChanges(changes map[string][]rename.Edit) []documentChange {
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]documentChange, 0, len(keys))
+	for _, k := range keys {
+		te := toTextEdits(changes[k])
+		sortTextEditsBottomUp(te)
+		out = append(out, documentChange{
+			TextDocument: &optionalVersionedTextDocumentIdentifier{URI: k},
+			Edits:        te,
+		})
+	}
+	return out
+}
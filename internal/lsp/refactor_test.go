@@ -0,0 +1,95 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refactorActionsAt opens uri with src and returns the code actions
+// of kind offered at line.
+func refactorActionsAt(t *testing.T, h *testHarness, uri, src string, line int, kind string) []codeAction {
+	t.Helper()
+	h.notify("textDocument/didOpen", didOpenTextDocumentParams{
+		TextDocument: textDocumentItem{URI: uri, LanguageID: "markdown", Version: 1, Text: src},
+	})
+	_ = h.awaitNotification("textDocument/publishDiagnostics", 5*time.Second)
+	raw, errResp := h.request("textDocument/codeAction", codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Range:        Range{Start: Position{Line: line}, End: Position{Line: line}},
+		Context:      codeActionContext{Only: []string{kind}},
+	})
+	require.Nil(t, errResp)
+	var actions []codeAction
+	require.NoError(t, json.Unmarshal(raw, &actions))
+	return actions
+}
+
+func TestCodeActionExtractSection(t *testing.T) {
+	t.Parallel()
+	src := "# Guide\n\n## Setup\n\nInstall.\n"
+	other := "# Other\n\n[s](a.md#setup)\n"
+	h, _, rootURI := rootedHarness(t, map[string]string{"a.md": src, "b.md": other})
+	uri := rootURI + "/a.md"
+
+	actions := refactorActionsAt(t, h, uri, src, 2, kindRefactorExtract)
+	require.Len(t, actions, 1)
+	a := actions[0]
+	assert.Equal(t, "Extract section to setup.md", a.Title)
+	assert.Equal(t, kindRefactorExtract, a.Kind)
+	dc := a.Edit.DocumentChanges
+	require.Len(t, dc, 4)
+	assert.Equal(t, documentChange{Kind: "create", URI: rootURI + "/setup.md"}, dc[0])
+	assert.Equal(t, "# Setup\n\nInstall.\n", dc[1].Edits[0].NewText)
+	assert.Equal(t, uri, dc[2].TextDocument.URI)
+	assert.Contains(t, dc[2].Edits[0].NewText, "<?include\nfile: setup.md\nheading-level: absolute\n?>\n## Setup\n")
+	assert.Equal(t, rootURI+"/b.md", dc[3].TextDocument.URI)
+	assert.Equal(t, "setup.md#setup", dc[3].Edits[0].NewText)
+
+	// Not on a heading: nothing to extract.
+	assert.Empty(t, refactorActionsAt(t, h, uri, src, 4, kindRefactorExtract))
+}
+
+func TestCodeActionInlineInclude(t *testing.T) {
+	t.Parallel()
+	src := "# Guide\n\n<?include\nfile: part.md\n?>\n## Part\n<?/include?>\n"
+	h, _, rootURI := rootedHarness(t, map[string]string{
+		"a.md": src, "part.md": "## Part\n", "b.md": "# B\n\n[p](part.md#part)\n",
+	})
+	uri := rootURI + "/a.md"
+
+	actions := refactorActionsAt(t, h, uri, src, 3, kindRefactorInline)
+	require.Len(t, actions, 1)
+	a := actions[0]
+	assert.Equal(t, "Inline include of part.md", a.Title)
+	assert.Equal(t, kindRefactorInline, a.Kind)
+	require.Contains(t, a.Edit.Changes, uri)
+	assert.Equal(t, "# Guide\n\n## Part\n", a.Edit.Changes[uri][0].NewText)
+	require.Contains(t, a.Edit.Changes, rootURI+"/b.md")
+	assert.Equal(t, "a.md#part", a.Edit.Changes[rootURI+"/b.md"][0].NewText)
+}
+
+func TestCodeActionShiftSection(t *testing.T) {
+	t.Parallel()
+	src := "# Guide\n\n## Setup\n\nInstall.\n\n### Deep\n\nx\n\n#### Deeper\n\ny\n"
+	h, _, rootURI := rootedHarness(t, map[string]string{"a.md": src})
+	uri := rootURI + "/a.md"
+
+	// Demoting "Deep" would skip a level under "Setup", so only the
+	// promotion is offered; it carries the subtree along.
+	actions := refactorActionsAt(t, h, uri, src, 6, kindRefactorRewrite)
+	require.Len(t, actions, 1)
+	a := actions[0]
+	assert.Equal(t, "Promote section", a.Title)
+	assert.Equal(t, kindRefactorRewrite, a.Kind)
+	require.Contains(t, a.Edit.Changes, uri)
+	assert.Equal(t,
+		"# Guide\n\n## Setup\n\nInstall.\n\n## Deep\n\nx\n\n### Deeper\n\ny\n",
+		a.Edit.Changes[uri][0].NewText)
+
+	// Not on a heading: nothing to shift.
+	assert.Empty(t, refactorActionsAt(t, h, uri, src, 4, kindRefactorRewrite))
+}
@@ -18,11 +18,13 @@ import (
 	"time"
 
 	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/customrule"
 	"github.com/jeduden/mdsmith/internal/engine"
 	fixpkg "github.com/jeduden/mdsmith/internal/fix"
 	"github.com/jeduden/mdsmith/internal/index"
 	"github.com/jeduden/mdsmith/internal/lint"
 	vlog "github.com/jeduden/mdsmith/internal/log"
+	"github.com/jeduden/mdsmith/internal/plugin"
 	"github.com/jeduden/mdsmith/internal/rule"
 )
 
@@ -31,6 +33,7 @@ import (
 type Server struct {
 	t              *transport
 	rules          []rule.Rule
+	plugins        *plugin.Pool
 	debounce       time.Duration
 	fetchTimeout   time.Duration
 	discoverConfig func(string) (string, error)
@@ -129,6 +132,9 @@ type Options struct {
 	Debounce time.Duration
 	// Logger receives server-side trace messages. May be nil.
 	Logger *vlog.Logger
+	// Plugins runs the rule plugins the config declares. Nil disables
+	// plugin rules. The caller closes it after Run returns.
+	Plugins *plugin.Pool
 }
 
 // New constructs a Server. The Server does not run until Run() is
@@ -148,6 +154,7 @@ func New(opts Options) *Server {
 	return &Server{
 		t:              newTransport(opts.Reader, opts.Writer),
 		rules:          opts.Rules,
+		plugins:        opts.Plugins,
 		debounce:       debounce,
 		fetchTimeout:   2 * time.Second,
 		discoverConfig: config.Discover,
@@ -421,7 +428,10 @@ func (s *Server) handleInitialize(msg *requestMessage) {
 				Save:      &saveOptions{IncludeText: false},
 			},
 			CodeActionProvider: codeActionOptions{
-				CodeActionKinds: []string{kindQuickFix, kindSourceFixAll},
+				CodeActionKinds: []string{
+					kindQuickFix, kindSourceFixAll,
+					kindRefactorExtract, kindRefactorInline, kindRefactorRewrite,
+				},
 			},
 			HoverProvider:           true,
 			DocumentSymbolProvider:  true,
@@ -858,7 +868,7 @@ func (s *Server) runLint(uri string) {
 	maxBytes := s.resolveMaxInputBytes(cfg)
 	r := &engine.Runner{
 		Config:           cfg,
-		Rules:            s.rules,
+		Rules:            s.rulesFor(cfg),
 		StripFrontMatter: frontMatterEnabled(cfg),
 		RootDir:          root,
 		MaxInputBytes:    maxBytes,
@@ -1060,6 +1070,10 @@ func (s *Server) handleCodeAction(msg *requestMessage) {
 // the client did not ask for so we don't run fix passes whose output
 // the client will discard.
 //
+// Diagnostics that carry suggestions (MDS060 spelling) also get one
+// action per replacement, and spelling ones an "add to project
+// dictionary" edit of the config file; see spelling.go.
+//
 // Per-rule fix passes are deduped within a single request: a file
 // with N MDS006 diagnostics issues only one fix.SourceWithRules call,
 // not N. The resulting WorkspaceEdit is shared across the
@@ -1083,6 +1097,10 @@ func (s *Server) computeCodeActions(
 			if d.Data == nil || d.Data.RuleName == "" {
 				continue
 			}
+			actions = append(actions, suggestionActions(d, p.TextDocument.URI)...)
+			if a, ok := s.addWordAction(d, doc); ok {
+				actions = append(actions, a)
+			}
 			rule := d.Data.RuleName
 			edit, cached := ruleEdits[rule]
 			if !cached {
@@ -1112,7 +1130,7 @@ func (s *Server) computeCodeActions(
 		relPath := workspaceRelative(root, doc.path)
 		fixed, err := fixpkg.Source(fixpkg.SourceOptions{
 			Config:           cfg,
-			Rules:            s.rules,
+			Rules:            s.rulesFor(cfg),
 			Path:             relPath,
 			Source:           doc.text,
 			RootDir:          root,
@@ -1129,7 +1147,7 @@ func (s *Server) computeCodeActions(
 		}
 	}
 
-	return actions
+	return append(actions, s.refactorActions(p, doc)...)
 }
 
 // quickFixEditFor returns the WorkspaceEdit produced by running just
@@ -1144,13 +1162,13 @@ func (s *Server) computeCodeActions(
 func (s *Server) quickFixEditFor(
 	rule string, doc *document, cfg *config.Config, root, uri string,
 ) *workspaceEdit {
-	if !isFixable(s.rules, rule) {
+	if !isFixable(s.rulesFor(cfg), rule) {
 		return nil
 	}
 	relPath := workspaceRelative(root, doc.path)
 	fixed, err := fixpkg.SourceWithRules(fixpkg.SourceOptions{
 		Config:           cfg,
-		Rules:            s.rules,
+		Rules:            s.rulesFor(cfg),
 		Path:             relPath,
 		Source:           doc.text,
 		RootDir:          root,
@@ -1285,6 +1303,12 @@ func (s *Server) reloadConfig() {
 	s.configPath = cfgPath
 	s.configMu.Unlock()
 
+	// Restart plugins on the next lint: the config may point at a
+	// new command, and a plugin that failed gets another chance.
+	if s.plugins != nil {
+		s.plugins.Close()
+	}
+
 	if loadErr != "" {
 		s.logger.Printf("config: %s", loadErr)
 		_ = s.t.writeNotification("window/logMessage",
@@ -1476,6 +1500,17 @@ func frontMatterEnabled(cfg *config.Config) bool {
 	return *cfg.FrontMatter
 }
 
+// rulesFor returns the server's rules followed by the custom and
+// plugin rules cfg declares, so a reloaded config picks up edited
+// definitions.
+func (s *Server) rulesFor(cfg *config.Config) []rule.Rule {
+	if cfg == nil {
+		return s.rules
+	}
+	all := customrule.With(s.rules, cfg.CustomRules)
+	return append(all, plugin.Rules(s.plugins, cfg.Plugins)...)
+}
+
 func isFixable(rules []rule.Rule, name string) bool {
 	for _, r := range rules {
 		if r.Name() != name {
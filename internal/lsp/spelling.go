@@ -0,0 +1,90 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+)
+
+// projectWordsPath is where the spelling rule (MDS060) reads the
+// project word list in the config file.
+var projectWordsPath = []string{"rules", "spelling", "words"}
+
+// suggestionActions returns one quick fix per replacement the rule
+// attached to d, each rewriting only the diagnostic's range.
+func suggestionActions(d Diagnostic, uri string) []codeAction {
+	if d.Data == nil {
+		return nil
+	}
+	actions := make([]codeAction, 0, len(d.Data.Suggestions))
+	for _, s := range d.Data.Suggestions {
+		actions = append(actions, codeAction{
+			Title:       fmt.Sprintf("Change to %q", s),
+			Kind:        kindQuickFix,
+			Diagnostics: []Diagnostic{d},
+			Edit: &workspaceEdit{Changes: map[string][]textEdit{
+				uri: {{Range: d.Range, NewText: s}},
+			}},
+		})
+	}
+	return actions
+}
+
+// addWordAction returns the "add to project dictionary" quick fix for
+// a spelling diagnostic: an edit of the loaded config file appending
+// the flagged word to rules.spelling.words. ok is false without a
+// config file, or when the file's spelling settings are not a block
+// mapping the word can be added to.
+func (s *Server) addWordAction(d Diagnostic, doc *document) (codeAction, bool) {
+	if d.Data == nil || d.Data.RuleName != "spelling" || d.Range.Start.Line != d.Range.End.Line {
+		return codeAction{}, false
+	}
+	word := rangeText(doc.text, d.Range)
+	_, cfgPath, _ := s.snapshotConfig()
+	if word == "" || cfgPath == "" {
+		return codeAction{}, false
+	}
+	cfgURI := pathToURI(cfgPath)
+	before, ok := s.configText(cfgURI, cfgPath)
+	if !ok {
+		return codeAction{}, false
+	}
+	after, err := yamlutil.AppendToList(before, projectWordsPath, word)
+	if err != nil || bytes.Equal(before, after) {
+		return codeAction{}, false
+	}
+	return codeAction{
+		Title:       fmt.Sprintf("Add %q to project dictionary", word),
+		Kind:        kindQuickFix,
+		Diagnostics: []Diagnostic{d},
+		Edit:        fullFileEdit(cfgURI, before, after),
+	}, true
+}
+
+// configText returns the config file's text, preferring an open
+// buffer so the edit applies to what the user sees.
+func (s *Server) configText(uri, path string) ([]byte, bool) {
+	if doc, ok := s.docs.get(uri); ok {
+		return doc.text, true
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // the project config file the server loaded
+	return data, err == nil
+}
+
+// rangeText returns the text of a single-line range of source.
+func rangeText(source []byte, r Range) string {
+	lines := splitLines(source)
+	if r.Start.Line < 0 || r.Start.Line >= len(lines) {
+		return ""
+	}
+	line := lines[r.Start.Line]
+	start := mdtext.UTF16ToByteOffset(line, r.Start.Character)
+	end := mdtext.UTF16ToByteOffset(line, r.End.Character)
+	if end <= start {
+		return ""
+	}
+	return string(line[start:end])
+}
@@ -0,0 +1,89 @@
+package lsp
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToLSPUsesEndColumnAndSuggestions(t *testing.T) {
+	t.Parallel()
+	got := toLSP(lint.Diagnostic{
+		Line: 1, Column: 7, EndColumn: 10, RuleName: "spelling",
+		Suggestions: []string{"the"}, Severity: lint.Warning,
+	}, [][]byte{[]byte("café teh cat")})
+	// "é" is two bytes but one UTF-16 unit.
+	assert.Equal(t, Position{Line: 0, Character: 5}, got.Range.Start)
+	assert.Equal(t, Position{Line: 0, Character: 8}, got.Range.End)
+	assert.Equal(t, []string{"the"}, got.Data.Suggestions)
+}
+
+func spellingDiag() Diagnostic {
+	return Diagnostic{
+		Range: Range{
+			Start: Position{Line: 2, Character: 4},
+			End:   Position{Line: 2, Character: 7},
+		},
+		Code: "MDS060",
+		Data: &diagnosticData{RuleName: "spelling", Suggestions: []string{"the", "ten"}},
+	}
+}
+
+func spellingParams() codeActionParams {
+	return codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: "file:///x.md"},
+		Context: codeActionContext{
+			Diagnostics: []Diagnostic{spellingDiag()},
+			Only:        []string{kindQuickFix},
+		},
+	}
+}
+
+func TestComputeCodeActionsSpelling(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("rules:\n  spelling:\n    words: [mdsmith]\n"), 0o644))
+	s := New(Options{Reader: nil, Writer: io.Discard, Rules: rule.All()})
+	s.configMu.Lock()
+	s.configPath = cfgPath
+	s.configMu.Unlock()
+	cfg := config.Merge(config.Defaults(), nil)
+	doc := &document{path: "x.md", text: []byte("# Hi\n\nSee teh cat.\n")}
+
+	actions := s.computeCodeActions(spellingParams(), doc, cfg, dir)
+	require.Len(t, actions, 3)
+	assert.Equal(t, `Change to "the"`, actions[0].Title)
+	assert.Equal(t, "the", actions[0].Edit.Changes["file:///x.md"][0].NewText)
+	assert.Equal(t, spellingDiag().Range, actions[0].Edit.Changes["file:///x.md"][0].Range)
+	assert.Equal(t, `Change to "ten"`, actions[1].Title)
+	assert.Equal(t, `Add "teh" to project dictionary`, actions[2].Title)
+	edits := actions[2].Edit.Changes[pathToURI(cfgPath)]
+	require.Len(t, edits, 1)
+	assert.Equal(t, "rules:\n  spelling:\n    words: [mdsmith, teh]\n", edits[0].NewText)
+}
+
+func TestComputeCodeActionsSpellingWithoutConfig(t *testing.T) {
+	t.Parallel()
+	s := New(Options{Reader: nil, Writer: io.Discard, Rules: rule.All()})
+	cfg := config.Merge(config.Defaults(), nil)
+	doc := &document{path: "x.md", text: []byte("# Hi\n\nSee teh cat.\n")}
+	actions := s.computeCodeActions(spellingParams(), doc, cfg, "")
+	require.Len(t, actions, 2, "suggestions only; no config file to add the word to")
+}
+
+func TestRangeText(t *testing.T) {
+	t.Parallel()
+	src := []byte("a\ncafé teh\n")
+	assert.Equal(t, "teh", rangeText(src, Range{
+		Start: Position{Line: 1, Character: 5}, End: Position{Line: 1, Character: 8},
+	}))
+	assert.Empty(t, rangeText(src, Range{Start: Position{Line: 9}}))
+}
@@ -984,8 +984,8 @@ func (s *Server) handleReferences(msg *requestMessage) {
 	case index.TokenDirectiveArg:
 		// References on a directive argument resolve to "every
 		// workspace edge that points at this file" — file links
-		// (no anchor) plus every <?include?>, <?build?>, and
-		// <?catalog?>. Limiting to EdgeFileLink (the previous
+		// (no anchor) plus every <?include?>, <?build?>, <?table?>,
+		// <?snippet?>, and <?catalog?>. Limiting to EdgeFileLink (the previous
 		// behavior) hid the directive-to-directive references that
 		// users actually need when navigating include / build chains.
 		if res.DirectiveTargetFile != "" {
@@ -1057,7 +1057,7 @@ func (s *Server) locationsForFileTop(file string, idx *index.Index) []location {
 
 // locationsForFileReferences returns every workspace edge whose
 // target is file: the union of file-top links and the include /
-// build / catalog directives that target this file. Reference-style
+// build / table / snippet / catalog directives that target this file. Reference-style
 // link uses are not included because they target a label, not a
 // file path.
 func (s *Server) locationsForFileReferences(file string, idx *index.Index) []location {
@@ -1069,7 +1069,7 @@ func (s *Server) locationsForFileReferences(file string, idx *index.Index) []loc
 			if e.TargetAnchor != "" {
 				continue
 			}
-		case index.EdgeInclude, index.EdgeBuild, index.EdgeCatalog:
+		case index.EdgeInclude, index.EdgeBuild, index.EdgeCatalog, index.EdgeTable, index.EdgeSnippet:
 			// keep
 		default:
 			continue
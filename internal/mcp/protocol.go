@@ -0,0 +1,127 @@
+// Package mcp implements a Model Context Protocol server for
+// mdsmith. It speaks JSON-RPC 2.0 over stdio, one message per line,
+// and handles the lifecycle, tool, and resource methods a coding
+// agent needs to call mdsmith in-process.
+package mcp
+
+import "encoding/json"
+
+// JSON-RPC 2.0 framing.
+
+// requestMessage is an incoming JSON-RPC request or notification. The
+// ID is absent on notifications.
+type requestMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// responseMessage is an outgoing reply. JSON-RPC 2.0 forbids result
+// and error appearing together, so the writer in transport.go takes
+// the success-vs-error branch up front.
+type responseMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+
+	codeResourceNotFound = -32002
+)
+
+// MCP types — only the subset the server actually emits or consumes.
+
+// supportedVersions lists the protocol revisions the server speaks,
+// newest first. initialize echoes the client's version when it is
+// listed and answers with the newest otherwise, as the spec asks.
+var supportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+type initializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+type initializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    serverCapabilities `json:"capabilities"`
+	ServerInfo      implementation     `json:"serverInfo"`
+}
+
+type serverCapabilities struct {
+	Tools     struct{} `json:"tools"`
+	Resources struct{} `json:"resources"`
+}
+
+type implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type toolDescriptor struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type listToolsResult struct {
+	Tools []toolDescriptor `json:"tools"`
+}
+
+type callToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// callToolResult carries a tool's output twice: as a JSON text block
+// for clients that only read content, and as structuredContent for
+// clients that parse it. IsError marks a tool-level failure the
+// model should see, as opposed to a protocol error.
+type callToolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type resourceDescriptor struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mimeType"`
+}
+
+type listResourcesResult struct {
+	Resources []resourceDescriptor `json:"resources"`
+}
+
+type readResourceParams struct {
+	URI string `json:"uri"`
+}
+
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MIMEType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type readResourceResult struct {
+	Contents []resourceContents `json:"contents"`
+}
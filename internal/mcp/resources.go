@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/rules"
+)
+
+// Resource is one readable document.
+type Resource struct {
+	URI         string
+	Name        string
+	Title       string
+	Description string
+	MIMEType    string
+	Text        string
+}
+
+// RuleResources returns every rule README as a text/markdown
+// resource at mdsmith://rules/<ID>, front matter stripped — the
+// same text `mdsmith help rule <id>` prints.
+func RuleResources() ([]Resource, error) {
+	infos, err := rules.ListRules()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Resource, 0, len(infos))
+	for _, info := range infos {
+		out = append(out, Resource{
+			URI:         "mdsmith://rules/" + info.ID,
+			Name:        info.Name,
+			Title:       info.ID + " " + info.Name,
+			Description: info.Description,
+			MIMEType:    "text/markdown",
+			Text:        rules.StripFrontMatter(info.Content),
+		})
+	}
+	return out, nil
+}
+
+func (s *Server) handleListResources() listResourcesResult {
+	out := listResourcesResult{Resources: []resourceDescriptor{}}
+	for _, r := range s.opts.Resources {
+		out.Resources = append(out.Resources, resourceDescriptor{
+			URI:         r.URI,
+			Name:        r.Name,
+			Title:       r.Title,
+			Description: r.Description,
+			MIMEType:    r.MIMEType,
+		})
+	}
+	return out
+}
+
+func (s *Server) handleReadResource(params json.RawMessage) (any, *responseError) {
+	var p readResourceParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams(err)
+	}
+	idx := slices.IndexFunc(s.opts.Resources, func(r Resource) bool {
+		return strings.EqualFold(r.URI, p.URI)
+	})
+	if idx < 0 {
+		// MCP reserves -32002 for "resource not found".
+		return nil, &responseError{Code: codeResourceNotFound, Message: "resource not found: " + p.URI}
+	}
+	r := s.opts.Resources[idx]
+	return readResourceResult{Contents: []resourceContents{{URI: r.URI, MIMEType: r.MIMEType, Text: r.Text}}}, nil
+}
@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Tool is one callable tool. Call receives the raw `arguments`
+// object (nil when the client sent none) and returns a value that
+// marshals to a JSON object; the server sends it back both as
+// structuredContent and as a JSON text block.
+type Tool struct {
+	Name        string
+	Description string
+	// InputSchema is the JSON Schema of the arguments object.
+	InputSchema map[string]any
+	Call        func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// ToolError is a tool failure the calling model should see and can
+// act on, such as a file that does not pass its schema. Data, when
+// set, is returned as the result's structured content.
+type ToolError struct {
+	Message string
+	Data    any
+}
+
+func (e *ToolError) Error() string { return e.Message }
+
+// DecodeArgs unmarshals a tool's arguments into v, rejecting unknown
+// fields so a misspelt argument fails loudly instead of being
+// ignored.
+func DecodeArgs(args json.RawMessage, v any) error {
+	if len(args) == 0 || string(args) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &ToolError{Message: fmt.Sprintf("invalid arguments: %v", err)}
+	}
+	return nil
+}
+
+// Options configures a Server.
+type Options struct {
+	// Name and Version are reported as serverInfo on initialize.
+	Name    string
+	Version string
+	// Tools is the tool set, listed in the given order.
+	Tools []Tool
+	// Resources is the resource set. Nil serves no resources.
+	Resources []Resource
+	// Reader is the MCP input stream (typically stdin).
+	Reader io.Reader
+	// Writer is the MCP output stream (typically stdout).
+	Writer io.Writer
+}
+
+// Server handles MCP requests one at a time, in arrival order.
+type Server struct {
+	opts Options
+	t    *transport
+}
+
+// New constructs a Server. The Server does not run until Run() is
+// called.
+func New(opts Options) *Server {
+	return &Server{opts: opts, t: newTransport(opts.Reader, opts.Writer)}
+}
+
+// Run serves requests until the input stream closes (returns nil),
+// ctx is cancelled (returns ctx.Err()), or a read or write fails.
+func (s *Server) Run(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		raw, err := s.t.readRaw()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := s.dispatch(ctx, raw); err != nil {
+			return err
+		}
+	}
+}
+
+// dispatch handles one message. Only transport write failures are
+// returned; everything else becomes a JSON-RPC error response.
+func (s *Server) dispatch(ctx context.Context, raw []byte) error {
+	var req requestMessage
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return s.t.writeError(nil, codeParseError, "parse error")
+	}
+	if req.Method == "" {
+		// A response to a server-initiated request; the server
+		// sends none, so there is nothing to match it against.
+		if len(req.ID) > 0 {
+			return nil
+		}
+		return s.t.writeError(nil, codeInvalidRequest, "missing method")
+	}
+	if len(req.ID) == 0 {
+		// Notifications (notifications/initialized,
+		// notifications/cancelled, …) need no reply.
+		return nil
+	}
+	result, rpcErr := s.handle(ctx, req)
+	if rpcErr != nil {
+		return s.t.writeError(req.ID, rpcErr.Code, rpcErr.Message)
+	}
+	return s.t.writeResponse(req.ID, result)
+}
+
+func (s *Server) handle(ctx context.Context, req requestMessage) (any, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return s.handleInitialize(req.Params)
+	case "ping":
+		return nil, nil
+	case "tools/list":
+		return s.handleListTools(), nil
+	case "tools/call":
+		return s.handleCallTool(ctx, req.Params)
+	case "resources/list":
+		return s.handleListResources(), nil
+	case "resources/read":
+		return s.handleReadResource(req.Params)
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+func (s *Server) handleInitialize(params json.RawMessage) (any, *responseError) {
+	var p initializeParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams(err)
+		}
+	}
+	version := supportedVersions[0]
+	if slices.Contains(supportedVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+	return initializeResult{
+		ProtocolVersion: version,
+		ServerInfo:      implementation{Name: s.opts.Name, Version: s.opts.Version},
+	}, nil
+}
+
+func (s *Server) handleListTools() listToolsResult {
+	out := listToolsResult{Tools: []toolDescriptor{}}
+	for _, t := range s.opts.Tools {
+		out.Tools = append(out.Tools, toolDescriptor{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: t.InputSchema,
+		})
+	}
+	return out
+}
+
+// handleCallTool runs a tool. An unknown tool is a protocol error;
+// a failing tool is a successful response with isError set, so the
+// model sees the message.
+func (s *Server) handleCallTool(ctx context.Context, params json.RawMessage) (any, *responseError) {
+	var p callToolParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams(err)
+	}
+	idx := slices.IndexFunc(s.opts.Tools, func(t Tool) bool { return t.Name == p.Name })
+	if idx < 0 {
+		return nil, &responseError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+	out, err := s.opts.Tools[idx].Call(ctx, p.Arguments)
+	if err != nil {
+		res := callToolResult{IsError: true, Content: []textContent{{Type: "text", Text: err.Error()}}}
+		var te *ToolError
+		if errors.As(err, &te) && te.Data != nil {
+			res.StructuredContent = te.Data
+			if text, merr := json.Marshal(te.Data); merr == nil {
+				res.Content = append(res.Content, textContent{Type: "text", Text: string(text)})
+			}
+		}
+		return res, nil
+	}
+	text, err := json.Marshal(out)
+	if err != nil {
+		return callToolResult{IsError: true, Content: []textContent{{Type: "text", Text: err.Error()}}}, nil
+	}
+	return callToolResult{
+		Content:           []textContent{{Type: "text", Text: string(text)}},
+		StructuredContent: out,
+	}, nil
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+}
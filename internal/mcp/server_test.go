@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip feeds lines to a fresh server and returns its replies,
+// one decoded message per output line.
+func roundTrip(t *testing.T, opts Options, lines ...string) []map[string]any {
+	t.Helper()
+	var out strings.Builder
+	opts.Reader = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	opts.Writer = &out
+	require.NoError(t, New(opts).Run(context.Background()))
+	var msgs []map[string]any
+	sc := bufio.NewScanner(strings.NewReader(out.String()))
+	for sc.Scan() {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &m), sc.Text())
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func echoTool() Tool {
+	return Tool{
+		Name:        "echo",
+		Description: "Echo the text argument.",
+		InputSchema: map[string]any{"type": "object"},
+		Call: func(_ context.Context, args json.RawMessage) (any, error) {
+			var in struct {
+				Text string `json:"text"`
+			}
+			if err := DecodeArgs(args, &in); err != nil {
+				return nil, err
+			}
+			if in.Text == "" {
+				return nil, &ToolError{Message: "text is required", Data: map[string]any{"missing": "text"}}
+			}
+			return map[string]any{"text": in.Text}, nil
+		},
+	}
+}
+
+func TestInitializeNegotiatesVersion(t *testing.T) {
+	msgs := roundTrip(t, Options{Name: "mdsmith", Version: "v1"},
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	)
+	require.Len(t, msgs, 3)
+	res := msgs[0]["result"].(map[string]any)
+	assert.Equal(t, "2024-11-05", res["protocolVersion"])
+	assert.Equal(t, map[string]any{"name": "mdsmith", "version": "v1"}, res["serverInfo"])
+	assert.Contains(t, res["capabilities"], "tools")
+	assert.Equal(t, supportedVersions[0], msgs[1]["result"].(map[string]any)["protocolVersion"])
+	assert.Equal(t, map[string]any{}, msgs[2]["result"])
+}
+
+func TestToolsListAndCall(t *testing.T) {
+	msgs := roundTrip(t, Options{Tools: []Tool{echoTool()}},
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+	)
+	require.Len(t, msgs, 2)
+	tools := msgs[0]["result"].(map[string]any)["tools"].([]any)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "echo", tools[0].(map[string]any)["name"])
+
+	res := msgs[1]["result"].(map[string]any)
+	assert.Equal(t, map[string]any{"text": "hi"}, res["structuredContent"])
+	assert.Nil(t, res["isError"])
+	content := res["content"].([]any)[0].(map[string]any)
+	assert.JSONEq(t, `{"text":"hi"}`, content["text"].(string))
+}
+
+func TestToolErrorsAreResults(t *testing.T) {
+	msgs := roundTrip(t, Options{Tools: []Tool{echoTool()}},
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"txt":"x"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"nope"}}`,
+	)
+	require.Len(t, msgs, 3)
+	res := msgs[0]["result"].(map[string]any)
+	assert.Equal(t, true, res["isError"])
+	assert.Equal(t, map[string]any{"missing": "text"}, res["structuredContent"])
+
+	res = msgs[1]["result"].(map[string]any)
+	assert.Equal(t, true, res["isError"])
+	assert.Contains(t, res["content"].([]any)[0].(map[string]any)["text"], "unknown field")
+
+	assert.Equal(t, float64(codeInvalidParams), msgs[2]["error"].(map[string]any)["code"])
+}
+
+func TestResources(t *testing.T) {
+	rs, err := RuleResources()
+	require.NoError(t, err)
+	require.NotEmpty(t, rs)
+	first := rs[0]
+	assert.True(t, strings.HasPrefix(first.URI, "mdsmith://rules/MDS"), first.URI)
+	assert.Equal(t, "text/markdown", first.MIMEType)
+	assert.False(t, strings.HasPrefix(first.Text, "---"), "front matter is stripped")
+
+	msgs := roundTrip(t, Options{Resources: rs},
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"`+first.URI+`"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"mdsmith://rules/MDS999"}}`,
+	)
+	require.Len(t, msgs, 3)
+	assert.Len(t, msgs[0]["result"].(map[string]any)["resources"], len(rs))
+	contents := msgs[1]["result"].(map[string]any)["contents"].([]any)
+	assert.Equal(t, first.Text, contents[0].(map[string]any)["text"])
+	assert.Equal(t, float64(codeResourceNotFound), msgs[2]["error"].(map[string]any)["code"])
+}
+
+func TestProtocolErrors(t *testing.T) {
+	msgs := roundTrip(t, Options{},
+		`not json`,
+		``,
+		`{"jsonrpc":"2.0","id":1,"method":"bogus"}`,
+		`{"jsonrpc":"2.0","id":2,"result":{}}`,
+		`{"jsonrpc":"2.0"}`,
+	)
+	require.Len(t, msgs, 3)
+	assert.Nil(t, msgs[0]["id"])
+	assert.Equal(t, float64(codeParseError), msgs[0]["error"].(map[string]any)["code"])
+	assert.Equal(t, float64(codeMethodNotFound), msgs[1]["error"].(map[string]any)["code"])
+	assert.Equal(t, float64(codeInvalidRequest), msgs[2]["error"].(map[string]any)["code"])
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestRunStopsOnWriteFailure(t *testing.T) {
+	srv := New(Options{
+		Reader: strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n"),
+		Writer: failingWriter{},
+	})
+	assert.ErrorContains(t, srv.Run(context.Background()), "broken pipe")
+}
+
+func TestRunHonoursCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	srv := New(Options{Reader: strings.NewReader(""), Writer: &strings.Builder{}})
+	assert.ErrorIs(t, srv.Run(ctx), context.Canceled)
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxMessageBytes caps one incoming message. Tool arguments are
+// paths and short strings, so the cap only guards against a runaway
+// client.
+const maxMessageBytes = 64 * 1024 * 1024
+
+// transport reads and writes newline-delimited JSON-RPC messages,
+// the MCP stdio framing: each message is one line of UTF-8 JSON with
+// no embedded newlines. Concurrent writers are serialized via
+// writeMu.
+type transport struct {
+	r       *bufio.Reader
+	w       io.Writer
+	writeMu sync.Mutex
+}
+
+func newTransport(r io.Reader, w io.Writer) *transport {
+	return &transport{r: bufio.NewReader(r), w: w}
+}
+
+// readRaw returns the next non-blank line without its terminator. A
+// final line without a newline is still returned; io.EOF follows.
+func (t *transport) readRaw() ([]byte, error) {
+	for {
+		var line []byte
+		for {
+			chunk, isPrefix, err := t.r.ReadLine()
+			if err != nil {
+				if errors.Is(err, io.EOF) && len(line) > 0 {
+					break
+				}
+				return nil, err
+			}
+			line = append(line, chunk...)
+			if len(line) > maxMessageBytes {
+				return nil, fmt.Errorf("mcp: message exceeds %d bytes", maxMessageBytes)
+			}
+			if !isPrefix {
+				break
+			}
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			return line, nil
+		}
+	}
+}
+
+// writeJSON marshals v and emits it as one line. encoding/json
+// escapes newlines inside strings, so the body never spans lines.
+func (t *transport) writeJSON(v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("mcp: encoding JSON: %w", err)
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.w.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("mcp: writing message: %w", err)
+	}
+	return nil
+}
+
+// writeResponse writes a successful response. A nil result is
+// serialized as an empty object, which is what MCP expects from
+// ping and the other result-less methods.
+func (t *transport) writeResponse(id json.RawMessage, result any) error {
+	if result == nil {
+		result = struct{}{}
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("mcp: encoding result: %w", err)
+	}
+	return t.writeJSON(responseMessage{JSONRPC: "2.0", ID: id, Result: raw})
+}
+
+// writeError writes an error response.
+func (t *transport) writeError(id json.RawMessage, code int, msg string) error {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return t.writeJSON(responseMessage{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &responseError{Code: code, Message: msg},
+	})
+}
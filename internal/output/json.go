@@ -14,10 +14,12 @@ type jsonDiagnostic struct {
 	File            string           `json:"file"`
 	Line            int              `json:"line"`
 	Column          int              `json:"column"`
+	EndColumn       int              `json:"end_column,omitempty"`
 	Rule            string           `json:"rule"`
 	Name            string           `json:"name"`
 	Severity        string           `json:"severity"`
 	Message         string           `json:"message"`
+	Suggestions     []string         `json:"suggestions,omitempty"`
 	SourceLines     []string         `json:"source_lines,omitempty"`
 	SourceStartLine int              `json:"source_start_line,omitempty"`
 	Explanation     *jsonExplanation `json:"explanation,omitempty"`
@@ -43,10 +45,12 @@ func (f *JSONFormatter) Format(w io.Writer, diagnostics []lint.Diagnostic) error
 			File:            d.File,
 			Line:            d.Line,
 			Column:          d.Column,
+			EndColumn:       d.EndColumn,
 			Rule:            d.RuleID,
 			Name:            d.RuleName,
 			Severity:        string(d.Severity),
 			Message:         d.Message,
+			Suggestions:     d.Suggestions,
 			SourceLines:     d.SourceLines,
 			SourceStartLine: d.SourceStartLine,
 			Explanation:     explanationToJSON(d.Explanation),
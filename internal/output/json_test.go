@@ -236,6 +236,31 @@ func TestJSONFormatter_SourceContextOmittedWhenEmpty(t *testing.T) {
 	assert.False(t, hasSourceStartLine, "source_start_line should be omitted when zero")
 }
 
+func TestJSONFormatter_EndColumnAndSuggestions(t *testing.T) {
+	f := &JSONFormatter{}
+	var buf bytes.Buffer
+
+	diagnostics := []lint.Diagnostic{
+		{
+			File:        "README.md",
+			Line:        3,
+			Column:      5,
+			EndColumn:   8,
+			RuleID:      "MDS060",
+			RuleName:    "spelling",
+			Severity:    lint.Warning,
+			Message:     `unknown word "teh"; did you mean "the"?`,
+			Suggestions: []string{"the", "ten"},
+		},
+	}
+
+	result := formatAndUnmarshal(t, f, &buf, diagnostics)
+	require.Len(t, result, 1)
+
+	assert.Equal(t, 8, result[0].EndColumn)
+	assert.Equal(t, []string{"the", "ten"}, result[0].Suggestions)
+}
+
 func TestJSONFormatter_ImplementsFormatter(t *testing.T) {
 	var _ Formatter = &JSONFormatter{}
 }
@@ -108,6 +108,29 @@ func MaskBodyTokens(text string, tokens []string) string {
 	return text
 }
 
+// BodyTokenSpans returns the byte ranges [start, end) of text covered
+// by the named body tokens. Unlike MaskBodyTokens it leaves text alone,
+// so callers that report offsets can skip the ranges instead.
+// Whole-text tokens cover all of text when they match.
+func BodyTokenSpans(text string, tokens []string) [][2]int {
+	var spans [][2]int
+	for _, tok := range tokens {
+		switch tok {
+		case VarToken:
+			spans = append(spans, fieldinterp.FieldSpans(text)...)
+		case HeadingQuestion:
+			if questionPattern.MatchString(text) {
+				return [][2]int{{0, len(text)}}
+			}
+		case PlaceholderSection:
+			if ellipsisPattern.MatchString(text) {
+				return [][2]int{{0, len(text)}}
+			}
+		}
+	}
+	return spans
+}
+
 // IsAllBodyTokens reports whether text (trimmed) consists only of
 // placeholder token patterns, with no other content. Unlike MaskBodyTokens,
 // this strips placeholder patterns to empty rather than replacing with
@@ -286,3 +286,13 @@ func TestHasCUEFrontmatter(t *testing.T) {
 	assert.False(t, placeholders.HasCUEFrontmatter(nil))
 	assert.False(t, placeholders.HasCUEFrontmatter([]string{}))
 }
+
+func TestBodyTokenSpans(t *testing.T) {
+	assert.Equal(t, [][2]int{{6, 12}},
+		placeholders.BodyTokenSpans("Hello {name} world", []string{placeholders.VarToken}))
+	assert.Equal(t, [][2]int{{0, 3}},
+		placeholders.BodyTokenSpans("...", []string{placeholders.PlaceholderSection}))
+	assert.Equal(t, [][2]int{{0, 1}},
+		placeholders.BodyTokenSpans("?", []string{placeholders.HeadingQuestion}))
+	assert.Nil(t, placeholders.BodyTokenSpans("{name}", []string{placeholders.CUEFrontmatter}))
+}
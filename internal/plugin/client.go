@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxMessageBytes caps one plugin response.
+const maxMessageBytes = 64 * 1024 * 1024
+
+// client speaks the protocol to one plugin instance, a process or a
+// WebAssembly module. Requests are serialised: a plugin only ever
+// sees one request in flight, so it can be written as a simple
+// read-eval-print loop. Any transport failure or timeout stops the
+// instance and makes the client fail every later call.
+type client struct {
+	spec    Spec
+	timeout time.Duration
+	conn    conn
+	caps    PluginCapabilities
+
+	mu     sync.Mutex
+	nextID int64
+	err    error
+}
+
+// conn carries encoded messages to one plugin instance.
+type conn interface {
+	// roundTrip sends one request and returns the response with
+	// the given id, giving up after timeout.
+	roundTrip(req []byte, id int64, timeout time.Duration) reply
+	// close stops the instance after shutdown; kill stops it now.
+	close()
+	kill()
+}
+
+// start launches spec's process or module and negotiates
+// capabilities.
+func start(spec Spec) (*client, error) {
+	timeout, err := spec.timeout()
+	if err != nil {
+		return nil, err
+	}
+	var cn conn
+	if spec.Wasm != "" {
+		cn, err = startModule(spec, timeout)
+	} else {
+		cn, err = startProcess(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &client{spec: spec, timeout: timeout, conn: cn}
+	if err := c.initialize(); err != nil {
+		cn.kill()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *client) initialize() error {
+	var res InitializeResult
+	err := c.call(methodInitialize, InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    HostCapabilities{Outline: true, Edits: true},
+		Rules:           c.spec.ruleNames(),
+	}, &res)
+	if err != nil {
+		return err
+	}
+	if res.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("protocol version %d, want %d", res.ProtocolVersion, ProtocolVersion)
+	}
+	for _, name := range c.spec.ruleNames() {
+		if !slices.Contains(res.Rules, name) {
+			return fmt.Errorf("plugin does not provide rule %q", name)
+		}
+	}
+	c.caps = res.Capabilities
+	return nil
+}
+
+// check runs one rule over one file.
+func (c *client) check(params CheckParams) (CheckResult, error) {
+	var res CheckResult
+	err := c.call(methodCheck, params, &res)
+	return res, err
+}
+
+// call sends one request and waits up to the plugin's timeout for
+// the matching response.
+func (c *client) call(method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	body, err := json.Marshal(requestMessage{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("%s: encoding request: %w", method, err)
+	}
+
+	rep := c.conn.roundTrip(body, id, c.timeout)
+	if rep.err != nil {
+		c.err = fmt.Errorf("%s: %w", method, rep.err)
+		c.conn.kill()
+		return c.err
+	}
+	if rep.rpcErr != nil {
+		return fmt.Errorf("%s: %w", method, rep.rpcErr)
+	}
+	if err := json.Unmarshal(rep.result, result); err != nil {
+		return fmt.Errorf("%s: decoding result: %w", method, err)
+	}
+	return nil
+}
+
+// reply is the outcome of one request. err is a transport failure,
+// which kills the plugin; rpcErr is an error the plugin answered
+// with, which leaves it running.
+type reply struct {
+	result json.RawMessage
+	rpcErr *responseError
+	err    error
+}
+
+// decodeReply parses one response line. ok is false for a message
+// that is not the response to id, such as a notification.
+func decodeReply(line []byte, id int64) (rep reply, ok bool) {
+	var msg responseMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return reply{err: fmt.Errorf("malformed message: %w", err)}, true
+	}
+	if msg.ID == nil || *msg.ID != id {
+		return reply{}, false
+	}
+	return reply{result: msg.Result, rpcErr: msg.Error}, true
+}
+
+// close asks a healthy plugin to shut down, then stops it.
+func (c *client) close() {
+	c.mu.Lock()
+	healthy := c.err == nil
+	c.mu.Unlock()
+	if healthy {
+		_ = c.call(methodShutdown, nil, &json.RawMessage{})
+	}
+	c.conn.close()
+}
@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	ruledocs "github.com/jeduden/mdsmith/internal/rules"
+)
+
+// Status is the status `mdsmith help rule` lists for plugin rules.
+const Status = "plugin"
+
+// Infos describes the rules s declares, for `mdsmith help rule`.
+// Nothing is started: the text comes from the config alone.
+func Infos(s Spec) []ruledocs.RuleInfo {
+	infos := make([]ruledocs.RuleInfo, 0, len(s.Rules))
+	for _, d := range s.Rules {
+		desc := d.Description
+		if desc == "" {
+			desc = fmt.Sprintf("Provided by plugin %s.", s.Name)
+		}
+		infos = append(infos, ruledocs.RuleInfo{
+			ID:          d.ID,
+			Name:        d.Name,
+			Status:      Status,
+			Description: desc,
+			Content:     content(s, d, desc),
+		})
+	}
+	return infos
+}
+
+func content(s Spec, d RuleDef, desc string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", d.ID, d.Name)
+	fmt.Fprintf(&b, "%s\n\n", desc)
+	b.WriteString("Provided by a plugin declared under `plugins:` in the config file.\n\n")
+	b.WriteString("## Plugin\n\n")
+	category := d.Category
+	if category == "" {
+		category = DefaultCategory
+	}
+	timeout, _ := s.timeout()
+	fmt.Fprintf(&b, "- Plugin: %s\n", s.Name)
+	if s.Wasm != "" {
+		fmt.Fprintf(&b, "- Module: `%s`\n", s.Wasm)
+	} else {
+		fmt.Fprintf(&b, "- Command: `%s`\n", strings.Join(s.Command, " "))
+	}
+	fmt.Fprintf(&b, "- Category: %s\n", category)
+	fmt.Fprintf(&b, "- Timeout: %s\n", timeout)
+	return b.String()
+}
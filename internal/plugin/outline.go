@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rules/astutil"
+	"github.com/jeduden/mdsmith/internal/rules/fencepos"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Outline serialises the block structure of f for plugins: one node
+// per block with a source position, nested as in the document. Lines
+// are 1-based and count from the first line of f.Source.
+func Outline(f *lint.File) []OutlineNode {
+	return f.Memo("plugin.outline", func() any {
+		return outlineChildren(f, f.AST)
+	}).([]OutlineNode)
+}
+
+func outlineChildren(f *lint.File, parent ast.Node) []OutlineNode {
+	var out []OutlineNode
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		if n.Type() != ast.TypeBlock {
+			continue
+		}
+		first, last := span(f, n)
+		if first == 0 {
+			continue
+		}
+		node := OutlineNode{Kind: kind(f, n), Line: first, EndLine: last}
+		switch v := n.(type) {
+		case *ast.Heading:
+			node.Level = v.Level
+			node.Text = astutil.HeadingText(v, f.Source)
+		case *ast.FencedCodeBlock:
+			node.Info = string(v.Language(f.Source))
+		}
+		node.Children = outlineChildren(f, n)
+		out = append(out, node)
+	}
+	return out
+}
+
+// span returns the first and last line of n and its descendants,
+// fence lines included. Nodes goldmark keeps no position for, such as
+// thematic breaks, report 0.
+func span(f *lint.File, n ast.Node) (first, last int) {
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var from, to int
+		switch {
+		case c.Kind() == ast.KindFencedCodeBlock:
+			fcb := c.(*ast.FencedCodeBlock)
+			from, to = fencepos.OpenLine(f, fcb), min(fencepos.CloseLine(f, fcb), len(f.Lines))
+		case c.Type() == ast.TypeBlock && c.Lines().Len() > 0:
+			lines := c.Lines()
+			from = f.LineOfOffset(lines.At(0).Start)
+			to = f.LineOfOffset(max(lines.At(lines.Len()-1).Stop-1, lines.At(0).Start))
+		case c.Kind() == ast.KindText:
+			seg := c.(*ast.Text).Segment
+			from, to = f.LineOfOffset(seg.Start), f.LineOfOffset(max(seg.Stop-1, seg.Start))
+		default:
+			return ast.WalkContinue, nil
+		}
+		if first == 0 || from < first {
+			first = from
+		}
+		last = max(last, to)
+		return ast.WalkContinue, nil
+	})
+	return first, last
+}
+
+// kind names a block in kebab case. Tables (parsed as paragraphs)
+// and the code block variants get their Markdown names.
+func kind(f *lint.File, n ast.Node) string {
+	switch v := n.(type) {
+	case *ast.Paragraph:
+		if astutil.IsTable(v, f) {
+			return "table"
+		}
+		return "paragraph"
+	case *ast.TextBlock:
+		return "paragraph"
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		return "code-block"
+	}
+	return kebab(n.Kind().String())
+}
+
+// kebab converts a node kind such as "ListItem" or "HTMLBlock" to
+// "list-item" or "html-block".
+func kebab(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(rs[i-1])
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(rs[i-1])) {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
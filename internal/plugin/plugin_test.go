@@ -0,0 +1,386 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlugin and fakeWasm are testdata/fakeplugin and
+// testdata/fakewasm, built once.
+var fakePlugin, fakeWasm string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mdsmith-plugin-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "creating temp dir: %v\n", err)
+		os.Exit(1)
+	}
+	fakePlugin = filepath.Join(dir, "fakeplugin")
+	fakeWasm = filepath.Join(dir, "fake.wasm")
+	builds := []*exec.Cmd{
+		exec.Command("go", "build", "-o", fakePlugin, "./testdata/fakeplugin"),
+		exec.Command("go", "build", "-buildmode=c-shared", "-o", fakeWasm, "./testdata/fakewasm"),
+	}
+	builds[1].Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	for _, cmd := range builds {
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "building fake plugin: %v\n", err)
+			_ = os.RemoveAll(dir)
+			os.Exit(1)
+		}
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func spec(rules ...string) Spec {
+	s := Spec{Name: "fake", Command: []string{fakePlugin}}
+	for i, name := range rules {
+		s.Rules = append(s.Rules, RuleDef{ID: fmt.Sprintf("FAKE%03d", i+1), Name: name})
+	}
+	return s
+}
+
+func pluginRule(t *testing.T, pool *Pool, s Spec, name string) rule.Rule {
+	t.Helper()
+	for _, r := range Rules(pool, []Spec{s}) {
+		if r.Name() == name {
+			return r
+		}
+	}
+	t.Fatalf("no rule %q", name)
+	return nil
+}
+
+func newPool(t *testing.T) *Pool {
+	t.Helper()
+	p := NewPool()
+	p.TrustCommands()
+	t.Cleanup(p.Close)
+	return p
+}
+
+func file(t *testing.T, path, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFileFromSource(path, []byte(src), true)
+	require.NoError(t, err)
+	return f
+}
+
+func check(r rule.Rule, f *lint.File) []lint.Diagnostic {
+	diags := r.Check(f)
+	f.AdjustDiagnostics(diags)
+	return diags
+}
+
+func TestRule_CheckAndFix(t *testing.T) {
+	r := pluginRule(t, newPool(t), spec("no-todo"), "no-todo")
+	src := "---\ntitle: x\n---\n# Title\n\nSome TODO here.\n"
+
+	diags := check(r, file(t, "doc.md", src))
+	require.Len(t, diags, 1)
+	d := diags[0]
+	assert.Equal(t, "doc.md", d.File)
+	assert.Equal(t, 6, d.Line, "line counts front matter")
+	assert.Equal(t, 6, d.Column)
+	assert.Equal(t, "FAKE001", d.RuleID)
+	assert.Equal(t, lint.Error, d.Severity, "severity defaults to error")
+	assert.Equal(t, "TODO left in text", d.Message)
+
+	fixed := r.(rule.FixableRule).Fix(file(t, "doc.md", src))
+	assert.Equal(t, "# Title\n\nSome DONE here.\n", string(fixed))
+}
+
+func TestPool_RefusesUntrustedCommands(t *testing.T) {
+	pool := NewPool()
+	t.Cleanup(pool.Close)
+	r := pluginRule(t, pool, spec("no-todo"), "no-todo")
+	src := "Some TODO here.\n"
+
+	diags := check(r, file(t, "doc.md", src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Contains(t, diags[0].Message, "plugin fake: command plugins are not trusted; set MDSMITH_TRUST_PLUGINS=1")
+	assert.Equal(t, src, string(r.(rule.FixableRule).Fix(file(t, "doc.md", src))))
+
+	wasm := pluginRule(t, pool, wasmSpec("no-todo"), "no-todo")
+	require.Len(t, check(wasm, file(t, "doc.md", src)), 1, "a sandboxed module needs no trust")
+	assert.Equal(t, "TODO left in text", check(wasm, file(t, "doc.md", src))[0].Message)
+}
+
+func TestTrustedByEnv(t *testing.T) {
+	for v, want := range map[string]bool{"": false, "0": false, "no": false, "1": true, "true": true} {
+		t.Setenv(TrustEnv, v)
+		assert.Equal(t, want, TrustedByEnv(), "%s=%q", TrustEnv, v)
+	}
+}
+
+func TestRule_SettingsReachPlugin(t *testing.T) {
+	r := pluginRule(t, newPool(t), spec("no-todo"), "no-todo")
+	require.NoError(t, r.(rule.Configurable).ApplySettings(map[string]any{"replacement": "FIXME"}))
+
+	fixed := r.(rule.FixableRule).Fix(file(t, "doc.md", "A TODO.\n"))
+	assert.Equal(t, "A FIXME.\n", string(fixed))
+
+	clone := rule.CloneRule(r)
+	fixed = clone.(rule.FixableRule).Fix(file(t, "doc.md", "A TODO.\n"))
+	assert.Equal(t, "A DONE.\n", string(fixed), "a reset clone drops settings")
+}
+
+func TestRule_FixSkipsGeneratedSections(t *testing.T) {
+	r := pluginRule(t, newPool(t), spec("no-todo"), "no-todo")
+	f := file(t, "doc.md", "TODO one\n\nTODO two\n")
+	f.GeneratedRanges = []lint.LineRange{{From: 3, To: 3}}
+
+	fixed := r.(rule.FixableRule).Fix(f)
+	assert.Equal(t, "DONE one\n\nTODO two\n", string(fixed))
+}
+
+func TestRule_OutlineAndFrontMatter(t *testing.T) {
+	pool := newPool(t)
+	s := spec("outline", "front-matter")
+	src := "---\ntitle: Hello\n---\n# Top\n\n- item\n\n  ## Nested\n"
+
+	diags := check(pluginRule(t, pool, s, "outline"), file(t, "doc.md", src))
+	require.Len(t, diags, 2)
+	assert.Equal(t, "h1 Top", diags[0].Message)
+	assert.Equal(t, 4, diags[0].Line)
+	assert.Equal(t, lint.Warning, diags[0].Severity)
+	assert.Equal(t, "h2 Nested", diags[1].Message)
+	assert.Equal(t, 8, diags[1].Line)
+
+	diags = check(pluginRule(t, pool, s, "front-matter"), file(t, "doc.md", src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "title Hello", diags[0].Message)
+}
+
+func TestPool_ReusesProcessAcrossFiles(t *testing.T) {
+	pool := newPool(t)
+	r := pluginRule(t, pool, spec("pid"), "pid")
+
+	first := check(r, file(t, "a.md", "a\n"))[0].Message
+	second := check(rule.CloneRule(r), file(t, "b.md", "b\n"))[0].Message
+	var pid1, pid2, n1, n2 int
+	_, err := fmt.Sscanf(first, "pid %d check %d", &pid1, &n1)
+	require.NoError(t, err)
+	_, err = fmt.Sscanf(second, "pid %d check %d", &pid2, &n2)
+	require.NoError(t, err)
+	assert.Equal(t, pid1, pid2, "one process serves both files")
+	assert.Equal(t, []int{1, 2}, []int{n1, n2})
+
+	pool.Close()
+	third := check(r, file(t, "c.md", "c\n"))[0].Message
+	assert.NotEqual(t, first, third, "Close restarts the plugin on next use")
+}
+
+func TestRule_CheckOncePerFile(t *testing.T) {
+	r := pluginRule(t, newPool(t), spec("pid"), "pid")
+	f := file(t, "a.md", "a\n")
+
+	first := r.Check(f)[0].Message
+	assert.Equal(t, first, r.Check(f)[0].Message)
+	_ = r.(rule.FixableRule).Fix(f)
+	assert.Contains(t, r.Check(file(t, "a.md", "a\n"))[0].Message, "check 2")
+}
+
+func TestRule_Timeout(t *testing.T) {
+	s := spec("hang", "pid")
+	s.Timeout = "200ms"
+	pool := newPool(t)
+
+	diags := check(pluginRule(t, pool, s, "hang"), file(t, "doc.md", "x\n"))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "plugin fake: check: no response within 200ms", diags[0].Message)
+	assert.Equal(t, 1, diags[0].Line)
+
+	// The process is gone; later checks fail fast.
+	diags = check(pluginRule(t, pool, s, "pid"), file(t, "doc.md", "x\n"))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "no response within 200ms")
+}
+
+func TestRule_ErrorResponseKeepsPlugin(t *testing.T) {
+	pool := newPool(t)
+	s := spec("fail", "pid")
+
+	diags := check(pluginRule(t, pool, s, "fail"), file(t, "doc.md", "x\n"))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "plugin fake: check: rule failed (code -32000)", diags[0].Message)
+
+	diags = check(pluginRule(t, pool, s, "pid"), file(t, "doc.md", "x\n"))
+	assert.Contains(t, diags[0].Message, "check 2")
+}
+
+func TestStart_Negotiation(t *testing.T) {
+	s := spec("missing")
+	_, err := start(s)
+	require.EqualError(t, err, `plugin does not provide rule "missing"`)
+
+	s = spec("pid")
+	s.Command = []string{fakePlugin, "-version", "2"}
+	_, err = start(s)
+	require.EqualError(t, err, "protocol version 2, want 1")
+
+	s.Command = []string{filepath.Join(t.TempDir(), "absent")}
+	_, err = start(s)
+	require.ErrorContains(t, err, "starting ")
+}
+
+func wasmSpec(rules ...string) Spec {
+	s := spec(rules...)
+	s.Command, s.Wasm = nil, fakeWasm
+	return s
+}
+
+func TestWasm_CheckAndFix(t *testing.T) {
+	r := pluginRule(t, newPool(t), wasmSpec("no-todo"), "no-todo")
+	src := "---\ntitle: x\n---\n# Title\n\nSome TODO here.\n"
+
+	diags := check(r, file(t, "doc.md", src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, [2]int{6, 6}, [2]int{diags[0].Line, diags[0].Column})
+	assert.Equal(t, "TODO left in text", diags[0].Message)
+
+	fixed := r.(rule.FixableRule).Fix(file(t, "doc.md", src))
+	assert.Equal(t, "# Title\n\nSome DONE here.\n", string(fixed))
+}
+
+func TestWasm_NoFilesystem(t *testing.T) {
+	path, err := filepath.Abs("plugin_test.go")
+	require.NoError(t, err)
+	r := pluginRule(t, newPool(t), wasmSpec("read-file"), "read-file")
+
+	diags := check(r, file(t, path, "x\n"))
+	require.Len(t, diags, 1)
+	assert.NotEqual(t, "<nil>", diags[0].Message, "the guest must not read host files")
+}
+
+func TestWasm_Timeout(t *testing.T) {
+	s := wasmSpec("spin", "no-todo")
+	s.Timeout = "300ms"
+	pool := newPool(t)
+
+	diags := check(pluginRule(t, pool, s, "spin"), file(t, "doc.md", "x\n"))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "plugin fake: check: no response within 300ms", diags[0].Message)
+
+	diags = check(pluginRule(t, pool, s, "no-todo"), file(t, "doc.md", "TODO\n"))
+	assert.Contains(t, diags[0].Message, "no response within 300ms", "a stopped module stays stopped")
+}
+
+func TestWasm_MemoryLimit(t *testing.T) {
+	s := wasmSpec("grow")
+	s.MemoryLimit = 32 * 1024 * 1024
+
+	diags := check(pluginRule(t, newPool(t), s, "grow"), file(t, "doc.md", "x\n"))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "plugin fake: check: handle:")
+}
+
+func TestWasm_MissingExports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.wasm")
+	// The smallest valid module: magic and version, nothing exported.
+	require.NoError(t, os.WriteFile(path, []byte("\x00asm\x01\x00\x00\x00"), 0o644))
+	s := spec("no-todo")
+	s.Command, s.Wasm = nil, path
+
+	_, err := start(s)
+	require.EqualError(t, err, "module exports no memory")
+}
+
+func TestSpec_Executable(t *testing.T) {
+	s := Spec{Command: []string{"./bin/lint"}, Dir: "/repo"}
+	assert.Equal(t, filepath.Join("/repo", "bin", "lint"), s.executable())
+	s.Command = []string{"lint-plugin"}
+	assert.Equal(t, "lint-plugin", s.executable(), "bare names use PATH")
+}
+
+func TestValidate(t *testing.T) {
+	ok := Spec{Name: "acme", Command: []string{"acme"}, Rules: []RuleDef{{ID: "ACME001", Name: "acme-names"}}}
+	tests := []struct {
+		name  string
+		edit  func(*Spec)
+		taken map[string]bool
+		err   string
+	}{
+		{name: "valid", edit: func(*Spec) {}},
+		{name: "bad name", edit: func(s *Spec) { s.Name = "Acme" },
+			err: `plugins[0] (Acme): name "Acme" must be lower-case kebab-case`},
+		{name: "no command", edit: func(s *Spec) { s.Command = nil },
+			err: "plugins[0] (acme): command or wasm is required"},
+		{name: "command and wasm", edit: func(s *Spec) { s.Wasm = "acme.wasm" },
+			err: "plugins[0] (acme): command and wasm are mutually exclusive"},
+		{name: "memory without wasm", edit: func(s *Spec) { s.Memory = "8MB" },
+			err: "plugins[0] (acme): memory applies only to wasm plugins"},
+		{name: "no rules", edit: func(s *Spec) { s.Rules = nil },
+			err: "plugins[0] (acme): rules must list at least one rule"},
+		{name: "bad timeout", edit: func(s *Spec) { s.Timeout = "soon" },
+			err: `plugins[0] (acme): timeout "soon" must be a positive duration`},
+		{name: "reserved id", edit: func(s *Spec) { s.Rules[0].ID = "mds900" },
+			err: `plugins[0] (acme): rule "acme-names": id "mds900": the MDS prefix is reserved for built-in rules`},
+		{name: "taken id", edit: func(*Spec) {}, taken: map[string]bool{"ACME001": true},
+			err: `plugins[0] (acme): rule "acme-names": id "ACME001" is already in use`},
+		{name: "taken name", edit: func(*Spec) {}, taken: map[string]bool{"acme-names": true},
+			err: `plugins[0] (acme): rule "acme-names": name is already in use`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ok
+			s.Rules = append([]RuleDef(nil), ok.Rules...)
+			tt.edit(&s)
+			taken := tt.taken
+			if taken == nil {
+				taken = map[string]bool{}
+			}
+			err := Validate([]Spec{s}, taken)
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.True(t, taken["ACME001"] && taken["acme-names"])
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+
+	err := Validate([]Spec{ok, ok}, map[string]bool{})
+	require.EqualError(t, err, `plugins[1] (acme): name "acme" is already in use`)
+}
+
+func TestOutline(t *testing.T) {
+	src := "---\nk: v\n---\n# Title\n\n> quote\n\n```go\nx\n```\n\n| A |\n| - |\n| 1 |\n\n---\n\n1. one\n2. two\n"
+	got := Outline(file(t, "doc.md", src))
+
+	kinds := make([]string, len(got))
+	for i, n := range got {
+		kinds[i] = n.Kind
+	}
+	assert.Equal(t, []string{"heading", "blockquote", "code-block", "table", "list"}, kinds,
+		"thematic breaks carry no position and are left out")
+	assert.Equal(t, OutlineNode{Kind: "heading", Line: 1, EndLine: 1, Level: 1, Text: "Title"}, got[0])
+	assert.Equal(t, "go", got[2].Info)
+	assert.Equal(t, [2]int{5, 7}, [2]int{got[2].Line, got[2].EndLine})
+	assert.Equal(t, [2]int{9, 11}, [2]int{got[3].Line, got[3].EndLine})
+	require.Len(t, got[4].Children, 2)
+	assert.Equal(t, "list-item", got[4].Children[0].Kind)
+	assert.Equal(t, "paragraph", got[4].Children[0].Children[0].Kind)
+	assert.Equal(t, 16, got[4].Children[1].Line)
+}
+
+func TestKebab(t *testing.T) {
+	for in, want := range map[string]string{
+		"Heading": "heading", "ListItem": "list-item", "HTMLBlock": "html-block",
+		"ThematicBreak": "thematic-break",
+	} {
+		assert.Equal(t, want, kebab(in))
+	}
+}
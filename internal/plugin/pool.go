@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// TrustEnv names the environment variable that opts in to running
+// command plugins. A `command:` in a checked-out .mdsmith.yml is an
+// arbitrary executable, so it only runs when the user says so.
+const TrustEnv = "MDSMITH_TRUST_PLUGINS"
+
+// ErrUntrusted is returned for a command plugin in a pool that does
+// not trust commands.
+var ErrUntrusted = errors.New("command plugins are not trusted")
+
+// TrustedByEnv reports whether TrustEnv is set to a true value.
+func TrustedByEnv() bool {
+	ok, err := strconv.ParseBool(os.Getenv(TrustEnv))
+	return err == nil && ok
+}
+
+// Pool owns the plugin processes of one caller. A process starts on
+// the first check that needs it and then serves every later check,
+// across files and across engine runs, until Close. A plugin that
+// fails to start, times out, or breaks the protocol stays failed
+// until Close, so a broken plugin costs one timeout rather than one
+// per file. The zero value is not usable; call NewPool.
+//
+// A new pool runs Wasm modules, which are sandboxed, but refuses
+// command plugins until TrustCommands is called.
+type Pool struct {
+	mu       sync.Mutex
+	clients  map[string]*entry
+	commands bool
+}
+
+// entry is one pool slot. once makes concurrent first checks share a
+// single start.
+type entry struct {
+	once sync.Once
+	c    *client
+	err  error
+}
+
+// NewPool returns an empty pool.
+func NewPool() *Pool {
+	return &Pool{clients: map[string]*entry{}}
+}
+
+// TrustCommands lets the pool start command plugins. Call it before
+// the first check, and only on the user's explicit opt-in.
+func (p *Pool) TrustCommands() {
+	p.mu.Lock()
+	p.commands = true
+	p.mu.Unlock()
+}
+
+// client returns the running process for spec, starting it if needed.
+func (p *Pool) client(spec Spec) (*client, error) {
+	p.mu.Lock()
+	if spec.Wasm == "" && !p.commands {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w; set %s=1 to run %q", ErrUntrusted, TrustEnv, spec.Command[0])
+	}
+	e, ok := p.clients[spec.key()]
+	if !ok {
+		e = &entry{}
+		p.clients[spec.key()] = e
+	}
+	p.mu.Unlock()
+	e.once.Do(func() { e.c, e.err = start(spec) })
+	return e.c, e.err
+}
+
+// Close shuts down every process and empties the pool. The pool stays
+// usable: the next check starts a fresh process, which is how the LSP
+// retries a failed plugin after a config reload.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	clients := p.clients
+	p.clients = map[string]*entry{}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range clients {
+		e.once.Do(func() {}) // a start still in flight completes first
+		if e.c == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(c *client) {
+			defer wg.Done()
+			c.close()
+		}(e.c)
+	}
+	wg.Wait()
+}
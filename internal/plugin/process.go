@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// shutdownGrace bounds how long close waits for a plugin to exit
+// after shutdown before killing it.
+const shutdownGrace = time.Second
+
+// process is a plugin executable speaking the protocol on its stdin
+// and stdout, one message per line.
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	out    *bufio.Reader
+	waited chan struct{}
+}
+
+func startProcess(spec Spec) (*process, error) {
+	cmd := exec.Command(spec.executable(), spec.Command[1:]...) // #nosec G204 -- runs only in a pool the user trusted
+	cmd.Dir = spec.Dir
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", spec.Command[0], err)
+	}
+	p := &process{cmd: cmd, stdin: stdin, out: bufio.NewReader(stdout), waited: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		close(p.waited)
+	}()
+	return p, nil
+}
+
+// executable resolves a relative command path against the config
+// directory; a bare name is looked up on PATH.
+func (s Spec) executable() string {
+	name := s.Command[0]
+	if s.Dir != "" && !filepath.IsAbs(name) && strings.ContainsRune(name, filepath.Separator) {
+		return filepath.Join(s.Dir, name)
+	}
+	return name
+}
+
+func (p *process) roundTrip(req []byte, id int64, timeout time.Duration) reply {
+	done := make(chan reply, 1)
+	go func() {
+		if _, err := p.stdin.Write(append(req, '\n')); err != nil {
+			done <- reply{err: err}
+			return
+		}
+		done <- p.readResponse(id)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case rep := <-done:
+		return rep
+	case <-timer.C:
+		// The reader goroutine unblocks once the killed process
+		// closes its stdout; done is buffered, so it never leaks.
+		return reply{err: fmt.Errorf("no response within %s", timeout)}
+	}
+}
+
+// readResponse reads up to the response with the given id,
+// skipping notifications and blank lines.
+func (p *process) readResponse(id int64) reply {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return reply{err: err}
+		}
+		if rep, ok := decodeReply(line, id); ok {
+			return rep
+		}
+	}
+}
+
+func (p *process) readLine() ([]byte, error) {
+	for {
+		var line []byte
+		for {
+			chunk, isPrefix, err := p.out.ReadLine()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, errors.New("plugin exited")
+				}
+				return nil, err
+			}
+			line = append(line, chunk...)
+			if len(line) > maxMessageBytes {
+				return nil, fmt.Errorf("message exceeds %d bytes", maxMessageBytes)
+			}
+			if !isPrefix {
+				break
+			}
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			return line, nil
+		}
+	}
+}
+
+// close closes stdin and kills the process if it has not exited
+// within shutdownGrace.
+func (p *process) close() {
+	_ = p.stdin.Close()
+	select {
+	case <-p.waited:
+	case <-time.After(shutdownGrace):
+		p.kill()
+	}
+}
+
+func (p *process) kill() {
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,131 @@
+// Package plugin runs lint rules implemented outside mdsmith. Each
+// entry under `plugins:` in .mdsmith.yml names an executable or a
+// WebAssembly module and the rules it provides. A Pool starts the
+// plugin on first use and keeps it running, so one instance serves
+// every file of a run instead of being spawned per file. mdsmith and
+// the plugin speak JSON-RPC 2.0: over the executable's stdin and
+// stdout, one message per line — the framing `mdsmith mcp` uses — or
+// through the module's exported handle function, which runs under
+// wazero with no filesystem or network. docs/reference/plugin-protocol.md
+// is the protocol specification for plugin authors.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is the protocol revision this host speaks. A plugin
+// must answer initialize with the same number.
+const ProtocolVersion = 1
+
+// Method names.
+const (
+	methodInitialize = "initialize"
+	methodCheck      = "check"
+	methodShutdown   = "shutdown"
+)
+
+// JSON-RPC 2.0 framing.
+
+type requestMessage struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// responseMessage is a reply. Messages without an ID (notifications
+// a plugin may emit) are skipped by the reader.
+type responseMessage struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// InitializeParams opens the session. Rules lists the rule names the
+// config expects the plugin to provide.
+type InitializeParams struct {
+	ProtocolVersion int              `json:"protocolVersion"`
+	Capabilities    HostCapabilities `json:"capabilities"`
+	Rules           []string         `json:"rules"`
+}
+
+// HostCapabilities tells the plugin what the host can send and apply.
+type HostCapabilities struct {
+	Outline bool `json:"outline"`
+	Edits   bool `json:"edits"`
+}
+
+// InitializeResult is the plugin's answer. Every configured rule must
+// appear in Rules.
+type InitializeResult struct {
+	ProtocolVersion int                `json:"protocolVersion"`
+	Rules           []string           `json:"rules"`
+	Capabilities    PluginCapabilities `json:"capabilities"`
+}
+
+// PluginCapabilities selects the check inputs the plugin wants; the
+// host omits the others. Edits declares that diagnostics may carry
+// edits, which `mdsmith fix` then applies.
+type PluginCapabilities struct {
+	Source      bool `json:"source"`
+	FrontMatter bool `json:"frontMatter"`
+	Outline     bool `json:"outline"`
+	Edits       bool `json:"edits"`
+}
+
+// CheckParams asks the plugin to run one rule over one file. Source
+// is the Markdown body after front matter; lines, columns, and edit
+// offsets all refer to it.
+type CheckParams struct {
+	Rule        string         `json:"rule"`
+	Settings    map[string]any `json:"settings,omitempty"`
+	Path        string         `json:"path"`
+	Source      *string        `json:"source,omitempty"`
+	FrontMatter map[string]any `json:"frontMatter,omitempty"`
+	Outline     []OutlineNode  `json:"outline,omitempty"`
+}
+
+// CheckResult carries the rule's findings for the file.
+type CheckResult struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Diagnostic is one finding. Line and Column are 1-based; Severity
+// is "error" (the default) or "warning".
+type Diagnostic struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+	Severity string `json:"severity,omitempty"`
+	Edits    []Edit `json:"edits,omitempty"`
+}
+
+// Edit replaces the source bytes [Start, End) with Text.
+type Edit struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// OutlineNode is one block of the document. Level is set on headings,
+// Text on headings (plain text), and Info on fenced code blocks.
+type OutlineNode struct {
+	Kind     string        `json:"kind"`
+	Line     int           `json:"line"`
+	EndLine  int           `json:"endLine"`
+	Level    int           `json:"level,omitempty"`
+	Text     string        `json:"text,omitempty"`
+	Info     string        `json:"info,omitempty"`
+	Children []OutlineNode `json:"children,omitempty"`
+}
@@ -0,0 +1,179 @@
+package plugin
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// Rule is one rule a plugin provides. Every check is a request to the
+// plugin's process in the Pool. Settings under `rules.<name>:` are
+// passed through to the plugin unvalidated.
+type Rule struct {
+	pool     *Pool
+	spec     Spec
+	def      RuleDef
+	settings map[string]any
+}
+
+var (
+	_ rule.FixableRule  = (*Rule)(nil)
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Cloner       = (*Rule)(nil)
+)
+
+// Rules returns the rules specs declare, backed by pool. specs must
+// have passed Validate. A nil pool yields no rules.
+func Rules(pool *Pool, specs []Spec) []rule.Rule {
+	if pool == nil {
+		return nil
+	}
+	var out []rule.Rule
+	for _, s := range specs {
+		for _, d := range s.Rules {
+			out = append(out, &Rule{pool: pool, spec: s, def: d})
+		}
+	}
+	return out
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return r.def.ID }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return r.def.Name }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string {
+	if r.def.Category != "" {
+		return r.def.Category
+	}
+	return DefaultCategory
+}
+
+// Check implements rule.Rule. A plugin failure is reported as an
+// error diagnostic on line 1 so it cannot pass silently.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	res, err := r.run(f)
+	if err != nil {
+		return []lint.Diagnostic{r.diag(f, 1, 1, lint.Error, fmt.Sprintf("plugin %s: %v", r.spec.Name, err))}
+	}
+	diags := make([]lint.Diagnostic, 0, len(res.Diagnostics))
+	for _, d := range res.Diagnostics {
+		sev := lint.Error
+		if d.Severity == string(lint.Warning) {
+			sev = lint.Warning
+		}
+		diags = append(diags, r.diag(f, max(d.Line, 1), max(d.Column, 1), sev, d.Message))
+	}
+	return diags
+}
+
+func (r *Rule) diag(f *lint.File, line, col int, sev lint.Severity, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File: f.Path, Line: line, Column: col,
+		RuleID: r.ID(), RuleName: r.Name(), Severity: sev, Message: msg,
+	}
+}
+
+// Fix implements rule.FixableRule by applying the edits attached to
+// the plugin's diagnostics. Out-of-range and overlapping edits are
+// dropped, as are edits inside generated sections.
+func (r *Rule) Fix(f *lint.File) []byte {
+	res, err := r.run(f)
+	if err != nil {
+		return f.Source
+	}
+	var edits []Edit
+	for _, d := range res.Diagnostics {
+		edits = append(edits, d.Edits...)
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		if e.Start < last || e.End < e.Start || e.End > len(f.Source) || inGenerated(f, f.LineOfOffset(e.Start)) {
+			continue
+		}
+		out = append(out, f.Source[last:e.Start]...)
+		out = append(out, e.Text...)
+		last = e.End
+	}
+	return append(out, f.Source[last:]...)
+}
+
+func inGenerated(f *lint.File, line int) bool {
+	for _, r := range f.GeneratedRanges {
+		if r.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// run sends the check request, once per file: Check and Fix on the
+// same File share the answer.
+func (r *Rule) run(f *lint.File) (CheckResult, error) {
+	type answer struct {
+		res CheckResult
+		err error
+	}
+	a := f.Memo("plugin.check:"+r.def.Name, func() any {
+		c, err := r.pool.client(r.spec)
+		if err != nil {
+			return answer{err: err}
+		}
+		res, err := c.check(r.params(f, c.caps))
+		if !c.caps.Edits {
+			for i := range res.Diagnostics {
+				res.Diagnostics[i].Edits = nil
+			}
+		}
+		return answer{res, err}
+	}).(answer)
+	return a.res, a.err
+}
+
+func (r *Rule) params(f *lint.File, caps PluginCapabilities) CheckParams {
+	p := CheckParams{Rule: r.def.Name, Settings: r.settings, Path: f.Path}
+	if caps.Source {
+		src := string(f.Source)
+		p.Source = &src
+	}
+	if caps.FrontMatter {
+		p.FrontMatter, _ = lint.ParseFrontMatterFields(f.FrontMatter)
+	}
+	if caps.Outline {
+		p.Outline = Outline(f)
+	}
+	return p
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	if r.settings == nil {
+		r.settings = map[string]any{}
+	}
+	maps.Copy(r.settings, s)
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable. Defaults live in the
+// plugin, so the host has none.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{}
+}
+
+// Clone implements rule.Cloner. Clones share the pool, so every
+// clone a run makes talks to the same process.
+func (r *Rule) Clone(reset bool) rule.Rule {
+	c := *r
+	c.settings = nil
+	if !reset {
+		c.settings = maps.Clone(r.settings)
+	}
+	return &c
+}
@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// DefaultTimeout bounds each request when a Spec sets no timeout.
+const DefaultTimeout = 10 * time.Second
+
+// DefaultCategory is the category of a plugin rule that names none.
+const DefaultCategory = "plugin"
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Spec is one `plugins:` entry as written in .mdsmith.yml. A plugin
+// is either a Command or a Wasm module.
+type Spec struct {
+	Name string `yaml:"name"`
+	// Command is the executable and its arguments. A relative path
+	// containing a separator resolves against the config directory.
+	Command []string `yaml:"command,omitempty"`
+	// Wasm is the path of a WebAssembly module, relative to the
+	// config directory, run in-process in a sandbox.
+	Wasm string `yaml:"wasm,omitempty"`
+	// Memory caps a Wasm module's linear memory, as a size ("64MB").
+	Memory string `yaml:"memory,omitempty"`
+	// Timeout bounds each request, as a Go duration ("5s").
+	Timeout string    `yaml:"timeout,omitempty"`
+	Rules   []RuleDef `yaml:"rules"`
+
+	// Dir is the directory of the config file that declared the
+	// plugin; the command runs there. Set by config.Load.
+	Dir string `yaml:"-"`
+	// MemoryLimit is Memory in bytes; zero means DefaultMemory. Set
+	// by config.Load.
+	MemoryLimit int64 `yaml:"-"`
+}
+
+// RuleDef declares one rule a plugin provides. The plugin must list
+// the name in its initialize result.
+type RuleDef struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Category    string `yaml:"category,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+func (s Spec) timeout() (time.Duration, error) {
+	if s.Timeout == "" {
+		return DefaultTimeout, nil
+	}
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("timeout %q must be a positive duration", s.Timeout)
+	}
+	return d, nil
+}
+
+func (s Spec) ruleNames() []string {
+	names := make([]string, len(s.Rules))
+	for i, r := range s.Rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// key identifies the instance a Spec runs, so a Pool shares one
+// process or module between identical specs.
+func (s Spec) key() string {
+	parts := []string{s.Dir, s.Wasm, s.Memory, s.Timeout, strings.Join(s.ruleNames(), ",")}
+	return strings.Join(append(parts, s.Command...), "\x00")
+}
+
+// Validate checks specs without starting anything. taken holds IDs
+// (upper-cased) and names already claimed by other configured rules;
+// the rules declared here are added to it. Built-in rules are checked
+// through the registry.
+func Validate(specs []Spec, taken map[string]bool) error {
+	seen := map[string]bool{}
+	for i, s := range specs {
+		if err := validateSpec(s, seen); err != nil {
+			return fmt.Errorf("plugins[%d] (%s): %w", i, s.Name, err)
+		}
+		for _, r := range s.Rules {
+			if err := validateRule(r, taken); err != nil {
+				return fmt.Errorf("plugins[%d] (%s): rule %q: %w", i, s.Name, r.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateSpec(s Spec, seen map[string]bool) error {
+	switch {
+	case !namePattern.MatchString(s.Name):
+		return fmt.Errorf("name %q must be lower-case kebab-case", s.Name)
+	case seen[s.Name]:
+		return fmt.Errorf("name %q is already in use", s.Name)
+	case len(s.Command) == 0 && s.Wasm == "":
+		return fmt.Errorf("command or wasm is required")
+	case len(s.Command) > 0 && s.Wasm != "":
+		return fmt.Errorf("command and wasm are mutually exclusive")
+	case len(s.Command) > 0 && s.Command[0] == "":
+		return fmt.Errorf("command must name an executable")
+	case s.Memory != "" && s.Wasm == "":
+		return fmt.Errorf("memory applies only to wasm plugins")
+	case len(s.Rules) == 0:
+		return fmt.Errorf("rules must list at least one rule")
+	}
+	seen[s.Name] = true
+	_, err := s.timeout()
+	return err
+}
+
+func validateRule(r RuleDef, taken map[string]bool) error {
+	id := strings.ToUpper(r.ID)
+	switch {
+	case r.ID == "":
+		return fmt.Errorf("id is required")
+	case strings.HasPrefix(id, "MDS"):
+		return fmt.Errorf("id %q: the MDS prefix is reserved for built-in rules", r.ID)
+	case !namePattern.MatchString(r.Name):
+		return fmt.Errorf("name must be lower-case kebab-case")
+	case taken[id] || rule.ByID(id) != nil:
+		return fmt.Errorf("id %q is already in use", r.ID)
+	case taken[r.Name] || rule.ByName(r.Name) != nil:
+		return fmt.Errorf("name is already in use")
+	}
+	taken[id], taken[r.Name] = true, true
+	return nil
+}
@@ -0,0 +1,149 @@
+// Command fakeplugin is a plugin used by the plugin and e2e tests.
+// It provides these rules:
+//
+//   - no-todo reports each "TODO" with an edit replacing it with the
+//     "replacement" setting (default "DONE").
+//   - outline reports each heading in the outline.
+//   - front-matter reports the front matter "title" field.
+//   - pid reports the process ID and how many checks it has served.
+//   - hang never answers.
+//   - fail answers with a JSON-RPC error.
+//
+// The -version flag overrides the protocol version it answers with.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+type request struct {
+	ID     *int64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type outlineNode struct {
+	Kind     string        `json:"kind"`
+	Line     int           `json:"line"`
+	Level    int           `json:"level"`
+	Text     string        `json:"text"`
+	Children []outlineNode `json:"children"`
+}
+
+type checkParams struct {
+	Rule        string         `json:"rule"`
+	Settings    map[string]any `json:"settings"`
+	Source      string         `json:"source"`
+	FrontMatter map[string]any `json:"frontMatter"`
+	Outline     []outlineNode  `json:"outline"`
+}
+
+type edit struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+type diagnostic struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+	Severity string `json:"severity,omitempty"`
+	Edits    []edit `json:"edits,omitempty"`
+}
+
+var checks int
+
+func main() {
+	version := flag.Int("version", 1, "protocol version to answer with")
+	flag.Parse()
+
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(nil, 64*1024*1024)
+	out := json.NewEncoder(os.Stdout)
+	// A notification first: the host must skip it.
+	_ = out.Encode(map[string]any{"jsonrpc": "2.0", "method": "log", "params": "ready"})
+	for in.Scan() {
+		var req request
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			os.Exit(1)
+		}
+		switch req.Method {
+		case "initialize":
+			reply(out, req.ID, map[string]any{
+				"protocolVersion": *version,
+				"rules":           []string{"no-todo", "outline", "front-matter", "pid", "hang", "fail"},
+				"capabilities":    map[string]bool{"source": true, "frontMatter": true, "outline": true, "edits": true},
+			})
+		case "check":
+			var p checkParams
+			_ = json.Unmarshal(req.Params, &p)
+			checks++
+			switch p.Rule {
+			case "hang":
+				time.Sleep(time.Hour)
+			case "fail":
+				_ = out.Encode(map[string]any{
+					"jsonrpc": "2.0", "id": req.ID,
+					"error": map[string]any{"code": -32000, "message": "rule failed"},
+				})
+				continue
+			}
+			reply(out, req.ID, map[string]any{"diagnostics": check(p)})
+		case "shutdown":
+			reply(out, req.ID, nil)
+			os.Exit(0)
+		}
+	}
+}
+
+func reply(out *json.Encoder, id *int64, result any) {
+	_ = out.Encode(map[string]any{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func check(p checkParams) []diagnostic {
+	diags := []diagnostic{}
+	switch p.Rule {
+	case "no-todo":
+		replacement := "DONE"
+		if s, ok := p.Settings["replacement"].(string); ok {
+			replacement = s
+		}
+		offset := 0
+		for i, line := range strings.SplitAfter(p.Source, "\n") {
+			if col := strings.Index(line, "TODO"); col >= 0 {
+				diags = append(diags, diagnostic{
+					Line: i + 1, Column: col + 1, Message: "TODO left in text",
+					Edits: []edit{{Start: offset + col, End: offset + col + 4, Text: replacement}},
+				})
+			}
+			offset += len(line)
+		}
+	case "outline":
+		var walk func([]outlineNode)
+		walk = func(nodes []outlineNode) {
+			for _, n := range nodes {
+				if n.Kind == "heading" {
+					diags = append(diags, diagnostic{
+						Line: n.Line, Column: 1, Severity: "warning",
+						Message: fmt.Sprintf("h%d %s", n.Level, n.Text),
+					})
+				}
+				walk(n.Children)
+			}
+		}
+		walk(p.Outline)
+	case "front-matter":
+		diags = append(diags, diagnostic{Line: 1, Column: 1, Message: fmt.Sprintf("title %v", p.FrontMatter["title"])})
+	case "pid":
+		msg := fmt.Sprintf("pid %d check %d", os.Getpid(), checks)
+		diags = append(diags, diagnostic{Line: 1, Column: 1, Message: msg})
+	}
+	return diags
+}
@@ -0,0 +1,106 @@
+//go:build wasip1
+
+// Command fakewasm is a WebAssembly plugin used by the plugin and e2e
+// tests. Build it with
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared
+//
+// It provides these rules:
+//
+//   - no-todo reports each "TODO" with an edit replacing it with "DONE".
+//   - read-file reports the error from reading the checked path.
+//   - spin never returns.
+//   - grow allocates until memory runs out.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+)
+
+type request struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type checkParams struct {
+	Rule   string `json:"rule"`
+	Path   string `json:"path"`
+	Source string `json:"source"`
+}
+
+var (
+	keepAlive = map[uintptr][]byte{}
+	out       []byte
+	sink      [][]byte
+)
+
+func main() {}
+
+//go:wasmexport alloc
+func alloc(size int32) int32 {
+	buf := make([]byte, size+1)
+	ptr := uintptr(unsafe.Pointer(&buf[0]))
+	keepAlive[ptr] = buf
+	return int32(ptr)
+}
+
+//go:wasmexport free
+func free(ptr int32) {
+	delete(keepAlive, uintptr(ptr))
+}
+
+//go:wasmexport handle
+func handle(ptr, length int32) int64 {
+	var req request
+	data := unsafe.Slice((*byte)(unsafe.Pointer(uintptr(ptr))), length)
+	_ = json.Unmarshal(data, &req)
+
+	var result any
+	switch req.Method {
+	case "initialize":
+		result = map[string]any{
+			"protocolVersion": 1,
+			"rules":           []string{"no-todo", "read-file", "spin", "grow"},
+			"capabilities":    map[string]bool{"source": true, "edits": true},
+		}
+	case "check":
+		var p checkParams
+		_ = json.Unmarshal(req.Params, &p)
+		result = map[string]any{"diagnostics": check(p)}
+	}
+	out, _ = json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	return int64(uintptr(unsafe.Pointer(&out[0])))<<32 | int64(len(out))
+}
+
+func check(p checkParams) []map[string]any {
+	diags := []map[string]any{}
+	switch p.Rule {
+	case "no-todo":
+		offset := 0
+		for i, line := range strings.SplitAfter(p.Source, "\n") {
+			if col := strings.Index(line, "TODO"); col >= 0 {
+				diags = append(diags, map[string]any{
+					"line": i + 1, "column": col + 1, "message": "TODO left in text",
+					"edits": []map[string]any{{"start": offset + col, "end": offset + col + 4, "text": "DONE"}},
+				})
+			}
+			offset += len(line)
+		}
+	case "read-file":
+		_, err := os.ReadFile(p.Path)
+		diags = append(diags, map[string]any{"line": 1, "column": 1, "message": fmt.Sprint(err)})
+	case "spin":
+		for {
+		}
+	case "grow":
+		for {
+			sink = append(sink, make([]byte, 1<<20))
+		}
+	}
+	return diags
+}
@@ -0,0 +1,169 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// DefaultMemory caps a WebAssembly plugin's linear memory when its
+// Spec sets no memory limit.
+const DefaultMemory = 64 * 1024 * 1024
+
+// wasmPageBytes is the size of one WebAssembly memory page.
+const wasmPageBytes = 64 * 1024
+
+// compilationCache shares compiled modules between runtimes, so a
+// pool restarted after a config reload does not compile again.
+var compilationCache = wazero.NewCompilationCache()
+
+// module is a WebAssembly plugin running in-process under wazero.
+// It exports its memory and
+//
+//	alloc(size i32) i32           reserve size bytes for the host
+//	handle(ptr i32, len i32) i64  answer the request at [ptr, ptr+len)
+//	free(ptr i32)                 optional; release an alloc
+//
+// handle returns (outPtr<<32)|outLen of the response message, which
+// must stay valid until the next call. The module gets WASI without
+// a filesystem, environment, or network; a linear memory capped at
+// the spec's limit; and the request timeout as its CPU budget.
+type module struct {
+	runtime wazero.Runtime
+	mod     api.Module
+	alloc   api.Function
+	handle  api.Function
+	free    api.Function
+}
+
+func startModule(spec Spec, timeout time.Duration) (*module, error) {
+	bin, err := os.ReadFile(spec.modulePath())
+	if err != nil {
+		return nil, fmt.Errorf("reading module: %w", err)
+	}
+	limit := spec.MemoryLimit
+	if limit <= 0 {
+		limit = DefaultMemory
+	}
+	r := wazero.NewRuntimeWithConfig(context.Background(), wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(uint32(max(limit/wasmPageBytes, 1))).
+		WithCompilationCache(compilationCache))
+	m := &module{runtime: r}
+	if err := m.instantiate(bin, spec.Name, timeout); err != nil {
+		m.kill()
+		return nil, err
+	}
+	return m, nil
+}
+
+// instantiate compiles bin and runs its initialisation. Compiling is
+// not bounded by the timeout; running guest code is.
+func (m *module) instantiate(bin []byte, name string, timeout time.Duration) error {
+	compiled, err := m.runtime.CompileModule(context.Background(), bin)
+	if err != nil {
+		return fmt.Errorf("compiling module: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, m.runtime); err != nil {
+		return fmt.Errorf("instantiating WASI: %w", err)
+	}
+	// No WithFS, WithEnv, or WithSysWalltime: the guest sees an empty
+	// filesystem, no environment, and a fake clock.
+	cfg := wazero.NewModuleConfig().
+		WithName(name).
+		WithStdout(os.Stderr).
+		WithStderr(os.Stderr).
+		WithStartFunctions() // a reactor: _initialize runs below
+	mod, err := m.runtime.InstantiateModule(ctx, compiled, cfg)
+	if err != nil {
+		return fmt.Errorf("instantiating module: %w", err)
+	}
+	if init := mod.ExportedFunction("_initialize"); init != nil {
+		if _, err := init.Call(ctx); err != nil {
+			return fmt.Errorf("_initialize: %w", err)
+		}
+	}
+	m.mod = mod
+	m.alloc = mod.ExportedFunction("alloc")
+	m.handle = mod.ExportedFunction("handle")
+	m.free = mod.ExportedFunction("free")
+	switch {
+	case mod.ExportedMemory("memory") == nil:
+		return errors.New("module exports no memory")
+	case m.alloc == nil || m.handle == nil:
+		return errors.New("module must export alloc and handle")
+	}
+	return nil
+}
+
+// modulePath resolves a relative module path against the config
+// directory.
+func (s Spec) modulePath() string {
+	if s.Dir != "" && !filepath.IsAbs(s.Wasm) {
+		return filepath.Join(s.Dir, s.Wasm)
+	}
+	return s.Wasm
+}
+
+func (m *module) roundTrip(req []byte, id int64, timeout time.Duration) reply {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	line, err := m.invoke(ctx, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("no response within %s", timeout)
+		}
+		return reply{err: err}
+	}
+	rep, ok := decodeReply(line, id)
+	if !ok {
+		return reply{err: fmt.Errorf("response id does not match request %d", id)}
+	}
+	return rep
+}
+
+// invoke copies req into guest memory, calls handle, and copies the
+// response out.
+func (m *module) invoke(ctx context.Context, req []byte) ([]byte, error) {
+	res, err := m.alloc.Call(ctx, uint64(len(req)))
+	if err != nil {
+		return nil, fmt.Errorf("alloc: %w", err)
+	}
+	ptr := uint32(res[0])
+	mem := m.mod.Memory()
+	if !mem.Write(ptr, req) {
+		return nil, errors.New("alloc returned memory out of range")
+	}
+	if m.free != nil {
+		defer func() { _, _ = m.free.Call(ctx, uint64(ptr)) }()
+	}
+	res, err = m.handle.Call(ctx, uint64(ptr), uint64(len(req)))
+	if err != nil {
+		return nil, fmt.Errorf("handle: %w", err)
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+	if outLen > maxMessageBytes {
+		return nil, fmt.Errorf("message exceeds %d bytes", maxMessageBytes)
+	}
+	out, ok := mem.Read(outPtr, outLen)
+	if !ok {
+		return nil, errors.New("handle returned memory out of range")
+	}
+	return append([]byte(nil), out...), nil
+}
+
+// close releases the runtime; a module has nothing to wait for.
+func (m *module) close() { m.kill() }
+
+func (m *module) kill() {
+	_ = m.runtime.Close(context.Background())
+}
@@ -93,11 +93,23 @@ func (e HeadingCollisionError) Error() string {
 func Heading(
 	ws Workspace, fileKey, file string, source []byte,
 	line int, oldName, newName string,
+) (map[string][]Edit, error) {
+	return HeadingSource(ws, fileKey, file, source, line, oldName, newName, newName)
+}
+
+// HeadingSource is Heading for callers that rewrite the heading's
+// Markdown rather than its visible text, such as a case fix that must
+// keep code spans and emphasis intact. newName is the new visible
+// text, which drives the slug remap; newSource replaces the heading
+// text on the source line.
+func HeadingSource(
+	ws Workspace, fileKey, file string, source []byte,
+	line int, oldName, newName, newSource string,
 ) (map[string][]Edit, error) {
 	if strings.TrimSpace(newName) == strings.TrimSpace(oldName) {
 		return map[string][]Edit{}, nil
 	}
-	if r := firstControlRune(newName); r != 0 {
+	if r := firstControlRune(newSource); r != 0 {
 		return nil, InvalidHeadingRuneError{Rune: r}
 	}
 	if mdtext.Slugify(newName) == "" {
@@ -109,7 +121,7 @@ func Heading(
 	}
 	// headingTextEdit's false branch is unreachable here: the caller
 	// resolved `line` to a heading line, so the row is a heading.
-	headingEdit, _ := headingTextEdit(source, line, newName)
+	headingEdit, _ := headingTextEdit(source, line, newSource)
 	changes := map[string][]Edit{fileKey: {headingEdit}}
 	for old, neu := range slugRemapPairs(oldSlugs, newSlugs) {
 		appendAnchorEditsForHeading(changes, ws, file, old, neu)
@@ -248,6 +260,15 @@ func slicesOfText(walks []headingWalk) []string {
 	return out
 }
 
+// AssignSlugs returns the anchor each heading text receives when the
+// texts appear in this order in one file: the bare slug first, then
+// `-1`, `-2`, … for later duplicates, "" for texts with no slug.
+// Refactorings that regroup headings across files (split, extract)
+// use it to predict the anchors of the files they write.
+func AssignSlugs(texts []string) []string {
+	return assignSlugs(texts)
+}
+
 // assignSlugs runs the same disambiguator pass
 // mdtext.CollectTOCItems uses, but over a parallel slice of texts so
 // callers can substitute a renamed heading's text in place without
@@ -487,25 +508,43 @@ func anchorEditForEdge(ws Workspace, e index.Edge, oldSlug, newSlug string) (str
 // on the same row. This handles image-in-link where destBounds would
 // otherwise stop at the inner ](img.png) and never reach ](url#slug).
 func anchorFragmentBytes(row []byte, textStart int, oldSlug string) (int, int, bool) {
+	_, hash, fragEnd, ok := anchorDestBytes(row, textStart, oldSlug)
+	if !ok {
+		return 0, 0, false
+	}
+	return hash + 1, fragEnd, true
+}
+
+// anchorDestBytes is anchorFragmentBytes widened to the whole
+// destination: it returns the offset where the destination's path
+// component starts (past any `<` and leading blanks), the offset of
+// the `#`, and the fragment end. A same-file `(#slug)` link has
+// pathStart == hash. Relocate uses the wider range to swap the file
+// a link points at, not just its slug.
+func anchorDestBytes(row []byte, textStart int, oldSlug string) (pathStart, hash, fragEnd int, ok bool) {
 	bracketStart := textStart
 	if bracketStart < 0 {
 		bracketStart = 0
 	}
 	if bracketStart >= len(row) {
-		return 0, 0, false
+		return 0, 0, 0, false
 	}
 	searchFrom := bracketStart
 	for {
 		open, closeIdx, ok := destBounds(row, searchFrom)
 		if !ok {
-			return 0, 0, false
+			return 0, 0, 0, false
 		}
 		hash := indexOfHash(row, open, closeIdx)
 		if hash >= 0 {
 			fragEnd := fragmentEnd(row, hash+1, closeIdx)
 			rawFrag := row[hash+1 : fragEnd]
 			if fragmentMatchesSlug(rawFrag, oldSlug) {
-				return hash + 1, fragEnd, true
+				pathStart := open
+				for pathStart < hash && (row[pathStart] == ' ' || row[pathStart] == '\t' || row[pathStart] == '<') {
+					pathStart++
+				}
+				return pathStart, hash, fragEnd, true
 			}
 		}
 		// This destination had no matching fragment; advance past it.
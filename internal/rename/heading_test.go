@@ -83,6 +83,20 @@ func TestHeading_SameFileAnchorSharesKey(t *testing.T) {
 	require.Len(t, changes["a.md"], 2)
 }
 
+func TestHeadingSource_KeepsMarkupAndSlugsFromVisibleText(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"a.md": "# The `foo` Flag\n",
+		"b.md": "See [flag](a.md#the-foo-flag).\n",
+	})
+	changes, err := HeadingSource(ws, "a.md", "a.md", ws.files["a.md"], 1,
+		"The foo Flag", "Using foo", "Using `foo`")
+	require.NoError(t, err)
+	require.Len(t, changes["a.md"], 1)
+	assert.Equal(t, "Using `foo`", changes["a.md"][0].NewText)
+	require.Len(t, changes["b.md"], 1)
+	assert.Equal(t, "using-foo", changes["b.md"][0].NewText)
+}
+
 func TestHeading_NoOpWhenUnchanged(t *testing.T) {
 	ws := newMemWorkspace(map[string]string{"a.md": "# Title\n"})
 	changes, err := Heading(ws, "a.md", "a.md", ws.files["a.md"], 1, "Title", "  Title  ")
@@ -0,0 +1,149 @@
+package rename
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/yuin/goldmark/ast"
+)
+
+// LevelRangeError reports a heading that a level shift would push
+// outside H1–H6. Line is the 1-based source line of the heading.
+type LevelRangeError struct {
+	Line  int
+	Text  string
+	Level int
+}
+
+func (e LevelRangeError) Error() string {
+	return fmt.Sprintf("line %d: heading %q would move to level %d (want 1-6)", e.Line, e.Text, e.Level)
+}
+
+// ShiftLevels returns source with every heading that starts on a
+// 1-based source line in [from, to] moved delta levels (negative
+// promotes, positive demotes). Setext headings are rewritten in ATX
+// form, since setext can only spell H1 and H2.
+//
+// Only document-level headings move: a heading nested in a list or
+// blockquote keeps its level, and headings inside `<?include?>` /
+// `<?catalog?>` bodies are left for the directive to regenerate.
+// A shift that would leave H1–H6 returns a LevelRangeError naming the
+// first offending heading and changes nothing. Heading text, and so
+// every slug, is unchanged.
+func ShiftLevels(source []byte, from, to, delta int) ([]byte, error) {
+	if delta == 0 {
+		return source, nil
+	}
+	return relevel(source, from, to, delta)
+}
+
+// relevel is ShiftLevels without the shortcut for a zero delta, which
+// still rewrites the setext headings in range as ATX.
+func relevel(source []byte, from, to, delta int) ([]byte, error) {
+	body, fmOffset := bodyAndFMOffset(source)
+	f, _ := lint.NewFile("", body) // NewFile never errors with current implementation
+	generated := gensection.FindAllGeneratedRanges(f)
+	segs := splitKeepCR(source)
+	drop := map[int]bool{}
+	for n := f.AST.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		bodyLine := f.LineOfOffset(h.Lines().At(0).Start)
+		line := bodyLine + fmOffset
+		if line < from || line > to || inLineRanges(bodyLine, generated) {
+			continue
+		}
+		level := h.Level + delta
+		if level < 1 || level > 6 {
+			return nil, LevelRangeError{Line: line, Text: headingText(h, body), Level: level}
+		}
+		shiftHeading(segs, drop, h, line, level)
+	}
+	var out [][]byte
+	for i, s := range segs {
+		if !drop[i] {
+			out = append(out, s)
+		}
+	}
+	return joinLF(out), nil
+}
+
+// shiftHeading rewrites the heading h starting on the 1-based line in
+// segs to level. ATX headings swap their `#` run; setext headings
+// collapse their text lines onto the first one and mark the rest,
+// plus the underline, in drop.
+func shiftHeading(segs [][]byte, drop map[int]bool, h *ast.Heading, line, level int) {
+	idx := line - 1
+	row, cr := trimCR(segs[idx])
+	if _, ok := atxHeadingTextStart(row); ok {
+		lead := skipLeadingSpaces(row, 3)
+		hashes := lead
+		for hashes < len(row) && row[hashes] == '#' {
+			hashes++
+		}
+		next := append([]byte(nil), row[:lead]...)
+		next = append(next, bytes.Repeat([]byte{'#'}, level)...)
+		next = append(next, row[hashes:]...)
+		segs[idx] = withCR(next, cr)
+		return
+	}
+	n := h.Lines().Len()
+	parts := make([]string, 0, n)
+	for i := 0; i < n && idx+i < len(segs); i++ {
+		r, _ := trimCR(segs[idx+i])
+		parts = append(parts, strings.TrimSpace(string(r)))
+		if i > 0 {
+			drop[idx+i] = true
+		}
+	}
+	if u := idx + n; u < len(segs) {
+		drop[u] = true
+	}
+	atx := strings.Repeat("#", level) + " " + strings.Join(parts, " ")
+	segs[idx] = withCR([]byte(atx), cr)
+}
+
+// headingText returns the visible text of a heading for diagnostics.
+func headingText(h *ast.Heading, body []byte) string {
+	var b strings.Builder
+	for i := 0; i < h.Lines().Len(); i++ {
+		seg := h.Lines().At(i)
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.Write(bytes.TrimSpace(seg.Value(body)))
+	}
+	return b.String()
+}
+
+// inLineRanges reports whether the 1-based line falls inside any of
+// ranges.
+func inLineRanges(line int, ranges []lint.LineRange) bool {
+	for _, r := range ranges {
+		if r.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimCR splits a trailing `\r` off a splitKeepCR segment.
+func trimCR(seg []byte) ([]byte, bool) {
+	if n := len(seg); n > 0 && seg[n-1] == '\r' {
+		return seg[:n-1], true
+	}
+	return seg, false
+}
+
+// withCR restores the `\r` trimCR removed.
+func withCR(row []byte, cr bool) []byte {
+	if cr {
+		return append(row, '\r')
+	}
+	return row
+}
@@ -0,0 +1,41 @@
+package rename
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftLevels_ATXAndSetext(t *testing.T) {
+	src := "Title\n=====\n\n## Sub ##\n\n  ### Deep\n"
+	got, err := ShiftLevels([]byte(src), 1, 6, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "## Title\n\n### Sub ##\n\n  #### Deep\n", string(got))
+}
+
+func TestShiftLevels_RangeFrontMatterAndCRLF(t *testing.T) {
+	src := "---\nx: 1\n---\n## A\r\n\r\n## B\r\n"
+	got, err := ShiftLevels([]byte(src), 4, 4, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nx: 1\n---\n# A\r\n\r\n## B\r\n", string(got))
+}
+
+func TestShiftLevels_SkipsNestedAndGenerated(t *testing.T) {
+	src := "# T\n\n> ## Quoted\n\n<?include\nfile: x.md\n?>\n## Inc\n<?/include?>\n"
+	got, err := ShiftLevels([]byte(src), 1, 20, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "## T\n\n> ## Quoted\n\n<?include\nfile: x.md\n?>\n## Inc\n<?/include?>\n", string(got))
+}
+
+func TestShiftLevels_OutOfRange(t *testing.T) {
+	src := "# A\n\n###### Six\n"
+	_, err := ShiftLevels([]byte(src), 1, 3, 1)
+	var lre LevelRangeError
+	require.ErrorAs(t, err, &lre)
+	assert.Equal(t, 3, lre.Line)
+	assert.Equal(t, 7, lre.Level)
+
+	_, err = ShiftLevels([]byte(src), 1, 3, -1)
+	assert.ErrorAs(t, err, &lre)
+}
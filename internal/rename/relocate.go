@@ -0,0 +1,227 @@
+package rename
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+)
+
+// Move records that the heading anchored at OldSlug in a relocated
+// file is reachable at (NewFile, NewSlug) once the refactoring lands.
+// NewFile is workspace-relative.
+type Move struct {
+	OldSlug string
+	NewFile string
+	NewSlug string
+}
+
+// Relocation describes headings leaving File — a `split` carving a
+// document into per-section files, or an extract moving one section
+// out. Moves lists every heading of File whose inbound links may
+// change, including headings that stay put (NewFile == File) when
+// the links pointing at them move away.
+//
+// Home reports the workspace file a 1-based source line of File ends
+// up in after the refactoring, so a link that travels with its
+// section is rewritten relative to its new home. nil means every
+// line stays in File. Lines of other files never move.
+type Relocation struct {
+	File  string
+	Moves []Move
+	Home  func(line int) string
+}
+
+// Relocate returns the edits that retarget every workspace anchor
+// link and `[label]: url` ref-def destination pointing at a moved
+// heading. Edits are keyed by ws.Resolve and positioned against the
+// current bytes, so callers apply them before cutting sections out
+// of File.
+//
+// A link keeps the author's spelling of its directory prefix when
+// only the file name changes; otherwise the path is recomputed
+// relative to the link's home. A link whose home is the target file
+// collapses to a bare `#slug`. Links whose destination would not
+// change produce no edit.
+func Relocate(ws Workspace, r Relocation) map[string][]Edit {
+	file := index.NormalizePath(r.File)
+	bySlug := make(map[string]Move, len(r.Moves))
+	for _, m := range r.Moves {
+		if m.OldSlug == "" {
+			continue
+		}
+		m.NewFile = index.NormalizePath(m.NewFile)
+		bySlug[m.OldSlug] = m
+	}
+	home := func(src string, line int) string {
+		if src == file && r.Home != nil {
+			if h := r.Home(line); h != "" {
+				return index.NormalizePath(h)
+			}
+		}
+		return src
+	}
+	changes := map[string][]Edit{}
+	for _, m := range r.Moves {
+		if m.OldSlug == "" {
+			continue
+		}
+		for _, e := range ws.IncomingAnchorEdges(file, m.OldSlug) {
+			src := index.NormalizePath(e.SourceFile)
+			key, edit, ok := relocateAnchorEdge(ws, e, file, bySlug[m.OldSlug], home(src, e.SourceLine))
+			if ok {
+				changes[key] = append(changes[key], edit)
+			}
+		}
+	}
+	appendRelocatedRefDefs(changes, ws, file, bySlug, home)
+	stableSortEdits(changes)
+	return changes
+}
+
+// relocateAnchorEdge converts one incoming edge into the edit that
+// points it at m, or ok=false when the source is unreadable, the link
+// can't be located, or the destination is already correct.
+func relocateAnchorEdge(ws Workspace, e index.Edge, file string, m Move, home string) (string, Edit, bool) {
+	key, source, ok := ws.Resolve(e.SourceFile)
+	if !ok {
+		return "", Edit{}, false
+	}
+	lines := splitLines(source)
+	if e.SourceLine < 1 || e.SourceLine > len(lines) {
+		return "", Edit{}, false
+	}
+	row := lines[e.SourceLine-1]
+	pathStart, hash, fragEnd, ok := anchorDestBytes(row, e.SourceCol-1, m.OldSlug)
+	if !ok {
+		return "", Edit{}, false
+	}
+	src := index.NormalizePath(e.SourceFile)
+	edit, ok := relocatedDestEdit(row, e.SourceLine, pathStart, hash, fragEnd, src, home, file, m)
+	return key, edit, ok
+}
+
+// relocatedDestEdit builds the edit that rewrites the destination
+// bytes row[pathStart:fragEnd] (path, `#`, fragment) to reach m from
+// home. Only the fragment is touched when the path survives as-is.
+func relocatedDestEdit(
+	row []byte, line, pathStart, hash, fragEnd int,
+	src, home, file string, m Move,
+) (Edit, bool) {
+	rawPath := string(row[pathStart:hash])
+	newPath := relocatedPath(rawPath, src, home, file, m.NewFile)
+	start, newText := pathStart, newPath+"#"+m.NewSlug
+	if newPath == rawPath {
+		if linkgraph.NormalizeAnchor(string(row[hash+1:fragEnd])) == m.NewSlug {
+			return Edit{}, false
+		}
+		start, newText = hash+1, m.NewSlug
+	}
+	return Edit{
+		Range: Range{
+			Start: Position{Line: line - 1, Character: mdtext.UTF16FromByteOffset(row, start)},
+			End:   Position{Line: line - 1, Character: mdtext.UTF16FromByteOffset(row, fragEnd)},
+		},
+		NewText: newText,
+	}, true
+}
+
+// relocatedPath returns the path component a link written in src,
+// living in home after the refactoring, uses to reach target. rawPath
+// is the link's current path ("" for a same-file `#slug` link) and
+// file the heading's original file.
+func relocatedPath(rawPath, src, home, file, target string) string {
+	if home == target {
+		return ""
+	}
+	if rawPath != "" && path.Dir(home) == path.Dir(src) && path.Dir(target) == path.Dir(file) &&
+		linkgraph.ResolveRelTarget(src, rawPath) == file {
+		// Same directory as the original: swap only the file name so
+		// `./guide.md` / `../docs/guide.md` keep their prefix.
+		if i := strings.LastIndexByte(rawPath, '/'); i >= 0 {
+			return rawPath[:i+1] + path.Base(target)
+		}
+		return path.Base(target)
+	}
+	return relativePath(path.Dir(home), target)
+}
+
+// relativePath returns target relative to the workspace directory
+// dir, in slash form. Both arguments are workspace-relative, so Rel
+// cannot fail; the fallback keeps the target unchanged regardless.
+func relativePath(dir, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// appendRelocatedRefDefs is the ref-def companion of the anchor-edge
+// pass (see appendRefDefDestEditsForHeading for why defs need one):
+// every `[label]: url#slug` whose destination resolves to a moved
+// heading of file is retargeted relative to the def line's home.
+func appendRelocatedRefDefs(
+	changes map[string][]Edit, ws Workspace, file string,
+	bySlug map[string]Move, home func(src string, line int) string,
+) {
+	for _, rel := range ws.Files() {
+		key, source, ok := ws.Resolve(rel)
+		if !ok {
+			continue
+		}
+		defFile := index.NormalizePath(rel)
+		body, fmOffset := bodyAndFMOffset(source)
+		fileLines := splitLines(source)
+		for _, m := range validRefDefMatches(body) {
+			fileLine := lineOfBodyOffset(body, m.matchIdx[2]) + fmOffset
+			if fileLine-1 >= len(fileLines) {
+				continue
+			}
+			edit, ok := relocatedRefDefEdit(fileLines[fileLine-1], fileLine, defFile, file, bySlug, home)
+			if ok {
+				changes[key] = append(changes[key], edit)
+			}
+		}
+	}
+}
+
+// relocatedRefDefEdit returns the edit for one ref-def line, or
+// ok=false when its destination does not reach a moved heading.
+func relocatedRefDefEdit(
+	row []byte, line int, defFile, file string,
+	bySlug map[string]Move, home func(src string, line int) string,
+) (Edit, bool) {
+	colonOff := refDefColonOffset(row)
+	if colonOff < 0 {
+		return Edit{}, false
+	}
+	destStart, destEnd := refDefDestRange(row, colonOff+1)
+	if destStart >= destEnd {
+		return Edit{}, false
+	}
+	t, ok := refDefParseTarget(string(row[destStart:destEnd]))
+	if !ok || t.fragment == "" {
+		return Edit{}, false
+	}
+	target := defFile
+	if !t.localAnchor {
+		target = linkgraph.ResolveRelTarget(defFile, t.path)
+	}
+	if target != file {
+		return Edit{}, false
+	}
+	m, ok := bySlug[linkgraph.NormalizeAnchor(t.fragment)]
+	if !ok {
+		return Edit{}, false
+	}
+	hash := destStart
+	for hash < destEnd && row[hash] != '#' {
+		hash++
+	}
+	fragEnd := fragmentEnd(row, hash+1, destEnd)
+	return relocatedDestEdit(row, line, destStart, hash, fragEnd, defFile, home(defFile, line), file, m)
+}
@@ -0,0 +1,69 @@
+package rename
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// applyAll applies every change set against the workspace bytes.
+func applyAll(t *testing.T, ws *memWorkspace, changes map[string][]Edit) map[string]string {
+	t.Helper()
+	out := make(map[string]string, len(changes))
+	for key, edits := range changes {
+		got, err := ApplyEdits(ws.files[key], edits)
+		require.NoError(t, err)
+		out[key] = string(got)
+	}
+	return out
+}
+
+func TestRelocate_RewritesCrossDirectoryLinks(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"docs/guide.md": "# Guide\n\n## Usage\n\nRun it.\n",
+		"README.md":     "Read [u](docs/guide.md#usage) and [v](./docs/guide.md#usage).\n",
+		"other/x.md":    "See [u](../docs/guide.md#usage).\n",
+	})
+	changes := Relocate(ws, Relocation{
+		File:  "docs/guide.md",
+		Moves: []Move{{OldSlug: "usage", NewFile: "docs/usage.md", NewSlug: "usage"}},
+	})
+	got := applyAll(t, ws, changes)
+	assert.Equal(t, "Read [u](docs/usage.md#usage) and [v](./docs/usage.md#usage).\n", got["README.md"])
+	assert.Equal(t, "See [u](../docs/usage.md#usage).\n", got["other/x.md"])
+}
+
+func TestRelocate_LinksTravelWithTheirSection(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"g.md": "# G\n\n[a](#a)\n\n## A\n\n[a](#a) [b](#b)\n\n## B\n\n[r]\n\n[r]: g.md#a\n",
+	})
+	home := func(line int) string {
+		switch {
+		case line >= 9:
+			return "b.md"
+		case line >= 5:
+			return "a.md"
+		}
+		return ""
+	}
+	changes := Relocate(ws, Relocation{
+		File: "g.md",
+		Moves: []Move{
+			{OldSlug: "a", NewFile: "a.md", NewSlug: "a"},
+			{OldSlug: "b", NewFile: "b.md", NewSlug: "b"},
+		},
+		Home: home,
+	})
+	got := applyAll(t, ws, changes)
+	assert.Equal(t, "# G\n\n[a](a.md#a)\n\n## A\n\n[a](#a) [b](b.md#b)\n\n## B\n\n[r]\n\n[r]: a.md#a\n", got["g.md"])
+}
+
+func TestRelocate_NoEditWhenDestinationUnchanged(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"g.md": "# G\n\n## A\n\nx\n",
+		"o.md": "[a](g.md#a)\n",
+	})
+	changes := Relocate(ws, Relocation{File: "g.md", Moves: []Move{{OldSlug: "a", NewFile: "g.md", NewSlug: "a"}}})
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,205 @@
+package rename
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// ErrNotSectionHeading is returned by ShiftSection when the requested
+// line does not start a document-level heading it can re-level.
+var ErrNotSectionHeading = errors.New("not a document-level heading")
+
+// SlugChange records one heading whose anchor a refactoring changed.
+type SlugChange struct {
+	Old string
+	New string
+}
+
+// Shifted is the result of ShiftSection.
+type Shifted struct {
+	// Key is the workspace key the shifted file resolved to.
+	Key string
+	// Source is the shifted file's new content.
+	Source []byte
+	// Baseline is the original content with the shifted headings
+	// spelled as ATX at their old levels. It has the same lines as
+	// Source, so linting both tells what the level change introduced.
+	Baseline []byte
+	// Headings is how many headings changed level.
+	Headings int
+	// Slugs lists the anchors of Key that changed, in document order.
+	Slugs []SlugChange
+	// Changes holds the anchor edits for every other workspace file
+	// that linked to a changed slug.
+	Changes map[string][]Edit
+}
+
+// ShiftSection moves the heading on the 1-based line of file, and
+// every heading below it up to the next heading at its level or
+// above, delta levels (negative promotes, positive demotes).
+//
+// The shift goes through ShiftLevels, so it refuses with a
+// LevelRangeError when any heading in the subtree would leave H1–H6.
+// Slugs normally survive a level change; when one does not — a
+// setext heading collapsing to ATX can respell its text — the old
+// and new anchors are reported and every workspace link to the old
+// one is retargeted.
+func ShiftSection(ws Workspace, file string, line, delta int) (Shifted, error) {
+	key, source, ok := ws.Resolve(file)
+	if !ok {
+		return Shifted{}, fmt.Errorf("cannot read %q", file)
+	}
+	start, end, err := subtreeLines(source, line)
+	if err != nil {
+		return Shifted{}, err
+	}
+	if delta == 0 {
+		return Shifted{Key: key, Source: source, Baseline: source}, nil
+	}
+	before := headingSlugs(source)
+	shifted, err := ShiftLevels(source, start, end, delta)
+	if err != nil {
+		return Shifted{}, err
+	}
+	baseline, err := relevel(source, start, end, 0)
+	if err != nil {
+		return Shifted{}, err
+	}
+	res := Shifted{Key: key, Source: shifted, Baseline: baseline, Headings: countShifted(source, start, end)}
+	after := headingSlugs(shifted)
+	var moves []Move
+	for i := range before {
+		if i >= len(after) || before[i] == after[i] || before[i] == "" {
+			continue
+		}
+		res.Slugs = append(res.Slugs, SlugChange{Old: before[i], New: after[i]})
+		moves = append(moves, Move{OldSlug: before[i], NewFile: file, NewSlug: after[i]})
+	}
+	if len(moves) == 0 {
+		return res, nil
+	}
+	changes := Relocate(ws, Relocation{File: file, Moves: moves})
+	if own := changes[key]; len(own) > 0 {
+		// Same-file fixes only touch link fragments, never a
+		// heading's line count, so they apply to the original bytes
+		// and the shift is replayed over the result.
+		edited, err := ApplyEdits(source, own)
+		if err != nil {
+			return Shifted{}, err
+		}
+		if res.Source, err = ShiftLevels(edited, start, end, delta); err != nil {
+			return Shifted{}, err
+		}
+		delete(changes, key)
+	}
+	res.Changes = changes
+	return res, nil
+}
+
+// subtreeLines returns the 1-based line range [start, end] of the
+// subtree headed by the document-level heading on line: up to the
+// line before the next document-level heading at its level or above,
+// or the end of the file.
+func subtreeLines(source []byte, line int) (int, int, error) {
+	level := 0
+	for _, h := range sectionHeadings(source) {
+		switch {
+		case level == 0 && h.line == line:
+			level = h.level
+		case level > 0 && h.level <= level:
+			return line, h.line - 1, nil
+		}
+	}
+	if level == 0 {
+		return 0, 0, fmt.Errorf("line %d: %w", line, ErrNotSectionHeading)
+	}
+	return line, len(splitLines(source)), nil
+}
+
+// countShifted returns how many headings ShiftLevels moves in
+// [from, to].
+func countShifted(source []byte, from, to int) int {
+	n := 0
+	for _, h := range sectionHeadings(source) {
+		if h.line >= from && h.line <= to {
+			n++
+		}
+	}
+	return n
+}
+
+// sectionHeading is a document-level heading: its 1-based source line
+// and level.
+type sectionHeading struct {
+	line  int
+	level int
+}
+
+// sectionHeadings returns the headings ShiftLevels can move: direct
+// document children outside generated directive bodies.
+func sectionHeadings(source []byte) []sectionHeading {
+	body, fmOffset := bodyAndFMOffset(source)
+	f, _ := lint.NewFile("", body) // NewFile never errors with current implementation
+	generated := gensection.FindAllGeneratedRanges(f)
+	var out []sectionHeading
+	for n := f.AST.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		bodyLine := f.LineOfOffset(h.Lines().At(0).Start)
+		if !inLineRanges(bodyLine, generated) {
+			out = append(out, sectionHeading{line: bodyLine + fmOffset, level: h.Level})
+		}
+	}
+	return out
+}
+
+// headingSlugs returns the anchor of every heading in source, in
+// document order.
+func headingSlugs(source []byte) []string {
+	body, _ := bodyAndFMOffset(source)
+	root := lint.NewParser().Parse(text.NewReader(body), parser.WithContext(parser.NewContext()))
+	return assignSlugs(slicesOfText(walkAllHeadings(root, body)))
+}
+
+// ShiftCheckRules names the rules whose findings a level shift must
+// not introduce: the heading-outline rules plus the kind schema.
+// Callers lint the file before and after with these rules under
+// ShiftCheckConfig and refuse the shift on any new diagnostic.
+var ShiftCheckRules = []string{
+	"heading-increment",
+	"first-line-heading",
+	"single-h1",
+	"no-duplicate-headings",
+	"required-structure",
+}
+
+// ShiftCheckConfig returns a copy of cfg with every ShiftCheckRules
+// rule enabled, keeping its settings, so a shift cannot break the
+// outline where the config leaves a rule off. single-h1, for one, is
+// off by default. Kinds and overrides still apply per file.
+func ShiftCheckConfig(cfg *config.Config) *config.Config {
+	out := *cfg
+	out.Rules = maps.Clone(cfg.Rules)
+	if out.Rules == nil {
+		out.Rules = map[string]config.RuleCfg{}
+	}
+	out.ExplicitRules = maps.Clone(cfg.ExplicitRules)
+	if out.ExplicitRules == nil {
+		out.ExplicitRules = map[string]bool{}
+	}
+	for _, name := range ShiftCheckRules {
+		out.Rules[name] = config.RuleCfg{Enabled: true, Settings: cfg.Rules[name].Settings}
+		out.ExplicitRules[name] = true
+	}
+	return &out
+}
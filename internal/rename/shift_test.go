@@ -0,0 +1,100 @@
+package rename
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftSection_DemotesWholeSubtree(t *testing.T) {
+	src := "# T\n\n## A\n\n### A1\n\ntext\n\n## B\n\n### B1\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src})
+	res, err := ShiftSection(ws, "g.md", 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "g.md", res.Key)
+	assert.Equal(t, "# T\n\n### A\n\n#### A1\n\ntext\n\n## B\n\n### B1\n", string(res.Source))
+	assert.Equal(t, 2, res.Headings)
+	assert.Empty(t, res.Slugs)
+	assert.Empty(t, res.Changes)
+}
+
+func TestShiftSection_PromotesToEndOfFile(t *testing.T) {
+	src := "# T\n\n## A\n\n### B\n\n#### C\n\nSee [c](#c).\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src})
+	res, err := ShiftSection(ws, "g.md", 5, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "# T\n\n## A\n\n## B\n\n### C\n\nSee [c](#c).\n", string(res.Source))
+	assert.Equal(t, 2, res.Headings)
+}
+
+func TestShiftSection_SetextCollapsesToATX(t *testing.T) {
+	src := "Title\n=====\n\nPart\n----\n\nbody\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src})
+	res, err := ShiftSection(ws, "g.md", 4, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Title\n=====\n\n### Part\n\nbody\n", string(res.Source))
+}
+
+func TestShiftSection_BaselineMatchesLines(t *testing.T) {
+	src := "Title\n=====\n\nPart\n----\n\nbody\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src})
+	res, err := ShiftSection(ws, "g.md", 4, 1)
+	require.NoError(t, err)
+	// Only the shifted range is respelled, at its old level.
+	assert.Equal(t, "Title\n=====\n\n## Part\n\nbody\n", string(res.Baseline))
+}
+
+func TestShiftCheckConfig_EnablesRulesTheConfigLeavesOff(t *testing.T) {
+	cfg := &config.Config{Rules: map[string]config.RuleCfg{
+		"single-h1":         {Enabled: false, Settings: map[string]any{"level": 1}},
+		"heading-increment": {Enabled: false},
+		"line-length":       {Enabled: false},
+	}}
+	got := ShiftCheckConfig(cfg)
+	for _, name := range ShiftCheckRules {
+		assert.True(t, got.Rules[name].Enabled, name)
+		assert.True(t, got.ExplicitRules[name], name)
+	}
+	assert.Equal(t, map[string]any{"level": 1}, got.Rules["single-h1"].Settings)
+	assert.Contains(t, ShiftCheckRules, "no-duplicate-headings")
+	assert.False(t, got.Rules["line-length"].Enabled)
+	assert.False(t, cfg.Rules["single-h1"].Enabled, "input config must not change")
+}
+
+func TestShiftSection_OutOfRange(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{"g.md": "# T\n\n## A\n\n###### Deep\n"})
+	_, err := ShiftSection(ws, "g.md", 3, 1)
+	var lre LevelRangeError
+	require.ErrorAs(t, err, &lre)
+	assert.Equal(t, 5, lre.Line)
+	assert.Equal(t, 7, lre.Level)
+}
+
+func TestShiftSection_NotAHeading(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{"g.md": "# T\n\ntext\n\n> ## Quoted\n"})
+	for _, line := range []int{2, 3, 5} {
+		_, err := ShiftSection(ws, "g.md", line, 1)
+		assert.True(t, errors.Is(err, ErrNotSectionHeading), "line %d: %v", line, err)
+	}
+}
+
+func TestShiftSection_ZeroDeltaIsNoop(t *testing.T) {
+	src := "# T\n\n## A\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src})
+	res, err := ShiftSection(ws, "g.md", 3, 0)
+	require.NoError(t, err)
+	assert.Equal(t, src, string(res.Source))
+	assert.Zero(t, res.Headings)
+}
+
+func TestShiftSection_FrontMatterOffset(t *testing.T) {
+	src := "---\ntitle: x\n---\n# T\n\n## A\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src})
+	res, err := ShiftSection(ws, "g.md", 6, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: x\n---\n# T\n\n### A\n", string(res.Source))
+}
@@ -2,11 +2,24 @@ package rule
 
 import "reflect"
 
+// Cloner is implemented by rules whose state reflection cannot copy
+// faithfully — adapters that wrap another rule value behind an
+// interface field, where a zero value would lose the wrapped rule.
+// CloneRule calls Clone(true) (fresh instance, default settings) and
+// CloneInstance calls Clone(false) (same state, distinct instance).
+type Cloner interface {
+	Clone(reset bool) Rule
+}
+
 // CloneRule creates a deep copy of a rule. If the rule implements
 // Configurable, the clone is produced by creating a new zero-value
 // instance and applying the original's DefaultSettings. Otherwise
-// it falls back to a reflect-based shallow copy of the struct.
+// it falls back to a reflect-based shallow copy of the struct. A
+// Cloner decides for itself.
 func CloneRule(r Rule) Rule {
+	if c, ok := r.(Cloner); ok {
+		return c.Clone(true)
+	}
 	if c, ok := r.(Configurable); ok {
 		// Create a new zero-value instance of the same concrete type.
 		rv := reflect.ValueOf(r)
@@ -50,6 +63,9 @@ func CloneRule(r Rule) Rule {
 // per-file settings, and rules do not mutate their own config during
 // Check.
 func CloneInstance(r Rule) Rule {
+	if c, ok := r.(Cloner); ok {
+		return c.Clone(false)
+	}
 	rv := reflect.ValueOf(r)
 	if rv.Kind() != reflect.Ptr {
 		// Value-type rule: the interface already holds a copy.
@@ -138,3 +138,27 @@ func TestCloneRule_ApplySettingsOnClone(t *testing.T) {
 	assert.Equal(t, 120, cs.Max)
 	assert.Equal(t, 80, original.Max, "original Max should still be 80")
 }
+
+// clonerStub records which Clone mode the clone helpers requested.
+type clonerStub struct {
+	configurableStub
+	reset *bool
+}
+
+func (r *clonerStub) Clone(reset bool) Rule {
+	*r.reset = reset
+	return &clonerStub{configurableStub: r.configurableStub, reset: r.reset}
+}
+
+func TestCloneRule_Cloner_DelegatesWithReset(t *testing.T) {
+	var reset bool
+	original := &clonerStub{configurableStub: configurableStub{id: "MDS001", name: "test"}, reset: &reset}
+
+	clone := CloneRule(original)
+	assert.NotSame(t, original, clone)
+	assert.True(t, reset, "CloneRule must ask a Cloner for a reset clone")
+
+	clone = CloneInstance(original)
+	assert.NotSame(t, original, clone)
+	assert.False(t, reset, "CloneInstance must ask a Cloner to keep state")
+}
@@ -16,6 +16,27 @@ type FixableRule interface {
 	Fix(f *lint.File) []byte
 }
 
+// HeadingRenamer is implemented by fixable rules whose fix rewrites
+// heading text. HeadingRenames lists the edits Fix would make so a
+// fixer that knows the workspace can route them through the heading
+// rename flow and retarget anchors in other files. A fixer without a
+// workspace calls Fix as for any other rule.
+type HeadingRenamer interface {
+	FixableRule
+	HeadingRenames(f *lint.File) []HeadingRename
+}
+
+// HeadingRename is one heading edit. Line is the 1-based line in
+// f.Source; Old and New are the visible heading text before and after
+// the edit, and Source is the new Markdown text of the heading line
+// between its markers.
+type HeadingRename struct {
+	Line   int
+	Old    string
+	New    string
+	Source string
+}
+
 // Configurable is implemented by rules that have user-tunable settings.
 type Configurable interface {
 	ApplySettings(settings map[string]any) error
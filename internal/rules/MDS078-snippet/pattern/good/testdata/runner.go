@@ -0,0 +1,18 @@
+package run
+
+import "context"
+
+// Runner executes jobs in order.
+type Runner struct {
+	Jobs []func(context.Context) error
+}
+
+// Run executes every job and stops at the first error.
+func (r *Runner) Run(ctx context.Context) error {
+	for _, job := range r.Jobs {
+		if err := job(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
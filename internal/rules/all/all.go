@@ -27,21 +27,26 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/concisenessscoring"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/crossfilereferenceintegrity" // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/descriptivelinktext"         // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/directivegraph"              // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/directorystructure"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/duplicatedcontent"           // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/emphasisstyle"               // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/emptysectionbody"            // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/externallinks"               // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/fencedcodelanguage"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/fencedcodestyle"             // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/fencedcodesyntax"            // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/firstlineheading"            // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/forbiddenparagraphstarts"    // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/forbiddentext"               // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/githooksync"                 // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/headingcase"                 // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/headingincrement"            // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/headingstyle"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/horizontalrulestyle"         // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/include"                     // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/linelength"                  // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/linkpolicy"                  // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/linkvalidity"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/listindent"                  // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/listmarkerspace"             // registers rule
@@ -57,6 +62,7 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/noinlinehtml"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/nomultipleblanks"            // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/noreferencestyle"            // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/nosecrets"                   // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/nospaceincodespans"          // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/nospaceinlinktext"           // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/notrailingpunctuation"       // registers rule
@@ -64,8 +70,10 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/noundefinedreferencelabels"  // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/nounusedlinkdefinitions"     // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/orderedlistnumbering"        // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/orphanedfiles"               // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphreadability"        // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/paragraphstructure"          // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/paragraphwrap"               // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/propernames"                 // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/recipesafety"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredmentions"            // registers rule
@@ -73,10 +81,15 @@ import (
 	_ "github.com/jeduden/mdsmith/internal/rules/requiredtextpatterns"        // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/singleh1"                    // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/singletrailingnewline"       // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/snippet"                     // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/spelling"                    // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/table"                       // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tableformat"                 // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tablereadability"            // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/terminology"                 // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/toc"                         // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tocdirective"                // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/tokenbudget"                 // registers rule
+	_ "github.com/jeduden/mdsmith/internal/rules/typography"                  // registers rule
 	_ "github.com/jeduden/mdsmith/internal/rules/unclosedcodeblock"           // registers rule
 )
@@ -0,0 +1,302 @@
+// Package directivegraph implements MDS077, which walks the workspace
+// include and catalog graph for regeneration loops the include rule
+// cannot see: include chains nested deeper than a limit, and catalogs
+// whose glob matches their own host file or a file that includes it.
+// Include cycles are left to MDS021, which already reports them.
+package directivegraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+)
+
+// DefaultMaxDepth matches the nesting limit the include rule
+// enforces while it expands a chain.
+const DefaultMaxDepth = 10
+
+func init() {
+	rule.Register(&Rule{MaxDepth: DefaultMaxDepth})
+}
+
+// Rule reports include and catalog directives that take part in a
+// catalog regeneration loop or an over-deep include chain.
+type Rule struct {
+	// MaxDepth is the most include hops a chain may take from the
+	// checked file.
+	MaxDepth int
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS077" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "directive-graph" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f.FS == nil {
+		return nil
+	}
+	idx, rel, ok := index.ForFile(f)
+	if !ok {
+		return nil
+	}
+	g := newGraph(idx, rel, linkgraph.ExtractDirectives(f))
+	var diags []lint.Diagnostic
+	for _, d := range g.directives {
+		var msg string
+		switch d.Kind {
+		case linkgraph.DirectiveInclude:
+			msg = r.checkInclude(g, d)
+		case linkgraph.DirectiveCatalog:
+			msg = checkCatalog(g, d)
+		}
+		if msg != "" {
+			diags = append(diags, lint.Diagnostic{
+				File:     f.Path,
+				Line:     d.Line,
+				Column:   d.Col,
+				RuleID:   r.ID(),
+				RuleName: r.Name(),
+				Severity: lint.Error,
+				Message:  msg,
+			})
+		}
+	}
+	return diags
+}
+
+// checkInclude returns the problem with an include directive of the
+// host, or "" when it has none. An include on a cycle is skipped:
+// MDS021 reports the cycle, and the chain has no depth while every
+// file on it reaches every other.
+func (r *Rule) checkInclude(g *graph, d linkgraph.DirectiveEdge) string {
+	target := linkgraph.ResolveRelTarget(g.host, d.Path)
+	if target == "" {
+		return ""
+	}
+	if g.path(target, g.host) != nil {
+		return ""
+	}
+	if long := g.longest(target); len(long) > r.MaxDepth {
+		return fmt.Sprintf("include chain is %d levels deep, over the limit of %d: %s",
+			len(long), r.MaxDepth, chain(g.host, long))
+	}
+	for _, f := range g.reach(target) {
+		if g.catalogs(f) {
+			return fmt.Sprintf("included %q has a catalog whose glob matches this file: %s",
+				f, chain(g.host, g.path(target, f)))
+		}
+	}
+	return ""
+}
+
+// checkCatalog returns the problem with a catalog directive of the
+// host, or "" when its glob matches neither the host nor a file that
+// includes it.
+func checkCatalog(g *graph, d linkgraph.DirectiveEdge) string {
+	globs := index.CatalogGlobs(g.host, d)
+	if linkgraph.MatchCatalog(globs, g.host) {
+		return "catalog glob matches its host file"
+	}
+	for _, f := range g.includers() {
+		if linkgraph.MatchCatalog(globs, f) {
+			return fmt.Sprintf("catalog glob matches %q, which includes this file: %s",
+				f, chain(f, g.path(f, g.host)[1:]))
+		}
+	}
+	return ""
+}
+
+// chain renders a file path followed by the files it leads to.
+func chain(from string, rest []string) string {
+	return strings.Join(append([]string{from}, rest...), " -> ")
+}
+
+// graph is the include graph of one workspace as the checked host
+// file sees it: the host's own edges come from its current buffer, the
+// rest from the index.
+type graph struct {
+	idx        *index.Index
+	host       string
+	directives []linkgraph.DirectiveEdge
+	memo       map[string][]string
+	incoming   map[string][]string
+}
+
+func newGraph(idx *index.Index, host string, directives []linkgraph.DirectiveEdge) *graph {
+	return &graph{idx: idx, host: host, directives: directives, memo: map[string][]string{}}
+}
+
+// includes returns the files p includes, in document order.
+func (g *graph) includes(p string) []string {
+	var out []string
+	if p == g.host {
+		for _, d := range g.directives {
+			if d.Kind != linkgraph.DirectiveInclude {
+				continue
+			}
+			if t := linkgraph.ResolveRelTarget(g.host, d.Path); t != "" {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	for _, e := range g.idx.OutgoingEdges(p) {
+		if e.Kind == index.EdgeInclude && e.TargetFile != "" {
+			out = append(out, e.TargetFile)
+		}
+	}
+	return out
+}
+
+// path returns the shortest include chain from one file to another,
+// both ends included, or nil when from never includes to.
+func (g *graph) path(from, to string) []string {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == to {
+			var out []string
+			for p := to; p != ""; p = prev[p] {
+				out = append([]string{p}, out...)
+			}
+			return out
+		}
+		for _, t := range g.includes(cur) {
+			if _, seen := prev[t]; !seen {
+				prev[t] = cur
+				queue = append(queue, t)
+			}
+		}
+	}
+	return nil
+}
+
+// reach returns p and every file it includes, directly or not, in
+// breadth-first order.
+func (g *graph) reach(p string) []string {
+	seen := map[string]bool{p: true}
+	out := []string{p}
+	for i := 0; i < len(out); i++ {
+		for _, t := range g.includes(out[i]) {
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
+// longest returns the longest include chain starting at p, p
+// included. Edges that close a cycle are skipped; MDS021 reports
+// those.
+func (g *graph) longest(p string) []string {
+	return g.longestFrom(p, map[string]bool{})
+}
+
+func (g *graph) longestFrom(p string, onPath map[string]bool) []string {
+	if c, ok := g.memo[p]; ok {
+		return c
+	}
+	onPath[p] = true
+	var best []string
+	for _, t := range g.includes(p) {
+		if onPath[t] {
+			continue
+		}
+		if c := g.longestFrom(t, onPath); len(c) > len(best) {
+			best = c
+		}
+	}
+	delete(onPath, p)
+	out := append([]string{p}, best...)
+	g.memo[p] = out
+	return out
+}
+
+// catalogs reports whether one of p's catalogs globs the host.
+func (g *graph) catalogs(p string) bool {
+	for _, e := range g.idx.OutgoingEdges(p) {
+		if e.Kind == index.EdgeCatalog && linkgraph.MatchCatalog(e.TargetGlobs, g.host) {
+			return true
+		}
+	}
+	return false
+}
+
+// includers returns the files that include the host, directly or
+// not, sorted.
+func (g *graph) includers() []string {
+	if g.incoming == nil {
+		g.incoming = map[string][]string{}
+		files := g.idx.Files()
+		if _, ok := g.idx.File(g.host); !ok {
+			files = append(files, g.host)
+		}
+		for _, f := range files {
+			for _, t := range g.includes(f) {
+				g.incoming[t] = append(g.incoming[t], f)
+			}
+		}
+	}
+	seen := map[string]bool{g.host: true}
+	queue := []string{g.host}
+	var out []string
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, f := range g.incoming[cur] {
+			if !seen[f] {
+				seen[f] = true
+				out = append(out, f)
+				queue = append(queue, f)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "max-depth":
+			n, ok := settings.ToInt(v)
+			if !ok || n < 1 {
+				return fmt.Errorf("directive-graph: max-depth must be a positive integer, got %v", v)
+			}
+			r.MaxDepth = n
+		default:
+			return fmt.Errorf("directive-graph: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{"max-depth": DefaultMaxDepth}
+}
+
+var (
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+)
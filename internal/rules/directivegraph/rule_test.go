@@ -0,0 +1,167 @@
+package directivegraph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	return dir
+}
+
+// check runs r on name as the engine would, with src as the buffer
+// when set and the file on disk otherwise.
+func check(t *testing.T, r *Rule, dir, name string, src []byte, cache *lint.RunCache) []lint.Diagnostic {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if src == nil {
+		var err error
+		src, err = os.ReadFile(p)
+		require.NoError(t, err)
+	}
+	f, err := lint.NewFileFromSource(p, src, true)
+	require.NoError(t, err)
+	f.FS = os.DirFS(filepath.Dir(p))
+	f.RootFS = os.DirFS(dir)
+	f.RootDir = dir
+	f.RunCache = cache
+	diags := r.Check(f)
+	f.AdjustDiagnostics(diags)
+	return diags
+}
+
+func include(target string) string {
+	return "<?include\nfile: " + target + "\n?>\n<?/include?>\n"
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS077", r.ID())
+	assert.Equal(t, "directive-graph", r.Name())
+	assert.Equal(t, "directive", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestCheck_IncludeCycleLeftToInclude(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.md":       "# A\n\n" + include("parts/b.md"),
+		"parts/b.md": "---\ntitle: B\n---\n" + include("c.md"),
+		"parts/c.md": "Intro.\n\n" + include("../a.md"),
+		"self.md":    include("self.md"),
+		"outside.md": include("a.md"),
+	})
+	// MDS021 reports cycles; a cycle has no depth to measure either.
+	r := &Rule{MaxDepth: 1}
+	cache := lint.NewRunCache()
+	for _, name := range []string{"a.md", "parts/b.md", "parts/c.md", "self.md"} {
+		assert.Empty(t, check(t, r, dir, name, nil, cache), name)
+	}
+	// A chain into a cycle is still measured up to the loop.
+	diags := check(t, r, dir, "outside.md", nil, cache)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "include chain is 3 levels deep, over the limit of 1: "+
+		"outside.md -> a.md -> parts/b.md -> parts/c.md", diags[0].Message)
+}
+
+func TestCheck_BufferReplacesDiskEdges(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.md": include("b.md"),
+		"b.md": include("c.md"),
+		"c.md": "# C\n",
+	})
+	r := &Rule{MaxDepth: 1}
+	assert.Len(t, check(t, r, dir, "a.md", nil, nil), 1)
+	// Editing a.md to include c.md directly shortens the chain even
+	// though the file on disk still includes b.md.
+	assert.Empty(t, check(t, r, dir, "a.md", []byte(include("c.md")), nil))
+}
+
+func TestCheck_IncludeDepth(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.md": include("b.md"),
+		"b.md": include("c.md") + "\n" + include("x.md"),
+		"c.md": include("d.md"),
+		"d.md": "# D\n",
+		"x.md": "# X\n",
+	})
+	r := &Rule{MaxDepth: 2}
+	diags := check(t, r, dir, "a.md", nil, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "include chain is 3 levels deep, over the limit of 2: a.md -> b.md -> c.md -> d.md",
+		diags[0].Message)
+	assert.Empty(t, check(t, r, dir, "b.md", nil, nil))
+
+	r.MaxDepth = 3
+	assert.Empty(t, check(t, r, dir, "a.md", nil, nil))
+}
+
+func TestCheck_CatalogMatchesHost(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"docs/index.md": "# Docs\n\n<?catalog\nglob: \"*.md\"\n?>\n<?/catalog?>\n",
+		"docs/guide.md": "# Guide\n\n<?catalog\nglob: \"topics/*.md\"\n?>\n<?/catalog?>\n",
+		"docs/topics/a": "not markdown\n",
+	})
+	r := &Rule{MaxDepth: DefaultMaxDepth}
+	diags := check(t, r, dir, "docs/index.md", nil, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, "catalog glob matches its host file", diags[0].Message)
+	assert.Empty(t, check(t, r, dir, "docs/guide.md", nil, nil))
+}
+
+func TestCheck_CatalogMatchesIncluder(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"wrapper.md":     "# Wrapper\n\n" + include("mid.md"),
+		"mid.md":         include("parts/list.md"),
+		"parts/list.md":  "<?catalog\nglob: \"../wrap*.md\"\n?>\n<?/catalog?>\n",
+		"parts/other.md": "<?catalog\nglob: \"../*.md\"\nsource-dir: nowhere\n?>\n<?/catalog?>\n",
+	})
+	r := &Rule{MaxDepth: DefaultMaxDepth}
+	diags := check(t, r, dir, "parts/list.md", nil, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Equal(t, `catalog glob matches "wrapper.md", which includes this file: `+
+		"wrapper.md -> mid.md -> parts/list.md", diags[0].Message)
+
+	diags = check(t, r, dir, "wrapper.md", nil, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, `included "parts/list.md" has a catalog whose glob matches this file: `+
+		"wrapper.md -> mid.md -> parts/list.md", diags[0].Message)
+
+	assert.Empty(t, check(t, r, dir, "mid.md", nil, nil))
+	assert.Empty(t, check(t, r, dir, "parts/other.md", nil, nil))
+}
+
+func TestCheck_NoProjectRoot(t *testing.T) {
+	f, err := lint.NewFile("a.md", []byte(include("a.md")))
+	require.NoError(t, err)
+	r := &Rule{MaxDepth: DefaultMaxDepth}
+	assert.Nil(t, r.Check(f))
+	f.FS = os.DirFS(t.TempDir())
+	assert.Nil(t, r.Check(f))
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	assert.Equal(t, DefaultMaxDepth, r.MaxDepth)
+	require.NoError(t, r.ApplySettings(map[string]any{"max-depth": 3}))
+	assert.Equal(t, 3, r.MaxDepth)
+	assert.ErrorContains(t, r.ApplySettings(map[string]any{"max-depth": 0}), "positive integer")
+	assert.ErrorContains(t, r.ApplySettings(map[string]any{"max-depth": "deep"}), "positive integer")
+	assert.ErrorContains(t, r.ApplySettings(map[string]any{"depth": 3}), "unknown setting")
+}
@@ -0,0 +1,146 @@
+// Package externallinks implements MDS075, which reports external
+// links that `mdsmith links check-external` found broken. The rule
+// only reads the command's cache, so it makes no network requests.
+package externallinks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeduden/mdsmith/internal/linkcheck"
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+func init() {
+	rule.Register(&Rule{Cache: linkcheck.DefaultCachePath})
+}
+
+// Rule reports http and https URLs whose cached check failed. URLs
+// the cache does not know are skipped.
+type Rule struct {
+	// Cache is the path of the result cache. A relative path resolves
+	// against the project root.
+	Cache string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS075" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "external-links" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "link" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	path := r.Cache
+	if !filepath.IsAbs(path) && f.RootDir != "" {
+		path = filepath.Join(f.RootDir, path)
+	}
+	cache, err := loadCache(path)
+	if err != nil {
+		return []lint.Diagnostic{r.diag(f, 1, 1, fmt.Sprintf("cannot load link cache %q: %v", r.Cache, err))}
+	}
+	var diags []lint.Diagnostic
+	for _, ref := range linkgraph.CollectURLs(f) {
+		lower := strings.ToLower(ref.URL)
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			continue
+		}
+		res, ok := cache.Lookup(ref.URL, 0, time.Time{})
+		if !ok || res.OK() {
+			continue
+		}
+		diags = append(diags, r.diag(f, f.LineOfOffset(ref.Start), f.ColumnOfOffset(ref.Start),
+			fmt.Sprintf("broken external link %q: %s", ref.URL, res.Reason())))
+	}
+	return diags
+}
+
+func (r *Rule) diag(f *lint.File, line, col int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     f.Path,
+		Line:     line,
+		Column:   col,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Warning,
+		Message:  msg,
+	}
+}
+
+// cachedFile is a loaded cache with the stat of its file when it was
+// read.
+type cachedFile struct {
+	mod   time.Time
+	size  int64
+	cache *linkcheck.Cache
+}
+
+// caches keeps loaded cache files by path, so a run reads the file
+// once rather than once per Markdown file. An entry is reloaded when
+// its file changes.
+var caches = struct {
+	sync.Mutex
+	byPath map[string]*cachedFile
+}{byPath: map[string]*cachedFile{}}
+
+// loadCache returns the cache at path. A missing file is an empty
+// cache: nothing has been checked yet.
+func loadCache(path string) (*linkcheck.Cache, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return linkcheck.NewCache(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	caches.Lock()
+	defer caches.Unlock()
+	if c := caches.byPath[path]; c != nil && c.mod.Equal(info.ModTime()) && c.size == info.Size() {
+		return c.cache, nil
+	}
+	cache, err := linkcheck.LoadCache(path)
+	if err != nil {
+		return nil, err
+	}
+	caches.byPath[path] = &cachedFile{mod: info.ModTime(), size: info.Size(), cache: cache}
+	return cache, nil
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "cache":
+			p, ok := v.(string)
+			if !ok || p == "" {
+				return fmt.Errorf("external-links: cache must be a non-empty string, got %v", v)
+			}
+			r.Cache = p
+		default:
+			return fmt.Errorf("external-links: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{"cache": linkcheck.DefaultCachePath}
+}
+
+var (
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+)
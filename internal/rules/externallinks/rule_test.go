@@ -0,0 +1,97 @@
+package externallinks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeduden/mdsmith/internal/linkcheck"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFile(t *testing.T, root, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	f.RootDir = root
+	return f
+}
+
+func writeCache(t *testing.T, path string, results ...linkcheck.Result) {
+	t.Helper()
+	c := linkcheck.NewCache()
+	for _, r := range results {
+		r.Checked = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		c.Store(r)
+	}
+	require.NoError(t, c.Save(path))
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS075", r.ID())
+	assert.Equal(t, "external-links", r.Name())
+	assert.Equal(t, "link", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestCheck_ReportsCachedFailures(t *testing.T) {
+	root := t.TempDir()
+	writeCache(t, filepath.Join(root, linkcheck.DefaultCachePath),
+		linkcheck.Result{URL: "https://a.test/ok", Status: 200},
+		linkcheck.Result{URL: "https://a.test/gone", Status: 410},
+		linkcheck.Result{URL: "http://b.test/", Error: "dial tcp: connection refused"},
+	)
+	r := &Rule{Cache: linkcheck.DefaultCachePath}
+	f := newFile(t, root, "[a](https://a.test/ok) [b](https://a.test/gone)\n\n"+
+		"<http://b.test/> and [c](https://a.test/unknown) and [d](gone.md)\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 2)
+	assert.Equal(t, `broken external link "https://a.test/gone": HTTP 410`, diags[0].Message)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Equal(t, 28, diags[0].Column)
+	assert.Equal(t,
+		`broken external link "http://b.test/": dial tcp: connection refused`, diags[1].Message)
+	assert.Equal(t, 3, diags[1].Line)
+}
+
+func TestCheck_MissingCacheReportsNothing(t *testing.T) {
+	r := &Rule{Cache: "none.json"}
+	assert.Empty(t, r.Check(newFile(t, t.TempDir(), "[a](https://a.test/)\n")))
+}
+
+func TestCheck_ReloadsChangedCache(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "links.json")
+	writeCache(t, path, linkcheck.Result{URL: "https://a.test/", Status: 200})
+	r := &Rule{Cache: "links.json"}
+	f := newFile(t, root, "[a](https://a.test/)\n")
+	assert.Empty(t, r.Check(f))
+
+	writeCache(t, path, linkcheck.Result{URL: "https://a.test/", Status: 500},
+		linkcheck.Result{URL: "https://padding.test/", Status: 200})
+	assert.Len(t, r.Check(f), 1)
+}
+
+func TestCheck_CorruptCache(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "links.json"), []byte("{"), 0o644))
+	r := &Rule{Cache: "links.json"}
+	diags := r.Check(newFile(t, root, "text\n"))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, `cannot load link cache "links.json"`)
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	assert.Equal(t, linkcheck.DefaultCachePath, r.Cache)
+	require.NoError(t, r.ApplySettings(map[string]any{"cache": "ci/links.json"}))
+	assert.Equal(t, "ci/links.json", r.Cache)
+	assert.Error(t, r.ApplySettings(map[string]any{"cache": ""}))
+	assert.Error(t, r.ApplySettings(map[string]any{"cache": 1}))
+	assert.Error(t, r.ApplySettings(map[string]any{"ttl": "1h"}))
+}
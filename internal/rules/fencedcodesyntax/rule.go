@@ -0,0 +1,233 @@
+// Package fencedcodesyntax implements MDS068, which parses the body of
+// fenced code blocks tagged json, yaml, toml, or go and reports syntax
+// errors at the line inside the fence where they occur.
+package fencedcodesyntax
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jeduden/mdsmith/internal/fenceinfo"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/fencepos"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/yuin/goldmark/ast"
+)
+
+func init() {
+	rule.Register(&Rule{})
+}
+
+// skipAttr is the info-string attribute that opts a block out.
+const skipAttr = "skip-validate"
+
+// Go wrap modes: how a Go snippet becomes a file go/parser accepts.
+const (
+	wrapAuto = "auto" // pick func or none from the snippet's first line
+	wrapFunc = "func" // statements: wrap them in a function body
+	wrapNone = "none" // declarations: add a package clause if missing
+)
+
+// aliases maps alternative info-string languages to the validator
+// name.
+var aliases = map[string]string{
+	"yml":    "yaml",
+	"golang": "go",
+}
+
+// allLanguages lists the languages the rule can validate.
+var allLanguages = []string{"go", "json", "toml", "yaml"}
+
+// Rule reports fenced code blocks whose body does not parse as the
+// language in their info string.
+type Rule struct {
+	// Languages lists the languages to validate; nil means all.
+	Languages []string
+	// GoWrap is the default Go wrap mode: auto, func, or none. A
+	// block's {wrap=...} attribute overrides it.
+	GoWrap string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS068" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "fenced-code-syntax" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "code" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	return rule.WalkNodes(r, f)
+}
+
+// CheckNode implements rule.NodeChecker.
+func (r *Rule) CheckNode(n ast.Node, entering bool, f *lint.File) []lint.Diagnostic {
+	fcb, ok := n.(*ast.FencedCodeBlock)
+	if !entering || !ok || fcb.Info == nil {
+		return nil
+	}
+	info := fenceinfo.Parse(string(fcb.Info.Segment.Value(f.Source)))
+	lang := canonical(info.Lang)
+	if info.Has(skipAttr) || !r.validates(lang) {
+		return nil
+	}
+	body, starts := content(f, fcb)
+	var serr *syntaxError
+	switch lang {
+	case "json":
+		serr = validateJSON(body)
+	case "yaml":
+		serr = validateYAML(body)
+	case "toml":
+		serr = validateTOML(body)
+	case "go":
+		mode := r.goWrap()
+		if v, ok := info.Get("wrap"); ok {
+			mode = v
+		}
+		if !validWrap(mode) {
+			return []lint.Diagnostic{r.diag(f, fencepos.OpenLine(f, fcb), 1,
+				fmt.Sprintf("unknown wrap mode %q; want auto, func, or none", mode))}
+		}
+		serr = validateGo(body, mode)
+	}
+	if serr == nil {
+		return nil
+	}
+	line, col := fencepos.OpenLine(f, fcb), 1
+	if len(starts) > 0 {
+		off := offsetOf(f, starts, serr)
+		line, col = f.LineOfOffset(off), f.ColumnOfOffset(off)
+	}
+	return []lint.Diagnostic{r.diag(f, line, col, fmt.Sprintf("invalid %s: %s", display[lang], serr.msg))}
+}
+
+// display names each language in messages.
+var display = map[string]string{"go": "Go", "json": "JSON", "toml": "TOML", "yaml": "YAML"}
+
+func (r *Rule) diag(f *lint.File, line, col int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     f.Path,
+		Line:     line,
+		Column:   col,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Error,
+		Message:  msg,
+	}
+}
+
+func canonical(lang string) string {
+	if a, ok := aliases[lang]; ok {
+		return a
+	}
+	return lang
+}
+
+func (r *Rule) validates(lang string) bool {
+	if r.Languages == nil {
+		return slices.Contains(allLanguages, lang)
+	}
+	return slices.Contains(r.Languages, lang)
+}
+
+func (r *Rule) goWrap() string {
+	if r.GoWrap == "" {
+		return wrapAuto
+	}
+	return r.GoWrap
+}
+
+func validWrap(mode string) bool {
+	return mode == wrapAuto || mode == wrapFunc || mode == wrapNone
+}
+
+// content returns the block's body and the source offset where each
+// of its lines starts.
+func content(f *lint.File, fcb *ast.FencedCodeBlock) ([]byte, []int) {
+	lines := fcb.Lines()
+	var body []byte
+	starts := make([]int, 0, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		starts = append(starts, seg.Start)
+		body = append(body, seg.Value(f.Source)...)
+	}
+	return body, starts
+}
+
+// offsetOf maps an error position in the body to a source offset. A
+// line past the body (a wrapper's closing brace) lands on the last
+// line; a column past its line lands on the line's end.
+func offsetOf(f *lint.File, starts []int, e *syntaxError) int {
+	i := min(max(e.line, 1), len(starts)) - 1
+	off := starts[i]
+	if e.col > 1 {
+		off += e.col - 1
+	}
+	end := len(f.Source)
+	if nl := indexNewline(f.Source, starts[i]); nl >= 0 {
+		end = nl
+	}
+	return min(off, end)
+}
+
+func indexNewline(src []byte, from int) int {
+	for i := from; i < len(src); i++ {
+		if src[i] == '\n' {
+			return i
+		}
+	}
+	return -1
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "languages":
+			list, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("fenced-code-syntax: languages must be a list of strings, got %T", v)
+			}
+			langs := make([]string, 0, len(list))
+			for _, l := range list {
+				l = canonical(l)
+				if !slices.Contains(allLanguages, l) {
+					return fmt.Errorf("fenced-code-syntax: unsupported language %q; supported: go, json, toml, yaml", l)
+				}
+				langs = append(langs, l)
+			}
+			r.Languages = langs
+		case "go-wrap":
+			mode, ok := v.(string)
+			if !ok || !validWrap(mode) {
+				return fmt.Errorf("fenced-code-syntax: go-wrap must be auto, func, or none, got %v", v)
+			}
+			r.GoWrap = mode
+		default:
+			return fmt.Errorf("fenced-code-syntax: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"languages": slices.Clone(allLanguages),
+		"go-wrap":   wrapAuto,
+	}
+}
+
+var (
+	_ rule.NodeChecker  = (*Rule)(nil)
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+)
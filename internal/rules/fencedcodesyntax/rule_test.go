@@ -0,0 +1,161 @@
+package fencedcodesyntax
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func check(t *testing.T, r *Rule, src string) []lint.Diagnostic {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	return r.Check(f)
+}
+
+func TestCheck_ValidBlocks(t *testing.T) {
+	src := "# Doc\n\n```json\n{\"a\": [1, 2]}\n```\n\n" +
+		"```yaml\na: 1\nb: [x, y]\n```\n\n" +
+		"```toml\n[server]\nport = 80\n```\n\n" +
+		"```go\nx := 1\nfmt.Println(x)\n```\n\n" +
+		"```go\nfunc F() int { return 1 }\n```\n\n" +
+		"```go\npackage main\n\nfunc main() {}\n```\n"
+	assert.Empty(t, check(t, &Rule{}, src))
+}
+
+func TestCheck_ReportsLineInsideFence(t *testing.T) {
+	tests := []struct {
+		name, src string
+		line, col int
+		msgPrefix string
+	}{
+		{
+			name:      "json",
+			src:       "# Doc\n\n```json\n{\n  \"a\": 1,\n  \"b\" 2\n}\n```\n",
+			line:      6,
+			col:       7,
+			msgPrefix: "invalid JSON: invalid character '2'",
+		},
+		{
+			name:      "json truncated",
+			src:       "```json\n{\"a\": 1\n```\n",
+			line:      2,
+			col:       8,
+			msgPrefix: "invalid JSON: unexpected end of JSON input",
+		},
+		{
+			name:      "yaml",
+			src:       "# Doc\n\n```yaml\na: 1\nb: c: d\n```\n",
+			line:      5,
+			col:       1,
+			msgPrefix: "invalid YAML: mapping values are not allowed",
+		},
+		{
+			name:      "toml",
+			src:       "# Doc\n\n```toml\n[server]\nport = = 80\n```\n",
+			line:      5,
+			col:       8,
+			msgPrefix: "invalid TOML: ",
+		},
+		{
+			name:      "go statements",
+			src:       "# Doc\n\n```go\nx := 1\nif x {\n```\n",
+			line:      5,
+			msgPrefix: "invalid Go: ",
+		},
+		{
+			name:      "go declarations",
+			src:       "```go\nfunc F() {\n\treturn 1 +\n}\n```\n",
+			line:      4,
+			col:       1,
+			msgPrefix: "invalid Go: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := check(t, &Rule{}, tt.src)
+			require.Len(t, diags, 1, "%v", diags)
+			d := diags[0]
+			assert.Equal(t, "MDS068", d.RuleID)
+			assert.Equal(t, tt.line, d.Line)
+			if tt.col > 0 {
+				assert.Equal(t, tt.col, d.Column)
+			}
+			assert.Contains(t, d.Message, tt.msgPrefix)
+		})
+	}
+}
+
+func TestCheck_SkipValidate(t *testing.T) {
+	src := "```json {skip-validate}\n{not json\n```\n"
+	assert.Empty(t, check(t, &Rule{}, src))
+}
+
+func TestCheck_UnknownAndDisabledLanguages(t *testing.T) {
+	src := "```python\ndef (:\n```\n\n```json\n{\n```\n\n```\nplain {\n```\n"
+	assert.Empty(t, check(t, &Rule{Languages: []string{"yaml"}}, src))
+	assert.Len(t, check(t, &Rule{}, src), 1)
+}
+
+func TestCheck_Aliases(t *testing.T) {
+	src := "```yml\na: [\n```\n\n```golang\nx :=\n```\n"
+	assert.Len(t, check(t, &Rule{}, src), 2)
+}
+
+func TestCheck_GoWrap(t *testing.T) {
+	stmts := "```go\nx := 1\n_ = x\n```\n"
+	assert.Empty(t, check(t, &Rule{GoWrap: "func"}, stmts))
+	assert.Len(t, check(t, &Rule{GoWrap: "none"}, stmts), 1)
+
+	override := "```go {wrap=func}\nx := 1\n_ = x\n```\n"
+	assert.Empty(t, check(t, &Rule{GoWrap: "none"}, override))
+
+	bad := "```go {wrap=main}\nx := 1\n```\n"
+	diags := check(t, &Rule{}, bad)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Contains(t, diags[0].Message, `unknown wrap mode "main"`)
+}
+
+func TestCheck_ErrorPastBodyClampsToLastLine(t *testing.T) {
+	src := "# Doc\n\n```go\nif true {\n```\n"
+	diags := check(t, &Rule{}, src)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 4, diags[0].Line)
+}
+
+func TestCheck_EmptyBody(t *testing.T) {
+	src := "# Doc\n\n```json\n```\n"
+	diags := check(t, &Rule{}, src)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"languages": []any{"yml", "json"},
+		"go-wrap":   "none",
+	}))
+	assert.Equal(t, []string{"yaml", "json"}, r.Languages)
+	assert.Equal(t, "none", r.GoWrap)
+
+	for _, s := range []map[string]any{
+		{"languages": "json"},
+		{"languages": []any{"python"}},
+		{"go-wrap": "main"},
+		{"go-wrap": 1},
+		{"bogus": true},
+	} {
+		assert.Error(t, (&Rule{}).ApplySettings(s), "%v", s)
+	}
+}
+
+func TestDefaultSettings(t *testing.T) {
+	ds := (&Rule{}).DefaultSettings()
+	assert.Equal(t, []string{"go", "json", "toml", "yaml"}, ds["languages"])
+	assert.Equal(t, "auto", ds["go-wrap"])
+}
@@ -0,0 +1,135 @@
+package fencedcodesyntax
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+)
+
+// syntaxError is a parse failure at a 1-based line and column of a
+// block's body. A zero column means the parser reports lines only.
+type syntaxError struct {
+	line, col int
+	msg       string
+}
+
+func validateJSON(body []byte) *syntaxError {
+	var v any
+	err := json.Unmarshal(body, &v)
+	if err == nil {
+		return nil
+	}
+	var se *json.SyntaxError
+	if !errors.As(err, &se) {
+		return &syntaxError{line: 1, msg: err.Error()}
+	}
+	// Offset counts the bytes read, the bad one included.
+	off := max(int(se.Offset)-1, 0)
+	if se.Offset >= int64(len(body)) {
+		off = len(bytes.TrimRight(body, " \t\r\n"))
+	}
+	line, col := position(body, off)
+	return &syntaxError{line: line, col: col, msg: se.Error()}
+}
+
+// position converts a byte offset in body to a 1-based line and
+// column.
+func position(body []byte, off int) (int, int) {
+	off = min(off, len(body))
+	line := 1 + bytes.Count(body[:off], []byte("\n"))
+	col := off - bytes.LastIndexByte(body[:off], '\n')
+	return line, col
+}
+
+// yamlLine finds the "line N" yaml.v3 puts in its messages.
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): `)
+
+func validateYAML(body []byte) *syntaxError {
+	err := yamlutil.ValidateSyntax(body)
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &syntaxError{line: line, msg: msg[len(m[0]):]}
+	}
+	return &syntaxError{line: 1, msg: strings.TrimPrefix(msg, "yaml: ")}
+}
+
+func validateTOML(body []byte) *syntaxError {
+	var v map[string]any
+	_, err := toml.Decode(string(body), &v)
+	if err == nil {
+		return nil
+	}
+	var pe toml.ParseError
+	if errors.As(err, &pe) {
+		return &syntaxError{line: pe.Position.Line, col: pe.Position.Col, msg: pe.Message}
+	}
+	return &syntaxError{line: 1, msg: err.Error()}
+}
+
+// declPrefixes start the first line of a snippet of Go declarations.
+var declPrefixes = []string{"package ", "import ", "import(", "func ", "type ", "var ", "var(", "const ", "const("}
+
+// validateGo parses body as Go. In func mode the body becomes a
+// function's statements; in none mode it is a file, given a package
+// clause if it lacks one; auto picks by the first code line.
+func validateGo(body []byte, mode string) *syntaxError {
+	if mode == wrapAuto {
+		mode = wrapFunc
+		if first := firstCodeLine(body); hasAnyPrefix(first, declPrefixes) {
+			mode = wrapNone
+		}
+	}
+	src, shift := body, 0
+	switch {
+	case mode == wrapFunc:
+		src = append(append([]byte("package p\nfunc _() {\n"), body...), "\n}\n"...)
+		shift = 2
+	case !hasAnyPrefix(firstCodeLine(body), []string{"package "}):
+		src = append([]byte("package p\n"), body...)
+		shift = 1
+	}
+	_, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err == nil {
+		return nil
+	}
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		e := list[0]
+		return &syntaxError{line: e.Pos.Line - shift, col: e.Pos.Column, msg: e.Msg}
+	}
+	return &syntaxError{line: 1, msg: err.Error()}
+}
+
+// firstCodeLine returns the first line of body that is neither blank
+// nor a line comment, without leading space.
+func firstCodeLine(body []byte) string {
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "//") {
+			return line
+		}
+	}
+	return ""
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
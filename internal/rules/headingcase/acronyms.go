@@ -0,0 +1,71 @@
+package headingcase
+
+import (
+	"fmt"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/schema"
+)
+
+// schemaSource is one entry of the schema-sources list the merge
+// layer copies in from required-structure: a schema file path or a
+// pre-parsed inline schema.
+type schemaSource struct {
+	file   string
+	inline *schema.Schema
+}
+
+// parseSchemaSources reads the required-structure schema-sources
+// shape. Only the acronyms of each schema are used here.
+func parseSchemaSources(v any) ([]schemaSource, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("heading-case: schema-sources must be a list, got %T", v)
+	}
+	out := make([]schemaSource, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("heading-case: schema-sources[%d] must be a map, got %T", i, item)
+		}
+		if p, ok := m["file"].(string); ok && p != "" {
+			out = append(out, schemaSource{file: p})
+			continue
+		}
+		im, ok := m["inline"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("heading-case: schema-sources[%d] must set `file` or `inline`", i)
+		}
+		sch, err := schema.ParseInline(im, "inline kind schema")
+		if err != nil {
+			return nil, fmt.Errorf("heading-case: schema-sources[%d].inline: %w", i, err)
+		}
+		out = append(out, schemaSource{inline: sch})
+	}
+	return out, nil
+}
+
+// knownSafeAcronyms returns the composed acronyms.known-safe list of
+// the schemas for f. A schema that fails to load contributes
+// nothing; required-structure reports it.
+func (r *Rule) knownSafeAcronyms(f *lint.File) []string {
+	var parsed []*schema.Schema
+	for _, src := range r.schemaSources {
+		if src.inline != nil {
+			parsed = append(parsed, src.inline)
+			continue
+		}
+		reader := &schema.FileReader{RootFS: f.RootFS, RootDir: f.RootDir, MaxBytes: f.MaxInputBytes}
+		if sch, err := schema.ParseFile(reader, src.file); err == nil && sch != nil {
+			parsed = append(parsed, sch)
+		}
+	}
+	if len(parsed) == 0 {
+		return nil
+	}
+	sch, err := schema.Compose(parsed...)
+	if err != nil || sch == nil || sch.Acronyms == nil {
+		return nil
+	}
+	return sch.Acronyms.KnownSafe
+}
@@ -0,0 +1,248 @@
+package headingcase
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	articles = []string{"a", "an", "the"}
+
+	// titleMinor is what the generic title style keeps lowercase:
+	// articles, coordinating conjunctions, and prepositions of up to
+	// four letters.
+	titleMinor = wordSet(articles, []string{
+		"and", "but", "for", "nor", "or", "so", "yet",
+		"as", "at", "by", "down", "from", "in", "into", "like", "near", "of",
+		"off", "on", "onto", "out", "over", "past", "per", "than", "to", "up",
+		"upon", "via", "vs", "with",
+	})
+
+	// apMinor follows the AP stylebook: articles, coordinating
+	// conjunctions, and prepositions of up to three letters.
+	apMinor = wordSet(articles, []string{
+		"and", "but", "for", "nor", "or", "so", "yet",
+		"as", "at", "by", "in", "of", "off", "on", "out", "per", "to", "up",
+		"via", "vs",
+	})
+
+	// chicagoMinor follows the Chicago Manual of Style: articles, the
+	// conjunctions and, but, for, or, and nor, "to" and "as", and
+	// every preposition regardless of length.
+	chicagoMinor = wordSet(articles, []string{
+		"and", "but", "for", "nor", "or", "to", "as",
+		"about", "above", "across", "after", "against", "along", "among",
+		"around", "at", "before", "behind", "below", "beneath", "beside",
+		"between", "beyond", "by", "down", "during", "except", "from", "in",
+		"inside", "into", "like", "near", "of", "off", "on", "onto", "out",
+		"outside", "over", "past", "per", "since", "through", "throughout",
+		"till", "toward", "towards", "under", "underneath", "until", "up",
+		"upon", "via", "vs", "with", "within", "without",
+	})
+)
+
+func wordSet(lists ...[]string) map[string]bool {
+	out := map[string]bool{}
+	for _, l := range lists {
+		for _, w := range l {
+			out[w] = true
+		}
+	}
+	return out
+}
+
+// minorWords returns the words style keeps lowercase inside a title,
+// or nil for sentence case.
+func minorWords(style string) map[string]bool {
+	switch style {
+	case "title":
+		return titleMinor
+	case "ap":
+		return apMinor
+	case "chicago":
+		return chicagoMinor
+	}
+	return nil
+}
+
+// styleLabel names style in diagnostics.
+func styleLabel(style string) string {
+	switch style {
+	case "title":
+		return "title case"
+	case "ap":
+		return "AP title case"
+	case "chicago":
+		return "Chicago title case"
+	}
+	return "sentence case"
+}
+
+// token is one whitespace-separated run of a heading. Fixed tokens
+// (code spans, raw HTML, autolinks, images) carry no text; they are
+// never changed but still count when finding the first and last word.
+type token struct {
+	start, end int // byte offsets in the source
+	text       string
+	fixed      bool
+}
+
+// core splits text into leading punctuation, the word, and trailing
+// punctuation. The word runs from the first to the last letter or
+// digit.
+func core(text string) (pre, word, post string) {
+	first := strings.IndexFunc(text, isWordRune)
+	if first < 0 {
+		return text, "", ""
+	}
+	last := strings.LastIndexFunc(text, isWordRune)
+	_, size := utf8.DecodeRuneInString(text[last:])
+	return text[:first], text[first : last+size], text[last+size:]
+}
+
+func isWordRune(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+// phrase is one entry of the preserved vocabulary, split into words.
+type phrase []string
+
+// caser computes the expected casing of a heading's tokens.
+type caser struct {
+	minor   map[string]bool
+	phrases []phrase // longest first
+}
+
+func newCaser(style string, vocabulary []string) *caser {
+	c := &caser{minor: minorWords(style)}
+	for _, v := range vocabulary {
+		if words := strings.Fields(v); len(words) > 0 {
+			c.phrases = append(c.phrases, words)
+		}
+	}
+	// Longest first so "GitHub Actions" wins over "GitHub".
+	sort.SliceStable(c.phrases, func(i, j int) bool { return len(c.phrases[i]) > len(c.phrases[j]) })
+	return c
+}
+
+// expect returns the expected text of every token.
+func (c *caser) expect(tokens []token) []string {
+	want := make([]string, len(tokens))
+	words := make([]string, len(tokens))
+	first, last := -1, -1
+	for i, t := range tokens {
+		want[i] = t.text
+		_, words[i], _ = core(t.text)
+		if t.fixed || words[i] != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	locked := c.matchPhrases(tokens, words, want)
+	startsClause := true
+	for i, t := range tokens {
+		if t.fixed {
+			startsClause = false
+			continue
+		}
+		if words[i] == "" {
+			continue
+		}
+		if !locked[i] && !preserved(words[i]) {
+			pre, _, post := core(t.text)
+			want[i] = pre + c.caseWord(words[i], startsClause || i == first, i == last) + post
+		}
+		startsClause = strings.HasSuffix(t.text, ":")
+	}
+	return want
+}
+
+// matchPhrases sets the expected text of every run of editable tokens
+// that spells a vocabulary phrase, ignoring case, to the phrase's own
+// casing, and reports which tokens it locked.
+func (c *caser) matchPhrases(tokens []token, words, want []string) []bool {
+	locked := make([]bool, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		for _, p := range c.phrases {
+			if !c.phraseAt(tokens, words, locked, i, p) {
+				continue
+			}
+			for k, w := range p {
+				pre, _, post := core(tokens[i+k].text)
+				want[i+k] = pre + w + post
+				locked[i+k] = true
+			}
+			i += len(p) - 1
+			break
+		}
+	}
+	return locked
+}
+
+func (c *caser) phraseAt(tokens []token, words []string, locked []bool, i int, p phrase) bool {
+	if i+len(p) > len(tokens) {
+		return false
+	}
+	for k, w := range p {
+		t := tokens[i+k]
+		if t.fixed || locked[i+k] || !strings.EqualFold(words[i+k], w) {
+			return false
+		}
+	}
+	return true
+}
+
+// preserved reports whether word's casing is deliberate and must not
+// change: it holds something other than letters, apostrophes, and
+// hyphens (digits, dots, slashes), or a hyphen-separated part has a
+// capital after its first letter (acronyms like API, names like
+// JavaScript or iOS).
+func preserved(word string) bool {
+	if word == "I" {
+		return true
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) && r != '\'' && r != '’' && r != '-' {
+			return true
+		}
+	}
+	for _, part := range strings.Split(word, "-") {
+		for i, r := range part {
+			if i > 0 && unicode.IsUpper(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// caseWord returns word in the rule's style. first and last mark the
+// word's position; title styles always capitalize both, and every
+// style capitalizes the first word of the heading or after a colon.
+func (c *caser) caseWord(word string, first, last bool) string {
+	parts := strings.Split(word, "-")
+	for i, part := range parts {
+		lower := strings.ToLower(part)
+		switch {
+		case i == 0 && first:
+			parts[i] = capitalize(part)
+		case c.minor == nil:
+			parts[i] = lower
+		case i == 0 && last, !c.minor[lower]:
+			parts[i] = capitalize(part)
+		default:
+			parts[i] = lower
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,306 @@
+// Package headingcase implements MDS069, which checks that headings
+// follow one capitalization style: sentence case or a title case.
+package headingcase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func init() {
+	rule.Register(&Rule{Style: "sentence"})
+}
+
+// styles lists the accepted values of the style setting.
+var styles = []string{"sentence", "title", "ap", "chicago"}
+
+// Rule checks heading capitalization. Words whose casing is
+// deliberate are left alone: vocabulary entries (exceptions,
+// proper-names entries, schema acronyms) keep their listed casing,
+// and code spans, acronyms, and mixed-case words are never changed.
+type Rule struct {
+	// Style is sentence, title, ap, or chicago.
+	Style string
+	// Exceptions lists words and phrases kept exactly as written.
+	Exceptions []string
+	// ProperNames is the proper-names `names` list. The config merge
+	// layer copies it in, so it is not set by hand.
+	ProperNames []string
+	// schemaSources is the required-structure `schema-sources` list
+	// the merge layer copies in; their known-safe acronyms are kept.
+	schemaSources []schemaSource
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS069" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "heading-case" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "heading" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// edit replaces source[start:end] with text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// headingFix is one heading whose casing is off.
+type headingFix struct {
+	heading *ast.Heading
+	edits   []edit
+	want    string // the heading's expected Markdown text
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	var diags []lint.Diagnostic
+	for _, hf := range r.collect(f) {
+		diags = append(diags, lint.Diagnostic{
+			File:     f.Path,
+			Line:     f.LineOfOffset(hf.heading.Lines().At(0).Start),
+			Column:   1,
+			RuleID:   r.ID(),
+			RuleName: r.Name(),
+			Severity: lint.Warning,
+			Message:  fmt.Sprintf("heading should use %s: %q", styleLabel(r.style()), hf.want),
+		})
+	}
+	return diags
+}
+
+// Fix implements rule.FixableRule. Multi-line setext headings are
+// reported but not fixed, matching HeadingRenames.
+func (r *Rule) Fix(f *lint.File) []byte {
+	var edits []edit
+	for _, hf := range r.collect(f) {
+		if hf.heading.Lines().Len() == 1 {
+			edits = append(edits, hf.edits...)
+		}
+	}
+	out := slices.Clone(f.Source)
+	for _, e := range slices.Backward(edits) {
+		out = slices.Concat(out[:e.start], []byte(e.text), out[e.end:])
+	}
+	return out
+}
+
+// HeadingRenames implements rule.HeadingRenamer, so `mdsmith fix`
+// rewrites anchors in other files when a fix changes a slug.
+func (r *Rule) HeadingRenames(f *lint.File) []rule.HeadingRename {
+	var out []rule.HeadingRename
+	for _, hf := range r.collect(f) {
+		if hf.heading.Lines().Len() != 1 {
+			continue
+		}
+		out = append(out, rule.HeadingRename{
+			Line:   f.LineOfOffset(hf.heading.Lines().At(0).Start),
+			Old:    mdtext.ExtractPlainText(hf.heading, f.Source),
+			New:    plainText(hf.want),
+			Source: hf.want,
+		})
+	}
+	return out
+}
+
+// plainText returns the visible text of heading Markdown src.
+func plainText(src string) string {
+	body := []byte("# " + src + "\n")
+	root := lint.NewParser().Parse(text.NewReader(body), parser.WithContext(parser.NewContext()))
+	if h, ok := root.FirstChild().(*ast.Heading); ok {
+		return mdtext.ExtractPlainText(h, body)
+	}
+	return src
+}
+
+// collect returns every heading whose casing differs from the style,
+// in document order.
+func (r *Rule) collect(f *lint.File) []headingFix {
+	c := newCaser(r.style(), r.vocabulary(f))
+	var out []headingFix
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		if h.Lines().Len() == 0 || strings.TrimSpace(mdtext.ExtractPlainText(h, f.Source)) == "..." {
+			// "..." is the required-structure wildcard marker.
+			return ast.WalkSkipChildren, nil
+		}
+		if hf, ok := checkHeading(c, h, f.Source); ok {
+			out = append(out, hf)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return out
+}
+
+// checkHeading compares a heading's tokens with their expected casing.
+func checkHeading(c *caser, h *ast.Heading, source []byte) (headingFix, bool) {
+	tokens := headingTokens(h, source)
+	want := c.expect(tokens)
+	hf := headingFix{heading: h}
+	for i, t := range tokens {
+		if want[i] != t.text {
+			hf.edits = append(hf.edits, edit{start: t.start, end: t.end, text: want[i]})
+		}
+	}
+	if len(hf.edits) == 0 {
+		return hf, false
+	}
+	lines := h.Lines()
+	start, end := lines.At(0).Start, lines.At(lines.Len()-1).Stop
+	var sb strings.Builder
+	prev := start
+	for _, e := range hf.edits {
+		sb.Write(source[prev:e.start])
+		sb.WriteString(e.text)
+		prev = e.end
+	}
+	sb.Write(source[prev:end])
+	hf.want = strings.TrimSpace(sb.String())
+	return hf, true
+}
+
+// headingTokens splits a heading's inline content into tokens. Text
+// inside emphasis and link text is editable; code spans, raw HTML,
+// autolinks, and images are fixed tokens.
+func headingTokens(h *ast.Heading, source []byte) []token {
+	var tokens []token
+	_ = ast.Walk(h, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n == h {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.Text:
+			tokens = appendTextTokens(tokens, v.Segment.Start, v.Segment.Value(source))
+		case *ast.CodeSpan, *ast.RawHTML, *ast.AutoLink, *ast.Image, *ast.String:
+			tokens = append(tokens, token{fixed: true})
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return tokens
+}
+
+// appendTextTokens splits the text at offset into whitespace-separated
+// tokens. A token that continues the previous one without a gap, as
+// when an escape splits a word across text nodes, extends it.
+func appendTextTokens(tokens []token, offset int, b []byte) []token {
+	s := string(b)
+	for i := 0; i < len(s); {
+		if isSpace(s[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && !isSpace(s[j]) {
+			j++
+		}
+		start, end := offset+i, offset+j
+		if n := len(tokens); n > 0 && !tokens[n-1].fixed && tokens[n-1].end == start {
+			tokens[n-1].end = end
+			tokens[n-1].text += s[i:j]
+		} else {
+			tokens = append(tokens, token{start: start, end: end, text: s[i:j]})
+		}
+		i = j
+	}
+	return tokens
+}
+
+func isSpace(b byte) bool { return b == ' ' || b == '\t' || b == '\n' || b == '\r' }
+
+func (r *Rule) style() string {
+	if r.Style == "" {
+		return "sentence"
+	}
+	return r.Style
+}
+
+// vocabulary returns every word and phrase whose casing is preserved
+// for f: exceptions, proper names, and the known-safe acronyms of
+// the file's schemas.
+func (r *Rule) vocabulary(f *lint.File) []string {
+	return slices.Concat(r.Exceptions, r.ProperNames, r.knownSafeAcronyms(f))
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		if err := r.applySetting(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Rule) applySetting(key string, v any) error {
+	switch key {
+	case "style":
+		style, ok := v.(string)
+		if !ok || !slices.Contains(styles, style) {
+			return fmt.Errorf("heading-case: style must be one of %s, got %v",
+				strings.Join(styles, ", "), v)
+		}
+		r.Style = style
+	case "exceptions":
+		list, ok := settings.ToStringSlice(v)
+		if !ok {
+			return fmt.Errorf("heading-case: exceptions must be a list of strings, got %T", v)
+		}
+		r.Exceptions = list
+	case "proper-names":
+		list, ok := settings.ToStringSlice(v)
+		if !ok {
+			return fmt.Errorf("heading-case: proper-names must be a list of strings, got %T", v)
+		}
+		r.ProperNames = list
+	case "schema-sources":
+		sources, err := parseSchemaSources(v)
+		if err != nil {
+			return err
+		}
+		r.schemaSources = sources
+	default:
+		return fmt.Errorf("heading-case: unknown setting %q", key)
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"style":      "sentence",
+		"exceptions": []string{},
+	}
+}
+
+// SettingMergeMode implements rule.ListMerger. Exceptions append
+// across config layers, like proper-names' names.
+func (r *Rule) SettingMergeMode(key string) rule.MergeMode {
+	if key == "exceptions" {
+		return rule.MergeAppend
+	}
+	return rule.MergeReplace
+}
+
+var (
+	_ rule.HeadingRenamer = (*Rule)(nil)
+	_ rule.Configurable   = (*Rule)(nil)
+	_ rule.Defaultable    = (*Rule)(nil)
+	_ rule.ListMerger     = (*Rule)(nil)
+)
@@ -0,0 +1,148 @@
+package headingcase
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS069", r.ID())
+	assert.Equal(t, "heading-case", r.Name())
+	assert.Equal(t, "heading", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestFix_Styles(t *testing.T) {
+	const src = "# A Guide To The CLI: getting started\n"
+	tests := []struct{ style, want string }{
+		{"sentence", "# A guide to the CLI: Getting started\n"},
+		{"title", "# A Guide to the CLI: Getting Started\n"},
+		{"ap", "# A Guide to the CLI: Getting Started\n"},
+		{"chicago", "# A Guide to the CLI: Getting Started\n"},
+	}
+	for _, tt := range tests {
+		r := &Rule{Style: tt.style}
+		assert.Equal(t, tt.want, string(r.Fix(newFile(t, src))), tt.style)
+	}
+}
+
+func TestFix_TitleStylesDiffer(t *testing.T) {
+	const src = "# Working with Files from the Shell\n"
+	tests := []struct{ style, want string }{
+		{"title", "# Working with Files from the Shell\n"},
+		{"ap", "# Working With Files From the Shell\n"},
+		{"chicago", "# Working with Files from the Shell\n"},
+	}
+	for _, tt := range tests {
+		r := &Rule{Style: tt.style}
+		assert.Equal(t, tt.want, string(r.Fix(newFile(t, src))), tt.style)
+	}
+	r := &Rule{Style: "chicago"}
+	assert.Equal(t, "# Search through the Index without Limits\n",
+		string(r.Fix(newFile(t, "# search through the index without limits\n"))))
+}
+
+func TestCheck_PreservesDeliberateCasing(t *testing.T) {
+	r := &Rule{Style: "sentence"}
+	for _, src := range []string{
+		"# Configure the `MaxBytes` option\n",
+		"# Use JavaScript with iOS and the HTTP API\n",
+		"# Read README.md and v1.2 notes\n",
+		"# What I learned\n",
+		"# ...\n",
+	} {
+		assert.Empty(t, r.Check(newFile(t, src)), src)
+	}
+}
+
+func TestCheck_Diagnostic(t *testing.T) {
+	r := &Rule{}
+	diags := r.Check(newFile(t, "Intro.\n\n## Getting **Started** With [The Tool](x.md)\n"))
+	require.Len(t, diags, 1)
+	d := diags[0]
+	assert.Equal(t, 3, d.Line)
+	assert.Equal(t, 1, d.Column)
+	assert.Equal(t, "MDS069", d.RuleID)
+	assert.Equal(t, `heading should use sentence case: "Getting **started** with [the tool](x.md)"`, d.Message)
+}
+
+func TestFix_Vocabulary(t *testing.T) {
+	r := &Rule{
+		Style:       "sentence",
+		Exceptions:  []string{"Markdown"},
+		ProperNames: []string{"GitHub Actions", "Go"},
+	}
+	f := newFile(t, "# Run Github actions on Markdown Files in go\n")
+	assert.Equal(t, "# Run GitHub Actions on Markdown files in Go\n", string(r.Fix(f)))
+}
+
+func TestFix_SchemaAcronyms(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"schema-sources": []any{map[string]any{"inline": map[string]any{
+			"acronyms": map[string]any{"known-safe": []any{"OAuth"}},
+		}}},
+	}))
+	f := newFile(t, "# Set Up oauth Tokens\n")
+	assert.Equal(t, "# Set up OAuth tokens\n", string(r.Fix(f)))
+}
+
+func TestFix_MultiLineSetextReportedNotFixed(t *testing.T) {
+	r := &Rule{}
+	f := newFile(t, "Two Line\nHeading Text\n===\n")
+	assert.Len(t, r.Check(f), 1)
+	assert.Equal(t, string(f.Source), string(r.Fix(f)))
+	assert.Empty(t, r.HeadingRenames(f))
+}
+
+func TestHeadingRenames(t *testing.T) {
+	r := &Rule{}
+	f := newFile(t, "# Fine\n\n## The `Foo` Option ##\n")
+	assert.Equal(t, []rule.HeadingRename{{
+		Line:   3,
+		Old:    "The Foo Option",
+		New:    "The Foo option",
+		Source: "The `Foo` option",
+	}}, r.HeadingRenames(f))
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"style":        "chicago",
+		"exceptions":   []any{"Kubernetes"},
+		"proper-names": []any{"GitHub"},
+	}))
+	assert.Equal(t, "chicago", r.Style)
+	assert.Equal(t, []string{"Kubernetes"}, r.Exceptions)
+	assert.Equal(t, []string{"GitHub"}, r.ProperNames)
+	assert.Equal(t, rule.MergeAppend, r.SettingMergeMode("exceptions"))
+	assert.Equal(t, rule.MergeReplace, r.SettingMergeMode("style"))
+
+	tests := []struct {
+		settings map[string]any
+		want     string
+	}{
+		{map[string]any{"style": "upper"},
+			"heading-case: style must be one of sentence, title, ap, chicago, got upper"},
+		{map[string]any{"exceptions": "x"}, "heading-case: exceptions must be a list of strings"},
+		{map[string]any{"proper-names": 1}, "heading-case: proper-names must be a list of strings"},
+		{map[string]any{"schema-sources": "x"}, "heading-case: schema-sources must be a list"},
+		{map[string]any{"case": "x"}, `heading-case: unknown setting "case"`},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, (&Rule{}).ApplySettings(tt.settings), tt.want)
+	}
+}
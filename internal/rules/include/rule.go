@@ -143,6 +143,10 @@ func validateIncludeDirective(
 		}
 	}
 
+	if msg := validateSelection(params); msg != "" {
+		return []lint.Diagnostic{makeDiag(filePath, line, msg)}
+	}
+
 	return nil
 }
 
@@ -211,6 +215,11 @@ func (r *Rule) generateIncludeContent(
 		return "", []lint.Diagnostic{makeDiag(filePath, line,
 			fmt.Sprintf("cannot read include file %q: %v", file, err))}
 	}
+	data, err = selectContent(data, params)
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("cannot select from include file %q: %v", file, err))}
+	}
 
 	// Track this file and recursively expand nested includes.
 	if r.visited != nil {
@@ -248,7 +257,9 @@ func processIncludedContent(
 	f *lint.File, filePath, file string, line int,
 ) string {
 	content := data
-	stripFM := true
+	// A selection already starts past the front matter, or was cut
+	// by line number on purpose.
+	stripFM := !hasSelection(params)
 	if sfm, ok := params["strip-frontmatter"]; ok && sfm == "false" {
 		stripFM = false
 	}
@@ -258,6 +269,9 @@ func processIncludedContent(
 	}
 
 	text := strings.TrimLeft(string(content), "\n")
+	if _, wrapped := params["wrap"]; wrapped && hasSelection(params) {
+		text = dedent(text)
+	}
 	includedPath := path.Join(path.Dir(filePath), file)
 	text = adjustLinks(text, includedPath, filePath)
 
@@ -289,8 +303,11 @@ func processIncludedContent(
 
 // findParentHeadingLevel returns the level of the most recent heading
 // before the given 1-based line in the file's AST. Returns 0 if the
-// marker is at the document root (no heading precedes it).
+// marker is at the document root (no heading precedes it). Headings
+// inside generated sections are skipped, so a sibling include's body
+// never becomes the parent of the next include.
 func findParentHeadingLevel(f *lint.File, markerLine int) int {
+	generated := gensection.FindAllGeneratedRanges(f)
 	parentLevel := 0
 	for child := f.AST.FirstChild(); child != nil; child = child.NextSibling() {
 		heading, ok := child.(*ast.Heading)
@@ -304,11 +321,24 @@ func findParentHeadingLevel(f *lint.File, markerLine int) int {
 		if headingLine >= markerLine {
 			break
 		}
+		if inRanges(headingLine, generated) {
+			continue
+		}
 		parentLevel = heading.Level
 	}
 	return parentLevel
 }
 
+// inRanges reports whether the 1-based line falls in any range.
+func inRanges(line int, ranges []lint.LineRange) bool {
+	for _, r := range ranges {
+		if r.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
 func makeDiag(file string, line int, msg string) lint.Diagnostic {
 	return lint.Diagnostic{
 		File:     file,
@@ -446,6 +446,22 @@ func TestCheck_HeadingLevelUnderH3(t *testing.T) {
 	expectDiags(t, diags, 0)
 }
 
+func TestCheck_HeadingLevelSiblingIncludes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.md": {Data: []byte("# A\n\nx\n")},
+		"b.md": {Data: []byte("# B\n\ny\n")},
+	}
+	// The first include's `## A` is generated, so it is not the
+	// second include's parent: both re-level under `# Doc`.
+	src := "# Doc\n\n<?include\nfile: a.md\nheading-level: absolute\n?>\n" +
+		"## A\n\nx\n<?/include?>\n\n<?include\nfile: b.md\nheading-level: absolute\n?>\n" +
+		"## B\n\ny\n<?/include?>\n"
+	f := newTestFile(t, "doc.md", src, fsys)
+	r := &Rule{}
+	diags := r.Check(f)
+	expectDiags(t, diags, 0)
+}
+
 func TestCheck_InvalidHeadingLevel(t *testing.T) {
 	fsys := fstest.MapFS{
 		"data.md": {Data: []byte("content\n")},
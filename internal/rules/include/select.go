@@ -0,0 +1,258 @@
+package include
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/yuin/goldmark/ast"
+)
+
+// selectionParams are the include parameters that narrow the included
+// file to part of its content. At most one selection may be given;
+// start-marker and end-marker count as one.
+var selectionParams = []string{"section", "lines", "start-marker", "end-marker"}
+
+// hasSelection reports whether params select part of the file.
+func hasSelection(params map[string]string) bool {
+	for _, k := range selectionParams {
+		if _, ok := params[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSelection checks the selection parameters for shape errors
+// that do not depend on the included file's content.
+func validateSelection(params map[string]string) string {
+	n := 0
+	for _, k := range selectionParams {
+		v, ok := params[k]
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(v) == "" {
+			return fmt.Sprintf("include directive has empty %q value", k)
+		}
+		if k != "end-marker" {
+			n++
+		}
+	}
+	_, hasStart := params["start-marker"]
+	_, hasEnd := params["end-marker"]
+	switch {
+	case n > 1:
+		return `include directive accepts only one of "section", "lines", or "start-marker"/"end-marker"`
+	case hasStart && !hasEnd:
+		return `include directive "start-marker" requires "end-marker"`
+	case hasEnd && !hasStart:
+		return `include directive "end-marker" requires "start-marker"`
+	}
+	if v, ok := params["lines"]; ok {
+		if _, err := parseLineRanges(v); err != nil {
+			return fmt.Sprintf(`include directive "lines" %v`, err)
+		}
+	}
+	return ""
+}
+
+// selectContent returns the part of data that params select. Line
+// ranges and markers count lines from the top of the file, front
+// matter included, so they match what an editor shows; a section is
+// looked up among the body's headings.
+func selectContent(data []byte, params map[string]string) ([]byte, error) {
+	if name, ok := params["section"]; ok {
+		return selectSection(data, name)
+	}
+	if spec, ok := params["lines"]; ok {
+		ranges, err := parseLineRanges(spec)
+		if err != nil {
+			return nil, err
+		}
+		return selectLines(data, ranges)
+	}
+	if start, ok := params["start-marker"]; ok {
+		return selectMarkers(data, start, params["end-marker"])
+	}
+	return data, nil
+}
+
+// lineRange is an inclusive, 1-based range of lines. to is 0 when the
+// range runs to the end of the file.
+type lineRange struct{ from, to int }
+
+// parseLineRanges parses a comma-separated list of line ranges: "5",
+// "5-12", or "5-" for line 5 through the end of the file.
+func parseLineRanges(spec string) ([]lineRange, error) {
+	var out []lineRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil || from < 1 {
+			return nil, fmt.Errorf(`must be ranges like "5-12" or "5-12,20-", got %q`, spec)
+		}
+		r := lineRange{from: from, to: from}
+		if isRange {
+			r.to = 0
+			if hi = strings.TrimSpace(hi); hi != "" {
+				r.to, err = strconv.Atoi(hi)
+				if err != nil || r.to < from {
+					return nil, fmt.Errorf(`must be ranges like "5-12" or "5-12,20-", got %q`, spec)
+				}
+			}
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// fileLines splits data into lines without the empty element a
+// trailing newline leaves.
+func fileLines(data []byte) []string {
+	lines := strings.Split(string(data), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func joinLines(lines []string) []byte {
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func selectLines(data []byte, ranges []lineRange) ([]byte, error) {
+	lines := fileLines(data)
+	var out []string
+	for _, r := range ranges {
+		to := r.to
+		if to == 0 {
+			to = len(lines)
+		}
+		if r.from > len(lines) || to > len(lines) {
+			return nil, fmt.Errorf("lines %d-%d are past the end of the file (%d lines)",
+				r.from, to, len(lines))
+		}
+		out = append(out, lines[r.from-1:to]...)
+	}
+	return joinLines(out), nil
+}
+
+// selectMarkers returns the lines between the first line ending in
+// start and the next line ending in end, both marker lines excluded.
+func selectMarkers(data []byte, start, end string) ([]byte, error) {
+	lines := fileLines(data)
+	from := -1
+	for i, l := range lines {
+		if from < 0 && isMarkerLine(l, start) {
+			from = i + 1
+			continue
+		}
+		if from >= 0 && isMarkerLine(l, end) {
+			return joinLines(lines[from:i]), nil
+		}
+	}
+	if from < 0 {
+		return nil, fmt.Errorf("start-marker %q not found", start)
+	}
+	return nil, fmt.Errorf("end-marker %q not found after start-marker", end)
+}
+
+// isMarkerLine reports whether line ends with marker, ignoring
+// trailing whitespace and a closing `-->` or `*/`. The marker must not
+// continue a longer word, so "region: x" does not match
+// "// endregion: x" or "// region: xy".
+func isMarkerLine(line, marker string) bool {
+	t := strings.TrimSpace(line)
+	for _, closer := range []string{"-->", "*/"} {
+		t = strings.TrimSpace(strings.TrimSuffix(t, closer))
+	}
+	marker = strings.TrimSpace(marker)
+	if !strings.HasSuffix(t, marker) {
+		return false
+	}
+	i := len(t) - len(marker)
+	return i == 0 || !isWordByte(t[i-1])
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// selectSection returns the heading named name and everything under
+// it, up to the next heading of the same or a higher level. name
+// matches the heading's text, case-insensitively, or its slug.
+func selectSection(data []byte, name string) ([]byte, error) {
+	_, body := lint.StripFrontMatter(data)
+	f, err := lint.NewFile("", body)
+	if err != nil {
+		return nil, err
+	}
+	want := strings.TrimPrefix(strings.TrimSpace(name), "#")
+	from, to, level := 0, 0, 0
+	for n := f.AST.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok || h.Lines().Len() == 0 {
+			continue
+		}
+		line := f.LineOfOffset(h.Lines().At(0).Start)
+		if from == 0 {
+			text := mdtext.ExtractPlainText(h, f.Source)
+			if strings.EqualFold(strings.TrimSpace(text), want) || mdtext.Slugify(text) == want {
+				from, level = line, h.Level
+			}
+			continue
+		}
+		if h.Level <= level {
+			to = line - 1
+			break
+		}
+	}
+	if from == 0 {
+		return nil, fmt.Errorf("section %q not found", name)
+	}
+	lines := fileLines(body)
+	if to == 0 {
+		to = len(lines)
+	}
+	// Drop the blank lines that separate the section from the next
+	// heading.
+	for to > from && strings.TrimSpace(lines[to-1]) == "" {
+		to--
+	}
+	return joinLines(lines[from-1 : to]), nil
+}
+
+// dedent removes the leading whitespace every non-blank line shares,
+// so a region cut from inside a function reads flush left.
+func dedent(text string) string {
+	lines := strings.Split(text, "\n")
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if prefix == "" {
+		return text
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(l, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
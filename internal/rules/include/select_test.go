@@ -0,0 +1,144 @@
+package include
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const guideSource = "---\ntitle: Guide\n---\n# Guide\n\nIntro.\n\n" +
+	"## Install the CLI\n\nRun the installer.\n\n### On macOS\n\nUse brew.\n\n" +
+	"## Usage\n\nRun it.\n"
+
+func TestSelectSection(t *testing.T) {
+	for _, name := range []string{"Install the CLI", "install the cli", "install-the-cli", "#install-the-cli"} {
+		got, err := selectSection([]byte(guideSource), name)
+		require.NoError(t, err, name)
+		assert.Equal(t, "## Install the CLI\n\nRun the installer.\n\n### On macOS\n\nUse brew.\n", string(got), name)
+	}
+
+	got, err := selectSection([]byte(guideSource), "Usage")
+	require.NoError(t, err)
+	assert.Equal(t, "## Usage\n\nRun it.\n", string(got))
+
+	got, err = selectSection([]byte("Intro\n=====\n\nText.\n\nNext\n====\n"), "intro")
+	require.NoError(t, err)
+	assert.Equal(t, "Intro\n=====\n\nText.\n", string(got))
+
+	_, err = selectSection([]byte(guideSource), "Title")
+	assert.EqualError(t, err, `section "Title" not found`)
+}
+
+func TestParseLineRanges(t *testing.T) {
+	got, err := parseLineRanges("3, 5-7,9-")
+	require.NoError(t, err)
+	assert.Equal(t, []lineRange{{3, 3}, {5, 7}, {9, 0}}, got)
+
+	for _, bad := range []string{"", "0", "a-b", "7-5", "3-x", "-4"} {
+		_, err := parseLineRanges(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSelectLines(t *testing.T) {
+	data := []byte("one\ntwo\nthree\nfour\n")
+	got, err := selectLines(data, []lineRange{{1, 1}, {3, 0}})
+	require.NoError(t, err)
+	assert.Equal(t, "one\nthree\nfour\n", string(got))
+
+	_, err = selectLines(data, []lineRange{{3, 5}})
+	assert.EqualError(t, err, "lines 3-5 are past the end of the file (4 lines)")
+}
+
+func TestSelectMarkers(t *testing.T) {
+	src := "package x\n\nfunc TestX(t *testing.T) {\n" +
+		"\t// region: example2\n\tskip()\n\t// endregion: example2\n" +
+		"\t// region: example\n\tx := New()\n\tx.Run()\n\t// endregion: example\n}\n"
+	got, err := selectMarkers([]byte(src), "region: example", "endregion: example")
+	require.NoError(t, err)
+	assert.Equal(t, "\tx := New()\n\tx.Run()\n", string(got))
+
+	got, err = selectMarkers([]byte("a\n<!-- start: intro -->\nb\n<!-- end: intro -->\nc\n"),
+		"start: intro", "end: intro")
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(got))
+
+	_, err = selectMarkers([]byte(src), "region: missing", "endregion: missing")
+	assert.EqualError(t, err, `start-marker "region: missing" not found`)
+	_, err = selectMarkers([]byte(src), "region: example", "endregion: other")
+	assert.EqualError(t, err, `end-marker "endregion: other" not found after start-marker`)
+}
+
+func TestDedent(t *testing.T) {
+	assert.Equal(t, "x := 1\n\tif x {\n\t}\n", dedent("\tx := 1\n\t\tif x {\n\t\t}\n"))
+	assert.Equal(t, "a\n\n  b\n", dedent("  a\n\n    b\n"))
+	assert.Equal(t, "a\n b\n", dedent("a\n b\n"))
+}
+
+func TestValidateSelection(t *testing.T) {
+	assert.Empty(t, validateSelection(map[string]string{"section": "Usage"}))
+	assert.Empty(t, validateSelection(map[string]string{"start-marker": "a", "end-marker": "b"}))
+	assert.Contains(t, validateSelection(map[string]string{"section": "Usage", "lines": "1-2"}),
+		"accepts only one of")
+	assert.Contains(t, validateSelection(map[string]string{"start-marker": "a"}), `requires "end-marker"`)
+	assert.Contains(t, validateSelection(map[string]string{"end-marker": "b"}), `requires "start-marker"`)
+	assert.Contains(t, validateSelection(map[string]string{"section": " "}), `empty "section" value`)
+	assert.Contains(t, validateSelection(map[string]string{"lines": "x"}), `"lines" must be ranges`)
+}
+
+func TestCheck_SectionUpToDate(t *testing.T) {
+	fsys := fstest.MapFS{"guide.md": {Data: []byte(guideSource)}}
+	src := "# Doc\n\n<?include\nfile: guide.md\nsection: usage\n?>\n## Usage\n\nRun it.\n<?/include?>\n"
+	expectDiags(t, (&Rule{}).Check(newTestFile(t, "doc.md", src, fsys)), 0)
+}
+
+func TestCheck_SectionOutOfDate(t *testing.T) {
+	fsys := fstest.MapFS{"guide.md": {Data: []byte(guideSource)}}
+	src := "# Doc\n\n<?include\nfile: guide.md\nsection: usage\n?>\n## Usage\n\nOld.\n<?/include?>\n"
+	expectDiagMsg(t, (&Rule{}).Check(newTestFile(t, "doc.md", src, fsys)), "generated section is out of date")
+}
+
+func TestCheck_SectionMissing(t *testing.T) {
+	fsys := fstest.MapFS{"guide.md": {Data: []byte(guideSource)}}
+	src := "# Doc\n\n<?include\nfile: guide.md\nsection: Removed\n?>\n<?/include?>\n"
+	expectDiagMsg(t, (&Rule{}).Check(newTestFile(t, "doc.md", src, fsys)),
+		`cannot select from include file "guide.md": section "Removed" not found`)
+}
+
+func TestFix_SectionWithHeadingLevel(t *testing.T) {
+	fsys := fstest.MapFS{"guide.md": {Data: []byte(guideSource)}}
+	src := "# Doc\n\n<?include\nfile: guide.md\nsection: Install the CLI\nheading-level: absolute\n?>\n<?/include?>\n"
+	got := string((&Rule{}).Fix(newTestFile(t, "doc.md", src, fsys)))
+	assert.Equal(t, "# Doc\n\n<?include\nfile: guide.md\nsection: Install the CLI\nheading-level: absolute\n?>\n"+
+		"## Install the CLI\n\nRun the installer.\n\n### On macOS\n\nUse brew.\n<?/include?>\n", got)
+}
+
+func TestFix_LinesCountFrontMatter(t *testing.T) {
+	fsys := fstest.MapFS{"guide.md": {Data: []byte(guideSource)}}
+	src := "# Doc\n\n<?include\nfile: guide.md\nlines: 4-6\n?>\n<?/include?>\n"
+	got := string((&Rule{}).Fix(newTestFile(t, "doc.md", src, fsys)))
+	assert.Equal(t, "# Doc\n\n<?include\nfile: guide.md\nlines: 4-6\n?>\n# Guide\n\nIntro.\n<?/include?>\n", got)
+}
+
+func TestFix_MarkersFromGoSource(t *testing.T) {
+	fsys := fstest.MapFS{"example_test.go": {Data: []byte("package x\n\nfunc TestX(t *testing.T) {\n" +
+		"\t// region: example\n\tx := New()\n\tif err := x.Run(); err != nil {\n\t\tt.Fatal(err)\n\t}\n" +
+		"\t// endregion: example\n}\n")}}
+	src := "# Doc\n\n<?include\nfile: example_test.go\nstart-marker: \"region: example\"\n" +
+		"end-marker: \"endregion: example\"\nwrap: go\n?>\n<?/include?>\n"
+	got := string((&Rule{}).Fix(newTestFile(t, "doc.md", src, fsys)))
+	assert.Contains(t, got, "?>\n\n```go\nx := New()\n"+
+		"if err := x.Run(); err != nil {\n\tt.Fatal(err)\n}\n```\n\n<?/include?>\n")
+}
+
+func TestFix_NestedIncludeInsideSection(t *testing.T) {
+	fsys := fstest.MapFS{
+		"guide.md": {Data: []byte("# Guide\n\n## Part\n\n<?include\nfile: part.md\n?>\n<?/include?>\n\n## Other\n")},
+		"part.md":  {Data: []byte("Shared text.\n")},
+	}
+	src := "# Doc\n\n<?include\nfile: guide.md\nsection: Part\n?>\n<?/include?>\n"
+	got := string((&Rule{}).Fix(newTestFile(t, "doc.md", src, fsys)))
+	assert.Contains(t, got, "## Part\n\n<?include\nfile: part.md\n?>\nShared text.\n<?/include?>\n<?/include?>\n")
+}
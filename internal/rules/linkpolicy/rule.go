@@ -0,0 +1,264 @@
+// Package linkpolicy implements MDS074, which checks link URLs against
+// a policy of allowed schemes, allowed and denied domains, required
+// https, and known redirects.
+package linkpolicy
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/linkgraph"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+)
+
+func init() {
+	rule.Register(&Rule{Schemes: defaultSchemes(), RequireHTTPS: true})
+}
+
+func defaultSchemes() []string { return []string{"https", "http", "mailto"} }
+
+// Rule reports URLs of links, images, autolinks, reference
+// definitions, and raw <a href> and <img src> tags that break the
+// policy. URLs without a scheme or host are relative and pass.
+type Rule struct {
+	// Schemes lists the URL schemes a link may use.
+	Schemes []string
+	// AllowedDomains, when set, are the only hosts links may point at.
+	// An entry also matches its subdomains.
+	AllowedDomains []string
+	// DeniedDomains are hosts links must not point at, with their
+	// subdomains.
+	DeniedDomains []string
+	// RequireHTTPS reports http URLs.
+	RequireHTTPS bool
+	// Redirects maps a URL prefix to the prefix that replaces it. The
+	// longest matching prefix wins.
+	Redirects map[string]string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS074" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "link-policy" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "link" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// violation is the first policy a URL breaks. fix is the URL to
+// write instead, or empty when there is no safe rewrite.
+type violation struct {
+	msg, fix string
+}
+
+// schemeRe matches a URL scheme. It is read from the raw text, not
+// url.Parse, so a URL that fails to parse is still checked.
+var schemeRe = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*):`)
+
+// evaluate returns the policy u breaks, if any.
+func (r *Rule) evaluate(u string) (violation, bool) {
+	scheme := ""
+	if m := schemeRe.FindStringSubmatch(u); m != nil {
+		scheme = strings.ToLower(m[1])
+	}
+	host := ""
+	if p, err := url.Parse(u); err == nil {
+		host = strings.ToLower(p.Hostname())
+	}
+	if scheme == "" && host == "" {
+		return violation{}, false
+	}
+	if scheme != "" && !slices.Contains(r.Schemes, scheme) {
+		return violation{msg: fmt.Sprintf("URL scheme %q is not allowed", scheme)}, true
+	}
+	if host != "" {
+		if matchDomain(host, r.DeniedDomains) {
+			return violation{msg: fmt.Sprintf("domain %q is denied", host)}, true
+		}
+		if len(r.AllowedDomains) > 0 && !matchDomain(host, r.AllowedDomains) {
+			return violation{msg: fmt.Sprintf("domain %q is not in allowed-domains", host)}, true
+		}
+	}
+	if to, ok := r.redirect(u); ok {
+		return violation{msg: fmt.Sprintf("known redirect; link to %q instead", to), fix: to}, true
+	}
+	if r.RequireHTTPS && scheme == "http" {
+		v := violation{msg: "insecure http URL; use https"}
+		// Only an allowlisted host is known to serve the same page
+		// over https.
+		if host != "" && matchDomain(host, r.AllowedDomains) {
+			v.fix = "https" + u[len("http"):]
+		}
+		return v, true
+	}
+	return violation{}, false
+}
+
+// redirect rewrites u with the longest matching redirect prefix.
+func (r *Rule) redirect(u string) (string, bool) {
+	best := ""
+	for from := range r.Redirects {
+		if len(from) > len(best) && strings.HasPrefix(u, from) {
+			best = from
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	return r.Redirects[best] + u[len(best):], true
+}
+
+// matchDomain reports whether host is one of domains or a subdomain
+// of one.
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	var diags []lint.Diagnostic
+	for _, ref := range linkgraph.CollectURLs(f) {
+		v, bad := r.evaluate(ref.URL)
+		if !bad {
+			continue
+		}
+		line, col := f.LineOfOffset(ref.Start), f.ColumnOfOffset(ref.Start)
+		d := lint.Diagnostic{
+			File:     f.Path,
+			Line:     line,
+			Column:   col,
+			RuleID:   r.ID(),
+			RuleName: r.Name(),
+			Severity: lint.Warning,
+			Message:  v.msg,
+		}
+		if ref.End >= 0 {
+			d.EndColumn = col + ref.End - ref.Start
+			if v.fix != "" {
+				d.Suggestions = []string{v.fix}
+			}
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// Fix implements rule.FixableRule. It applies redirects and, for
+// allowlisted hosts, upgrades http to https. URLs the source spells
+// differently from their value are left alone.
+func (r *Rule) Fix(f *lint.File) []byte {
+	var out bytes.Buffer
+	prev := 0
+	for _, ref := range linkgraph.CollectURLs(f) {
+		if ref.End < 0 || ref.Start < prev {
+			continue
+		}
+		v, bad := r.evaluate(ref.URL)
+		if !bad || v.fix == "" {
+			continue
+		}
+		out.Write(f.Source[prev:ref.Start])
+		out.WriteString(v.fix)
+		prev = ref.End
+	}
+	out.Write(f.Source[prev:])
+	return out.Bytes()
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "schemes", "allowed-domains", "denied-domains":
+			list, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("link-policy: %s must be a list of strings, got %T", k, v)
+			}
+			switch k {
+			case "schemes":
+				for i, sc := range list {
+					list[i] = strings.ToLower(strings.TrimSuffix(sc, ":"))
+				}
+				r.Schemes = list
+			case "allowed-domains":
+				r.AllowedDomains = list
+			default:
+				r.DeniedDomains = list
+			}
+		case "require-https":
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("link-policy: require-https must be a bool, got %T", v)
+			}
+			r.RequireHTTPS = b
+		case "redirects":
+			m, err := toRedirects(v)
+			if err != nil {
+				return fmt.Errorf("link-policy: %w", err)
+			}
+			r.Redirects = m
+		default:
+			return fmt.Errorf("link-policy: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// toRedirects reads a map of URL prefixes to their replacements.
+func toRedirects(v any) (map[string]string, error) {
+	raw, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("redirects must be a map of URL prefixes, got %T", v)
+	}
+	out := make(map[string]string, len(raw))
+	for from, to := range raw {
+		s, ok := to.(string)
+		if from == "" || !ok {
+			return nil, fmt.Errorf("redirects[%q] must map a non-empty prefix to a string, got %T", from, to)
+		}
+		out[from] = s
+	}
+	return out, nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"schemes":         defaultSchemes(),
+		"allowed-domains": []string{},
+		"denied-domains":  []string{},
+		"require-https":   true,
+		"redirects":       map[string]any{},
+	}
+}
+
+// SettingMergeMode implements rule.ListMerger. The domain lists append
+// across config layers; a layer that sets schemes replaces them.
+func (r *Rule) SettingMergeMode(key string) rule.MergeMode {
+	if key == "allowed-domains" || key == "denied-domains" {
+		return rule.MergeAppend
+	}
+	return rule.MergeReplace
+}
+
+var (
+	_ rule.FixableRule  = (*Rule)(nil)
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+	_ rule.ListMerger   = (*Rule)(nil)
+)
@@ -0,0 +1,192 @@
+package linkpolicy
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func newRule(t *testing.T, s map[string]any) *Rule {
+	t.Helper()
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	require.NoError(t, r.ApplySettings(s))
+	return r
+}
+
+func messages(diags []lint.Diagnostic) []string {
+	out := make([]string, len(diags))
+	for i, d := range diags {
+		out[i] = d.Message
+	}
+	return out
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS074", r.ID())
+	assert.Equal(t, "link-policy", r.Name())
+	assert.Equal(t, "link", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestCheck_UnsafeSchemes(t *testing.T) {
+	r := newRule(t, nil)
+	f := newFile(t, "[a](javascript:alert(1)) and ![b](data:image/png;base64,AAAA)\n\n"+
+		"[c](file:///etc/passwd) and [d](MAILTO:me@example.com)\n")
+	diags := r.Check(f)
+	assert.Equal(t, []string{
+		`URL scheme "javascript" is not allowed`,
+		`URL scheme "data" is not allowed`,
+		`URL scheme "file" is not allowed`,
+	}, messages(diags))
+	assert.Equal(t, 5, diags[0].Column)
+	assert.Equal(t, 24, diags[0].EndColumn)
+	assert.Empty(t, diags[0].Suggestions)
+	assert.Equal(t, 3, diags[2].Line)
+}
+
+func TestCheck_RelativeLinksPass(t *testing.T) {
+	r := newRule(t, map[string]any{"allowed-domains": []any{"example.com"}})
+	f := newFile(t, "[a](guide.md) [b](../x.md#top) [c](#anchor) [d](/abs/path)\n")
+	assert.Empty(t, r.Check(f))
+}
+
+func TestCheck_HTTPUpgradeForAllowlistedHosts(t *testing.T) {
+	r := newRule(t, map[string]any{"allowed-domains": []any{"example.com", "insecure.org"}})
+	f := newFile(t, "See [docs](http://docs.example.com/a) and [old](http://insecure.org/).\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "insecure http URL; use https", diags[0].Message)
+	assert.Equal(t, []string{"https://docs.example.com/a"}, diags[0].Suggestions)
+	assert.Equal(t,
+		"See [docs](https://docs.example.com/a) and [old](https://insecure.org/).\n",
+		string(r.Fix(f)))
+}
+
+func TestCheck_HTTPWithoutAllowlistIsNotFixed(t *testing.T) {
+	r := newRule(t, nil)
+	f := newFile(t, "[a](http://example.com/)\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Empty(t, diags[0].Suggestions)
+	assert.Equal(t, string(f.Source), string(r.Fix(f)))
+
+	r = newRule(t, map[string]any{"require-https": false})
+	assert.Empty(t, r.Check(f))
+}
+
+func TestCheck_Domains(t *testing.T) {
+	r := newRule(t, map[string]any{
+		"allowed-domains": []any{"example.com"},
+		"denied-domains":  []any{"bad.example.com"},
+	})
+	f := newFile(t, "[a](https://example.com/) [b](https://www.example.com/)\n"+
+		"[c](https://api.bad.example.com/) [d](https://example.org/) [e](https://notexample.com/)\n")
+	assert.Equal(t, []string{
+		`domain "api.bad.example.com" is denied`,
+		`domain "example.org" is not in allowed-domains`,
+		`domain "notexample.com" is not in allowed-domains`,
+	}, messages(r.Check(f)))
+}
+
+func TestCheck_Redirects(t *testing.T) {
+	r := newRule(t, map[string]any{"redirects": map[string]any{
+		"http://golang.org/":       "https://go.dev/",
+		"http://golang.org/pkg/":   "https://pkg.go.dev/",
+		"https://example.com/old/": "https://example.com/new/",
+	}})
+	f := newFile(t, "[a](http://golang.org/pkg/fmt/) [b](http://golang.org/doc/)\n"+
+		"<https://example.com/old/page>\n")
+	diags := r.Check(f)
+	assert.Equal(t, []string{
+		`known redirect; link to "https://pkg.go.dev/fmt/" instead`,
+		`known redirect; link to "https://go.dev/doc/" instead`,
+		`known redirect; link to "https://example.com/new/page" instead`,
+	}, messages(diags))
+	assert.Equal(t, 2, diags[2].Column)
+	assert.Equal(t,
+		"[a](https://pkg.go.dev/fmt/) [b](https://go.dev/doc/)\n<https://example.com/new/page>\n",
+		string(r.Fix(f)))
+}
+
+func TestCheck_AutolinksAndHTML(t *testing.T) {
+	r := newRule(t, map[string]any{"allowed-domains": []any{"example.com"}})
+	f := newFile(t, "Visit <http://example.com/> or\n"+
+		`<a href="javascript:void(0)">x</a> <img src='http://example.com/i.png'>`+"\n\n"+
+		"<div>\n<a href=https://evil.test/>y</a>\n</div>\n")
+	diags := r.Check(f)
+	assert.Equal(t, []string{
+		"insecure http URL; use https",
+		`URL scheme "javascript" is not allowed`,
+		"insecure http URL; use https",
+		`domain "evil.test" is not in allowed-domains`,
+	}, messages(diags))
+	assert.Equal(t, 10, diags[1].Column)
+	assert.Equal(t, 5, diags[3].Line)
+	assert.Equal(t, "Visit <https://example.com/> or\n"+
+		`<a href="javascript:void(0)">x</a> <img src='https://example.com/i.png'>`+"\n\n"+
+		"<div>\n<a href=https://evil.test/>y</a>\n</div>\n",
+		string(r.Fix(f)))
+}
+
+func TestCheck_ReferenceDefinitions(t *testing.T) {
+	r := newRule(t, map[string]any{"allowed-domains": []any{"example.com"}})
+	f := newFile(t, "Read [the docs][docs] and [more].\n\n"+
+		"[docs]: http://example.com/docs\n[more]:\n  <javascript:alert(1)>\n")
+	diags := r.Check(f)
+	assert.Equal(t, []string{
+		"insecure http URL; use https",
+		`URL scheme "javascript" is not allowed`,
+	}, messages(diags))
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, 9, diags[0].Column)
+	assert.Equal(t, 5, diags[1].Line)
+	assert.Contains(t, string(r.Fix(f)), "[docs]: https://example.com/docs\n")
+}
+
+func TestCheck_SkipsCode(t *testing.T) {
+	r := newRule(t, nil)
+	f := newFile(t, "```md\n[a](javascript:x)\n[b]: file:///x\n```\n\n"+
+		"    [c](data:x)\n\nInline `[d](file:///y)` code.\n")
+	assert.Empty(t, r.Check(f))
+}
+
+func TestCheck_SameURLTwice(t *testing.T) {
+	r := newRule(t, map[string]any{"allowed-domains": []any{"example.com"}})
+	f := newFile(t, "[http://example.com/](http://example.com/)\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 23, diags[0].Column)
+	assert.Equal(t, "[http://example.com/](https://example.com/)\n", string(r.Fix(f)))
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	for _, s := range []map[string]any{
+		{"schemes": "https"},
+		{"allowed-domains": 1},
+		{"denied-domains": []any{1}},
+		{"require-https": "yes"},
+		{"redirects": []any{"x"}},
+		{"redirects": map[string]any{"http://a/": 1}},
+		{"redirects": map[string]any{"": "https://a/"}},
+		{"bogus": true},
+	} {
+		assert.Error(t, (&Rule{}).ApplySettings(s), "%v", s)
+	}
+}
+
+func TestApplySettings_NormalizesSchemes(t *testing.T) {
+	r := newRule(t, map[string]any{"schemes": []any{"HTTPS:", "ftp"}})
+	assert.Equal(t, []string{"https", "ftp"}, r.Schemes)
+	assert.Empty(t, r.Check(newFile(t, "[a](ftp://example.com/f)\n")))
+}
@@ -0,0 +1,117 @@
+// Package orphanedfiles implements MDS076, which reports Markdown
+// files that no entry point reaches through links, includes, or
+// catalogs. `mdsmith list orphans` prints the same set.
+package orphanedfiles
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+)
+
+// DefaultEntryPoints are the pages a reader starts from: every
+// README and index page.
+var DefaultEntryPoints = []string{"README.md", "index.md"}
+
+func init() {
+	rule.Register(&Rule{EntryPoints: DefaultEntryPoints})
+}
+
+// Rule reports a file that no entry point reaches.
+type Rule struct {
+	// EntryPoints are globpath patterns for the files reachability
+	// starts from. A pattern without a slash matches the base name in
+	// any directory; a `!` prefix excludes.
+	EntryPoints []string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS076" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "orphaned-files" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "link" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	// The graph spans the project root; without one (stdin, or no
+	// config) there is no workspace to be reachable from.
+	if f.FS == nil {
+		return nil
+	}
+	idx, rel, ok := index.ForFile(f)
+	if !ok || !r.orphans(f, idx)[rel] {
+		return nil
+	}
+	return []lint.Diagnostic{{
+		File:     f.Path,
+		Line:     1,
+		Column:   1,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Warning,
+		Message:  "file is not reachable from any entry point",
+	}}
+}
+
+// orphans returns the files of idx no entry point reaches, keyed by
+// root-relative path. The set is built once per run when the engine
+// supplies a RunCache.
+func (r *Rule) orphans(f *lint.File, idx *index.Index) map[string]bool {
+	build := func() any {
+		out := map[string]bool{}
+		for _, p := range idx.Unreachable(r.EntryPoints) {
+			out[p] = true
+		}
+		return out
+	}
+	if f.RunCache == nil {
+		return build().(map[string]bool)
+	}
+	key := fmt.Sprintf("orphaned-files\x00%s\x00%d\x00%s",
+		f.RootDir, f.MaxInputBytes, strings.Join(r.EntryPoints, "\x00"))
+	return f.RunCache.Workspace(key, build).(map[string]bool)
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "entry-points":
+			list, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("orphaned-files: entry-points must be a list of strings, got %T", v)
+			}
+			for _, p := range list {
+				if !doublestar.ValidatePattern(strings.TrimPrefix(p, "!")) {
+					return fmt.Errorf("orphaned-files: invalid entry-points pattern %q", p)
+				}
+			}
+			r.EntryPoints = list
+		default:
+			return fmt.Errorf("orphaned-files: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{"entry-points": append([]string(nil), DefaultEntryPoints...)}
+}
+
+var (
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+)
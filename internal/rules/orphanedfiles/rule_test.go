@@ -0,0 +1,100 @@
+package orphanedfiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func check(t *testing.T, r *Rule, dir, name string, cache *lint.RunCache) []lint.Diagnostic {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	src, err := os.ReadFile(p)
+	require.NoError(t, err)
+	f, err := lint.NewFile(p, src)
+	require.NoError(t, err)
+	f.FS = os.DirFS(filepath.Dir(p))
+	f.RootFS = os.DirFS(dir)
+	f.RootDir = dir
+	f.RunCache = cache
+	return r.Check(f)
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS076", r.ID())
+	assert.Equal(t, "orphaned-files", r.Name())
+	assert.Equal(t, "link", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestCheck_ReportsUnreachableFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"README.md":         "# Home\n\n[Guide](docs/guide.md)\n",
+		"docs/guide.md":     "# Guide\n",
+		"docs/orphan.md":    "---\ntitle: Orphan\n---\n# Orphan\n\n[Home](../README.md)\n",
+		"vendor/x/doc.md":   "# Vendored\n",
+		"node_modules/m.md": "# Module\n",
+		".gitignore":        "vendor/\n",
+	})
+	r := &Rule{EntryPoints: DefaultEntryPoints}
+	cache := lint.NewRunCache()
+	assert.Empty(t, check(t, r, dir, "README.md", cache))
+	assert.Empty(t, check(t, r, dir, "docs/guide.md", cache))
+	diags := check(t, r, dir, "docs/orphan.md", cache)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "file is not reachable from any entry point", diags[0].Message)
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Equal(t, 1, diags[0].Column)
+	// Gitignored and node_modules files are outside the graph.
+	assert.Empty(t, check(t, r, dir, "vendor/x/doc.md", cache))
+	assert.Empty(t, check(t, r, dir, "node_modules/m.md", cache))
+}
+
+func TestCheck_EntryPointsSetting(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"README.md":        "# Home\n",
+		"docs/start.md":    "# Start\n\n<?catalog\nglob: \"topics/*.md\"\n?>\n<?/catalog?>\n",
+		"docs/topics/a.md": "# A\n",
+	})
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	assert.Len(t, check(t, r, dir, "docs/topics/a.md", nil), 1)
+
+	require.NoError(t, r.ApplySettings(map[string]any{"entry-points": []any{"README.md", "docs/start.md"}}))
+	assert.Empty(t, check(t, r, dir, "docs/topics/a.md", nil))
+	assert.Empty(t, check(t, r, dir, "docs/start.md", nil))
+}
+
+func TestCheck_NoProjectRoot(t *testing.T) {
+	f, err := lint.NewFile("orphan.md", []byte("# Orphan\n"))
+	require.NoError(t, err)
+	assert.Empty(t, (&Rule{EntryPoints: DefaultEntryPoints}).Check(f))
+}
+
+func TestApplySettings_Errors(t *testing.T) {
+	for _, s := range []map[string]any{
+		{"entry-points": "README.md"},
+		{"entry-points": []any{1}},
+		{"entry-points": []any{"["}},
+		{"bogus": true},
+	} {
+		assert.Error(t, (&Rule{}).ApplySettings(s), "%v", s)
+	}
+}
@@ -0,0 +1,343 @@
+package paragraphwrap
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// atom is a run of text that never breaks across lines: a word, or a
+// code span, link, or raw HTML tag with the words around it that it
+// touches.
+type atom struct {
+	text  string
+	start int // source offset of the first byte
+	line  int // index of the block line the atom starts on
+	last  int // index of the block line the atom ends on
+	width int // in runes, the unit line-length counts
+}
+
+// block is one paragraph or tight list item text split into atoms.
+type block struct {
+	start, end int    // source range the reflow replaces
+	indent     string // prefix of every continuation line
+	firstWidth int    // width of the first line's container prefix
+	contWidth  int    // width of indent
+	atoms      []atom
+}
+
+var (
+	// alertRe matches a GitHub alert marker such as [!NOTE], which
+	// must stay alone on the first line of its blockquote.
+	alertRe = regexp.MustCompile(`^\[![A-Za-z]+\]$`)
+	// codeSpanRe matches a code span inside an atom.
+	codeSpanRe = regexp.MustCompile("`+[^`]*`+")
+	// delimiterRowRe matches a table delimiter row.
+	delimiterRowRe = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+)
+
+// newBlock splits the text of n into atoms, or returns nil when the
+// block must keep its line breaks: tables, display math, and text
+// with hard line breaks.
+func newBlock(n ast.Node, source []byte) *block {
+	first, ok := firstLine(n, source)
+	if !ok {
+		return nil
+	}
+	breakable, joins, ok := scanInlines(n, source)
+	if !ok {
+		return nil
+	}
+	b := &block{}
+	b.split(n.Lines(), first, source, breakable, joins)
+	if len(b.atoms) == 0 {
+		return nil
+	}
+	b.setPrefix(source)
+	return b
+}
+
+// firstLine returns the index of the first line to reflow: 1 after
+// an alert marker, else 0. ok is false for tables and display math.
+func firstLine(n ast.Node, source []byte) (int, bool) {
+	lines := n.Lines()
+	first := 0
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		line := bytes.TrimSpace(source[seg.Start:seg.Stop])
+		switch {
+		case i == 0 && alertRe.Match(line):
+			first = 1
+		case bytes.HasPrefix(line, []byte("|")), bytes.Equal(line, []byte("$$")),
+			bytes.Contains(line, []byte("|")) && delimiterRowRe.Match(line):
+			return 0, false
+		}
+	}
+	return first, first < lines.Len()
+}
+
+// split cuts lines[first:] into atoms at breakable whitespace and at
+// line ends not in joins; a joined line end becomes one space.
+func (b *block) split(lines *text.Segments, first int, source []byte, breakable, joins map[int]bool) {
+	var sb strings.Builder
+	cur := atom{start: -1}
+	flush := func() {
+		if cur.start >= 0 {
+			cur.text = sb.String()
+			cur.width = utf8.RuneCountInString(cur.text)
+			b.atoms = append(b.atoms, cur)
+		}
+		sb.Reset()
+		cur = atom{start: -1}
+	}
+	for i := first; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		ls, le := trimSpace(source, seg.Start, seg.Stop)
+		if i == first {
+			b.start = ls
+		}
+		b.end = le
+		for o := ls; o < le; o++ {
+			c := source[o]
+			if (c == ' ' || c == '\t') && breakable[o] {
+				flush()
+				continue
+			}
+			if cur.start < 0 {
+				cur.start, cur.line = o, i
+			}
+			sb.WriteByte(c)
+			cur.last = i
+		}
+		if i < lines.Len()-1 && joins[i] && cur.start >= 0 {
+			sb.WriteByte(' ')
+		} else {
+			flush()
+		}
+	}
+	flush()
+}
+
+// setPrefix derives the continuation prefix from the container prefix
+// of the first line: blockquote markers and whitespace stay, list
+// markers turn into spaces.
+func (b *block) setPrefix(source []byte) {
+	lineStart := bytes.LastIndexByte(source[:b.start], '\n') + 1
+	prefix := source[lineStart:b.start]
+	indent := make([]byte, len(prefix))
+	for i, c := range prefix {
+		if c == '>' || c == '\t' {
+			indent[i] = c
+		} else {
+			indent[i] = ' '
+		}
+	}
+	b.indent = string(indent)
+	b.firstWidth = utf8.RuneCount(prefix)
+	b.contWidth = len(indent)
+}
+
+// scanInlines reports which source offsets hold whitespace a line may
+// break at, and which line ends fall inside a code span, link, or
+// raw HTML and so must be joined. Whitespace is breakable only inside
+// text that no such node encloses. ok is false when the block has a
+// hard line break.
+func scanInlines(n ast.Node, source []byte) (breakable map[int]bool, joins map[int]bool, ok bool) {
+	lines := n.Lines()
+	lineOf := func(off int) int {
+		return sort.Search(lines.Len(), func(i int) bool { return lines.At(i).Stop > off })
+	}
+	breakable, joins, ok = map[int]bool{}, map[int]bool{}, true
+	spans := map[ast.Node][2]int{}
+	cover := func(p ast.Node, start, stop int) {
+		lo, hi := lineOf(start), lineOf(max(stop-1, start))
+		if s, seen := spans[p]; seen {
+			lo, hi = min(lo, s[0]), max(hi, s[1])
+		}
+		spans[p] = [2]int{lo, hi}
+	}
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || c == n {
+			return ast.WalkContinue, nil
+		}
+		if h, isHTML := c.(*ast.RawHTML); isHTML {
+			for i := 0; i < h.Segments.Len(); i++ {
+				seg := h.Segments.At(i)
+				cover(h, seg.Start, seg.Stop)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		t, isText := c.(*ast.Text)
+		if !isText {
+			return ast.WalkContinue, nil
+		}
+		if t.HardLineBreak() {
+			ok = false
+			return ast.WalkStop, nil
+		}
+		if p := protector(t, n); p != nil {
+			cover(p, t.Segment.Start, t.Segment.Stop)
+			return ast.WalkContinue, nil
+		}
+		for o := t.Segment.Start; o < t.Segment.Stop; o++ {
+			if source[o] == ' ' || source[o] == '\t' {
+				breakable[o] = true
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, s := range spans {
+		for i := s[0]; i < s[1]; i++ {
+			joins[i] = true
+		}
+	}
+	return breakable, joins, ok
+}
+
+// protector returns the outermost code span, link, image, or autolink
+// between t and the block root, or nil when t is breakable text.
+func protector(t ast.Node, root ast.Node) ast.Node {
+	var out ast.Node
+	for p := t.Parent(); p != nil && p != root; p = p.Parent() {
+		switch p.(type) {
+		case *ast.CodeSpan, *ast.Link, *ast.Image, *ast.AutoLink:
+			out = p
+		}
+	}
+	return out
+}
+
+func trimSpace(source []byte, start, stop int) (int, int) {
+	for start < stop && isSpace(source[start]) {
+		start++
+	}
+	for stop > start && isSpace(source[stop-1]) {
+		stop--
+	}
+	return start, stop
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+// current returns, for every atom, whether it starts a new line in
+// the source.
+func (b *block) current() []bool {
+	cur := make([]bool, len(b.atoms))
+	for k := 1; k < len(b.atoms); k++ {
+		cur[k] = b.atoms[k].line > b.atoms[k-1].last
+	}
+	return cur
+}
+
+// overlong returns the first atom of the first line in layout that is
+// wider than limit and could break before one of its later atoms.
+func (b *block) overlong(layout []bool, limit int) (int, bool) {
+	for k := 0; k < len(b.atoms); {
+		width, breakable := b.firstWidth, false
+		if k > 0 {
+			width = b.contWidth
+		}
+		width += b.atoms[k].width
+		j := k + 1
+		for ; j < len(b.atoms) && !layout[j]; j++ {
+			width += 1 + b.atoms[j].width
+			breakable = breakable || !startsBlock(b.atoms[j].text)
+		}
+		if width > limit && breakable {
+			return k, true
+		}
+		k = j
+	}
+	return 0, false
+}
+
+// render joins the atoms into the source text of layout.
+func (b *block) render(layout []bool) string {
+	var sb strings.Builder
+	for k, a := range b.atoms {
+		switch {
+		case k == 0:
+		case layout[k]:
+			sb.WriteString("\n" + b.indent)
+		default:
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(a.text)
+	}
+	return sb.String()
+}
+
+// sentenceStarts reports which atoms begin a sentence, other than the
+// first. The mdtext sentence splitter runs over the atoms with their
+// emphasis markers trimmed and code spans masked, so a version number
+// in code does not read as an abbreviation; a boundary inside an atom
+// is ignored.
+func sentenceStarts(atoms []atom) []bool {
+	starts := make([]bool, len(atoms))
+	var sb strings.Builder
+	ends := make([]int, len(atoms))
+	for k, a := range atoms {
+		if k > 0 {
+			sb.WriteByte(' ')
+		}
+		t := codeSpanRe.ReplaceAllString(a.text, "code")
+		if trimmed := strings.Trim(t, "*_~"); trimmed != "" {
+			t = trimmed
+		}
+		sb.WriteString(t)
+		ends[k] = sb.Len()
+	}
+	text := sb.String()
+	if !strings.ContainsAny(text, ".!?") {
+		return starts
+	}
+	pos, k := 0, 0
+	for _, s := range mdtext.SplitSentences(text) {
+		i := strings.Index(text[pos:], s)
+		if i < 0 {
+			break
+		}
+		pos += i + len(s)
+		for k < len(atoms) && ends[k] < pos {
+			k++
+		}
+		if k+1 < len(atoms) && ends[k] == pos {
+			starts[k+1] = true
+		}
+	}
+	return starts
+}
+
+// startsBlock reports whether a line starting with s could open a
+// block construct (heading, blockquote, list item, thematic break,
+// setext underline, fence, HTML block, table row, or footnote) and
+// so change how the paragraph parses.
+func startsBlock(s string) bool {
+	switch {
+	case strings.Trim(s, "#") == "", strings.Trim(s, "-*_+=") == "":
+		return true
+	case strings.ContainsAny(s[:1], ">|<"):
+		return true
+	case strings.HasPrefix(s, "```"), strings.HasPrefix(s, "~~~"):
+		return true
+	case strings.HasPrefix(s, "[^") && strings.Contains(s, "]:"):
+		return true
+	}
+	return isOrderedMarker(s)
+}
+
+// isOrderedMarker reports whether s is an ordered list marker such as
+// "1." or "12)".
+func isOrderedMarker(s string) bool {
+	digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	if digits == 0 || digits > 9 || digits != len(s)-1 {
+		return false
+	}
+	return s[digits] == '.' || s[digits] == ')'
+}
@@ -0,0 +1,212 @@
+// Package paragraphwrap implements MDS070, which checks where
+// paragraphs break their lines: one sentence per line, hard wrapping
+// at a width, or both.
+package paragraphwrap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/yuin/goldmark/ast"
+)
+
+func init() {
+	rule.Register(&Rule{Mode: "sentence", Max: 80})
+}
+
+// modes lists the accepted values of the mode setting.
+var modes = []string{"sentence", "wrap", "sentence-wrap"}
+
+// Rule checks the line breaks of paragraphs, tight list items, and
+// the paragraphs of blockquotes. Code spans, links, images, autolinks,
+// and raw HTML are never split across lines, and tables are skipped.
+type Rule struct {
+	// Mode is sentence, wrap, or sentence-wrap.
+	Mode string
+	// Max is the wrap width in characters. Unless set, the config
+	// merge layer copies it from line-length's max.
+	Max int
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS070" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "paragraph-wrap" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "whitespace" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// finding is one block whose line breaks are off.
+type finding struct {
+	block *block
+	want  []bool // the canonical layout, see layout
+	atom  int    // the atom the diagnostic points at
+	msg   string
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	var diags []lint.Diagnostic
+	for _, fd := range r.collect(f) {
+		off := fd.block.atoms[fd.atom].start
+		diags = append(diags, lint.Diagnostic{
+			File:     f.Path,
+			Line:     f.LineOfOffset(off),
+			Column:   f.ColumnOfOffset(off),
+			RuleID:   r.ID(),
+			RuleName: r.Name(),
+			Severity: lint.Warning,
+			Message:  fd.msg,
+		})
+	}
+	return diags
+}
+
+// Fix implements rule.FixableRule. It reflows every flagged block
+// into the canonical layout; other blocks keep their line breaks.
+func (r *Rule) Fix(f *lint.File) []byte {
+	out := slices.Clone(f.Source)
+	for _, fd := range slices.Backward(r.collect(f)) {
+		b := fd.block
+		out = slices.Concat(out[:b.start], []byte(b.render(fd.want)), out[b.end:])
+	}
+	return out
+}
+
+// collect returns a finding for every block whose line breaks are
+// off, in document order.
+func (r *Rule) collect(f *lint.File) []finding {
+	var out []finding
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+			if b := newBlock(n, f.Source); b != nil {
+				if fd, ok := r.check(b); ok {
+					out = append(out, fd)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+// check compares a block's line breaks with the canonical layout.
+// In sentence mode every difference counts. The wrap modes only
+// report missing sentence breaks and over-long lines that a break
+// could shorten, so hand-wrapped short lines stay valid.
+func (r *Rule) check(b *block) (finding, bool) {
+	var starts []bool
+	if r.mode() != "wrap" {
+		starts = sentenceStarts(b.atoms)
+	} else {
+		starts = make([]bool, len(b.atoms))
+	}
+	want := r.layout(b, starts)
+	cur := b.current()
+	if slices.Equal(want, cur) {
+		return finding{}, false
+	}
+	fd := finding{block: b, want: want}
+	for k := range want {
+		if want[k] && starts[k] && !cur[k] {
+			fd.atom, fd.msg = k, "sentence should start on its own line"
+			return fd, true
+		}
+	}
+	if r.mode() == "sentence" {
+		for k := range want {
+			if cur[k] && !want[k] {
+				fd.atom, fd.msg = k, "line break inside a sentence"
+				return fd, true
+			}
+		}
+		return finding{}, false
+	}
+	if k, ok := b.overlong(cur, r.Max); ok {
+		fd.atom, fd.msg = k, fmt.Sprintf("line exceeds %d characters; reflow the paragraph", r.Max)
+		return fd, true
+	}
+	return finding{}, false
+}
+
+// layout returns, for every atom, whether it starts a new line in the
+// canonical layout: a break before each sentence in the sentence
+// modes, and a greedy fill up to Max in the wrap modes. An atom that
+// would start a block construct at the beginning of a line never
+// gets a break before it.
+func (r *Rule) layout(b *block, starts []bool) []bool {
+	want := make([]bool, len(b.atoms))
+	sentences, wraps := r.mode() != "wrap", r.mode() != "sentence"
+	width := b.firstWidth + b.atoms[0].width
+	for k := 1; k < len(b.atoms); k++ {
+		a := b.atoms[k]
+		if !startsBlock(a.text) {
+			want[k] = (sentences && starts[k]) || (wraps && width+1+a.width > r.Max)
+		}
+		if want[k] {
+			width = b.contWidth + a.width
+		} else {
+			width += 1 + a.width
+		}
+	}
+	return want
+}
+
+func (r *Rule) mode() string {
+	if r.Mode == "" {
+		return "sentence"
+	}
+	return r.Mode
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "mode":
+			mode, ok := v.(string)
+			if !ok || !slices.Contains(modes, mode) {
+				return fmt.Errorf("paragraph-wrap: mode must be one of %s, got %v",
+					strings.Join(modes, ", "), v)
+			}
+			r.Mode = mode
+		case "max":
+			n, ok := settings.ToInt(v)
+			if !ok || n <= 0 {
+				return fmt.Errorf("paragraph-wrap: max must be a positive integer, got %v", v)
+			}
+			r.Max = n
+		default:
+			return fmt.Errorf("paragraph-wrap: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable. The merge layer
+// replaces max with line-length's max unless the config sets it.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"mode": "sentence",
+		"max":  80,
+	}
+}
+
+var (
+	_ rule.FixableRule  = (*Rule)(nil)
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+)
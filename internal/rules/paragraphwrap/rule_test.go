@@ -0,0 +1,140 @@
+package paragraphwrap
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS070", r.ID())
+	assert.Equal(t, "paragraph-wrap", r.Name())
+	assert.Equal(t, "whitespace", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestSentence_SplitsAndJoins(t *testing.T) {
+	r := &Rule{Mode: "sentence", Max: 80}
+	f := newFile(t, "Intro.\n\nFirst one. Second\nsentence here, e.g. with an abbreviation.\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, 12, diags[0].Column)
+	assert.Equal(t, "sentence should start on its own line", diags[0].Message)
+	assert.Equal(t,
+		"Intro.\n\nFirst one.\nSecond sentence here, e.g. with an abbreviation.\n",
+		string(r.Fix(f)))
+}
+
+func TestSentence_BreakInsideSentence(t *testing.T) {
+	r := &Rule{Mode: "sentence", Max: 80}
+	f := newFile(t, "One sentence\nacross two lines.\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 2, diags[0].Line)
+	assert.Equal(t, "line break inside a sentence", diags[0].Message)
+	assert.Equal(t, "One sentence across two lines.\n", string(r.Fix(f)))
+}
+
+func TestSentence_ContainersKeepPrefixes(t *testing.T) {
+	r := &Rule{Mode: "sentence", Max: 80}
+	src := "- Item one. Item\n  two.\n\n> Quote one. Quote two.\n\n1. **Bold.** Then text.\n"
+	want := "- Item one.\n  Item two.\n\n> Quote one.\n> Quote two.\n\n1. **Bold.**\n   Then text.\n"
+	f := newFile(t, src)
+	assert.Len(t, r.Check(f), 3)
+	fixed := r.Fix(f)
+	assert.Equal(t, want, string(fixed))
+	assert.Empty(t, r.Check(newFile(t, string(fixed))))
+}
+
+func TestWrap_ReflowsLongLines(t *testing.T) {
+	r := &Rule{Mode: "wrap", Max: 20}
+	f := newFile(t, "aaa bbb ccc ddd eee fff ggg\nhhh\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "line exceeds 20 characters; reflow the paragraph", diags[0].Message)
+	fixed := r.Fix(f)
+	assert.Equal(t, "aaa bbb ccc ddd eee\nfff ggg hhh\n", string(fixed))
+	assert.Empty(t, r.Check(newFile(t, string(fixed))))
+}
+
+func TestWrap_ShortLinesAreValid(t *testing.T) {
+	r := &Rule{Mode: "wrap", Max: 20}
+	assert.Empty(t, r.Check(newFile(t, "aaa\nbbb ccc\nddd\n")))
+}
+
+func TestSentenceWrap(t *testing.T) {
+	r := &Rule{Mode: "sentence-wrap", Max: 20}
+	f := newFile(t, "Short one. This sentence runs past the limit.\n")
+	fixed := r.Fix(f)
+	assert.Equal(t, "Short one.\nThis sentence runs\npast the limit.\n", string(fixed))
+	assert.Empty(t, r.Check(newFile(t, string(fixed))))
+}
+
+func TestWrap_NeverBreaksInlineConstructs(t *testing.T) {
+	r := &Rule{Mode: "wrap", Max: 10}
+	src := "see `a b c d` and [the link text](x.md) or <span a=\"b c\">z</span>\n"
+	// A line never starts with "<", which could open an HTML block.
+	want := "see\n`a b c d`\nand\n[the link text](x.md)\nor <span a=\"b c\">z</span>\n"
+	assert.Equal(t, want, string(r.Fix(newFile(t, src))))
+
+	// A code span split across lines is one atom, not a line break.
+	r = &Rule{Mode: "sentence", Max: 80}
+	assert.Empty(t, r.Check(newFile(t, "Run `go\ntest` now.\n")))
+}
+
+func TestWrap_AvoidsBlockStarts(t *testing.T) {
+	r := &Rule{Mode: "wrap", Max: 10}
+	src := "aaaaaaa - bbb # ccc 1. ddd > eee\n"
+	fixed := string(r.Fix(newFile(t, src)))
+	for _, line := range []string{"- ", "# ", "1. ", "> "} {
+		assert.NotContains(t, "\n"+fixed, "\n"+line, fixed)
+	}
+}
+
+func TestSkipsTablesMathAndHardBreaks(t *testing.T) {
+	r := &Rule{Mode: "sentence", Max: 10}
+	for _, src := range []string{
+		"| a. b | c |\n|---|---|\n| d. e | f |\n",
+		"a | b\n--|--\nc. d | e\n",
+		"$$\nx. y\n$$\n",
+		"One.  \nTwo. Three.\n",
+	} {
+		assert.Empty(t, r.Check(newFile(t, src)), src)
+	}
+}
+
+func TestAlertMarkerStaysAlone(t *testing.T) {
+	r := &Rule{Mode: "sentence", Max: 80}
+	f := newFile(t, "> [!NOTE]\n> One. Two.\n")
+	assert.Equal(t, "> [!NOTE]\n> One.\n> Two.\n", string(r.Fix(f)))
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{"mode": "wrap", "max": 72}))
+	assert.Equal(t, "wrap", r.Mode)
+	assert.Equal(t, 72, r.Max)
+
+	tests := []struct {
+		settings map[string]any
+		want     string
+	}{
+		{map[string]any{"mode": "fill"}, "paragraph-wrap: mode must be one of sentence, wrap, sentence-wrap, got fill"},
+		{map[string]any{"max": 0}, "paragraph-wrap: max must be a positive integer, got 0"},
+		{map[string]any{"width": 1}, `paragraph-wrap: unknown setting "width"`},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, (&Rule{}).ApplySettings(tt.settings), tt.want)
+	}
+}
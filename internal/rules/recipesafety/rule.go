@@ -209,25 +209,38 @@ func (r *Rule) checkRecipe(filePath, name string, rec recipe) []lint.Diagnostic
 		return []lint.Diagnostic{r.diag(filePath, lint.Error,
 			fmt.Sprintf("recipe %q: command must not be empty", name))}
 	}
-	diags := r.checkExecutable(filePath, name, tokens[0])
-	diags = append(diags, r.checkTokens(filePath, name, tokens)...)
+	var diags []lint.Diagnostic
+	for _, p := range ArgvProblems(tokens) {
+		diags = append(diags, r.diag(filePath, lint.Error, fmt.Sprintf("recipe %q: %s", name, p)))
+	}
 	diags = append(diags, r.checkUnusedParams(filePath, name, rec)...)
 	return diags
 }
 
-func (r *Rule) checkExecutable(filePath, name, exe string) []lint.Diagnostic {
-	var diags []lint.Diagnostic
+// ArgvProblems returns the shell-safety problems in one command's
+// argv: a shell interpreter or a ".." component in the executable,
+// shell operators, and fused placeholders. The checks assume the argv
+// runs without a shell, as recipes do. Messages name no recipe, so
+// other runners (mdsmith test) can report them too.
+func ArgvProblems(argv []string) []string {
+	if len(argv) == 0 {
+		return nil
+	}
+	problems := checkExecutable(argv[0])
+	return append(problems, checkTokens(argv)...)
+}
+
+func checkExecutable(exe string) []string {
+	var problems []string
 	if shellInterpreters[exe] {
-		diags = append(diags, r.diag(filePath, lint.Error,
-			fmt.Sprintf("recipe %q: command uses shell interpreter %q — use the direct binary",
-				name, exe)))
+		problems = append(problems,
+			fmt.Sprintf("command uses shell interpreter %q — use the direct binary", exe))
 	}
 	if hasDotDotSegment(exe) {
-		diags = append(diags, r.diag(filePath, lint.Error,
-			fmt.Sprintf("recipe %q: executable %q contains a .. path component",
-				name, exe)))
+		problems = append(problems,
+			fmt.Sprintf("executable %q contains a .. path component", exe))
 	}
-	return diags
+	return problems
 }
 
 // hasDotDotSegment reports whether the path has a segment that is exactly "..".
@@ -240,29 +253,27 @@ func hasDotDotSegment(p string) bool {
 	return false
 }
 
-func (r *Rule) checkTokens(filePath, name string, tokens []string) []lint.Diagnostic {
-	var diags []lint.Diagnostic
+func checkTokens(tokens []string) []string {
+	var problems []string
 	for _, tok := range tokens {
 		isSinglePlaceholder := placeholderRe.MatchString(tok) &&
 			placeholderRe.FindString(tok) == tok
 		if !isSinglePlaceholder {
 			for _, op := range shellOperators {
 				if strings.Contains(tok, op) {
-					diags = append(diags, r.diag(filePath, lint.Error,
-						fmt.Sprintf("recipe %q: command contains shell operator %q — use a wrapper script",
-							name, op)))
+					problems = append(problems,
+						fmt.Sprintf("command contains shell operator %q — use a wrapper script", op))
 					break
 				}
 			}
 		}
 		if fusedRe.MatchString(tok) {
-			fused := fusedRe.FindString(tok)
-			diags = append(diags, r.diag(filePath, lint.Error,
-				fmt.Sprintf("recipe %q: command contains fused placeholders %q — separate with a delimiter",
-					name, fused)))
+			problems = append(problems,
+				fmt.Sprintf("command contains fused placeholders %q — separate with a delimiter",
+					fusedRe.FindString(tok)))
 		}
 	}
-	return diags
+	return problems
 }
 
 func (r *Rule) checkUnusedParams(filePath, name string, rec recipe) []lint.Diagnostic {
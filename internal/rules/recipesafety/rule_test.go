@@ -565,3 +565,14 @@ func TestCheck_DiagnosticFields(t *testing.T) {
 	assert.Equal(t, "recipe-safety", d.RuleName)
 	assert.Equal(t, lint.Error, d.Severity)
 }
+
+func TestArgvProblems(t *testing.T) {
+	assert.Empty(t, ArgvProblems(nil))
+	assert.Empty(t, ArgvProblems([]string{"echo", "hello", "{name}"}))
+	assert.Equal(t, []string{
+		`command uses shell interpreter "sh" — use the direct binary`,
+		`command contains shell operator ";" — use a wrapper script`,
+	}, ArgvProblems([]string{"sh", "-c", "a;b"}))
+	assert.Equal(t, []string{`executable "../bin/tool" contains a .. path component`},
+		ArgvProblems([]string{"../bin/tool"}))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -613,7 +614,7 @@ func (r *Rule) Fix(f *lint.File) []byte {
 	if len(sources) == 1 && sources[0].File != "" && !r.isSchemaFileAt(f, sources[0].File) {
 		schData, schPath, loadErr := r.loadSchemaAt(f, sources[0].File)
 		if loadErr == nil {
-			parsedSch, parseErr := parseSchema(schData, schPath, f.MaxInputBytes)
+			parsedSch, parseErr := parseSchemaFS(f.RootFS, schData, schPath, f.MaxInputBytes)
 			if parseErr == nil {
 				docFMRaw, _ := readDocFrontMatterRaw(f)
 				return fixBodySyncIn(f, parsedSch, docFMRaw)
@@ -786,7 +787,7 @@ func (r *Rule) checkSingleFileSchema(f *lint.File, schemaPath string) []lint.Dia
 		return append(diags, r.diag(f.Path, 1, err.Error()))
 	}
 
-	sch, err := parseSchema(schData, schPath, f.MaxInputBytes)
+	sch, err := parseSchemaFS(f.RootFS, schData, schPath, f.MaxInputBytes)
 	if err != nil {
 		return append(diags, r.diag(f.Path, 1,
 			fmt.Sprintf("invalid schema %q: %v", schemaPath, err)))
@@ -929,7 +930,7 @@ func (r *Rule) bodySyncDiagnostics(f *lint.File, schemaPath string, docFMRaw map
 	if err != nil {
 		return append(diags, r.diag(f.Path, 1, err.Error()))
 	}
-	sch, err := parseSchema(data, schemaPath, f.MaxInputBytes)
+	sch, err := parseSchemaFS(f.RootFS, data, schemaPath, f.MaxInputBytes)
 	if err != nil {
 		// The compose path reports schema-parse errors separately;
 		// avoid duplicating them here.
@@ -1232,6 +1233,13 @@ const maxSchemaIncludeDepth = 10
 // required headings. When schemaPath is non-empty, <?include?> directives
 // are expanded and their headings spliced in.
 func parseSchema(data []byte, schemaPath string, maxBytes int64) (*parsedSchema, error) {
+	return parseSchemaFS(nil, data, schemaPath, maxBytes)
+}
+
+// parseSchemaFS is parseSchema with included fragments read from fsys,
+// the file's RootFS the schema itself was read from. A nil fsys reads
+// from the OS filesystem.
+func parseSchemaFS(fsys fs.FS, data []byte, schemaPath string, maxBytes int64) (*parsedSchema, error) {
 	prefix, content := lint.StripFrontMatter(data)
 
 	cfg, err := parseSchemaFrontMatter(prefix)
@@ -1258,7 +1266,7 @@ func parseSchema(data []byte, schemaPath string, maxBytes int64) (*parsedSchema,
 		visited := map[string]bool{cleanPath: true}
 		chain := []string{cleanPath}
 		var fp string
-		headings, fp, err = extractSchemaHeadings(f, schemaPath, visited, chain, maxBytes)
+		headings, fp, err = extractSchemaHeadings(fsys, f, schemaPath, visited, chain, maxBytes)
 		if err != nil {
 			return nil, err
 		}
@@ -1292,7 +1300,7 @@ func parseSchema(data []byte, schemaPath string, maxBytes int64) (*parsedSchema,
 // expanding <?include?> PIs by splicing in the included file's headings.
 // It uses a visited set for cycle detection.
 func extractSchemaHeadings(
-	schemaFile *lint.File, schemaPath string,
+	fsys fs.FS, schemaFile *lint.File, schemaPath string,
 	visited map[string]bool, chain []string, maxBytes int64,
 ) ([]docHeading, string, error) {
 	var headings []docHeading
@@ -1314,7 +1322,7 @@ func extractSchemaHeadings(
 				return ast.WalkContinue, nil
 			}
 			fragHeadings, fp, walkErr := expandSchemaInclude(
-				node, schemaFile.Source, schemaPath, visited, chain, maxBytes)
+				fsys, node, schemaFile.Source, schemaPath, visited, chain, maxBytes)
 			if walkErr != nil {
 				return ast.WalkStop, walkErr
 			}
@@ -1361,7 +1369,7 @@ func resolveSchemaIncludePath(
 }
 
 func expandSchemaInclude(
-	pi *lint.ProcessingInstruction, source []byte,
+	fsys fs.FS, pi *lint.ProcessingInstruction, source []byte,
 	schemaPath string, visited map[string]bool, chain []string, maxBytes int64,
 ) ([]docHeading, string, error) {
 	includedPath, err := resolveSchemaIncludePath(pi, source, schemaPath)
@@ -1381,7 +1389,7 @@ func expandSchemaInclude(
 			"cyclic include: %s", strings.Join(chainCopy, " -> "))
 	}
 
-	fragData, err := lint.ReadFileLimited(includedPath, maxBytes)
+	fragData, err := readSchemaInclude(fsys, includedPath, maxBytes)
 	if err != nil {
 		return nil, "", fmt.Errorf(
 			"cannot read schema include file %q: %w", includedPath, err)
@@ -1402,7 +1410,7 @@ func expandSchemaInclude(
 	visited[includedPath] = true
 	chain = append(chain, includedPath)
 	fragHeadings, fp2, err := extractSchemaHeadings(
-		fragFile, includedPath, visited, chain, maxBytes)
+		fsys, fragFile, includedPath, visited, chain, maxBytes)
 	delete(visited, includedPath)
 	if err != nil {
 		return nil, "", err
@@ -1414,6 +1422,15 @@ func expandSchemaInclude(
 	return fragHeadings, fp, nil
 }
 
+// readSchemaInclude reads an included schema fragment from fsys when
+// set, so includes resolve against the same root as the schema.
+func readSchemaInclude(fsys fs.FS, path string, maxBytes int64) ([]byte, error) {
+	if fsys != nil {
+		return lint.ReadFSFileLimited(fsys, filepath.ToSlash(path), maxBytes)
+	}
+	return lint.ReadFileLimited(path, maxBytes)
+}
+
 // extractPIFileParam parses the YAML body of an include PI to extract
 // the "file" parameter.
 func extractPIFileParam(pi *lint.ProcessingInstruction, source []byte) (string, error) {
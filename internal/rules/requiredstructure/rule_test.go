@@ -1216,6 +1216,29 @@ func TestCheck_SchemaViaRootFS(t *testing.T) {
 	expectDiags(t, diags, 0)
 }
 
+// A schema read via RootFS resolves its includes there too. The test
+// runs from the package directory, outside dir, so an include read
+// from the working directory would not be found.
+func TestCheck_SchemaIncludeViaRootFS(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "schemas", "common"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "plan.md"),
+		[]byte("# ?\n\n## Goal\n\n<?include\nfile: common/tasks.md\n?>\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "common", "tasks.md"),
+		[]byte("## Tasks\n"), 0o644))
+
+	r := &Rule{Schema: "schemas/plan.md"}
+	f := newTestFile(t, filepath.Join(dir, "doc.md"), "# Plan\n\n## Goal\n\n## Tasks\n")
+	f.SetRootDir(dir)
+	expectDiags(t, r.Check(f), 0)
+
+	f = newTestFile(t, filepath.Join(dir, "doc.md"), "# Plan\n\n## Goal\n")
+	f.SetRootDir(dir)
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "## Tasks")
+}
+
 func TestCheck_SchemaRejectsAbsolutePathWithRootFS(t *testing.T) {
 	dir := t.TempDir()
 	r := &Rule{Schema: "/etc/passwd"}
@@ -0,0 +1,201 @@
+// Package snippet implements MDS078, the <?snippet?> generated-section
+// directive that quotes a Go declaration from a source file in a
+// fenced go block.
+package snippet
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+func init() {
+	rule.Register(&Rule{})
+}
+
+// Rule checks and fixes <?snippet?>...<?/snippet?> generated sections.
+//
+// engineOnce serialises lazy engine init; the rule is a registered
+// singleton and the LSP server may call Check from concurrent
+// goroutines.
+type Rule struct {
+	engineOnce sync.Once
+	engine     *gensection.Engine
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS078" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "snippet" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// RuleID implements gensection.Directive.
+func (r *Rule) RuleID() string { return "MDS078" }
+
+// RuleName implements gensection.Directive.
+func (r *Rule) RuleName() string { return "snippet" }
+
+func (r *Rule) getEngine() *gensection.Engine {
+	r.engineOnce.Do(func() {
+		r.engine = gensection.NewEngine(r)
+	})
+	return r.engine
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f.FS == nil {
+		return nil
+	}
+	return r.getEngine().Check(f)
+}
+
+// Fix implements rule.FixableRule.
+func (r *Rule) Fix(f *lint.File) []byte {
+	if f.FS == nil {
+		return f.Source
+	}
+	return r.getEngine().Fix(f)
+}
+
+// Validate implements gensection.Directive.
+func (r *Rule) Validate(filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) []lint.Diagnostic {
+	if msg := validateParams(params); msg != "" {
+		return []lint.Diagnostic{makeDiag(filePath, line, msg)}
+	}
+	return nil
+}
+
+// Generate implements gensection.Directive.
+func (r *Rule) Generate(f *lint.File, filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) (string, []lint.Diagnostic) {
+	file := params["file"]
+	filePath = filepath.ToSlash(filePath)
+	readFS, readPath, msg := resolvePath(f, filePath, file)
+	if msg != "" {
+		return "", []lint.Diagnostic{makeDiag(filePath, line, msg)}
+	}
+	data, err := lint.ReadFSFileLimited(readFS, readPath, f.MaxInputBytes)
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("cannot read snippet file %q: %v", file, err))}
+	}
+	sym := parseSymbol(params["symbol"])
+	opts := options{doc: params["doc"] != "false", body: params["body"] != "false"}
+	text, err := extract(data, sym, opts)
+	if err == errNotFound {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			notFoundMessage(readFS, readPath, file, sym, f.MaxInputBytes))}
+	}
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("cannot extract %q from snippet file %q: %v", sym, file, err))}
+	}
+	fence := strings.Repeat("`", minFenceLen(text))
+	return "\n" + fence + "go\n" + gensection.EnsureTrailingNewline(text) + fence + "\n\n", nil
+}
+
+func validateParams(params map[string]string) string {
+	file, ok := params["file"]
+	if !ok || strings.TrimSpace(file) == "" {
+		return `snippet directive missing required "file" parameter`
+	}
+	if filepath.IsAbs(file) {
+		return "snippet directive has absolute file path"
+	}
+	symbol, ok := params["symbol"]
+	if !ok || strings.TrimSpace(symbol) == "" {
+		return `snippet directive missing required "symbol" parameter`
+	}
+	if !parseSymbol(symbol).valid() {
+		return fmt.Sprintf(`snippet directive "symbol" must be a name like "Config" or "Runner.Run", got %q`,
+			symbol)
+	}
+	for _, k := range []string{"doc", "body"} {
+		if v, ok := params[k]; ok && v != "true" && v != "false" {
+			return fmt.Sprintf(`snippet directive %q must be "true" or "false"`, k)
+		}
+	}
+	return ""
+}
+
+// resolvePath resolves file against the directory of filePath. With a
+// project root the path is read from RootFS and may not leave it;
+// without one, FS is the host file's directory and ".." is refused.
+func resolvePath(f *lint.File, filePath, file string) (fs.FS, string, string) {
+	resolved := path.Clean(path.Join(path.Dir(filePath), file))
+	if f.RootFS != nil {
+		if resolved == ".." || strings.HasPrefix(resolved, "../") {
+			return nil, "", "snippet file path escapes project root"
+		}
+		return f.RootFS, resolved, ""
+	}
+	for _, elem := range strings.Split(file, "/") {
+		if elem == ".." {
+			return nil, "", `snippet file path contains ".." but project root is not configured`
+		}
+	}
+	return f.FS, path.Clean(file), ""
+}
+
+// notFoundMessage names the missing symbol and, when another Go file
+// in the same directory now declares it, the file it moved to.
+func notFoundMessage(readFS fs.FS, readPath, file string, sym symbol, maxBytes int64) string {
+	msg := fmt.Sprintf("symbol %q not found in %q", sym, file)
+	dir := path.Dir(readPath)
+	entries, err := fs.ReadDir(readFS, dir)
+	if err != nil {
+		return msg
+	}
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == path.Base(readPath) || path.Ext(e.Name()) != ".go" {
+			continue
+		}
+		data, err := lint.ReadFSFileLimited(readFS, path.Join(dir, e.Name()), maxBytes)
+		if err != nil {
+			continue
+		}
+		if _, err := extract(data, sym, options{doc: true, body: true}); err == nil {
+			moved := path.Join(path.Dir(file), e.Name())
+			return fmt.Sprintf("%s; it is now declared in %q", msg, moved)
+		}
+	}
+	return msg
+}
+
+func makeDiag(file string, line int, msg string) lint.Diagnostic {
+	return gensection.MakeDiag("MDS078", "snippet", file, line, msg)
+}
+
+// minFenceLen returns the shortest backtick fence that no backtick
+// run inside text can close.
+func minFenceLen(text string) int {
+	n := 3
+	for _, line := range strings.Split(text, "\n") {
+		run := 0
+		for _, c := range line {
+			if c != '`' {
+				run = 0
+				continue
+			}
+			run++
+			if run >= n {
+				n = run + 1
+			}
+		}
+	}
+	return n
+}
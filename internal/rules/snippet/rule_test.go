@@ -0,0 +1,241 @@
+package snippet
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+)
+
+const runnerSource = `package run
+
+import "context"
+
+// Config holds the runner settings.
+type Config struct {
+	Name string // display name
+}
+
+// Runner executes jobs.
+type Runner struct{ cfg Config }
+
+// Run executes every job in order.
+func (r *Runner) Run(ctx context.Context) error {
+	return nil
+}
+
+// Run is the package-level shortcut.
+func Run() error { return (&Runner{}).Run(context.Background()) }
+
+const (
+	// DefaultName is used when Config.Name is empty.
+	DefaultName = "runner" // shown in logs
+
+	maxJobs = 8
+)
+
+type (
+	// Job is one unit of work.
+	Job struct {
+		ID string
+	}
+)
+
+// Box holds one value.
+type Box[T any] struct{ v T }
+
+// Get returns the value.
+func (b Box[T]) Get() T { return b.v }
+`
+
+func newTestFile(t *testing.T, source string, fsys fstest.MapFS) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("doc.md", []byte(source))
+	require.NoError(t, err)
+	f.FS = fsys
+	f.RootFS = fsys
+	return f
+}
+
+func directive(params string) string {
+	return "# Doc\n\n<?snippet\nfile: run/runner.go\n" + params + "?>\n<?/snippet?>\n"
+}
+
+func runnerFS() fstest.MapFS {
+	return fstest.MapFS{"run/runner.go": {Data: []byte(runnerSource)}}
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS078", r.ID())
+	assert.Equal(t, "snippet", r.Name())
+	assert.Equal(t, "directive", r.Category())
+}
+
+func TestExtract(t *testing.T) {
+	all := options{doc: true, body: true}
+	tests := []struct {
+		name   string
+		symbol string
+		opts   options
+		want   string
+	}{
+		{"method", "Runner.Run", all,
+			"// Run executes every job in order.\nfunc (r *Runner) Run(ctx context.Context) error {\n\treturn nil\n}"},
+		{"pointer receiver form", "(*Runner).Run", options{body: true},
+			"func (r *Runner) Run(ctx context.Context) error {\n\treturn nil\n}"},
+		{"signature only", "Runner.Run", options{doc: true},
+			"// Run executes every job in order.\nfunc (r *Runner) Run(ctx context.Context) error"},
+		{"function beside method", "Run", options{body: true},
+			"func Run() error { return (&Runner{}).Run(context.Background()) }"},
+		{"type", "Config", all,
+			"// Config holds the runner settings.\ntype Config struct {\n\tName string // display name\n}"},
+		{"grouped const", "DefaultName", all,
+			"// DefaultName is used when Config.Name is empty.\nconst DefaultName = \"runner\" // shown in logs"},
+		{"grouped const without doc", "maxJobs", all, "const maxJobs = 8"},
+		{"grouped type", "Job", all, "// Job is one unit of work.\ntype Job struct {\n\tID string\n}"},
+		{"generic receiver", "Box.Get", options{body: true}, "func (b Box[T]) Get() T { return b.v }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extract([]byte(runnerSource), parseSymbol(tt.symbol), tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExtract_MultiParagraphDoc(t *testing.T) {
+	const src = `package run
+
+// Start launches the runner.
+//
+// It returns once every worker is up.
+func Start() {}
+
+// Mode picks how jobs run.
+//
+// The zero value runs them in order.
+type Mode int
+
+const (
+	// Serial runs one job at a time.
+	//
+	// It is the default.
+	Serial Mode = iota
+	Parallel
+)
+`
+	all := options{doc: true, body: true}
+	for _, tt := range []struct{ symbol, want string }{
+		{"Start", "// Start launches the runner.\n//\n// It returns once every worker is up.\nfunc Start() {}"},
+		{"Mode", "// Mode picks how jobs run.\n//\n// The zero value runs them in order.\ntype Mode int"},
+		{"Serial", "// Serial runs one job at a time.\n//\n// It is the default.\nconst Serial Mode = iota"},
+	} {
+		t.Run(tt.symbol, func(t *testing.T) {
+			got, err := extract([]byte(src), parseSymbol(tt.symbol), all)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExtract_Errors(t *testing.T) {
+	_, err := extract([]byte(runnerSource), parseSymbol("Runner.Stop"), options{body: true})
+	assert.Equal(t, errNotFound, err)
+	_, err = extract([]byte(runnerSource), parseSymbol("Config.Name"), options{body: true})
+	assert.Equal(t, errNotFound, err, "fields are not methods")
+	_, err = extract([]byte(runnerSource), parseSymbol("Config"), options{})
+	assert.EqualError(t, err, `"body" applies only to functions and methods, and "Config" is a type`)
+	_, err = extract([]byte("package x\nfunc {"), parseSymbol("X"), options{})
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	r := &Rule{}
+	valid := map[string]string{"file": "a.go", "symbol": "Runner.Run", "doc": "false", "body": "true"}
+	assert.Empty(t, r.Validate("doc.md", 3, valid, nil))
+	for _, tt := range []struct {
+		params map[string]string
+		want   string
+	}{
+		{map[string]string{"symbol": "X"}, `missing required "file"`},
+		{map[string]string{"file": "/abs/a.go", "symbol": "X"}, "absolute file path"},
+		{map[string]string{"file": "a.go"}, `missing required "symbol"`},
+		{map[string]string{"file": "a.go", "symbol": "a.b.c"}, `"symbol" must be a name`},
+		{map[string]string{"file": "a.go", "symbol": "X", "doc": "y"}, `"doc" must be "true" or "false"`},
+	} {
+		diags := r.Validate("doc.md", 3, tt.params, nil)
+		require.Len(t, diags, 1, tt.want)
+		assert.Contains(t, diags[0].Message, tt.want)
+		assert.Equal(t, 3, diags[0].Line)
+	}
+}
+
+func TestFix_WritesFencedBlock(t *testing.T) {
+	src := "# Doc\n\n<?snippet\nfile: run/runner.go\nsymbol: Runner.Run\ndoc: \"false\"\n?>\n<?/snippet?>\n"
+	got := string((&Rule{}).Fix(newTestFile(t, src, runnerFS())))
+	assert.Equal(t, "# Doc\n\n<?snippet\nfile: run/runner.go\nsymbol: Runner.Run\ndoc: \"false\"\n?>\n"+
+		"\n```go\nfunc (r *Runner) Run(ctx context.Context) error {\n\treturn nil\n}\n```\n\n<?/snippet?>\n", got)
+}
+
+func TestCheck_UpToDateAndStale(t *testing.T) {
+	r := &Rule{}
+	fixed := r.Fix(newTestFile(t, directive("symbol: Config\n"), runnerFS()))
+	assert.Empty(t, r.Check(newTestFile(t, string(fixed), runnerFS())))
+
+	changed := fstest.MapFS{"run/runner.go": {Data: []byte(
+		"package run\n\n// Config holds the runner settings.\ntype Config struct {\n\tName string\n\tJobs int\n}\n")}}
+	diags := r.Check(newTestFile(t, string(fixed), changed))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "generated section is out of date", diags[0].Message)
+}
+
+func TestCheck_SymbolDeleted(t *testing.T) {
+	diags := (&Rule{}).Check(newTestFile(t, directive("symbol: Runner.Stop\n"), runnerFS()))
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, `symbol "Runner.Stop" not found in "run/runner.go"`, diags[0].Message)
+}
+
+func TestCheck_SymbolMoved(t *testing.T) {
+	fsys := runnerFS()
+	fsys["run/config.go"] = &fstest.MapFile{Data: []byte("package run\n\ntype Options struct{}\n")}
+	fsys["run/jobs.go"] = &fstest.MapFile{Data: []byte("package run\n\nfunc (r *Runner) Stop() {}\n")}
+	diags := (&Rule{}).Check(newTestFile(t, directive("symbol: Runner.Stop\n"), fsys))
+	require.Len(t, diags, 1)
+	assert.Equal(t, `symbol "Runner.Stop" not found in "run/runner.go"; it is now declared in "run/jobs.go"`,
+		diags[0].Message)
+}
+
+func TestCheck_FileErrors(t *testing.T) {
+	r := &Rule{}
+	diags := r.Check(newTestFile(t, directive("symbol: X\n"), fstest.MapFS{}))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, `cannot read snippet file "run/runner.go"`)
+
+	broken := fstest.MapFS{"run/runner.go": {Data: []byte("package run\nfunc {\n")}}
+	diags = r.Check(newTestFile(t, directive("symbol: X\n"), broken))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, `cannot extract "X" from snippet file "run/runner.go"`)
+
+	src := "# Doc\n\n<?snippet\nfile: ../x.go\nsymbol: X\n?>\n<?/snippet?>\n"
+	diags = r.Check(newTestFile(t, src, runnerFS()))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "snippet file path escapes project root", diags[0].Message)
+}
+
+func TestCheck_NoFS(t *testing.T) {
+	f, err := lint.NewFile("doc.md", []byte(directive("symbol: X\n")))
+	require.NoError(t, err)
+	assert.Nil(t, (&Rule{}).Check(f))
+	assert.Equal(t, f.Source, (&Rule{}).Fix(f))
+}
+
+func TestMinFenceLen(t *testing.T) {
+	assert.Equal(t, 3, minFenceLen("x := 1"))
+	assert.Equal(t, 4, minFenceLen("s := `a`\n// ```go"))
+}
@@ -0,0 +1,182 @@
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// errNotFound reports that the source file has no declaration of the
+// requested symbol.
+var errNotFound = errors.New("symbol not found")
+
+// symbol names a top-level declaration: a function, type, constant, or
+// variable by name, or a method by receiver type and name.
+type symbol struct {
+	recv string
+	name string
+}
+
+// parseSymbol parses "Name", "Recv.Method", or "(*Recv).Method".
+func parseSymbol(s string) symbol {
+	s = strings.TrimSpace(s)
+	recv, name, ok := strings.Cut(s, ".")
+	if !ok {
+		return symbol{name: s}
+	}
+	recv = strings.TrimSuffix(strings.TrimPrefix(recv, "("), ")")
+	return symbol{recv: strings.TrimPrefix(recv, "*"), name: name}
+}
+
+func (s symbol) valid() bool {
+	return token.IsIdentifier(s.name) && (s.recv == "" || token.IsIdentifier(s.recv))
+}
+
+func (s symbol) String() string {
+	if s.recv == "" {
+		return s.name
+	}
+	return s.recv + "." + s.name
+}
+
+// options selects the parts of a declaration the snippet shows.
+type options struct {
+	doc  bool // leading doc comment
+	body bool // function or method body
+}
+
+// extract returns the Go source of sym's declaration in src, gofmt'd.
+// A constant, variable, or type declared inside a parenthesized group
+// is quoted on its own, with its keyword.
+//
+// The whole file is gofmt'd first and the declaration is cut from it
+// verbatim, so doc comments keep their paragraphs. Only a spec lifted
+// out of a group is formatted again, to undo the group's indent.
+func extract(src []byte, sym symbol, opts options) (string, error) {
+	if out, err := format.Source(src); err == nil {
+		src = out
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	cut := func(from, to token.Pos) string {
+		return string(src[fset.Position(from).Offset:fset.Position(to).Offset])
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name == sym.name && receiverName(d) == sym.recv {
+				return extractFunc(d, opts, cut), nil
+			}
+		case *ast.GenDecl:
+			if sym.recv != "" {
+				continue
+			}
+			if spec, doc, comment := findSpec(d, sym.name); spec != nil {
+				return extractSpec(d, spec, doc, comment, sym, opts, cut)
+			}
+		}
+	}
+	return "", errNotFound
+}
+
+func extractFunc(d *ast.FuncDecl, opts options, cut func(from, to token.Pos) string) string {
+	start, end := d.Pos(), d.End()
+	if opts.doc && d.Doc != nil {
+		start = d.Doc.Pos()
+	}
+	if !opts.body && d.Body != nil {
+		end = d.Body.Lbrace
+	}
+	return strings.TrimRight(cut(start, end), " \t\n")
+}
+
+func extractSpec(d *ast.GenDecl, spec ast.Spec, doc, comment *ast.CommentGroup,
+	sym symbol, opts options, cut func(from, to token.Pos) string,
+) (string, error) {
+	if !opts.body {
+		return "", fmt.Errorf(`"body" applies only to functions and methods, and %q is a %s`,
+			sym.name, d.Tok)
+	}
+	end := spec.End()
+	if comment != nil {
+		end = comment.End()
+	}
+	// An ungrouped declaration carries its doc comment on the GenDecl
+	// and needs no reformatting.
+	if !d.Lparen.IsValid() {
+		start := d.Pos()
+		if opts.doc && d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+		return cut(start, end), nil
+	}
+	text := d.Tok.String() + " " + cut(spec.Pos(), end)
+	if opts.doc && doc != nil {
+		text = cut(doc.Pos(), doc.End()) + "\n" + text
+	}
+	return ungrouped(text), nil
+}
+
+// findSpec returns the spec of d that declares name, with its doc and
+// trailing line comments, or a nil spec when d does not declare it.
+func findSpec(d *ast.GenDecl, name string) (ast.Spec, *ast.CommentGroup, *ast.CommentGroup) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if s.Name.Name == name {
+				return s, s.Doc, s.Comment
+			}
+		case *ast.ValueSpec:
+			for _, n := range s.Names {
+				if n.Name == name {
+					return s, s.Doc, s.Comment
+				}
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// receiverName returns the base type name of fn's receiver, without
+// pointer or type parameters, or "" for a plain function.
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// ungrouped gofmts a spec lifted out of a group, which re-indents it
+// and drops the alignment it shared with its neighbours. The spec is
+// wrapped in a file of its own, since format.Source on a bare fragment
+// rewrites doc comments. Text gofmt rejects is kept as is.
+func ungrouped(text string) string {
+	const header = "package p\n\n"
+	if out, err := format.Source([]byte(header + text)); err == nil {
+		text = strings.TrimPrefix(string(out), header)
+	}
+	return strings.TrimRight(text, " \t\n")
+}
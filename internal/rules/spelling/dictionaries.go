@@ -0,0 +1,46 @@
+package spelling
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jeduden/mdsmith/internal/hunspell"
+)
+
+// cachedDictionary is a loaded dictionary with the stat of its .dic
+// file when it was read.
+type cachedDictionary struct {
+	mod  time.Time
+	size int64
+	dict *hunspell.Dictionary
+	err  error
+}
+
+// dictionaries caches loaded dictionaries by path across files and
+// runs, so a long-lived process such as the language server parses
+// each one once. An entry is reloaded when its .dic file changes.
+var dictionaries = struct {
+	sync.Mutex
+	byPath map[string]*cachedDictionary
+}{byPath: map[string]*cachedDictionary{}}
+
+func loadDictionary(path string) (*hunspell.Dictionary, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	dictionaries.Lock()
+	c := dictionaries.byPath[path]
+	dictionaries.Unlock()
+	if c != nil && c.mod.Equal(info.ModTime()) && c.size == info.Size() {
+		return c.dict, c.err
+	}
+	d, err := hunspell.Load(path)
+	dictionaries.Lock()
+	dictionaries.byPath[path] = &cachedDictionary{
+		mod: info.ModTime(), size: info.Size(), dict: d, err: err,
+	}
+	dictionaries.Unlock()
+	return d, err
+}
@@ -0,0 +1,246 @@
+// Package spelling implements MDS060, which checks prose words against
+// Hunspell dictionaries and a project word list.
+package spelling
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/hunspell"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/placeholders"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/yuin/goldmark/ast"
+)
+
+func init() {
+	rule.Register(&Rule{})
+}
+
+// maxSuggestions bounds the replacements attached to a diagnostic.
+const maxSuggestions = 5
+
+// Rule reports prose words that no configured dictionary knows and the
+// project word list does not allow.
+type Rule struct {
+	// Dictionaries are paths to Hunspell .dic files; each needs its
+	// .aff file beside it. Relative paths resolve against the project
+	// root. A word is correct when any dictionary accepts it.
+	Dictionaries []string
+	// Words is the project allowlist. It appends across config layers
+	// so kind layers extend the project vocabulary. An all-lowercase
+	// entry also allows the capitalised and upper-case forms.
+	Words []string
+	// Placeholders lists placeholder tokens whose spans are skipped.
+	Placeholders []string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS060" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "spelling" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "prose" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if len(r.Dictionaries) == 0 {
+		return []lint.Diagnostic{r.diag(f, 1, 1, "no dictionaries configured; set dictionaries")}
+	}
+	dicts := make([]*hunspell.Dictionary, 0, len(r.Dictionaries))
+	for _, p := range r.Dictionaries {
+		d, err := loadDictionary(resolvePath(f.RootDir, p))
+		if err != nil {
+			return []lint.Diagnostic{r.diag(f, 1, 1, fmt.Sprintf("cannot load dictionary %q: %v", p, err))}
+		}
+		dicts = append(dicts, d)
+	}
+	c := &checker{
+		dicts: dicts,
+		allow: newAllowlist(r.Words),
+		skip:  skippedSpans(f.Source, r.Placeholders),
+	}
+	var diags []lint.Diagnostic
+	for _, w := range r.collectWords(f) {
+		text := string(f.Source[w[0]:w[1]])
+		if overlaps(c.skip, w) || c.correct(text) {
+			continue
+		}
+		line, col := f.LineOfOffset(w[0]), f.ColumnOfOffset(w[0])
+		d := r.diag(f, line, col, fmt.Sprintf("unknown word %q", text))
+		d.EndColumn = col + len(text)
+		d.Suggestions = c.suggest(text)
+		if len(d.Suggestions) > 0 {
+			d.Message += fmt.Sprintf("; did you mean %q?", d.Suggestions[0])
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+func (r *Rule) diag(f *lint.File, line, col int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     f.Path,
+		Line:     line,
+		Column:   col,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Warning,
+		Message:  msg,
+	}
+}
+
+// resolvePath anchors a relative dictionary path at the project root.
+func resolvePath(root, p string) string {
+	if filepath.IsAbs(p) || root == "" {
+		return p
+	}
+	return filepath.Join(root, p)
+}
+
+// collectWords returns the byte ranges of the words to check: those
+// in prose text, headings, link text, and image alt text. Code, HTML,
+// and autolinks are skipped, as is front matter, which is not part of
+// f.Source. A word split across text nodes is returned once.
+func (r *Rule) collectWords(f *lint.File) [][2]int {
+	var out [][2]int
+	seen := map[int]bool{}
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.AutoLink, *ast.CodeSpan, *ast.FencedCodeBlock, *ast.CodeBlock,
+			*ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			for _, w := range splitWords(f.Source, v.Segment.Start, v.Segment.Stop) {
+				if !seen[w[0]] {
+					seen[w[0]] = true
+					out = append(out, w)
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+// checker decides whether a word is spelled correctly.
+type checker struct {
+	dicts []*hunspell.Dictionary
+	allow allowlist
+	skip  [][2]int
+}
+
+func (c *checker) correct(w string) bool {
+	if c.allow.has(w) {
+		return true
+	}
+	for _, d := range c.dicts {
+		if d.Check(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest merges the suggestions of every dictionary, best first.
+func (c *checker) suggest(w string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, d := range c.dicts {
+		for _, s := range d.Suggest(w, maxSuggestions) {
+			if !seen[s] && len(out) < maxSuggestions {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// allowlist holds the project words: exact entries, and the
+// all-lowercase entries that match any casing.
+type allowlist struct {
+	exact, folded map[string]bool
+}
+
+func newAllowlist(words []string) allowlist {
+	a := allowlist{exact: map[string]bool{}, folded: map[string]bool{}}
+	for _, w := range words {
+		w = strings.ReplaceAll(w, "’", "'")
+		a.exact[w] = true
+		if strings.ToLower(w) == w {
+			a.folded[w] = true
+		}
+	}
+	return a
+}
+
+func (a allowlist) has(w string) bool {
+	w = strings.ReplaceAll(w, "’", "'")
+	return a.exact[w] || a.folded[strings.ToLower(w)]
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "dictionaries", "words":
+			list, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("spelling: %s must be a list of strings, got %T", k, v)
+			}
+			if k == "words" {
+				r.Words = list
+			} else {
+				r.Dictionaries = list
+			}
+		case "placeholders":
+			toks, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("spelling: placeholders must be a list of strings, got %T", v)
+			}
+			if err := placeholders.Validate(toks); err != nil {
+				return fmt.Errorf("spelling: %w", err)
+			}
+			r.Placeholders = toks
+		default:
+			return fmt.Errorf("spelling: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"dictionaries": []string{},
+		"words":        []string{},
+		"placeholders": []string{},
+	}
+}
+
+// SettingMergeMode implements rule.ListMerger. The word list and the
+// placeholder vocabulary append across config layers so a kind can
+// extend them; dictionaries replace, so a kind can switch language.
+func (r *Rule) SettingMergeMode(key string) rule.MergeMode {
+	if key == "words" || key == "placeholders" {
+		return rule.MergeAppend
+	}
+	return rule.MergeReplace
+}
+
+var (
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+	_ rule.ListMerger   = (*Rule)(nil)
+)
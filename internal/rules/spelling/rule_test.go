@@ -0,0 +1,125 @@
+package spelling
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	f.RootDir = "testdata"
+	return f
+}
+
+func ruleWith(words ...string) *Rule {
+	return &Rule{Dictionaries: []string{"en.dic"}, Words: words}
+}
+
+// unknown returns the flagged words of diags.
+func unknown(f *lint.File, diags []lint.Diagnostic) []string {
+	var out []string
+	for _, d := range diags {
+		line := f.Lines[d.Line-1]
+		out = append(out, string(line[d.Column-1:d.EndColumn-1]))
+	}
+	return out
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS060", r.ID())
+	assert.Equal(t, "spelling", r.Name())
+	assert.Equal(t, "prose", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestCheck_ReportsUnknownWordWithSuggestions(t *testing.T) {
+	f := newFile(t, "# The Cat\n\nThe cat sat on teh mat.\n")
+	diags := ruleWith().Check(f)
+	require.Len(t, diags, 1)
+	d := diags[0]
+	assert.Equal(t, 3, d.Line)
+	assert.Equal(t, 16, d.Column)
+	assert.Equal(t, 19, d.EndColumn)
+	assert.Equal(t, `unknown word "teh"; did you mean "the"?`, d.Message)
+	assert.Equal(t, "the", d.Suggestions[0])
+	assert.Equal(t, lint.Warning, d.Severity)
+}
+
+func TestCheck_Skips(t *testing.T) {
+	src := "The cat sat.\n\n" +
+		"See `qwzx` and <!-- qwzx --> and <https://qwzx.example>.\n\n" +
+		"See https://qwzx.example/zzq and docs/qwzx.md and qwzx@example.com.\n\n" +
+		"See v2beta and camelCase and HTML and a_b and {qwzx}.\n\n" +
+		"```\nqwzx\n```\n"
+	f := newFile(t, src)
+	r := ruleWith()
+	r.Placeholders = []string{"var-token"}
+	assert.Empty(t, unknown(f, r.Check(f)))
+}
+
+func TestCheck_ChecksLinkAndAltText(t *testing.T) {
+	f := newFile(t, "See [qwzx](https://a.example) and ![zzq](a.png).\n")
+	assert.Equal(t, []string{"qwzx", "zzq"}, unknown(f, ruleWith().Check(f)))
+}
+
+func TestCheck_WordsAndApostrophes(t *testing.T) {
+	f := newFile(t, "The cat's files don't see well-known kubectl Kubectl GitHub Github.\n")
+	assert.Equal(t, []string{"Github"}, unknown(f, ruleWith("kubectl", "GitHub").Check(f)))
+}
+
+func TestCheck_FrontMatterNotChecked(t *testing.T) {
+	f, err := lint.NewFileFromSource("test.md", []byte("---\nqwzx: zzq\n---\nThe cat.\n"), true)
+	require.NoError(t, err)
+	f.RootDir = "testdata"
+	assert.Empty(t, ruleWith().Check(f))
+}
+
+func TestCheck_DictionaryProblems(t *testing.T) {
+	f := newFile(t, "The cat.\n")
+	diags := (&Rule{}).Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "no dictionaries configured; set dictionaries", diags[0].Message)
+
+	diags = (&Rule{Dictionaries: []string{"missing.dic"}}).Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, `cannot load dictionary "missing.dic"`)
+}
+
+func TestLoadDictionary_ReloadsChangedFile(t *testing.T) {
+	dir := t.TempDir()
+	aff, err := os.ReadFile(filepath.Join("testdata", "en.aff"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "x.aff"), aff, 0o644))
+	dic := filepath.Join(dir, "x.dic")
+	require.NoError(t, os.WriteFile(dic, []byte("1\ncat\n"), 0o644))
+	d, err := loadDictionary(dic)
+	require.NoError(t, err)
+	assert.False(t, d.Check("dog"))
+	require.NoError(t, os.WriteFile(dic, []byte("2\ncat\ndog\n"), 0o644))
+	d, err = loadDictionary(dic)
+	require.NoError(t, err)
+	assert.True(t, d.Check("dog"))
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"dictionaries": []any{"a.dic"},
+		"words":        []any{"mdsmith"},
+		"placeholders": []any{"var-token"},
+	}))
+	assert.Equal(t, []string{"a.dic"}, r.Dictionaries)
+	assert.Equal(t, []string{"mdsmith"}, r.Words)
+	assert.Equal(t, []string{"var-token"}, r.Placeholders)
+	assert.Error(t, r.ApplySettings(map[string]any{"words": "x"}))
+	assert.Error(t, r.ApplySettings(map[string]any{"placeholders": []any{"nope"}}))
+	assert.Error(t, r.ApplySettings(map[string]any{"bogus": true}))
+}
@@ -0,0 +1,147 @@
+package spelling
+
+import (
+	"regexp"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jeduden/mdsmith/internal/placeholders"
+)
+
+// skipPatterns match text that is not prose even outside code: URLs,
+// email addresses, and dotted or slashed names like file paths,
+// domains, and package names.
+var skipPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(?:\b[a-z][a-z0-9+.-]*://|\bwww\.|\bmailto:)[^\s<>()\[\]]+`),
+	regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`),
+	regexp.MustCompile(`[\w~-]*(?:[./\\][\w~-]+)+/?`),
+}
+
+// skippedSpans returns the merged, sorted byte ranges of src that are
+// never spell-checked: URLs, paths, emails, and placeholder tokens.
+func skippedSpans(src []byte, tokens []string) [][2]int {
+	var spans [][2]int
+	for _, re := range skipPatterns {
+		for _, m := range re.FindAllIndex(src, -1) {
+			if hasDotOrSlash(src[m[0]:m[1]]) {
+				spans = append(spans, [2]int{m[0], m[1]})
+			}
+		}
+	}
+	spans = append(spans, placeholders.BodyTokenSpans(string(src), tokens)...)
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	merged := spans[:0]
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], s[1])
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// hasDotOrSlash reports whether b joins two name parts with '.', '/',
+// '\', ':' or '@', so "end." at a sentence end is not skipped.
+func hasDotOrSlash(b []byte) bool {
+	for i := 1; i+1 < len(b); i++ {
+		switch b[i] {
+		case '.', '/', '\\', ':', '@':
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether w intersects any of the sorted, disjoint
+// spans.
+func overlaps(spans [][2]int, w [2]int) bool {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i][1] > w[0] })
+	return i < len(spans) && spans[i][0] < w[1]
+}
+
+// splitWords returns the byte ranges of the words in src[start:stop]
+// that a dictionary can judge. A word is a run of letters with
+// apostrophes inside it; hyphens separate words. Runs that contain
+// digits or underscores, camelCase names, and all-caps acronyms are
+// left out. A run is judged whole even where it crosses start or
+// stop, since goldmark splits text nodes at '_' and similar.
+func splitWords(src []byte, start, stop int) [][2]int {
+	var out [][2]int
+	for i := start; i < stop; {
+		r, n := utf8.DecodeRune(src[i:])
+		if !isWordRune(r) {
+			i += n
+			continue
+		}
+		from := i
+		if i == start {
+			from = runStart(src, i)
+		}
+		end := runEnd(src, i)
+		if checkable(src[from:end]) {
+			out = append(out, [2]int{from, end})
+		}
+		i = end
+	}
+	return out
+}
+
+// runStart returns the start of the word run that contains offset i.
+func runStart(src []byte, i int) int {
+	for i > 0 {
+		r, n := utf8.DecodeLastRune(src[:i])
+		if !isWordRune(r) {
+			break
+		}
+		i -= n
+	}
+	return i
+}
+
+// runEnd returns the end of the word run starting at start. An
+// apostrophe belongs to the run only between two word runes.
+func runEnd(text []byte, start int) int {
+	end := start
+	for end < len(text) {
+		r, n := utf8.DecodeRune(text[end:])
+		if isWordRune(r) {
+			end += n
+			continue
+		}
+		if r == '\'' || r == '’' {
+			if next, _ := utf8.DecodeRune(text[end+n:]); end+n < len(text) && isWordRune(next) {
+				end += n
+				continue
+			}
+		}
+		break
+	}
+	return end
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// checkable reports whether word is plain enough to look up: no digits
+// or underscores, and either lower case, capitalised, or a single
+// capital letter.
+func checkable(word []byte) bool {
+	upper, lower := 0, 0
+	for i, r := range string(word) {
+		switch {
+		case unicode.IsDigit(r), r == '_':
+			return false
+		case unicode.IsUpper(r):
+			if i > 0 && lower > 0 {
+				return false // camelCase
+			}
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	return lower > 0 || upper == 1
+}
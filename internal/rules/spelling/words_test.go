@@ -0,0 +1,39 @@
+package spelling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func words(text string) []string {
+	var out []string
+	for _, w := range splitWords([]byte(text), 0, len(text)) {
+		out = append(out, text[w[0]:w[1]])
+	}
+	return out
+}
+
+func TestSplitWords(t *testing.T) {
+	assert.Equal(t, []string{"don't", "rock’n’roll", "well", "known", "Café", "I"},
+		words("'don't' rock’n’roll well-known Café I"))
+	assert.Empty(t, words("v2 a_b camelCase HTML iPhone 42"))
+}
+
+func TestSplitWords_RunCrossesSegment(t *testing.T) {
+	src := []byte("see a_b")
+	assert.Empty(t, splitWords(src, 6, 7))
+	assert.Equal(t, [][2]int{{0, 3}}, splitWords(src, 0, 5))
+}
+
+func TestSkippedSpans(t *testing.T) {
+	src := []byte("see https://a.example/x, a/b.md, me@a.example and {name}. End.")
+	spans := skippedSpans(src, []string{"var-token"})
+	var got []string
+	for _, s := range spans {
+		got = append(got, string(src[s[0]:s[1]]))
+	}
+	assert.Equal(t, []string{"https://a.example/x,", "a/b.md", "me@a.example", "{name}"}, got)
+	assert.True(t, overlaps(spans, [2]int{4, 9}))
+	assert.False(t, overlaps(spans, [2]int{0, 3}))
+}
@@ -0,0 +1,172 @@
+package table
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+)
+
+// formats are the data file formats the directive reads.
+var formats = []string{"csv", "json", "yaml"}
+
+// inferFormat returns the format named by params["format"], or the one
+// the file extension implies, or "" when neither gives a known format.
+func inferFormat(params map[string]string) string {
+	if f, ok := params["format"]; ok {
+		return strings.ToLower(strings.TrimSpace(f))
+	}
+	switch strings.ToLower(path.Ext(params["file"])) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return ""
+}
+
+// record is one row of a data file. display holds each cell as the
+// table shows it; typed holds the values `where` filters on.
+type record struct {
+	display map[string]string
+	typed   map[string]any
+}
+
+// dataset is a parsed data file: its column names in file order and
+// its rows.
+type dataset struct {
+	keys []string
+	rows []record
+}
+
+func (d *dataset) hasKey(k string) bool {
+	for _, key := range d.keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// loadData parses data in the given format. A CSV file's first row
+// names the columns. A JSON or YAML file holds a list of objects whose
+// keys, in first-seen order, name the columns.
+func loadData(data []byte, format string) (*dataset, error) {
+	if format == "csv" {
+		return loadCSV(data)
+	}
+	// JSON is read through the YAML parser, which accepts it and keeps
+	// object keys in file order.
+	return loadRecords(data)
+}
+
+func loadCSV(data []byte) (*dataset, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return &dataset{}, nil
+	}
+	ds := &dataset{keys: rows[0]}
+	for i, k := range ds.keys {
+		ds.keys[i] = strings.TrimSpace(k)
+		if ds.keys[i] == "" {
+			return nil, fmt.Errorf("header column %d has no name", i+1)
+		}
+	}
+	for _, row := range rows[1:] {
+		rec := record{display: map[string]string{}, typed: map[string]any{}}
+		for i, cell := range row {
+			rec.display[ds.keys[i]] = cell
+			rec.typed[ds.keys[i]] = csvValue(cell)
+		}
+		ds.rows = append(ds.rows, rec)
+	}
+	return ds, nil
+}
+
+// csvValue types a CSV cell for `where`, so `count > 2` and
+// `stable: true` work on CSV data as they do on YAML.
+func csvValue(s string) any {
+	t := strings.TrimSpace(s)
+	if n, err := strconv.Atoi(t); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(t, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return f
+	}
+	if t == "true" || t == "false" {
+		return t == "true"
+	}
+	return s
+}
+
+func loadRecords(data []byte) (*dataset, error) {
+	doc, err := yamlutil.UnmarshalNodeSafe(data)
+	if err != nil {
+		return nil, err
+	}
+	ds := &dataset{}
+	if len(doc.Content) == 0 {
+		return ds, nil
+	}
+	list := doc.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("want a list of objects at the top level")
+	}
+	seen := map[string]bool{}
+	for i, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("item %d is not an object", i+1)
+		}
+		rec := record{display: map[string]string{}, typed: map[string]any{}}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			k, v := item.Content[j].Value, item.Content[j+1]
+			if !seen[k] {
+				seen[k] = true
+				ds.keys = append(ds.keys, k)
+			}
+			var typed any
+			if err := v.Decode(&typed); err != nil {
+				return nil, fmt.Errorf("item %d key %q: %w", i+1, k, err)
+			}
+			rec.typed[k] = typed
+			rec.display[k] = nodeText(v)
+		}
+		ds.rows = append(ds.rows, rec)
+	}
+	return ds, nil
+}
+
+// nodeText renders a value as a table cell: a scalar as written in the
+// file, a list of scalars joined with ", ", and anything else empty.
+func nodeText(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return ""
+		}
+		return n.Value
+	case yaml.SequenceNode:
+		parts := make([]string, 0, len(n.Content))
+		for _, c := range n.Content {
+			if c.Kind != yaml.ScalarNode {
+				return ""
+			}
+			parts = append(parts, c.Value)
+		}
+		return strings.Join(parts, ", ")
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package table
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/query"
+)
+
+// numericSortPrefix marks a sort key compared as numbers, as in
+// catalog: `numeric:since`, or `-numeric:since` for descending.
+const numericSortPrefix = "numeric:"
+
+// parseSort splits a sort value into its column, direction, and
+// whether it compares as numbers. An empty value keeps file order.
+func parseSort(v string) (key string, descending, numeric bool) {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, "-") {
+		descending = true
+		v = v[1:]
+	}
+	if strings.HasPrefix(v, numericSortPrefix) {
+		numeric = true
+		v = v[len(numericSortPrefix):]
+	}
+	return strings.TrimSpace(v), descending, numeric
+}
+
+// filterRows returns the rows whose values satisfy the CUE `where`
+// expression, or every row when expr is empty. Validate reports a
+// broken expression, so one that fails to compile filters nothing.
+func filterRows(rows []record, expr string) []record {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return slices.Clone(rows)
+	}
+	m, err := query.Compile(expr)
+	if err != nil {
+		return slices.Clone(rows)
+	}
+	var out []record
+	for _, r := range rows {
+		if m.Match(r.typed) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// sortRows orders rows by key, case-insensitively. When numeric is
+// set and every value parses as a number the rows compare as numbers;
+// otherwise the whole sort falls back to text, as catalog's does. Rows
+// with equal keys keep their file order.
+func sortRows(rows []record, key string, descending, numeric bool) {
+	if key == "" {
+		return
+	}
+	useNums := numeric
+	for _, r := range rows {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(r.display[key]), 64); err != nil {
+			useNums = false
+			break
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].display[key], rows[j].display[key]
+		var cmp int
+		if useNums {
+			x, _ := strconv.ParseFloat(strings.TrimSpace(a), 64)
+			y, _ := strconv.ParseFloat(strings.TrimSpace(b), 64)
+			switch {
+			case x < y:
+				cmp = -1
+			case x > y:
+				cmp = 1
+			}
+		} else {
+			cmp = strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+// renderTable writes rows as an unaligned Markdown table; the caller
+// aligns it with tablefmt.
+func renderTable(headers, cols []string, rows []record) string {
+	var b strings.Builder
+	writeRow(&b, headers, func(s string) string { return s })
+	sep := make([]string, len(cols))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeRow(&b, sep, func(s string) string { return s })
+	for _, r := range rows {
+		writeRow(&b, cols, func(c string) string { return r.display[c] })
+	}
+	return b.String()
+}
+
+func writeRow(b *strings.Builder, keys []string, cell func(string) string) {
+	b.WriteString("|")
+	for _, k := range keys {
+		b.WriteString(" " + escapeCell(cell(k)) + " |")
+	}
+	b.WriteString("\n")
+}
+
+// escapeCell keeps a value inside its cell: pipes are escaped and
+// line breaks become spaces.
+func escapeCell(s string) string {
+	s = strings.Join(strings.Fields(strings.ReplaceAll(s, "\r\n", "\n")), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,268 @@
+// Package table implements MDS079, the <?table?> generated-section
+// directive that renders a CSV, JSON, or YAML data file as a Markdown
+// table.
+package table
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/query"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/jeduden/mdsmith/internal/rules/tablefmt"
+)
+
+func init() {
+	rule.Register(&Rule{Pad: 1, SeparatorStyle: tablefmt.SeparatorSpaced})
+}
+
+// Rule checks and fixes <?table?>...<?/table?> generated sections.
+//
+// engineOnce serialises lazy engine init; the rule is a registered
+// singleton and the LSP server may call Check from concurrent
+// goroutines.
+//
+// Pad and SeparatorStyle mirror MDS025 (table-format)'s knobs, as
+// catalog's do, so a generated table is already in the canonical form
+// table-format expects.
+type Rule struct {
+	engineOnce     sync.Once
+	engine         *gensection.Engine
+	Pad            int
+	SeparatorStyle tablefmt.SeparatorStyle
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS079" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "table" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "directive" }
+
+// RuleID implements gensection.Directive.
+func (r *Rule) RuleID() string { return "MDS079" }
+
+// RuleName implements gensection.Directive.
+func (r *Rule) RuleName() string { return "table" }
+
+func (r *Rule) getEngine() *gensection.Engine {
+	r.engineOnce.Do(func() {
+		r.engine = gensection.NewEngine(r)
+	})
+	return r.engine
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	if f.FS == nil {
+		return nil
+	}
+	return r.getEngine().Check(f)
+}
+
+// Fix implements rule.FixableRule.
+func (r *Rule) Fix(f *lint.File) []byte {
+	if f.FS == nil {
+		return f.Source
+	}
+	return r.getEngine().Fix(f)
+}
+
+// Validate implements gensection.Directive.
+func (r *Rule) Validate(filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) []lint.Diagnostic {
+	if msg := validateParams(params); msg != "" {
+		return []lint.Diagnostic{makeDiag(filePath, line, msg)}
+	}
+	return nil
+}
+
+// Generate implements gensection.Directive.
+func (r *Rule) Generate(f *lint.File, filePath string, line int,
+	params map[string]string, _ map[string]gensection.ColumnConfig,
+) (string, []lint.Diagnostic) {
+	file := params["file"]
+	filePath = filepath.ToSlash(filePath)
+	readFS, readPath, msg := resolvePath(f, filePath, file)
+	if msg != "" {
+		return "", []lint.Diagnostic{makeDiag(filePath, line, msg)}
+	}
+	data, err := lint.ReadFSFileLimited(readFS, readPath, f.MaxInputBytes)
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("cannot read table file %q: %v", file, err))}
+	}
+	ds, err := loadData(data, inferFormat(params))
+	if err != nil {
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf("cannot parse table file %q: %v", file, err))}
+	}
+	cols := splitList(params["select"])
+	if len(cols) == 0 {
+		cols = ds.keys
+	}
+	if len(cols) == 0 {
+		// An empty CSV or a bare [] has nothing to build a header from.
+		if params["empty"] != "" {
+			return "\n" + gensection.EnsureTrailingNewline(params["empty"]) + "\n", nil
+		}
+		return "", []lint.Diagnostic{makeDiag(filePath, line,
+			fmt.Sprintf(`no columns in %q; add rows or set "empty"`, file))}
+	}
+	sortKey, descending, numeric := parseSort(params["sort"])
+	for _, c := range append(slices.Clone(cols), sortKey) {
+		if c != "" && !ds.hasKey(c) {
+			return "", []lint.Diagnostic{makeDiag(filePath, line,
+				fmt.Sprintf("column %q not found in %q; it has %s", c, file, quoteList(ds.keys)))}
+		}
+	}
+	rows := filterRows(ds.rows, params["where"])
+	sortRows(rows, sortKey, descending, numeric)
+	if len(rows) == 0 && params["empty"] != "" {
+		return "\n" + gensection.EnsureTrailingNewline(params["empty"]) + "\n", nil
+	}
+	headers := splitList(params["headers"])
+	if len(headers) == 0 {
+		headers = cols
+	}
+	content := tablefmt.FormatStringWithConfig(renderTable(headers, cols, rows), tablefmt.Config{
+		Pad:            r.Pad,
+		SeparatorStyle: r.SeparatorStyle,
+	})
+	return "\n" + content + "\n", nil
+}
+
+func validateParams(params map[string]string) string {
+	file, ok := params["file"]
+	if !ok || strings.TrimSpace(file) == "" {
+		return `table directive missing required "file" parameter`
+	}
+	if filepath.IsAbs(file) {
+		return "table directive has absolute file path"
+	}
+	if format := inferFormat(params); !slices.Contains(formats, format) {
+		if _, ok := params["format"]; ok {
+			return fmt.Sprintf(`table directive "format" must be one of csv, json, or yaml, got %q`, params["format"])
+		}
+		return fmt.Sprintf(`table directive cannot tell the format of %q; set "format" to csv, json, or yaml`, file)
+	}
+	for _, k := range []string{"select", "headers", "sort", "where"} {
+		if v, ok := params[k]; ok && strings.TrimSpace(v) == "" {
+			return fmt.Sprintf("table directive has empty %q value", k)
+		}
+	}
+	if h, ok := params["headers"]; ok {
+		n := len(splitList(h))
+		if want := len(splitList(params["select"])); n != want {
+			return fmt.Sprintf(`table directive "headers" has %d entries but "select" has %d`, n, want)
+		}
+	}
+	if v, ok := params["sort"]; ok {
+		if key, _, _ := parseSort(v); key == "" {
+			return fmt.Sprintf("table directive has invalid sort value %q", v)
+		}
+	}
+	if expr := strings.TrimSpace(params["where"]); expr != "" {
+		if _, err := query.Compile(expr); err != nil {
+			return fmt.Sprintf(`table directive has invalid "where" expression: %v`, err)
+		}
+	}
+	return ""
+}
+
+// splitList splits a list parameter. A YAML list arrives joined with
+// newlines; a plain string is split on commas.
+func splitList(v string) []string {
+	sep := ","
+	if strings.Contains(v, "\n") {
+		sep = "\n"
+	}
+	var out []string
+	for _, p := range strings.Split(v, sep) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func quoteList(keys []string) string {
+	if len(keys) == 0 {
+		return "no columns"
+	}
+	q := make([]string, len(keys))
+	for i, k := range keys {
+		q[i] = fmt.Sprintf("%q", k)
+	}
+	return strings.Join(q, ", ")
+}
+
+// resolvePath resolves file against the directory of filePath. With a
+// project root the path is read from RootFS and may not leave it;
+// without one, FS is the host file's directory and ".." is refused.
+func resolvePath(f *lint.File, filePath, file string) (fs.FS, string, string) {
+	resolved := path.Clean(path.Join(path.Dir(filePath), file))
+	if f.RootFS != nil {
+		if resolved == ".." || strings.HasPrefix(resolved, "../") {
+			return nil, "", "table file path escapes project root"
+		}
+		return f.RootFS, resolved, ""
+	}
+	for _, elem := range strings.Split(file, "/") {
+		if elem == ".." {
+			return nil, "", `table file path contains ".." but project root is not configured`
+		}
+	}
+	return f.FS, path.Clean(file), ""
+}
+
+func makeDiag(file string, line int, msg string) lint.Diagnostic {
+	return gensection.MakeDiag("MDS079", "table", file, line, msg)
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "pad":
+			n, ok := settings.ToInt(v)
+			if !ok {
+				return fmt.Errorf("table: pad must be an integer, got %T", v)
+			}
+			if n < 0 {
+				return fmt.Errorf("table: pad must be non-negative, got %d", n)
+			}
+			r.Pad = n
+		case "separator-style":
+			style, err := tablefmt.ParseSeparatorStyle(v, "table")
+			if err != nil {
+				return err
+			}
+			r.SeparatorStyle = style
+		default:
+			return fmt.Errorf("table: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"pad":             1,
+		"separator-style": "spaced",
+	}
+}
+
+var _ rule.Configurable = (*Rule)(nil)
@@ -0,0 +1,236 @@
+package table
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rules/tablefmt"
+)
+
+const featuresCSV = "Feature,Linux,macOS,Since\n" +
+	"Watch mode,yes,yes,12\n" +
+	"LSP,yes,partial,3\n" +
+	"\"Pipes | tees\",no,yes,7\n"
+
+func newRule() *Rule { return &Rule{Pad: 1, SeparatorStyle: tablefmt.SeparatorSpaced} }
+
+func newTestFile(t *testing.T, source string, fsys fstest.MapFS) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("docs/doc.md", []byte(source))
+	require.NoError(t, err)
+	f.FS = fsys
+	f.RootFS = fsys
+	return f
+}
+
+func directive(file, params string) string {
+	return "# Doc\n\n<?table\nfile: " + file + "\n" + params + "?>\n<?/table?>\n"
+}
+
+// generated runs Fix on a fresh table directive and returns the body
+// between its markers.
+func generated(t *testing.T, fsys fstest.MapFS, file, params string) string {
+	t.Helper()
+	src := directive(file, params)
+	got := string(newRule().Fix(newTestFile(t, src, fsys)))
+	head := "# Doc\n\n<?table\nfile: " + file + "\n" + params + "?>\n"
+	require.Contains(t, got, head)
+	body := got[len(head):]
+	require.Contains(t, body, "<?/table?>\n")
+	return body[:len(body)-len("<?/table?>\n")]
+}
+
+func featuresFS() fstest.MapFS {
+	return fstest.MapFS{"docs/data/features.csv": {Data: []byte(featuresCSV)}}
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := newRule()
+	assert.Equal(t, "MDS079", r.ID())
+	assert.Equal(t, "table", r.Name())
+	assert.Equal(t, "directive", r.Category())
+}
+
+func TestGenerate_CSVAllColumns(t *testing.T) {
+	got := generated(t, featuresFS(), "data/features.csv", "")
+	assert.Equal(t, "\n"+
+		"| Feature       | Linux | macOS   | Since |\n"+
+		"| ------------- | ----- | ------- | ----- |\n"+
+		"| Watch mode    | yes   | yes     | 12    |\n"+
+		"| LSP           | yes   | partial | 3     |\n"+
+		"| Pipes \\| tees | no    | yes     | 7     |\n"+
+		"\n", got)
+	assert.Empty(t, tablefmt.Violations(gensection.SplitLines([]byte(got)), nil, tablefmt.Config{Pad: 1}),
+		"output is already in table-format's canonical form")
+}
+
+func TestGenerate_SelectHeadersWhereSort(t *testing.T) {
+	got := generated(t, featuresFS(), "data/features.csv",
+		"select: [Feature, Since]\nheaders: [Name, Since version]\n"+
+			"where: 'Linux: \"yes\"'\nsort: numeric:Since\n")
+	assert.Equal(t, "\n"+
+		"| Name       | Since version |\n"+
+		"| ---------- | ------------- |\n"+
+		"| LSP        | 3             |\n"+
+		"| Watch mode | 12            |\n"+
+		"\n", got)
+
+	got = generated(t, featuresFS(), "data/features.csv",
+		"select: Feature, Since\nwhere: 'Since: >5'\nsort: -Feature\n")
+	assert.Contains(t, got, "| Watch mode    | 12    |\n| Pipes \\| tees | 7     |\n")
+}
+
+func TestGenerate_YAMLAndJSON(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/data/os.yaml": {Data: []byte("- name: Linux\n  arch: [amd64, arm64]\n  stable: true\n" +
+			"- name: Plan 9\n  stable: false\n  note: ~\n")},
+		"docs/data/os.json": {Data: []byte(`[{"name": "Linux", "tier": 1}, {"tier": 2, "name": "BSD"}]`)},
+	}
+	got := generated(t, fsys, "data/os.yaml", "")
+	assert.Equal(t, "\n"+
+		"| name   | arch         | stable | note |\n"+
+		"| ------ | ------------ | ------ | ---- |\n"+
+		"| Linux  | amd64, arm64 | true   |      |\n"+
+		"| Plan 9 |              | false  |      |\n"+
+		"\n", got)
+
+	got = generated(t, fsys, "data/os.yaml", "select: [name]\nwhere: 'stable: true'\n")
+	assert.Equal(t, "\n| name  |\n| ----- |\n| Linux |\n\n", got)
+
+	got = generated(t, fsys, "data/os.json", "where: 'tier: <2'\n")
+	assert.Equal(t, "\n| name  | tier |\n| ----- | ---- |\n| Linux | 1    |\n\n", got)
+}
+
+func TestGenerate_EmptyFallback(t *testing.T) {
+	got := generated(t, featuresFS(), "data/features.csv", "where: 'Since: >100'\nempty: No features yet.\n")
+	assert.Equal(t, "\nNo features yet.\n\n", got)
+
+	got = generated(t, featuresFS(), "data/features.csv", "select: [Feature]\nwhere: 'Since: >100'\n")
+	assert.Equal(t, "\n| Feature |\n| ------- |\n\n", got)
+}
+
+func TestGenerate_NoColumns(t *testing.T) {
+	r := newRule()
+	for _, tt := range []struct{ name, file, data string }{
+		{"empty csv", "data/x.csv", ""},
+		{"empty json", "data/x.json", "[]"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"docs/" + tt.file: {Data: []byte(tt.data)}}
+			diags := r.Check(newTestFile(t, directive(tt.file, ""), fsys))
+			require.Len(t, diags, 1)
+			assert.Equal(t, `no columns in "`+tt.file+`"; add rows or set "empty"`, diags[0].Message)
+			assert.Equal(t, 3, diags[0].Line)
+
+			got := generated(t, fsys, tt.file, "empty: No data yet.\n")
+			assert.Equal(t, "\nNo data yet.\n\n", got)
+		})
+	}
+}
+
+func TestCheck_UpToDateAndStale(t *testing.T) {
+	r := newRule()
+	fixed := r.Fix(newTestFile(t, directive("data/features.csv", "sort: Feature\n"), featuresFS()))
+	assert.Empty(t, r.Check(newTestFile(t, string(fixed), featuresFS())))
+
+	changed := fstest.MapFS{"docs/data/features.csv": {Data: []byte(featuresCSV + "Export,yes,yes,20\n")}}
+	diags := r.Check(newTestFile(t, string(fixed), changed))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "generated section is out of date", diags[0].Message)
+}
+
+func TestCheck_DataErrors(t *testing.T) {
+	r := newRule()
+	for _, tt := range []struct {
+		name   string
+		fsys   fstest.MapFS
+		params string
+		want   string
+	}{
+		{"missing column", featuresFS(), "select: [Feature, Windows]\n",
+			`column "Windows" not found in "data/features.csv"; it has "Feature", "Linux", "macOS", "Since"`},
+		{"missing sort column", featuresFS(), "sort: Windows\n", `column "Windows" not found`},
+		{"missing file", fstest.MapFS{}, "", `cannot read table file "data/features.csv"`},
+		{"ragged csv", fstest.MapFS{"docs/data/features.csv": {Data: []byte("a,b\n1\n")}}, "",
+			`cannot parse table file "data/features.csv"`},
+		{"unnamed header", fstest.MapFS{"docs/data/features.csv": {Data: []byte("a,\n1,2\n")}}, "",
+			"header column 2 has no name"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := r.Check(newTestFile(t, directive("data/features.csv", tt.params), tt.fsys))
+			require.Len(t, diags, 1)
+			assert.Contains(t, diags[0].Message, tt.want)
+			assert.Equal(t, 3, diags[0].Line)
+		})
+	}
+
+	notList := fstest.MapFS{"docs/data/x.yaml": {Data: []byte("name: Linux\n")}}
+	diags := r.Check(newTestFile(t, directive("data/x.yaml", ""), notList))
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "want a list of objects")
+
+	diags = r.Check(newTestFile(t, directive("../../x.csv", ""), featuresFS()))
+	require.Len(t, diags, 1)
+	assert.Equal(t, "table file path escapes project root", diags[0].Message)
+}
+
+func TestValidate(t *testing.T) {
+	r := newRule()
+	ok := map[string]string{"file": "a.txt", "format": "csv", "select": "a\nb", "headers": "A\nB",
+		"sort": "-numeric:a", "where": `a: "x"`}
+	assert.Empty(t, r.Validate("doc.md", 3, ok, nil))
+	for _, tt := range []struct {
+		params map[string]string
+		want   string
+	}{
+		{map[string]string{}, `missing required "file"`},
+		{map[string]string{"file": "/abs/a.csv"}, "absolute file path"},
+		{map[string]string{"file": "a.txt"}, `cannot tell the format of "a.txt"`},
+		{map[string]string{"file": "a.csv", "format": "toml"}, `"format" must be one of csv, json, or yaml`},
+		{map[string]string{"file": "a.csv", "select": " "}, `empty "select" value`},
+		{map[string]string{"file": "a.csv", "headers": "A"}, `"headers" has 1 entries but "select" has 0`},
+		{map[string]string{"file": "a.csv", "sort": "-"}, `invalid sort value "-"`},
+		{map[string]string{"file": "a.csv", "where": "a: ("}, `invalid "where" expression`},
+	} {
+		diags := r.Validate("doc.md", 3, tt.params, nil)
+		require.Len(t, diags, 1, tt.want)
+		assert.Contains(t, diags[0].Message, tt.want)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{"a", "b c"}, splitList("a, b c"))
+	assert.Equal(t, []string{"a, b", "c"}, splitList("a, b\nc"))
+	assert.Nil(t, splitList(""))
+}
+
+func TestEscapeCell(t *testing.T) {
+	assert.Equal(t, `a \| b`, escapeCell("a | b"))
+	assert.Equal(t, "one two", escapeCell("one\r\ntwo"))
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	assert.Equal(t, 1, r.Pad)
+	require.NoError(t, r.ApplySettings(map[string]any{"pad": 0, "separator-style": "compact"}))
+	assert.Equal(t, 0, r.Pad)
+	assert.Equal(t, tablefmt.SeparatorCompact, r.SeparatorStyle)
+	assert.ErrorContains(t, r.ApplySettings(map[string]any{"pad": -1}), "non-negative")
+	assert.ErrorContains(t, r.ApplySettings(map[string]any{"align": "left"}), "unknown setting")
+
+	got := generated(t, featuresFS(), "data/features.csv", "select: [Feature]\nwhere: 'Feature: \"LSP\"'\n")
+	assert.Equal(t, "\n| Feature |\n| ------- |\n| LSP     |\n\n", got)
+}
+
+func TestCheck_NoFS(t *testing.T) {
+	f, err := lint.NewFile("doc.md", []byte(directive("a.csv", "")))
+	require.NoError(t, err)
+	assert.Nil(t, newRule().Check(f))
+	assert.Equal(t, f.Source, newRule().Fix(f))
+}
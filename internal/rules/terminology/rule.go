@@ -0,0 +1,262 @@
+// Package terminology implements MDS072, which replaces discouraged
+// terms with the preferred ones from a terminology list.
+package terminology
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"github.com/yuin/goldmark/ast"
+)
+
+func init() {
+	rule.Register(&Rule{})
+}
+
+// Rule reports prose that uses a discouraged term and suggests the
+// preferred alternatives. Code, HTML, and autolinks are skipped.
+type Rule struct {
+	// terms are the entries of the terms setting.
+	terms []term
+	// Vocabularies are paths to shared YAML files with a terms list
+	// of the same shape. Relative paths resolve against the project
+	// root.
+	Vocabularies []string
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS072" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "terminology" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "prose" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// match is one occurrence of a discouraged term.
+type match struct {
+	start, end int
+	// prefer holds the replacements, best first.
+	prefer []string
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	terms, err := r.allTerms(f)
+	if err != nil {
+		return []lint.Diagnostic{r.diag(f, 1, 1, err.Error())}
+	}
+	var diags []lint.Diagnostic
+	for _, m := range collect(f, terms) {
+		found := string(f.Source[m.start:m.end])
+		line, col := f.LineOfOffset(m.start), f.ColumnOfOffset(m.start)
+		prefer := make([]string, len(m.prefer))
+		for i, p := range m.prefer {
+			prefer[i] = normalizeSpace(p)
+		}
+		d := r.diag(f, line, col, fmt.Sprintf("prefer %s over %q", quoteAll(prefer), normalizeSpace(found)))
+		// Suggestions replace the text up to EndColumn, which only
+		// exists for a match on one line; fix handles the others.
+		if !strings.Contains(found, "\n") {
+			d.EndColumn = col + len(found)
+			d.Suggestions = m.prefer
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// Fix implements rule.FixableRule. Each match is replaced with its
+// first preferred form.
+func (r *Rule) Fix(f *lint.File) []byte {
+	terms, err := r.allTerms(f)
+	if err != nil {
+		return slices.Clone(f.Source)
+	}
+	var out bytes.Buffer
+	prev := 0
+	for _, m := range collect(f, terms) {
+		out.Write(f.Source[prev:m.start])
+		out.WriteString(m.prefer[0])
+		prev = m.end
+	}
+	out.Write(f.Source[prev:])
+	return out.Bytes()
+}
+
+func (r *Rule) diag(f *lint.File, line, col int, msg string) lint.Diagnostic {
+	return lint.Diagnostic{
+		File:     f.Path,
+		Line:     line,
+		Column:   col,
+		RuleID:   r.ID(),
+		RuleName: r.Name(),
+		Severity: lint.Warning,
+		Message:  msg,
+	}
+}
+
+// allTerms returns the configured terms followed by those of every
+// vocabulary file.
+func (r *Rule) allTerms(f *lint.File) ([]term, error) {
+	out := slices.Clone(r.terms)
+	for _, p := range r.Vocabularies {
+		terms, err := loadVocabulary(resolvePath(f.RootDir, p), f.MaxInputBytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load vocabulary %q: %v", p, err)
+		}
+		out = append(out, terms...)
+	}
+	return out, nil
+}
+
+// resolvePath anchors a relative vocabulary path at the project root.
+func resolvePath(root, p string) string {
+	if filepath.IsAbs(p) || root == "" {
+		return p
+	}
+	return filepath.Join(root, p)
+}
+
+// collect returns the matches of terms in f's prose, in source order.
+// Where matches overlap, the earliest and then the longest wins.
+// Text already spelled as a preferred form is not a match.
+func collect(f *lint.File, terms []term) []match {
+	var all []match
+	for _, run := range textRuns(f) {
+		src := f.Source[run[0]:run[1]]
+		for _, t := range terms {
+			for _, m := range t.re.FindAllSubmatchIndex(src, -1) {
+				prefer := t.replacements(src, m)
+				if m[1] > m[0] && !slices.Contains(prefer, string(src[m[0]:m[1]])) {
+					all = append(all, match{start: run[0] + m[0], end: run[0] + m[1], prefer: prefer})
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(all, func(a, b match) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return b.end - a.end
+	})
+	out := all[:0]
+	prev := 0
+	for _, m := range all {
+		if m.start >= prev {
+			out = append(out, m)
+			prev = m.end
+		}
+	}
+	return out
+}
+
+// textRuns returns the source ranges of prose text in document order.
+// Text nodes of one block separated only by whitespace, such as a
+// soft line break, form one run, so a term can span a wrapped line.
+func textRuns(f *lint.File) [][2]int {
+	var out [][2]int
+	var last ast.Node
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.AutoLink, *ast.CodeSpan, *ast.FencedCodeBlock, *ast.CodeBlock,
+			*ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			seg := v.Segment
+			if seg.Len() == 0 {
+				return ast.WalkContinue, nil
+			}
+			b := blockOf(v)
+			if k := len(out) - 1; k >= 0 && b == last && seg.Start >= out[k][1] &&
+				len(bytes.TrimSpace(f.Source[out[k][1]:seg.Start])) == 0 {
+				out[k][1] = seg.Stop
+			} else {
+				out = append(out, [2]int{seg.Start, seg.Stop})
+				last = b
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+// blockOf returns the nearest block ancestor of the inline node n.
+func blockOf(n ast.Node) ast.Node {
+	for n != nil && n.Type() != ast.TypeBlock {
+		n = n.Parent()
+	}
+	return n
+}
+
+// quoteAll formats alternatives as "a", "a" or "b", or "a", "b", or "c".
+func quoteAll(alts []string) string {
+	q := make([]string, len(alts))
+	for i, a := range alts {
+		q[i] = fmt.Sprintf("%q", a)
+	}
+	if len(q) < 3 {
+		return strings.Join(q, " or ")
+	}
+	return strings.Join(q[:len(q)-1], ", ") + ", or " + q[len(q)-1]
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		switch k {
+		case "terms":
+			terms, err := parseTerms(v, "terms")
+			if err != nil {
+				return fmt.Errorf("terminology: %w", err)
+			}
+			r.terms = terms
+		case "vocabularies":
+			list, ok := settings.ToStringSlice(v)
+			if !ok {
+				return fmt.Errorf("terminology: vocabularies must be a list of strings, got %T", v)
+			}
+			r.Vocabularies = list
+		default:
+			return fmt.Errorf("terminology: unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"terms":        []any{},
+		"vocabularies": []string{},
+	}
+}
+
+// SettingMergeMode implements rule.ListMerger. Both lists append
+// across config layers, so a kind adds its own vocabulary to the
+// project's.
+func (r *Rule) SettingMergeMode(key string) rule.MergeMode {
+	if key == "terms" || key == "vocabularies" {
+		return rule.MergeAppend
+	}
+	return rule.MergeReplace
+}
+
+var (
+	_ rule.FixableRule  = (*Rule)(nil)
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+	_ rule.ListMerger   = (*Rule)(nil)
+)
@@ -0,0 +1,151 @@
+package terminology
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func newRule(t *testing.T, s map[string]any) *Rule {
+	t.Helper()
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(s))
+	return r
+}
+
+func entry(term string, prefer any, opts ...any) map[string]any {
+	m := map[string]any{"term": term, "prefer": prefer}
+	for i := 0; i+1 < len(opts); i += 2 {
+		m[opts[i].(string)] = opts[i+1]
+	}
+	return m
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS072", r.ID())
+	assert.Equal(t, "terminology", r.Name())
+	assert.Equal(t, "prose", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestCheck_ReportsWithSuggestions(t *testing.T) {
+	r := newRule(t, map[string]any{"terms": []any{
+		entry("whitelist", "allowlist"),
+		entry("login", []any{"sign in", "log in"}),
+	}})
+	f := newFile(t, "Add it to the Whitelist, then login.\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 2)
+	assert.Equal(t, `prefer "Allowlist" over "Whitelist"`, diags[0].Message)
+	assert.Equal(t, 15, diags[0].Column)
+	assert.Equal(t, 24, diags[0].EndColumn)
+	assert.Equal(t, []string{"Allowlist"}, diags[0].Suggestions)
+	assert.Equal(t, `prefer "sign in" or "log in" over "login"`, diags[1].Message)
+	assert.Equal(t, []string{"sign in", "log in"}, diags[1].Suggestions)
+	assert.Equal(t, "Add it to the Allowlist, then sign in.\n", string(r.Fix(f)))
+}
+
+func TestWordBoundaries(t *testing.T) {
+	r := newRule(t, map[string]any{"terms": []any{entry("e-mail", "email")}})
+	assert.Equal(t, "Send an email or EMAIL.\nNot re-e-mails.\n",
+		string(r.Fix(newFile(t, "Send an e-mail or E-MAIL.\nNot re-e-mails.\n"))))
+
+	r = newRule(t, map[string]any{"terms": []any{entry("mail", "post", "word", false)}})
+	assert.Len(t, r.Check(newFile(t, "email\n")), 1)
+}
+
+func TestCaseSensitive(t *testing.T) {
+	r := newRule(t, map[string]any{"terms": []any{
+		entry("JS", "JavaScript", "case-sensitive", true),
+		entry("github", "GitHub"),
+	}})
+	f := newFile(t, "JS and js on Github and GitHub.\n")
+	assert.Equal(t, "JavaScript and js on GitHub and GitHub.\n", string(r.Fix(f)))
+	assert.Len(t, r.Check(f), 2)
+}
+
+func TestRegexWithGroups(t *testing.T) {
+	r := newRule(t, map[string]any{"terms": []any{
+		entry(`\blog ?in (to|with)\b`, "sign in $1", "regex", true),
+	}})
+	f := newFile(t, "Login to the app. Log in with SSO. A login page.\n")
+	assert.Equal(t, "Sign in to the app. Sign in with SSO. A login page.\n", string(r.Fix(f)))
+}
+
+func TestSpansLineBreaksButNotCode(t *testing.T) {
+	r := newRule(t, map[string]any{"terms": []any{entry("master branch", "main branch")}})
+	src := "Push to the master\nbranch. Not `master branch`.\n\n```sh\ngit push master branch\n```\n"
+	diags := r.Check(newFile(t, src))
+	require.Len(t, diags, 1)
+	assert.Equal(t, `prefer "main branch" over "master branch"`, diags[0].Message)
+	assert.Zero(t, diags[0].EndColumn)
+	assert.Empty(t, diags[0].Suggestions)
+	assert.Equal(t, "Push to the main\nbranch. Not `master branch`.\n\n```sh\ngit push master branch\n```\n",
+		string(r.Fix(newFile(t, src))))
+}
+
+func TestFixKeepsWrappedGaps(t *testing.T) {
+	r := newRule(t, map[string]any{"terms": []any{
+		entry("log in to", "sign in to"),
+		entry("master branch", "main"),
+		entry("e-mail", "electronic mail"),
+	}})
+	src := "Log\nin to it. Use the master\n  branch. Send an e-mail.\n"
+	assert.Equal(t, "Sign\nin to it. Use the main. Send an electronic mail.\n", string(r.Fix(newFile(t, src))))
+}
+
+func TestVocabularyFile(t *testing.T) {
+	dir := t.TempDir()
+	vocab := "terms:\n  - term: blacklist\n    prefer: [denylist, blocklist]\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terms.yml"), []byte(vocab), 0o644))
+
+	r := newRule(t, map[string]any{"vocabularies": []any{"terms.yml"}})
+	f := newFile(t, "Check the blacklist.\n")
+	f.SetRootDir(dir)
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, `prefer "denylist" or "blocklist" over "blacklist"`, diags[0].Message)
+
+	r = newRule(t, map[string]any{"vocabularies": []any{"missing.yml"}})
+	diags = r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, `cannot load vocabulary "missing.yml"`)
+	assert.Equal(t, "Check the blacklist.\n", string(r.Fix(f)))
+}
+
+func TestApplySettings(t *testing.T) {
+	tests := []struct {
+		settings map[string]any
+		want     string
+	}{
+		{map[string]any{"terms": "x"}, "terminology: terms must be a list, got string"},
+		{map[string]any{"terms": []any{"x"}}, "terminology: terms[0] must be a map, got string"},
+		{
+			map[string]any{"terms": []any{map[string]any{"term": "x"}}},
+			"terminology: terms[0]: term and prefer are required",
+		},
+		{map[string]any{"terms": []any{entry("(", "x", "regex", true)}}, `terminology: terms[0]: invalid regex "("`},
+		{
+			map[string]any{"terms": []any{entry("x", "y", "word", "yes")}},
+			"terminology: terms[0]: word must be a bool, got string",
+		},
+		{map[string]any{"terms": []any{entry("x", "y", "why", "z")}}, `terminology: terms[0]: unknown key "why"`},
+		{map[string]any{"vocabularies": "a.yml"}, "terminology: vocabularies must be a list of strings, got string"},
+		{map[string]any{"words": []any{}}, `terminology: unknown setting "words"`},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, (&Rule{}).ApplySettings(tt.settings), tt.want)
+	}
+}
@@ -0,0 +1,258 @@
+package terminology
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rules/settings"
+	"gopkg.in/yaml.v3"
+)
+
+// term is one compiled entry of a terminology list.
+type term struct {
+	// source is the term as configured, a literal or a regexp.
+	source string
+	re     *regexp.Regexp
+	// regex is set when source is a regexp; its prefer entries may
+	// then reference capture groups as $1 or ${name}.
+	regex  bool
+	prefer []string
+}
+
+// parseTerms reads a list of term entries. where prefixes errors, e.g.
+// "terms" or the path of a vocabulary file.
+func parseTerms(v any, where string) ([]term, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list, got %T", where, v)
+	}
+	out := make([]term, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be a map, got %T", where, i, item)
+		}
+		t, err := compileTerm(m)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", where, i, err)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// compileTerm builds the matcher of one entry. Literal terms match
+// any run of whitespace between their words, so a term still matches
+// when a paragraph wraps inside it.
+func compileTerm(m map[string]any) (term, error) {
+	opts := map[string]bool{"regex": false, "word": true, "case-sensitive": false}
+	var t term
+	for k, v := range m {
+		switch k {
+		case "term":
+			t.source, _ = v.(string)
+			if strings.TrimSpace(t.source) == "" {
+				return term{}, fmt.Errorf("term must be a non-empty string, got %v", v)
+			}
+		case "prefer":
+			t.prefer = toStrings(v)
+			if len(t.prefer) == 0 {
+				return term{}, fmt.Errorf("prefer must be a string or a list of strings, got %v", v)
+			}
+		case "regex", "word", "case-sensitive":
+			b, ok := v.(bool)
+			if !ok {
+				return term{}, fmt.Errorf("%s must be a bool, got %T", k, v)
+			}
+			opts[k] = b
+		default:
+			return term{}, fmt.Errorf("unknown key %q", k)
+		}
+	}
+	if t.source == "" || len(t.prefer) == 0 {
+		return term{}, fmt.Errorf("term and prefer are required")
+	}
+	t.regex = opts["regex"]
+	expr := t.source
+	if !t.regex {
+		expr = literalPattern(t.source, opts["word"])
+	}
+	if !opts["case-sensitive"] {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return term{}, fmt.Errorf("invalid regex %q: %w", t.source, err)
+	}
+	t.re = re
+	return t, nil
+}
+
+// toStrings accepts a string or a list of strings.
+func toStrings(v any) []string {
+	if s, ok := v.(string); ok && s != "" {
+		return []string{s}
+	}
+	list, _ := settings.ToStringSlice(v)
+	return list
+}
+
+// literalPattern quotes a literal term, lets each space match any
+// whitespace run, and with word set anchors the ends at word
+// boundaries where the term starts or ends with a word character.
+func literalPattern(s string, word bool) string {
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		fields[i] = regexp.QuoteMeta(f)
+	}
+	expr := strings.Join(fields, `\s+`)
+	if !word {
+		return expr
+	}
+	if r, _ := utf8.DecodeRuneInString(s); isWordRune(r) {
+		expr = `\b` + expr
+	}
+	if r, _ := utf8.DecodeLastRuneInString(s); isWordRune(r) {
+		expr += `\b`
+	}
+	return expr
+}
+
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// replacements returns the preferred forms for the match m of t in
+// src, with capture groups expanded, the case of the match carried
+// over, and the whitespace between its words kept.
+func (t term) replacements(src []byte, m []int) []string {
+	matched := string(src[m[0]:m[1]])
+	words := normalizeSpace(matched)
+	out := make([]string, 0, len(t.prefer))
+	for _, p := range t.prefer {
+		if t.regex {
+			p = string(t.re.Expand(nil, []byte(p), src, m))
+		}
+		out = append(out, keepGaps(matchCase(p, words, t.source), matched))
+	}
+	return out
+}
+
+var spaceRun = regexp.MustCompile(`\s+`)
+
+// normalizeSpace collapses each whitespace run in s to one space.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// keepGaps gives the i-th whitespace run of repl the i-th run of
+// matched, so a term wrapped across lines is replaced without joining
+// them. Runs past the last one in matched are left as written.
+func keepGaps(repl, matched string) string {
+	gaps := spaceRun.FindAllString(matched, -1)
+	if len(gaps) == 0 {
+		return repl
+	}
+	i := 0
+	return spaceRun.ReplaceAllStringFunc(repl, func(run string) string {
+		if i >= len(gaps) {
+			return run
+		}
+		i++
+		return gaps[i-1]
+	})
+}
+
+// matchCase carries the case of matched over to repl: an all-caps
+// match gives an all-caps replacement, and a capitalized match a
+// capitalized one. A match spelled exactly like the configured term
+// keeps repl as written, so "JS" can prefer "JavaScript".
+func matchCase(repl, matched, source string) string {
+	if matched == source {
+		return repl
+	}
+	letters, upper := 0, 0
+	for _, r := range matched {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters > 1 && upper == letters {
+		return strings.ToUpper(repl)
+	}
+	first, _ := utf8.DecodeRuneInString(matched)
+	if !unicode.IsUpper(first) {
+		return repl
+	}
+	r, w := utf8.DecodeRuneInString(repl)
+	return string(unicode.ToUpper(r)) + repl[w:]
+}
+
+// vocabularyFile is the shape of a shared vocabulary file.
+type vocabularyFile struct {
+	Terms []any `yaml:"terms"`
+}
+
+// cachedVocabulary is a parsed vocabulary with the stat of its file
+// when it was read.
+type cachedVocabulary struct {
+	mod   time.Time
+	size  int64
+	terms []term
+	err   error
+}
+
+// vocabularies caches parsed vocabulary files by path, so a
+// long-lived process such as the language server reads each one once.
+// An entry is reloaded when its file changes.
+var vocabularies = struct {
+	sync.Mutex
+	byPath map[string]*cachedVocabulary
+}{byPath: map[string]*cachedVocabulary{}}
+
+func loadVocabulary(path string, maxBytes int64) ([]term, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	vocabularies.Lock()
+	c := vocabularies.byPath[path]
+	vocabularies.Unlock()
+	if c != nil && c.mod.Equal(info.ModTime()) && c.size == info.Size() {
+		return c.terms, c.err
+	}
+	terms, err := parseVocabulary(path, maxBytes)
+	vocabularies.Lock()
+	vocabularies.byPath[path] = &cachedVocabulary{
+		mod: info.ModTime(), size: info.Size(), terms: terms, err: err,
+	}
+	vocabularies.Unlock()
+	return terms, err
+}
+
+func parseVocabulary(path string, maxBytes int64) ([]term, error) {
+	data, err := lint.ReadFileLimited(path, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	var vf vocabularyFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&vf); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return parseTerms(vf.Terms, "terms")
+}
@@ -0,0 +1,29 @@
+package terminology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchCase(t *testing.T) {
+	tests := []struct {
+		repl, matched, source, want string
+	}{
+		{"allowlist", "whitelist", "whitelist", "allowlist"},
+		{"allowlist", "Whitelist", "whitelist", "Allowlist"},
+		{"allowlist", "WHITELIST", "whitelist", "ALLOWLIST"},
+		{"JavaScript", "JS", "JS", "JavaScript"},
+		{"JavaScript", "js", "JS", "JavaScript"},
+		{"sign in", "Login", `log ?in`, "Sign in"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchCase(tt.repl, tt.matched, tt.source), tt.matched)
+	}
+}
+
+func TestLiteralPattern(t *testing.T) {
+	assert.Equal(t, `\bsign\s+in\b`, literalPattern("sign  in", true))
+	assert.Equal(t, `\bC\+\+`, literalPattern("C++", true))
+	assert.Equal(t, `e-mail`, literalPattern("e-mail", false))
+}
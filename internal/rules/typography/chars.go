@@ -0,0 +1,81 @@
+package typography
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// curlyQuotes maps each curly quote to its straight form.
+var curlyQuotes = map[rune]string{
+	'“': `"`, '”': `"`, '„': `"`, '‟': `"`,
+	'‘': "'", '’': "'", '‚': "'", '‛': "'",
+}
+
+// invisible describes a character that renders as nothing or as a
+// plain space, and what the fix puts in its place.
+type invisible struct {
+	name string
+	repl string
+	// ascii limits the check to a character whose neighbours are
+	// ASCII; joiners are needed in emoji sequences and some scripts.
+	ascii bool
+}
+
+var invisibles = map[rune]invisible{
+	'\u00a0': {name: "non-breaking space", repl: " "},
+	'\u2007': {name: "figure space", repl: " "},
+	'\u202f': {name: "narrow no-break space", repl: " "},
+	'\u00ad': {name: "soft hyphen"},
+	'\u200b': {name: "zero-width space"},
+	'\u2060': {name: "word joiner"},
+	'\ufeff': {name: "zero-width no-break space"},
+	'\u200c': {name: "zero-width non-joiner", ascii: true},
+	'\u200d': {name: "zero-width joiner", ascii: true},
+}
+
+// bidiControls names the characters that reorder displayed text, the
+// building blocks of Trojan Source attacks (CVE-2021-42574).
+var bidiControls = map[rune]string{
+	'\u061c': "arabic letter mark",
+	'\u200e': "left-to-right mark",
+	'\u200f': "right-to-left mark",
+	'\u202a': "left-to-right embedding",
+	'\u202b': "right-to-left embedding",
+	'\u202c': "pop directional formatting",
+	'\u202d': "left-to-right override",
+	'\u202e': "right-to-left override",
+	'\u2066': "left-to-right isolate",
+	'\u2067': "right-to-left isolate",
+	'\u2068': "first strong isolate",
+	'\u2069': "pop directional isolate",
+}
+
+// codePoint formats r as U+XXXX.
+func codePoint(r rune) string { return fmt.Sprintf("U+%04X", r) }
+
+// opensQuote reports whether a straight quote after prev opens a
+// quotation: at the start of text, after whitespace, after an opening
+// bracket or dash, or after another opening quote.
+func opensQuote(prev rune) bool {
+	switch prev {
+	case utf8.RuneError, ' ', '\t', '\n', '(', '[', '{', '—', '–', '“', '‘', '\u00a0':
+		return true
+	}
+	return false
+}
+
+// smartQuote returns the curly form of the straight quote q after prev.
+func smartQuote(q, prev rune) string {
+	open := opensQuote(prev)
+	switch {
+	case q == '"' && open:
+		return "“"
+	case q == '"':
+		return "”"
+	case open:
+		return "‘"
+	}
+	return "’"
+}
+
+func isASCII(r rune) bool { return r < utf8.RuneSelf }
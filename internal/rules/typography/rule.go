@@ -0,0 +1,267 @@
+// Package typography implements MDS071, which enforces a typography
+// profile on prose: quote style, dash style, ellipses, Unicode NFC
+// normalization, and no invisible characters.
+package typography
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/yuin/goldmark/ast"
+	"golang.org/x/text/unicode/norm"
+)
+
+func init() {
+	rule.Register(&Rule{Quotes: "straight", Dashes: "any", Ellipsis: "ascii", NFC: true, Invisible: true})
+}
+
+// styles lists the accepted values of the quotes, dashes, and
+// ellipsis settings.
+var styles = map[string][]string{
+	"quotes":   {"straight", "smart", "any"},
+	"dashes":   {"ascii", "unicode", "any"},
+	"ellipsis": {"ascii", "unicode", "any"},
+}
+
+// Rule checks the typography of prose text. Code spans, code blocks,
+// raw HTML, and link destinations are left alone. Strict mode also
+// reports bidi control characters anywhere in the file, code
+// included, as errors.
+type Rule struct {
+	// Quotes is straight, smart, or any.
+	Quotes string
+	// Dashes is ascii (-- and -), unicode (— for --), or any.
+	Dashes string
+	// Ellipsis is ascii (...), unicode (…), or any.
+	Ellipsis string
+	// NFC requires text in Unicode Normalization Form C.
+	NFC bool
+	// Invisible flags invisible characters such as non-breaking and
+	// zero-width spaces.
+	Invisible bool
+	// Strict flags bidi control characters as a security issue.
+	Strict bool
+}
+
+// ID implements rule.Rule.
+func (r *Rule) ID() string { return "MDS071" }
+
+// Name implements rule.Rule.
+func (r *Rule) Name() string { return "typography" }
+
+// Category implements rule.Rule.
+func (r *Rule) Category() string { return "prose" }
+
+// EnabledByDefault implements rule.Defaultable.
+func (r *Rule) EnabledByDefault() bool { return false }
+
+// edit replaces source[start:end] with repl.
+type edit struct {
+	start, end int
+	repl       string
+	msg        string
+}
+
+// Check implements rule.Rule.
+func (r *Rule) Check(f *lint.File) []lint.Diagnostic {
+	var diags []lint.Diagnostic
+	diag := func(off int, sev lint.Severity, msg string) {
+		diags = append(diags, lint.Diagnostic{
+			File:     f.Path,
+			Line:     f.LineOfOffset(off),
+			Column:   f.ColumnOfOffset(off),
+			RuleID:   r.ID(),
+			RuleName: r.Name(),
+			Severity: sev,
+			Message:  msg,
+		})
+	}
+	for _, seg := range proseSegments(f) {
+		for _, e := range r.scan(f.Source, seg[0], seg[1]) {
+			diag(e.start, lint.Warning, e.msg)
+		}
+		if text := f.Source[seg[0]:seg[1]]; r.NFC && !norm.NFC.IsNormal(text) {
+			diag(seg[0]+norm.NFC.QuickSpan(text), lint.Warning, "text is not in Unicode NFC form")
+		}
+	}
+	if r.Strict {
+		for off, w := 0, 0; off < len(f.Source); off += w {
+			var c rune
+			c, w = utf8.DecodeRune(f.Source[off:])
+			if name, ok := bidiControls[c]; ok {
+				diag(off, lint.Error, fmt.Sprintf(
+					"bidi control character %s (%s) can disguise text (Trojan Source)", codePoint(c), name))
+			}
+		}
+	}
+	slices.SortStableFunc(diags, func(a, b lint.Diagnostic) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return diags
+}
+
+// Fix implements rule.FixableRule. Bidi control characters are never
+// removed; they need a human to look at the text around them.
+func (r *Rule) Fix(f *lint.File) []byte {
+	out := slices.Clone(f.Source)
+	for _, seg := range slices.Backward(proseSegments(f)) {
+		text := string(f.Source[seg[0]:seg[1]])
+		edits := r.scan(f.Source, seg[0], seg[1])
+		for _, e := range slices.Backward(edits) {
+			text = text[:e.start-seg[0]] + e.repl + text[e.end-seg[0]:]
+		}
+		if r.NFC {
+			text = norm.NFC.String(text)
+		}
+		if text != string(f.Source[seg[0]:seg[1]]) {
+			out = slices.Concat(out[:seg[0]], []byte(text), out[seg[1]:])
+		}
+	}
+	return out
+}
+
+// proseSegments returns the source ranges of text outside code spans,
+// in document order. Code blocks, raw HTML, autolinks, and link
+// destinations carry no text nodes, so they never appear.
+func proseSegments(f *lint.File) [][2]int {
+	var out [][2]int
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.CodeSpan, *ast.CodeBlock, *ast.FencedCodeBlock, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if v.Segment.Len() > 0 {
+				out = append(out, [2]int{v.Segment.Start, v.Segment.Stop})
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+// scan returns the edits for source[start:end] in offset order.
+func (r *Rule) scan(source []byte, start, end int) []edit {
+	var edits []edit
+	for off := start; off < end; {
+		c, w := utf8.DecodeRune(source[off:end])
+		prev, _ := utf8.DecodeLastRune(source[:off])
+		next, _ := utf8.DecodeRune(source[off+w : end])
+		if e, ok := r.check(source, off, end, c, prev, next); ok {
+			edits = append(edits, e)
+			off = e.end
+			continue
+		}
+		off += w
+	}
+	return edits
+}
+
+// check returns the edit for the character c at off, if it breaks the
+// profile. prev and next are the characters around it, or
+// utf8.RuneError at either end.
+func (r *Rule) check(source []byte, off, end int, c, prev, next rune) (edit, bool) {
+	_, w := utf8.DecodeRune(source[off:end])
+	one := func(repl, msg string) (edit, bool) {
+		return edit{start: off, end: off + w, repl: repl, msg: msg}, true
+	}
+	switch {
+	case r.Quotes == "straight" && curlyQuotes[c] != "":
+		return one(curlyQuotes[c], fmt.Sprintf("curly quote %c should be straight", c))
+	case r.Quotes == "smart" && (c == '"' || c == '\'') && prev != '\\':
+		q := smartQuote(c, prev)
+		return one(q, fmt.Sprintf("straight quote %c should be %s", c, q))
+	case r.Dashes == "ascii" && c == '—':
+		return one("--", "em dash — should be --")
+	case r.Dashes == "ascii" && c == '–':
+		return one("-", "en dash – should be -")
+	case r.Dashes == "unicode" && c == '-' && next == '-' && prev != '-' &&
+		!bytes.HasPrefix(source[off+2:end], []byte("-")):
+		return edit{start: off, end: off + 2, repl: "—", msg: "-- should be an em dash —"}, true
+	case r.Ellipsis == "ascii" && c == '…':
+		return one("...", "ellipsis … should be ...")
+	case r.Ellipsis == "unicode" && c == '.' && prev != '.' &&
+		bytes.HasPrefix(source[off:end], []byte("...")) && !bytes.HasPrefix(source[off+3:end], []byte(".")):
+		return edit{start: off, end: off + 3, repl: "…", msg: "... should be an ellipsis …"}, true
+	}
+	if inv, ok := invisibles[c]; ok && r.Invisible && (!inv.ascii || isASCII(prev) && isASCII(next)) {
+		return one(inv.repl, fmt.Sprintf("invisible character %s (%s)", codePoint(c), inv.name))
+	}
+	return edit{}, false
+}
+
+// ApplySettings implements rule.Configurable.
+func (r *Rule) ApplySettings(s map[string]any) error {
+	for k, v := range s {
+		if err := r.applySetting(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Rule) applySetting(key string, v any) error {
+	switch key {
+	case "quotes":
+		return applyStyle(key, v, &r.Quotes)
+	case "dashes":
+		return applyStyle(key, v, &r.Dashes)
+	case "ellipsis":
+		return applyStyle(key, v, &r.Ellipsis)
+	case "nfc":
+		return applyBool(key, v, &r.NFC)
+	case "invisible":
+		return applyBool(key, v, &r.Invisible)
+	case "strict":
+		return applyBool(key, v, &r.Strict)
+	default:
+		return fmt.Errorf("typography: unknown setting %q", key)
+	}
+}
+
+func applyStyle(key string, v any, target *string) error {
+	style, ok := v.(string)
+	if !ok || !slices.Contains(styles[key], style) {
+		return fmt.Errorf("typography: %s must be one of %s, got %v",
+			key, strings.Join(styles[key], ", "), v)
+	}
+	*target = style
+	return nil
+}
+
+func applyBool(key string, v any, target *bool) error {
+	b, ok := v.(bool)
+	if !ok {
+		return fmt.Errorf("typography: %s must be a bool, got %T", key, v)
+	}
+	*target = b
+	return nil
+}
+
+// DefaultSettings implements rule.Configurable.
+func (r *Rule) DefaultSettings() map[string]any {
+	return map[string]any{
+		"quotes":    "straight",
+		"dashes":    "any",
+		"ellipsis":  "ascii",
+		"nfc":       true,
+		"invisible": true,
+		"strict":    false,
+	}
+}
+
+var (
+	_ rule.FixableRule  = (*Rule)(nil)
+	_ rule.Configurable = (*Rule)(nil)
+	_ rule.Defaultable  = (*Rule)(nil)
+)
@@ -0,0 +1,157 @@
+package typography
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFile(t *testing.T, src string) *lint.File {
+	t.Helper()
+	f, err := lint.NewFile("test.md", []byte(src))
+	require.NoError(t, err)
+	return f
+}
+
+func defaults() *Rule {
+	return &Rule{Quotes: "straight", Dashes: "any", Ellipsis: "ascii", NFC: true, Invisible: true}
+}
+
+func messages(diags []lint.Diagnostic) []string {
+	var out []string
+	for _, d := range diags {
+		out = append(out, d.Message)
+	}
+	return out
+}
+
+func TestRuleMetadata(t *testing.T) {
+	r := &Rule{}
+	assert.Equal(t, "MDS071", r.ID())
+	assert.Equal(t, "typography", r.Name())
+	assert.Equal(t, "prose", r.Category())
+	assert.False(t, r.EnabledByDefault())
+}
+
+func TestDefaults_StraightQuotesAndASCIIEllipsis(t *testing.T) {
+	r := defaults()
+	f := newFile(t, "He said “hi”, it’s fine… — ok\n")
+	diags := r.Check(f)
+	assert.Equal(t, []string{
+		"curly quote “ should be straight",
+		"curly quote ” should be straight",
+		"curly quote ’ should be straight",
+		"ellipsis … should be ...",
+	}, messages(diags))
+	assert.Equal(t, 1, diags[0].Line)
+	assert.Equal(t, 9, diags[0].Column)
+	assert.Equal(t, "He said \"hi\", it's fine... — ok\n", string(r.Fix(f)))
+}
+
+func TestSmartQuotes(t *testing.T) {
+	r := &Rule{Quotes: "smart"}
+	f := newFile(t, "She said \"it's 'fine'\" (\"yes\").\n")
+	assert.Len(t, r.Check(f), 7)
+	fixed := r.Fix(f)
+	assert.Equal(t, "She said “it’s ‘fine’” (“yes”).\n", string(fixed))
+	assert.Empty(t, r.Check(newFile(t, string(fixed))))
+}
+
+func TestDashes(t *testing.T) {
+	r := &Rule{Dashes: "unicode"}
+	f := newFile(t, "one -- two - three --- four\n")
+	assert.Equal(t, []string{"-- should be an em dash —"}, messages(r.Check(f)))
+	assert.Equal(t, "one — two - three --- four\n", string(r.Fix(f)))
+
+	r = &Rule{Dashes: "ascii"}
+	f = newFile(t, "one — two – three\n")
+	assert.Equal(t, "one -- two - three\n", string(r.Fix(f)))
+}
+
+func TestUnicodeEllipsis(t *testing.T) {
+	r := &Rule{Ellipsis: "unicode"}
+	f := newFile(t, "Wait... then.... go\n")
+	assert.Equal(t, []string{"... should be an ellipsis …"}, messages(r.Check(f)))
+	assert.Equal(t, "Wait… then.... go\n", string(r.Fix(f)))
+}
+
+func TestInvisibleCharacters(t *testing.T) {
+	r := defaults()
+	f := newFile(t, "a\u00a0b soft\u00adhyphen zero\u200bwidth\n")
+	assert.Equal(t, []string{
+		"invisible character U+00A0 (non-breaking space)",
+		"invisible character U+00AD (soft hyphen)",
+		"invisible character U+200B (zero-width space)",
+	}, messages(r.Check(f)))
+	assert.Equal(t, "a b softhyphen zerowidth\n", string(r.Fix(f)))
+}
+
+func TestJoinersInEmojiAreKept(t *testing.T) {
+	r := defaults()
+	assert.Empty(t, r.Check(newFile(t, "Family \U0001F468\u200d\U0001F469 here\n")))
+	assert.Len(t, r.Check(newFile(t, "a\u200db\n")), 1)
+}
+
+func TestNFC(t *testing.T) {
+	r := defaults()
+	f := newFile(t, "Cafe\u0301 menu\n")
+	diags := r.Check(f)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "text is not in Unicode NFC form", diags[0].Message)
+	assert.Equal(t, "Café menu\n", string(r.Fix(f)))
+}
+
+func TestSkipsCode(t *testing.T) {
+	r := defaults()
+	src := "Use `“x”` here.\n\n```go\ns := \"…\u00a0\"\n```\n\n    indented ’\n\n" +
+		"<div>“raw”</div>\n\n[link](https://example.com/a’b)\n"
+	f := newFile(t, src)
+	assert.Empty(t, r.Check(f))
+	assert.Equal(t, src, string(r.Fix(f)))
+}
+
+func TestStrictFlagsBidiEverywhere(t *testing.T) {
+	src := "Text \u202eevil\u202c.\n\n```go\nx := 1 // \u2066hidden\u2069\n```\n"
+	f := newFile(t, src)
+	assert.Empty(t, defaults().Check(f))
+
+	r := defaults()
+	r.Strict = true
+	diags := r.Check(f)
+	require.Len(t, diags, 4)
+	assert.Equal(t, lint.Error, diags[0].Severity)
+	assert.Equal(t,
+		"bidi control character U+202E (right-to-left override) can disguise text (Trojan Source)",
+		diags[0].Message)
+	assert.Equal(t, 4, diags[2].Line)
+	assert.Equal(t, src, string(r.Fix(f)))
+}
+
+func TestApplySettings(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(map[string]any{
+		"quotes": "smart", "dashes": "unicode", "ellipsis": "any", "nfc": false, "invisible": false, "strict": true,
+	}))
+	assert.Equal(t, &Rule{Quotes: "smart", Dashes: "unicode", Ellipsis: "any", Strict: true}, r)
+
+	tests := []struct {
+		settings map[string]any
+		want     string
+	}{
+		{map[string]any{"quotes": "curly"}, "typography: quotes must be one of straight, smart, any, got curly"},
+		{map[string]any{"dashes": 1}, "typography: dashes must be one of ascii, unicode, any, got 1"},
+		{map[string]any{"strict": "yes"}, "typography: strict must be a bool, got string"},
+		{map[string]any{"apostrophes": "smart"}, `typography: unknown setting "apostrophes"`},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, (&Rule{}).ApplySettings(tt.settings), tt.want)
+	}
+}
+
+func TestDefaultSettingsMatchRegistration(t *testing.T) {
+	r := &Rule{}
+	require.NoError(t, r.ApplySettings(r.DefaultSettings()))
+	assert.Equal(t, defaults(), r)
+}
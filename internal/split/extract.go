@@ -0,0 +1,148 @@
+package split
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/rename"
+)
+
+// ErrNotSection is returned by Extract when the line is not a
+// document-level heading outside generated sections.
+var ErrNotSection = errors.New("not a section heading")
+
+// Extraction is the outcome of Extract. Nothing has been written yet.
+type Extraction struct {
+	// IndexKey is the Workspace key of the original file.
+	IndexKey string
+	// Index is the original file's new content, with the section
+	// replaced by an `<?include?>` whose body is already generated.
+	Index []byte
+	// Section is the new file.
+	Section Section
+	// Changes are the anchor rewrites for every other workspace file.
+	Changes map[string][]rename.Edit
+}
+
+// Extract moves the section whose heading starts on the 1-based
+// source line of file into a new file next to it and replaces the
+// section with an `<?include?>` of that file. name is the new file's
+// base name; "" names it after the heading's anchor.
+//
+// Whether the file is re-leveled depends on the closest heading
+// above the section, whatever its level. When that heading is exactly
+// one level above the section's, or the section is an H1 with no
+// heading above it, the file is shifted so the section heading is an
+// H1, and the directive carries `heading-level: absolute` to shift it
+// back when included. In every other case the file keeps the
+// section's original levels and the directive has no heading-level.
+// Either way the index renders as it did before.
+func Extract(ws rename.Workspace, file string, line int, name string) (*Extraction, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("file name %q must not contain a path separator", name)
+	}
+	file = index.NormalizePath(file)
+	key, src, ok := ws.Resolve(file)
+	if !ok {
+		return nil, fmt.Errorf("cannot read %s", file)
+	}
+	heads := collectHeadings(file, src)
+	c, parentLevel, err := findSection(file, heads, line, len(splitRows(src)), name)
+	if err != nil {
+		return nil, err
+	}
+	chunks := []chunk{c}
+	home := func(l int) string {
+		if l >= c.start && l <= c.end {
+			return c.file
+		}
+		return file
+	}
+	changes := rename.Relocate(ws, rename.Relocation{
+		File: file, Moves: planMoves(file, heads, chunks, Include), Home: home,
+	})
+	rewritten, err := rename.ApplyEdits(src, changes[key])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	delete(changes, key)
+
+	rows := splitRows(rewritten)
+	body := joinRows(trimBlankRows(rows[c.start-1 : c.end]))
+	absolute := parentLevel == c.head.level-1
+	content := body
+	if absolute {
+		if content, err = rename.ShiftLevels(body, 1, c.end-c.start+1, 1-c.head.level); err != nil {
+			return nil, err
+		}
+		// The generated body is the file re-leveled under its parent,
+		// which is the section itself in ATX form.
+		if body, err = rename.ShiftLevels(content, 1, c.end-c.start+1, c.head.level-1); err != nil {
+			return nil, err
+		}
+	}
+	var out [][]byte
+	out = append(out, trimBlankRows(rows[:c.start-1])...)
+	out = appendBlock(out, extractBlock(path.Base(c.file), absolute, body))
+	if tail := trimBlankRows(rows[c.end:]); len(tail) > 0 {
+		out = append(out, nil)
+		out = append(out, tail...)
+	}
+	return &Extraction{
+		IndexKey: key,
+		Index:    joinRows(out),
+		Section:  Section{File: c.file, Heading: c.head.text, Source: content},
+		Changes:  changes,
+	}, nil
+}
+
+// findSection locates the section whose heading starts on line and
+// names its file. It also returns the level of the closest heading
+// before the section, which may be a sibling or a deeper heading
+// (0 when there is none).
+func findSection(file string, heads []heading, line, lineCount int, name string) (chunk, int, error) {
+	parentLevel := 0
+	for i, h := range heads {
+		if h.line == line && h.topLevel {
+			if h.anchor == "" && name == "" {
+				return chunk{}, 0, EmptySlugError{Line: h.line, Heading: h.text}
+			}
+			end := lineCount
+			for _, next := range heads[i+1:] {
+				if next.topLevel && next.level <= h.level {
+					end = next.line - 1
+					break
+				}
+			}
+			if name == "" {
+				name = h.anchor + ".md"
+			}
+			target := path.Join(path.Dir(file), name)
+			if target == file {
+				return chunk{}, 0, NameCollisionError{File: target}
+			}
+			return chunk{start: h.line, end: end, head: h, file: target}, parentLevel, nil
+		}
+		if h.line < line && h.topLevel {
+			parentLevel = h.level
+		}
+	}
+	return chunk{}, 0, fmt.Errorf("line %d: %w", line, ErrNotSection)
+}
+
+// extractBlock renders the `<?include?>` that replaces an extracted
+// section, body included so the index is current without a fix pass.
+func extractBlock(name string, absolute bool, body []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<?include\nfile: %s\n", name)
+	if absolute {
+		b.WriteString("heading-level: absolute\n")
+	}
+	b.WriteString("?>\n")
+	b.Write(body)
+	b.WriteString("<?/include?>\n")
+	return b.String()
+}
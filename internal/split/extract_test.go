@@ -0,0 +1,46 @@
+package split
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract_UnderParentUsesAbsoluteLevels(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"docs/g.md": "# G\n\nSee [b](#b).\n\n## A\n\nx [g](#g)\n\n### B\n\ny\n\n## C\n\nz\n",
+		"o.md":      "[b](docs/g.md#b)\n",
+	})
+	res, err := Extract(ws, "docs/g.md", 5, "")
+	require.NoError(t, err)
+	assert.Equal(t, "docs/a.md", res.Section.File)
+	assert.Equal(t, "# A\n\nx [g](g.md#g)\n\n## B\n\ny\n", string(res.Section.Source))
+	assert.Equal(t, "# G\n\nSee [b](a.md#b).\n\n<?include\nfile: a.md\nheading-level: absolute\n?>\n"+
+		"## A\n\nx [g](g.md#g)\n\n### B\n\ny\n<?/include?>\n\n## C\n\nz\n", string(res.Index))
+	got, err := rename.ApplyEdits(ws.files["o.md"], res.Changes["o.md"])
+	require.NoError(t, err)
+	assert.Equal(t, "[b](docs/a.md#b)\n", string(got))
+}
+
+func TestExtract_AfterSiblingKeepsLevels(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{"g.md": "# G\n\n## A\n\nx\n\n## C\n\nz\n"})
+	res, err := Extract(ws, "g.md", 7, "part.md")
+	require.NoError(t, err)
+	assert.Equal(t, "g.md", res.IndexKey)
+	assert.Equal(t, "part.md", res.Section.File)
+	assert.Equal(t, "## C\n\nz\n", string(res.Section.Source))
+	assert.Equal(t, "# G\n\n## A\n\nx\n\n<?include\nfile: part.md\n?>\n## C\n\nz\n<?/include?>\n", string(res.Index))
+}
+
+func TestExtract_Errors(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{"g.md": "# G\n\ntext\n\n## G\n\nx\n"})
+	_, err := Extract(ws, "g.md", 3, "")
+	assert.ErrorIs(t, err, ErrNotSection)
+	_, err = Extract(ws, "g.md", 5, "sub/x.md")
+	assert.Error(t, err)
+	_, err = Extract(ws, "g.md", 1, "")
+	var collision NameCollisionError
+	assert.ErrorAs(t, err, &collision)
+}
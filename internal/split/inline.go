@@ -0,0 +1,157 @@
+package split
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rename"
+)
+
+// ErrNoInclude is returned by Inline when the line is not inside a
+// well-formed `<?include?>` block.
+var ErrNoInclude = errors.New("no include directive at this line")
+
+// ErrEmptyInclude is returned by Inline when the include body has not
+// been generated, so there is nothing to inline.
+var ErrEmptyInclude = errors.New("include body is empty; run mdsmith fix first")
+
+// Inlining is the outcome of Inline. Nothing has been written yet.
+type Inlining struct {
+	// IndexKey is the Workspace key of the including file.
+	IndexKey string
+	// Index is the including file's new content.
+	Index []byte
+	// Included is the workspace-relative path of the included file.
+	// It is left on disk; callers decide whether to remove it.
+	Included string
+	// Changes are the anchor rewrites for every other workspace
+	// file, the included file excluded.
+	Changes map[string][]rename.Edit
+}
+
+// Inline replaces the `<?include?>` block covering the 1-based source
+// line of file with its generated body and drops the markers. The
+// body is kept as it stands — what the file renders today — so a
+// stale include should be regenerated first.
+//
+// Anchor links that pointed at a heading of the included file are
+// retargeted to the same heading in file, under the anchor it has
+// there. Links in file that name file itself, such as ones the body
+// carried back to its old host, collapse to a bare `#slug`, as they
+// do in the extract direction.
+func Inline(ws rename.Workspace, file string, line int) (*Inlining, error) {
+	file = index.NormalizePath(file)
+	key, src, ok := ws.Resolve(file)
+	if !ok {
+		return nil, fmt.Errorf("cannot read %s", file)
+	}
+	f, _ := lint.NewFileFromSource(file, src, true) // never errors with current implementation
+	mp, included, err := findInclude(f, file, line-f.LineOffset)
+	if err != nil {
+		return nil, err
+	}
+	if mp.ContentFrom > mp.ContentTo {
+		return nil, ErrEmptyInclude
+	}
+	from, to := mp.ContentFrom+f.LineOffset, mp.ContentTo+f.LineOffset
+
+	heads := collectHeadings(file, src)
+	var changes map[string][]rename.Edit
+	if incKey, incSrc, ok := ws.Resolve(included); ok {
+		moves := inlineMoves(file, heads, collectHeadings(included, incSrc), from, to)
+		changes = rename.Relocate(ws, rename.Relocation{File: included, Moves: moves})
+		delete(changes, incKey)
+	} else {
+		changes = map[string][]rename.Edit{}
+	}
+	self := rename.Relocate(ws, rename.Relocation{File: file, Moves: selfMoves(file, heads)})
+	rewritten, err := rename.ApplyEdits(src, append(changes[key], self[key]...))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	delete(changes, key)
+
+	rows := splitRows(rewritten)
+	var out [][]byte
+	out = append(out, rows[:mp.StartLine+f.LineOffset-1]...)
+	out = append(out, rows[from-1:to]...)
+	out = append(out, rows[mp.EndLine+f.LineOffset:]...)
+	return &Inlining{IndexKey: key, Index: joinRows(out), Included: included, Changes: changes}, nil
+}
+
+// FindIncludeLine returns the 1-based source line of the first
+// `<?include?>` in src whose file parameter names target, both taken
+// relative to file's directory.
+func FindIncludeLine(file string, src []byte, target string) (int, bool) {
+	file = index.NormalizePath(file)
+	want := path.Join(path.Dir(file), target)
+	f, _ := lint.NewFileFromSource(file, src, true) // never errors with current implementation
+	pairs, _ := gensection.FindMarkerPairs(f, "include", "", "")
+	for _, mp := range pairs {
+		dir, diags := gensection.ParseDirective(file, mp, "", "")
+		if len(diags) == 0 && path.Join(path.Dir(file), dir.Params["file"]) == want {
+			return mp.StartLine + f.LineOffset, true
+		}
+	}
+	return 0, false
+}
+
+// findInclude returns the include marker pair covering the 1-based
+// body line and the workspace path of the file it includes.
+func findInclude(f *lint.File, file string, line int) (gensection.MarkerPair, string, error) {
+	pairs, _ := gensection.FindMarkerPairs(f, "include", "", "")
+	for _, mp := range pairs {
+		if line < mp.StartLine || line > mp.EndLine {
+			continue
+		}
+		dir, diags := gensection.ParseDirective(file, mp, "", "")
+		if len(diags) > 0 || dir.Params["file"] == "" {
+			return mp, "", fmt.Errorf("line %d: include directive has no valid file parameter", mp.StartLine)
+		}
+		return mp, path.Join(path.Dir(file), dir.Params["file"]), nil
+	}
+	return gensection.MarkerPair{}, "", fmt.Errorf("line %d: %w", line, ErrNoInclude)
+}
+
+// selfMoves maps every heading of file onto itself. Relocating file
+// by them rewrites only its own links that spell out its name.
+func selfMoves(file string, heads []heading) []rename.Move {
+	var moves []rename.Move
+	for _, h := range heads {
+		if h.anchor != "" {
+			moves = append(moves, rename.Move{OldSlug: h.anchor, NewFile: file, NewSlug: h.anchor})
+		}
+	}
+	return moves
+}
+
+// inlineMoves pairs each heading of the included file with the copy
+// of it in file's include body (source lines [from, to]), in order.
+// A heading whose copy cannot be matched by text is left alone.
+func inlineMoves(file string, fileHeads, incHeads []heading, from, to int) []rename.Move {
+	var body []heading
+	for _, h := range fileHeads {
+		if h.line >= from && h.line <= to {
+			body = append(body, h)
+		}
+	}
+	var moves []rename.Move
+	j := 0
+	for _, h := range incHeads {
+		for j < len(body) && body[j].text != h.text {
+			j++
+		}
+		if j == len(body) {
+			break
+		}
+		if h.anchor != "" && body[j].anchor != "" {
+			moves = append(moves, rename.Move{OldSlug: h.anchor, NewFile: file, NewSlug: body[j].anchor})
+		}
+		j++
+	}
+	return moves
+}
@@ -0,0 +1,50 @@
+package split
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInline_ExpandsBodyAndRetargetsAnchors(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"g.md": "---\nt: 1\n---\n# G\n\nSee [s](part.md#setup).\n\n## Setup\n\n" +
+			"<?include\nfile: part.md\n?>\n## Setup\n\nx\n<?/include?>\n",
+		"part.md": "## Setup\n\nx\n",
+		"o.md":    "[s](part.md#setup) [t](./part.md#setup)\n",
+	})
+	res, err := Inline(ws, "g.md", 11)
+	require.NoError(t, err)
+	assert.Equal(t, "part.md", res.Included)
+	assert.Equal(t, "---\nt: 1\n---\n# G\n\nSee [s](#setup-1).\n\n## Setup\n\n## Setup\n\nx\n", string(res.Index))
+	got, err := rename.ApplyEdits(ws.files["o.md"], res.Changes["o.md"])
+	require.NoError(t, err)
+	assert.Equal(t, "[s](g.md#setup-1) [t](./g.md#setup-1)\n", string(got))
+	assert.NotContains(t, res.Changes, "part.md")
+}
+
+func TestInline_CollapsesSelfLinks(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"docs/g.md": "# G\n\nSee [usage](g.md#usage).\n\n" +
+			"<?include\nfile: usage.md\n?>\n## Usage\n\nBack to [g](./g.md#g).\n<?/include?>\n",
+		"docs/usage.md": "## Usage\n\nBack to [g](./g.md#g).\n",
+		"o.md":          "[g](docs/g.md#g)\n",
+	})
+	res, err := Inline(ws, "docs/g.md", 5)
+	require.NoError(t, err)
+	assert.Equal(t, "# G\n\nSee [usage](#usage).\n\n## Usage\n\nBack to [g](#g).\n", string(res.Index))
+	assert.NotContains(t, res.Changes, "o.md")
+}
+
+func TestInline_Errors(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"g.md":    "# G\n\n<?include\nfile: part.md\n?>\n<?/include?>\n",
+		"part.md": "x\n",
+	})
+	_, err := Inline(ws, "g.md", 1)
+	assert.ErrorIs(t, err, ErrNoInclude)
+	_, err = Inline(ws, "g.md", 4)
+	assert.ErrorIs(t, err, ErrEmptyInclude)
+}
@@ -0,0 +1,480 @@
+// Package split implements the section-moving refactorings: `mdsmith
+// split` carves a long Markdown file into one file per heading section
+// at a chosen level and turns the original into an index over them;
+// Extract moves a single section out behind an `<?include?>`; Inline
+// is its inverse, expanding an `<?include?>` in place.
+//
+// Each section file is named after its heading's anchor and lands in
+// the original's directory, so relative links inside a moved section
+// keep resolving. Anchor links that pointed into a moved section —
+// from anywhere in the workspace, the original file included — are
+// retargeted through rename.Relocate.
+//
+// The package reads through a rename.Workspace and returns bytes;
+// disk writes are the CLI layer's responsibility.
+package split
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/archetype/gensection"
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/mdtext"
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Mode selects what replaces the sections in the original file.
+type Mode int
+
+const (
+	// Catalog replaces each run of sections with a `<?catalog?>` that
+	// links the new files in their original order. Section headings
+	// are promoted so every new file starts at H1, and each file gets
+	// `title` / `weight` front matter for the catalog to render.
+	Catalog Mode = iota
+	// Include replaces each section with an `<?include?>` of its new
+	// file. As with Extract, a section whose parent heading sits one
+	// level up is promoted to start at H1 and included with
+	// `heading-level: absolute`; any other keeps its original levels.
+	// Either way the index renders as the original did.
+	Include
+	// TOC is Include plus a `<?toc?>` block ahead of the first
+	// include, listing the included headings.
+	TOC
+)
+
+// ParseMode maps the `--index` flag value to a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "catalog", "":
+		return Catalog, nil
+	case "include":
+		return Include, nil
+	case "toc":
+		return TOC, nil
+	}
+	return 0, fmt.Errorf("unknown index mode %q (want catalog, include, or toc)", s)
+}
+
+// Options configures Split.
+type Options struct {
+	// Level is the heading level that starts a section (1–6).
+	Level int
+	// Index selects how the original file points at the sections.
+	Index Mode
+}
+
+// Section is one new file produced by Split.
+type Section struct {
+	// File is the workspace-relative path of the new file.
+	File string
+	// Heading is the visible text of the section's heading.
+	Heading string
+	// Source is the new file's content.
+	Source []byte
+}
+
+// Result is the outcome of a split. Nothing has been written yet.
+type Result struct {
+	// IndexKey is the Workspace key of the original file.
+	IndexKey string
+	// Index is the original file's new content.
+	Index []byte
+	// Sections are the new files in document order.
+	Sections []Section
+	// Changes are the anchor rewrites for every other workspace
+	// file, keyed like rename.Heading's result.
+	Changes map[string][]rename.Edit
+}
+
+// ErrNoSections is returned when the file has no heading at the
+// requested level outside generated sections.
+var ErrNoSections = errors.New("no headings at the requested level")
+
+// EmptySlugError reports a section heading whose text slugifies to
+// nothing, so there is no file name to give its section.
+type EmptySlugError struct {
+	Line    int
+	Heading string
+}
+
+func (e EmptySlugError) Error() string {
+	return fmt.Sprintf("line %d: heading %q has no slug to name its file", e.Line, e.Heading)
+}
+
+// NameCollisionError reports a section whose file name is the
+// original file's own name.
+type NameCollisionError struct{ File string }
+
+func (e NameCollisionError) Error() string {
+	return fmt.Sprintf("section file %s would overwrite the file being split", e.File)
+}
+
+// heading is one heading of the original file.
+type heading struct {
+	line     int // 1-based source line
+	level    int
+	text     string
+	anchor   string // disambiguated anchor in the original file
+	document bool   // direct child of the document
+	topLevel bool   // document child outside generated bodies
+}
+
+// chunk is one section being moved: source lines [start, end].
+// absolute marks an Include or TOC section promoted to H1 and
+// re-leveled by `heading-level: absolute`.
+type chunk struct {
+	start, end int
+	head       heading
+	file       string
+	absolute   bool
+}
+
+// Split computes the split of file at opts.Level. It returns a
+// NameCollisionError or EmptySlugError before producing anything when
+// a section cannot be named, and ErrNoSections when there is nothing
+// to split.
+func Split(ws rename.Workspace, file string, opts Options) (*Result, error) {
+	if opts.Level < 1 || opts.Level > 6 {
+		return nil, fmt.Errorf("level %d out of range (want 1-6)", opts.Level)
+	}
+	file = index.NormalizePath(file)
+	key, src, ok := ws.Resolve(file)
+	if !ok {
+		return nil, fmt.Errorf("cannot read %s", file)
+	}
+	heads := collectHeadings(file, src)
+	lineCount := len(splitRows(src))
+	chunks, err := findChunks(file, heads, opts.Level, lineCount)
+	if err != nil {
+		return nil, err
+	}
+	home := func(line int) string {
+		for _, c := range chunks {
+			if line >= c.start && line <= c.end {
+				return c.file
+			}
+		}
+		return file
+	}
+	if opts.Index != Catalog {
+		markAbsolute(heads, chunks)
+	}
+	moves := planMoves(file, heads, chunks, opts.Index)
+	changes := rename.Relocate(ws, rename.Relocation{File: file, Moves: moves, Home: home})
+	rewritten, err := rename.ApplyEdits(src, changes[key])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	delete(changes, key)
+	res, err := assemble(key, rewritten, chunks, opts)
+	if err != nil {
+		return nil, err
+	}
+	res.Changes = changes
+	return res, nil
+}
+
+// markAbsolute sets absolute on each chunk whose parent — the closest
+// top-level heading before it that stays in the index — sits exactly
+// one level up, the condition Extract uses.
+func markAbsolute(heads []heading, chunks []chunk) {
+	for i := range chunks {
+		parentLevel := 0
+		for _, h := range heads {
+			if h.line >= chunks[i].start {
+				break
+			}
+			if h.topLevel && !inChunks(h.line, chunks) {
+				parentLevel = h.level
+			}
+		}
+		chunks[i].absolute = parentLevel == chunks[i].head.level-1
+	}
+}
+
+// inChunks reports whether the 1-based line falls in any chunk.
+func inChunks(line int, chunks []chunk) bool {
+	for _, c := range chunks {
+		if line >= c.start && line <= c.end {
+			return true
+		}
+	}
+	return false
+}
+
+// collectHeadings returns every heading of src in document order with
+// its original anchor. Headings nested in containers or generated
+// bodies still take part in slug disambiguation but are never
+// section boundaries.
+func collectHeadings(file string, src []byte) []heading {
+	f, _ := lint.NewFileFromSource(file, src, true) // never errors with current implementation
+	generated := gensection.FindAllGeneratedRanges(f)
+	var heads []heading
+	_ = ast.Walk(f.AST, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !entering || !ok || h.Lines().Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		bodyLine := f.LineOfOffset(h.Lines().At(0).Start)
+		_, isDoc := h.Parent().(*ast.Document)
+		heads = append(heads, heading{
+			line:     bodyLine + f.LineOffset,
+			level:    h.Level,
+			text:     mdtext.ExtractPlainText(h, f.Source),
+			document: isDoc,
+			topLevel: isDoc && !inRanges(bodyLine, generated),
+		})
+		return ast.WalkContinue, nil
+	})
+	texts := make([]string, len(heads))
+	for i, h := range heads {
+		texts[i] = h.text
+	}
+	for i, a := range rename.AssignSlugs(texts) {
+		heads[i].anchor = a
+	}
+	return heads
+}
+
+// findChunks cuts the file into sections: each top-level heading at
+// level starts one, running to the line before the next top-level
+// heading at that level or above.
+func findChunks(file string, heads []heading, level, lineCount int) ([]chunk, error) {
+	var chunks []chunk
+	for i, h := range heads {
+		if !h.topLevel || h.level != level {
+			continue
+		}
+		end := lineCount
+		for _, next := range heads[i+1:] {
+			if next.topLevel && next.level <= level {
+				end = next.line - 1
+				break
+			}
+		}
+		if h.anchor == "" {
+			return nil, EmptySlugError{Line: h.line, Heading: h.text}
+		}
+		name := path.Join(path.Dir(file), h.anchor+".md")
+		if name == file {
+			return nil, NameCollisionError{File: name}
+		}
+		chunks = append(chunks, chunk{start: h.line, end: end, head: h, file: name})
+	}
+	if len(chunks) == 0 {
+		return nil, ErrNoSections
+	}
+	return chunks, nil
+}
+
+// planMoves maps every anchored heading to its post-split home. A
+// heading in a section moves to the section file under the anchor it
+// gets there; a heading that stays keeps its anchor, except in
+// Catalog mode where removing the sections can shift the
+// disambiguator of later duplicates.
+func planMoves(file string, heads []heading, chunks []chunk, mode Mode) []rename.Move {
+	owner := make([]int, len(heads))
+	groups := make([][]string, len(chunks)+1) // last group: the index
+	for i, h := range heads {
+		owner[i] = len(chunks)
+		for c, ch := range chunks {
+			if h.line >= ch.start && h.line <= ch.end {
+				owner[i] = c
+				break
+			}
+		}
+		groups[owner[i]] = append(groups[owner[i]], h.text)
+	}
+	slugs := make([][]string, len(groups))
+	for g, texts := range groups {
+		slugs[g] = rename.AssignSlugs(texts)
+	}
+	seen := make([]int, len(groups))
+	var moves []rename.Move
+	for i, h := range heads {
+		g := owner[i]
+		newSlug := slugs[g][seen[g]]
+		seen[g]++
+		if h.anchor == "" {
+			continue
+		}
+		m := rename.Move{OldSlug: h.anchor, NewFile: file, NewSlug: h.anchor}
+		if g < len(chunks) {
+			m.NewFile, m.NewSlug = chunks[g].file, newSlug
+		} else if mode == Catalog {
+			m.NewSlug = newSlug
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+// assemble cuts the rewritten source into the section files and the
+// index. In Catalog mode each section file is promoted to start at
+// H1 and gets `title` / `weight` front matter for the catalog row;
+// in Include and TOC mode only absolute sections are promoted.
+func assemble(key string, src []byte, chunks []chunk, opts Options) (*Result, error) {
+	rows := splitRows(src)
+	res := &Result{IndexKey: key}
+	for i, c := range chunks {
+		body := joinRows(trimBlankRows(rows[c.start-1 : c.end]))
+		if c.absolute {
+			shifted, err := rename.ShiftLevels(body, 1, c.end-c.start+1, 1-opts.Level)
+			if err != nil {
+				return nil, err
+			}
+			body = shifted
+		}
+		if opts.Index == Catalog {
+			shifted, err := rename.ShiftLevels(body, 1, c.end-c.start+1, 1-opts.Level)
+			if err != nil {
+				return nil, err
+			}
+			fm, err := frontMatter(c.head.text, i+1)
+			if err != nil {
+				return nil, err
+			}
+			body = append(fm, shifted...)
+		}
+		res.Sections = append(res.Sections, Section{File: c.file, Heading: c.head.text, Source: body})
+	}
+	res.Index = buildIndex(rows, chunks, opts)
+	return res, nil
+}
+
+// sectionFrontMatter is the front matter of a Catalog-mode section.
+type sectionFrontMatter struct {
+	Title  string `yaml:"title"`
+	Weight int    `yaml:"weight"`
+}
+
+// frontMatter renders the `---`-fenced front matter for a section.
+func frontMatter(title string, weight int) ([]byte, error) {
+	data, err := yamlutil.Marshal(sectionFrontMatter{Title: title, Weight: weight})
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte("---\n"), data...)
+	return append(out, "---\n"...), nil
+}
+
+// buildIndex replaces each section's lines with its directive. In
+// Catalog mode consecutive sections share one `<?catalog?>`; in
+// Include and TOC mode each section gets its own `<?include?>`.
+// Directive bodies are left empty for `mdsmith fix` to generate once
+// the section files exist.
+func buildIndex(rows [][]byte, chunks []chunk, opts Options) []byte {
+	var out [][]byte
+	next := 1
+	tocDone := opts.Index != TOC
+	for i := 0; i < len(chunks); {
+		out = append(out, trimBlankRows(rows[next-1:chunks[i].start-1])...)
+		run := []chunk{chunks[i]}
+		for i+len(run) < len(chunks) && chunks[i+len(run)].start == run[len(run)-1].end+1 {
+			run = append(run, chunks[i+len(run)])
+		}
+		if !tocDone {
+			out = appendBlock(out, tocBlock(opts.Level))
+			tocDone = true
+		}
+		if opts.Index == Catalog {
+			out = appendBlock(out, catalogBlock(run))
+		} else {
+			for _, c := range run {
+				out = appendBlock(out, includeBlock(c))
+			}
+		}
+		next = run[len(run)-1].end + 1
+		i += len(run)
+	}
+	out = append(out, trimBlankRows(rows[next-1:])...)
+	return joinRows(out)
+}
+
+// appendBlock appends a directive block separated by a blank line
+// from whatever precedes it.
+func appendBlock(out [][]byte, block string) [][]byte {
+	if len(out) > 0 {
+		out = append(out, nil)
+	}
+	for _, l := range strings.Split(strings.TrimSuffix(block, "\n"), "\n") {
+		out = append(out, []byte(l))
+	}
+	return out
+}
+
+// catalogBlock lists a run of section files in document order. The
+// glob names each file literally; the `weight` front-matter key
+// assemble writes keeps the original order.
+func catalogBlock(run []chunk) string {
+	var b strings.Builder
+	b.WriteString("<?catalog\nglob:\n")
+	for _, c := range run {
+		fmt.Fprintf(&b, "  - %q\n", path.Base(c.file))
+	}
+	b.WriteString("sort: numeric:weight\n")
+	b.WriteString("row: \"- [{title}]({filename})\"\n?>\n<?/catalog?>\n")
+	return b.String()
+}
+
+// includeBlock embeds one section file, re-leveled under its parent
+// when the file was promoted.
+func includeBlock(c chunk) string {
+	level := ""
+	if c.absolute {
+		level = "heading-level: absolute\n"
+	}
+	return fmt.Sprintf("<?include\nfile: %s\n%s?>\n<?/include?>\n", path.Base(c.file), level)
+}
+
+// tocBlock lists the headings from the split level down.
+func tocBlock(level int) string {
+	return fmt.Sprintf("<?toc\nmin-level: %d\n?>\n<?/toc?>\n", level)
+}
+
+// inRanges reports whether the 1-based line falls in any range.
+func inRanges(line int, ranges []lint.LineRange) bool {
+	for _, r := range ranges {
+		if r.Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRows splits src into lines without their `\n`, dropping the
+// empty row a trailing newline leaves behind.
+func splitRows(src []byte) [][]byte {
+	rows := bytes.Split(src, []byte{'\n'})
+	if n := len(rows); n > 0 && len(rows[n-1]) == 0 {
+		rows = rows[:n-1]
+	}
+	return rows
+}
+
+// trimBlankRows drops leading and trailing blank rows.
+func trimBlankRows(rows [][]byte) [][]byte {
+	for len(rows) > 0 && len(bytes.TrimSpace(rows[0])) == 0 {
+		rows = rows[1:]
+	}
+	for len(rows) > 0 && len(bytes.TrimSpace(rows[len(rows)-1])) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
+
+// joinRows joins rows with `\n` and ends the result with one.
+func joinRows(rows [][]byte) []byte {
+	if len(rows) == 0 {
+		return nil
+	}
+	return append(bytes.Join(rows, []byte{'\n'}), '\n')
+}
@@ -0,0 +1,139 @@
+package split
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/index"
+	"github.com/jeduden/mdsmith/internal/rename"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memWorkspace backs rename.Workspace with the production index over
+// an in-memory file set.
+type memWorkspace struct {
+	idx   *index.Index
+	files map[string][]byte
+}
+
+func newMemWorkspace(files map[string]string) *memWorkspace {
+	bytesMap := make(map[string][]byte, len(files))
+	rels := make([]string, 0, len(files))
+	for rel, body := range files {
+		bytesMap[rel] = []byte(body)
+		rels = append(rels, rel)
+	}
+	idx := index.New(".")
+	idx.BuildSerial(rels, func(rel string) ([]byte, error) { return bytesMap[rel], nil })
+	return &memWorkspace{idx: idx, files: bytesMap}
+}
+
+func (w *memWorkspace) IncomingAnchorEdges(file, slug string) []index.Edge {
+	return w.idx.IncomingEdges(file, slug)
+}
+
+func (w *memWorkspace) Files() []string { return w.idx.Files() }
+
+func (w *memWorkspace) Resolve(file string) (string, []byte, bool) {
+	n := index.NormalizePath(file)
+	b, ok := w.files[n]
+	return n, b, ok
+}
+
+const guide = "# Guide\n\nSee [usage](#usage).\n\n## Setup\n\nInstall.\n\n" +
+	"## Usage\n\nBack to [setup](#setup).\n\n## FAQ\n\n### Usage\n\nDup.\n"
+
+func TestSplit_Catalog(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"docs/guide.md": guide,
+		"README.md":     "[u](docs/guide.md#usage) [d](docs/guide.md#usage-1)\n",
+	})
+	res, err := Split(ws, "docs/guide.md", Options{Level: 2, Index: Catalog})
+	require.NoError(t, err)
+	require.Len(t, res.Sections, 3)
+
+	assert.Equal(t, "docs/setup.md", res.Sections[0].File)
+	assert.Equal(t, "---\ntitle: Setup\nweight: 1\n---\n# Setup\n\nInstall.\n", string(res.Sections[0].Source))
+	assert.Equal(t, "---\ntitle: Usage\nweight: 2\n---\n# Usage\n\nBack to [setup](setup.md#setup).\n",
+		string(res.Sections[1].Source))
+	// The duplicate `### Usage` loses its disambiguator once alone.
+	assert.Equal(t, "---\ntitle: FAQ\nweight: 3\n---\n# FAQ\n\n## Usage\n\nDup.\n", string(res.Sections[2].Source))
+
+	assert.Equal(t, "# Guide\n\nSee [usage](usage.md#usage).\n\n<?catalog\nglob:\n"+
+		"  - \"setup.md\"\n  - \"usage.md\"\n  - \"faq.md\"\nsort: numeric:weight\n"+
+		"row: \"- [{title}]({filename})\"\n?>\n<?/catalog?>\n", string(res.Index))
+
+	got, err := rename.ApplyEdits(ws.files["README.md"], res.Changes["README.md"])
+	require.NoError(t, err)
+	assert.Equal(t, "[u](docs/usage.md#usage) [d](docs/faq.md#usage)\n", string(got))
+}
+
+func TestSplit_IncludePromotesUnderParent(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{"guide.md": guide})
+	res, err := Split(ws, "guide.md", Options{Level: 2, Index: Include})
+	require.NoError(t, err)
+	assert.Equal(t, "# Setup\n\nInstall.\n", string(res.Sections[0].Source))
+	assert.Equal(t, "# FAQ\n\n## Usage\n\nDup.\n", string(res.Sections[2].Source))
+	assert.Equal(t, "# Guide\n\nSee [usage](usage.md#usage).\n\n"+
+		"<?include\nfile: setup.md\nheading-level: absolute\n?>\n<?/include?>\n\n"+
+		"<?include\nfile: usage.md\nheading-level: absolute\n?>\n<?/include?>\n\n"+
+		"<?include\nfile: faq.md\nheading-level: absolute\n?>\n<?/include?>\n", string(res.Index))
+}
+
+func TestSplit_IncludeWithoutParentKeepsLevels(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{"g.md": "Intro.\n\n## A\n\nx\n\n## B\n\ny\n"})
+	res, err := Split(ws, "g.md", Options{Level: 2, Index: Include})
+	require.NoError(t, err)
+	assert.Equal(t, "## A\n\nx\n", string(res.Sections[0].Source))
+	assert.Equal(t, "Intro.\n\n<?include\nfile: a.md\n?>\n<?/include?>\n\n"+
+		"<?include\nfile: b.md\n?>\n<?/include?>\n", string(res.Index))
+}
+
+func TestSplit_TOCPrecedesIncludesAndKeepsTrailer(t *testing.T) {
+	src := "# Guide\n\n## A\n\nx\n\n# Appendix\n\ny\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src})
+	res, err := Split(ws, "g.md", Options{Level: 2, Index: TOC})
+	require.NoError(t, err)
+	assert.Equal(t, "# Guide\n\n<?toc\nmin-level: 2\n?>\n<?/toc?>\n\n"+
+		"<?include\nfile: a.md\nheading-level: absolute\n?>\n<?/include?>\n# Appendix\n\ny\n", string(res.Index))
+	assert.Equal(t, "# A\n\nx\n", string(res.Sections[0].Source))
+}
+
+func TestSplit_Errors(t *testing.T) {
+	ws := newMemWorkspace(map[string]string{
+		"a.md":     "# A\n\ntext\n",
+		"setup.md": "# Top\n\n## Setup\n\nx\n",
+		"sym.md":   "# T\n\n## !!!\n\nx\n",
+	})
+	_, err := Split(ws, "a.md", Options{Level: 2})
+	assert.ErrorIs(t, err, ErrNoSections)
+
+	_, err = Split(ws, "setup.md", Options{Level: 2})
+	var collision NameCollisionError
+	assert.ErrorAs(t, err, &collision)
+
+	_, err = Split(ws, "sym.md", Options{Level: 2})
+	var empty EmptySlugError
+	require.ErrorAs(t, err, &empty)
+	assert.Equal(t, 3, empty.Line)
+
+	_, err = Split(ws, "a.md", Options{Level: 7})
+	assert.Error(t, err)
+}
+
+func TestSplit_SkipsGeneratedAndNestedHeadings(t *testing.T) {
+	src := "# G\n\n> ## Quoted\n\n<?include\nfile: x.md\n?>\n## Included\n<?/include?>\n"
+	ws := newMemWorkspace(map[string]string{"g.md": src, "x.md": "## Included\n"})
+	_, err := Split(ws, "g.md", Options{Level: 2})
+	assert.ErrorIs(t, err, ErrNoSections)
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": Catalog, "catalog": Catalog, "include": Include, "toc": TOC} {
+		got, err := ParseMode(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseMode("list")
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package yamlutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotList reports that AppendToList found a value it cannot add a
+// list item to, such as a scalar or a flow mapping.
+var ErrNotList = errors.New("not an editable list")
+
+// AppendToList returns src, the text of a YAML document, with item
+// appended to the string list at path (a chain of mapping keys).
+// Missing keys are created as block mappings and the rest of the
+// text is kept byte for byte, comments and layout included, which a
+// decode and re-encode would not do. src comes back unchanged when
+// item is already listed. It fails with ErrNotList when a value on
+// the path is neither a block mapping nor, at the end, a list.
+func AppendToList(src []byte, path []string, item string) ([]byte, error) {
+	doc, err := UnmarshalNodeSafe(src)
+	if err != nil {
+		return nil, err
+	}
+	scalar, err := yaml.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	e := &listEditor{src: src, path: path, item: item, scalar: strings.TrimSpace(string(scalar))}
+	if len(doc.Content) == 0 {
+		return e.insert(len(src), e.block(0, path)), nil
+	}
+	return e.edit(doc.Content[0], nil, 0)
+}
+
+// listEditor inserts one list item into a YAML document's text.
+type listEditor struct {
+	src    []byte
+	path   []string
+	item   string
+	scalar string // item as a YAML scalar
+}
+
+// edit descends path from node, the value of key (nil for the
+// document root), depth levels down.
+func (e *listEditor) edit(node, key *yaml.Node, depth int) ([]byte, error) {
+	if depth == len(e.path) {
+		return e.addItem(node, key)
+	}
+	if isEmpty(node) && key != nil {
+		return e.insert(e.lineEnd(key.Line), e.block(key.Column+1, e.path[depth:])), nil
+	}
+	if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 {
+		return nil, fmt.Errorf("%s: %w", strings.Join(e.path[:depth], "."), ErrNotList)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == e.path[depth] {
+			return e.edit(node.Content[i+1], node.Content[i], depth+1)
+		}
+	}
+	if key == nil {
+		return e.insert(len(e.src), e.block(0, e.path[depth:])), nil
+	}
+	indent := key.Column + 1
+	if len(node.Content) > 0 {
+		indent = node.Content[0].Column - 1
+	}
+	return e.insert(e.lineEnd(key.Line), e.block(indent, e.path[depth:])), nil
+}
+
+// addItem appends the item to seq, the value of key.
+func (e *listEditor) addItem(seq, key *yaml.Node) ([]byte, error) {
+	if isEmpty(seq) {
+		return e.insert(e.lineEnd(key.Line), e.block(key.Column+1, nil)), nil
+	}
+	if seq.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s: %w", strings.Join(e.path, "."), ErrNotList)
+	}
+	for _, it := range seq.Content {
+		if it.Value == e.item {
+			return e.src, nil
+		}
+	}
+	if seq.Style&yaml.FlowStyle == 0 {
+		last := seq.Content[len(seq.Content)-1]
+		return e.insert(e.lineEnd(last.Line), e.block(seq.Column-1, nil)), nil
+	}
+	end := closingBracket(e.src, e.offset(seq.Line, seq.Column))
+	if end < 0 {
+		return nil, fmt.Errorf("%s: %w", strings.Join(e.path, "."), ErrNotList)
+	}
+	text := e.scalar
+	if len(seq.Content) > 0 {
+		text = ", " + text
+	}
+	return e.splice(end, text), nil
+}
+
+// block renders keys as nested block mappings at indent, ending in a
+// one-entry sequence holding the item.
+func (e *listEditor) block(indent int, keys []string) string {
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(strings.Repeat(" ", indent) + k + ":\n")
+		indent += 2
+	}
+	b.WriteString(strings.Repeat(" ", indent) + "- " + e.scalar + "\n")
+	return b.String()
+}
+
+// insert adds whole lines of text at off, the start of a line or the
+// end of the file.
+func (e *listEditor) insert(off int, text string) []byte {
+	if off == len(e.src) && off > 0 && e.src[off-1] != '\n' {
+		text = "\n" + text
+	}
+	return e.splice(off, text)
+}
+
+func (e *listEditor) splice(off int, text string) []byte {
+	out := make([]byte, 0, len(e.src)+len(text))
+	out = append(out, e.src[:off]...)
+	out = append(out, text...)
+	return append(out, e.src[off:]...)
+}
+
+// offset returns the byte offset of a 1-based line and column.
+func (e *listEditor) offset(line, col int) int {
+	return e.lineStart(line) + col - 1
+}
+
+// lineStart returns the byte offset where 1-based line begins.
+func (e *listEditor) lineStart(line int) int {
+	off := 0
+	for n := 1; n < line; n++ {
+		i := bytes.IndexByte(e.src[off:], '\n')
+		if i < 0 {
+			return len(e.src)
+		}
+		off += i + 1
+	}
+	return off
+}
+
+// lineEnd returns the offset just past line's newline, where a new
+// line after it starts.
+func (e *listEditor) lineEnd(line int) int {
+	return e.lineStart(line + 1)
+}
+
+// isEmpty reports whether n is a value left blank, as in "words:".
+func isEmpty(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null" && n.Value == ""
+}
+
+// closingBracket returns the offset of the ']' that closes the flow
+// sequence opening at start, skipping quoted strings.
+func closingBracket(src []byte, start int) int {
+	var quote byte
+	for i := start; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
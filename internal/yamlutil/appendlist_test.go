@@ -0,0 +1,88 @@
+package yamlutil_test
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/yamlutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var wordsPath = []string{"rules", "spelling", "words"}
+
+func TestAppendToList(t *testing.T) {
+	tests := []struct {
+		name, cfg, want string
+	}{
+		{
+			name: "empty file",
+			cfg:  "",
+			want: "rules:\n  spelling:\n    words:\n      - kubectl\n",
+		},
+		{
+			name: "no rules key",
+			cfg:  "# project\nfront-matter: true",
+			want: "# project\nfront-matter: true\nrules:\n  spelling:\n    words:\n      - kubectl\n",
+		},
+		{
+			name: "no spelling key",
+			cfg:  "rules:\n    line-length: false\n",
+			want: "rules:\n    spelling:\n      words:\n        - kubectl\n    line-length: false\n",
+		},
+		{
+			name: "no words key",
+			cfg:  "rules:\n  spelling:\n    dictionaries: [en.dic] # local\n",
+			want: "rules:\n  spelling:\n    words:\n      - kubectl\n    dictionaries: [en.dic] # local\n",
+		},
+		{
+			name: "blank words",
+			cfg:  "rules:\n  spelling:\n    words:\n",
+			want: "rules:\n  spelling:\n    words:\n      - kubectl\n",
+		},
+		{
+			name: "block list",
+			cfg:  "rules:\n  spelling:\n    words:\n    - mdsmith # tool\n\n  x: true\n",
+			want: "rules:\n  spelling:\n    words:\n    - mdsmith # tool\n    - kubectl\n\n  x: true\n",
+		},
+		{
+			name: "flow list",
+			cfg:  "rules:\n  spelling:\n    words: [\"a]b\", mdsmith]\n",
+			want: "rules:\n  spelling:\n    words: [\"a]b\", mdsmith, kubectl]\n",
+		},
+		{
+			name: "empty flow list",
+			cfg:  "rules:\n  spelling:\n    words: []\n",
+			want: "rules:\n  spelling:\n    words: [kubectl]\n",
+		},
+		{
+			name: "already listed",
+			cfg:  "rules:\n  spelling:\n    words: [kubectl]\n",
+			want: "rules:\n  spelling:\n    words: [kubectl]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlutil.AppendToList([]byte(tt.cfg), wordsPath, "kubectl")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestAppendToList_QuotesYAMLKeywords(t *testing.T) {
+	got, err := yamlutil.AppendToList([]byte("rules:\n  spelling:\n    words: []\n"), wordsPath, "null")
+	require.NoError(t, err)
+	assert.Equal(t, "rules:\n  spelling:\n    words: [\"null\"]\n", string(got))
+}
+
+func TestAppendToList_NotAList(t *testing.T) {
+	for _, cfg := range []string{
+		"rules:\n  spelling: true\n",
+		"rules: {spelling: {words: []}}\n",
+		"rules:\n  spelling:\n    words: kubectl\n",
+		"- a\n",
+	} {
+		_, err := yamlutil.AppendToList([]byte(cfg), wordsPath, "kubectl")
+		assert.ErrorIs(t, err, yamlutil.ErrNotList, cfg)
+	}
+}
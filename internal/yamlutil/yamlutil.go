@@ -12,6 +12,10 @@
 //     (needed when inspecting YAML structure before decoding into typed values).
 //   - [Marshal] — thin wrapper around yaml.Marshal for consistency; safe for
 //     output marshaling where data originates from trusted Go values.
+//   - [ValidateSyntax] — check that text parses as YAML without decoding it,
+//     e.g. a snippet in a fenced code block.
+//   - [AppendToList] — add an item to a list in a user's YAML file while
+//     keeping the rest of the text, comments and layout, unchanged.
 //
 // See docs/security/2026-04-05-adversarial-markdown.md for threat model context.
 package yamlutil
@@ -82,6 +86,23 @@ func UnmarshalNodeSafe(data []byte) (yaml.Node, error) {
 	return node, nil
 }
 
+// ValidateSyntax reports the first syntax error in data, which may hold
+// several "---"-separated documents. It decodes into yaml.Node trees
+// only, so anchors and aliases are accepted but never expanded.
+func ValidateSyntax(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 // Marshal is a thin wrapper around yaml.Marshal for consistency with
 // UnmarshalSafe. Safe for output marshaling where data originates from
 // trusted Go values.
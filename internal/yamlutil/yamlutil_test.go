@@ -171,3 +171,11 @@ func TestTopLevelMappingLines(t *testing.T) {
 		assert.Equal(t, map[string]int{"id": 3}, lines)
 	})
 }
+
+func TestValidateSyntax(t *testing.T) {
+	assert.NoError(t, yamlutil.ValidateSyntax(nil))
+	assert.NoError(t, yamlutil.ValidateSyntax([]byte("a: 1\n---\nb: &x [1]\nc: *x\n")))
+	err := yamlutil.ValidateSyntax([]byte("a: 1\n---\nb: c: d\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+}
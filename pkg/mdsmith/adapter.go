@@ -0,0 +1,128 @@
+package mdsmith
+
+import (
+	"reflect"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// adapter presents a caller-defined Rule to the engine as a
+// rule.Rule. wrap picks the variant matching the optional interfaces
+// the wrapped rule implements, because the engine discovers fixing
+// and settings support by type assertion.
+type adapter struct {
+	r Rule
+}
+
+// fixAdapter wraps a FixableRule.
+type fixAdapter struct{ adapter }
+
+// confAdapter wraps a Configurable Rule.
+type confAdapter struct{ adapter }
+
+// confFixAdapter wraps a Configurable FixableRule.
+type confFixAdapter struct{ confAdapter }
+
+var (
+	_ rule.Cloner       = (*adapter)(nil)
+	_ rule.FixableRule  = (*fixAdapter)(nil)
+	_ rule.Configurable = (*confAdapter)(nil)
+	_ rule.FixableRule  = (*confFixAdapter)(nil)
+)
+
+// wrap returns the engine adapter for r.
+func wrap(r Rule) rule.Rule {
+	_, fixable := r.(FixableRule)
+	_, conf := r.(Configurable)
+	a := adapter{r: r}
+	switch {
+	case fixable && conf:
+		return &confFixAdapter{confAdapter{a}}
+	case fixable:
+		return &fixAdapter{a}
+	case conf:
+		return &confAdapter{a}
+	default:
+		return &a
+	}
+}
+
+func (a *adapter) ID() string       { return a.r.ID() }
+func (a *adapter) Name() string     { return a.r.Name() }
+func (a *adapter) Category() string { return a.r.Category() }
+
+// Check runs the wrapped rule and fills the fields a caller may leave
+// empty on its diagnostics.
+func (a *adapter) Check(f *lint.File) []lint.Diagnostic {
+	diags := a.r.Check(newFile(f))
+	if len(diags) == 0 {
+		return nil
+	}
+	out := make([]lint.Diagnostic, len(diags))
+	for i, d := range diags {
+		out[i] = lint.Diagnostic{
+			File:     d.File,
+			Line:     d.Line,
+			Column:   d.Column,
+			RuleID:   d.RuleID,
+			RuleName: d.RuleName,
+			Severity: lint.Severity(d.Severity),
+			Message:  d.Message,
+		}
+		if out[i].File == "" {
+			out[i].File = f.Path
+		}
+		if out[i].RuleID == "" {
+			out[i].RuleID = a.r.ID()
+		}
+		if out[i].RuleName == "" {
+			out[i].RuleName = a.r.Name()
+		}
+		if out[i].Severity == "" {
+			out[i].Severity = lint.Error
+		}
+	}
+	return out
+}
+
+// Clone copies the wrapped rule rather than the adapter, whose zero
+// value would have no rule at all. It mirrors rule.CloneRule and
+// rule.CloneInstance: a reset clone of a Configurable pointer rule is
+// a fresh zero value with DefaultSettings applied; every other clone
+// is a shallow copy.
+func (a *adapter) Clone(reset bool) rule.Rule {
+	rv := reflect.ValueOf(a.r)
+	if rv.Kind() != reflect.Ptr {
+		return wrap(a.r)
+	}
+	clone := reflect.New(rv.Elem().Type())
+	c, conf := a.r.(Configurable)
+	if !reset || !conf {
+		clone.Elem().Set(rv.Elem())
+		return wrap(clone.Interface().(Rule))
+	}
+	fresh := clone.Interface().(Rule)
+	_ = fresh.(Configurable).ApplySettings(c.DefaultSettings())
+	return wrap(fresh)
+}
+
+// Fix runs the wrapped rule's fix.
+func (a *fixAdapter) Fix(f *lint.File) []byte {
+	return a.r.(FixableRule).Fix(newFile(f))
+}
+
+// ApplySettings forwards to the wrapped rule.
+func (a *confAdapter) ApplySettings(settings map[string]any) error {
+	return a.r.(Configurable).ApplySettings(settings)
+}
+
+// DefaultSettings forwards to the wrapped rule.
+func (a *confAdapter) DefaultSettings() map[string]any {
+	return a.r.(Configurable).DefaultSettings()
+}
+
+// Fix runs the wrapped rule's fix.
+func (a *confFixAdapter) Fix(f *lint.File) []byte {
+	return a.r.(FixableRule).Fix(newFile(f))
+}
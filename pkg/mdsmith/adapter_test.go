@@ -0,0 +1,62 @@
+package mdsmith
+
+import (
+	"testing"
+
+	"github.com/jeduden/mdsmith/internal/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type settingsRule struct {
+	Max int
+}
+
+func (r *settingsRule) ID() string                 { return "X001" }
+func (r *settingsRule) Name() string               { return "settings" }
+func (r *settingsRule) Category() string           { return "test" }
+func (r *settingsRule) Check(_ *File) []Diagnostic { return nil }
+func (r *settingsRule) Fix(f *File) []byte         { return f.Source }
+
+func (r *settingsRule) ApplySettings(settings map[string]any) error {
+	if v, ok := settings["max"].(int); ok {
+		r.Max = v
+	}
+	return nil
+}
+
+func (r *settingsRule) DefaultSettings() map[string]any {
+	return map[string]any{"max": 10}
+}
+
+type valueRule struct{}
+
+func (valueRule) ID() string                 { return "X002" }
+func (valueRule) Name() string               { return "value" }
+func (valueRule) Category() string           { return "test" }
+func (valueRule) Check(_ *File) []Diagnostic { return nil }
+
+func TestWrap_PicksVariantByInterfaces(t *testing.T) {
+	assert.IsType(t, &confFixAdapter{}, wrap(&settingsRule{}))
+	assert.IsType(t, &adapter{}, wrap(valueRule{}))
+	_, fixable := wrap(valueRule{}).(rule.FixableRule)
+	assert.False(t, fixable)
+	_, conf := wrap(valueRule{}).(rule.Configurable)
+	assert.False(t, conf)
+}
+
+func TestAdapter_CloneCopiesWrappedRule(t *testing.T) {
+	orig := &settingsRule{Max: 42}
+	wrapped := wrap(orig)
+
+	inst := rule.CloneInstance(wrapped).(*confFixAdapter)
+	require.NotSame(t, orig, inst.r)
+	assert.Equal(t, 42, inst.r.(*settingsRule).Max)
+
+	fresh := rule.CloneRule(wrapped).(*confFixAdapter)
+	require.NotSame(t, orig, fresh.r)
+	assert.Equal(t, 10, fresh.r.(*settingsRule).Max)
+	assert.Equal(t, 42, orig.Max)
+
+	assert.Equal(t, valueRule{}, rule.CloneRule(wrap(valueRule{})).(*adapter).r)
+}
@@ -0,0 +1,86 @@
+package mdsmith
+
+import (
+	"fmt"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/lint"
+
+	// Register the built-in rules so config defaults and every
+	// Linter see the same rule set as the CLI.
+	_ "github.com/jeduden/mdsmith/internal/rules/all"
+)
+
+// Config is a resolved mdsmith configuration: the built-in defaults
+// merged with an optional .mdsmith.yml, as `mdsmith check` sees it.
+// A Config is immutable once built and safe to share between Linters.
+type Config struct {
+	cfg  *config.Config
+	path string
+}
+
+// DefaultConfig returns the configuration used when no .mdsmith.yml
+// exists: every built-in rule at its default enabled state.
+func DefaultConfig() *Config {
+	return &Config{cfg: config.Merge(config.Defaults(), nil)}
+}
+
+// LoadConfig reads the .mdsmith.yml at path and merges it over the
+// built-in defaults.
+func LoadConfig(path string) (*Config, error) {
+	loaded, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.Merge(config.Defaults(), loaded)
+	config.InjectBuildConfig(cfg, path)
+	return &Config{cfg: cfg, path: path}, nil
+}
+
+// DiscoverConfig walks up from dir to the nearest .mdsmith.yml,
+// stopping at a .git directory, and loads it. It returns
+// DefaultConfig when no config file is found.
+func DiscoverConfig(dir string) (*Config, error) {
+	path, err := config.Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return DefaultConfig(), nil
+	}
+	return LoadConfig(path)
+}
+
+// Path returns the config file the Config was loaded from, or "" for
+// DefaultConfig.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Deprecations returns human-readable warnings about deprecated keys
+// in the loaded config file. The CLI prints them to stderr.
+func (c *Config) Deprecations() []string {
+	return append([]string(nil), c.cfg.Deprecations...)
+}
+
+// maxInputBytes resolves the config's max-input-size to a byte cap,
+// falling back to the CLI default when the key is absent.
+func (c *Config) maxInputBytes() (int64, error) {
+	if c.cfg.MaxInputSize == "" {
+		return lint.DefaultMaxInputBytes, nil
+	}
+	n, err := config.ParseSize(c.cfg.MaxInputSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max-input-size %q: %w", c.cfg.MaxInputSize, err)
+	}
+	return n, nil
+}
+
+// stripFrontMatter reports whether YAML front matter is split off
+// before rules run (the `front-matter:` key, default true).
+func (c *Config) stripFrontMatter() bool {
+	if c.cfg.FrontMatter != nil {
+		return *c.cfg.FrontMatter
+	}
+	return true
+}
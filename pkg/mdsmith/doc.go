@@ -0,0 +1,23 @@
+// Package mdsmith embeds the mdsmith lint engine in a Go program.
+//
+// It is the in-process alternative to shelling out to the binary and
+// parsing `check --format json`: load a [Config] the same way the CLI
+// does ([LoadConfig], [DiscoverConfig], [DefaultConfig]), build a
+// [Linter] with [New], then lint in-memory sources ([Linter.Lint]) or
+// files on an [io/fs.FS] ([Linter.LintFS]) and apply fixes
+// ([Linter.Fix], [Linter.FixFS]). Every call returns typed
+// [Diagnostic] values, never formatted text.
+//
+// The built-in rules are always present. [Options.Rules] registers
+// caller-defined [Rule] and [FixableRule] implementations alongside
+// them for one Linter; they are enabled unless the config turns them
+// off by name, and a [Configurable] rule receives its settings from
+// the config's `rules:` block exactly like a built-in.
+//
+// The package is a facade: the engine, config, and rule registry stay
+// in internal packages, and nothing here aliases their types, so the
+// engine can keep changing underneath.
+//
+// As a public package this is a cross-system contract. Its
+// compatibility policy lives in docs/development/go-api.md.
+package mdsmith
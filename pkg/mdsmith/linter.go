@@ -0,0 +1,326 @@
+package mdsmith
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jeduden/mdsmith/internal/config"
+	"github.com/jeduden/mdsmith/internal/customrule"
+	"github.com/jeduden/mdsmith/internal/engine"
+	fixpkg "github.com/jeduden/mdsmith/internal/fix"
+	"github.com/jeduden/mdsmith/internal/plugin"
+	"github.com/jeduden/mdsmith/internal/rule"
+)
+
+// Options configures a Linter. The zero value lints with
+// DefaultConfig and the built-in rules only.
+type Options struct {
+	// Config is the configuration to lint with. Nil means
+	// DefaultConfig.
+	Config *Config
+	// Rules are caller-defined rules that run alongside the
+	// built-ins. They are enabled unless the config disables them
+	// by name.
+	Rules []Rule
+	// RootDir is the project root on disk, used by Lint and Fix for
+	// rules that resolve root-relative paths and for .gitignore
+	// matching. Empty means the directory of the config file, or none
+	// for DefaultConfig. LintFS and FixFS ignore it: their paths are
+	// relative to the fs.FS, not the disk.
+	RootDir string
+	// MaxInputBytes caps the size of each linted source. Zero uses
+	// the config's max-input-size (2 MB when unset); a negative
+	// value removes the cap.
+	MaxInputBytes int64
+	// TrustPluginCommands lets the config's `command:` plugins run.
+	// They are arbitrary executables, so leave it false for configs
+	// you did not write; sandboxed `wasm:` plugins run either way.
+	TrustPluginCommands bool
+}
+
+// Result is the outcome of linting one or more documents.
+type Result struct {
+	// FilesChecked is the number of documents linted, after the
+	// config's ignore list.
+	FilesChecked int
+	// Diagnostics are sorted by file, line, column, and message.
+	Diagnostics []Diagnostic
+	// Errors are per-document failures (unreadable, oversized, bad
+	// front matter, invalid rule settings). A document with an
+	// error may still contribute diagnostics from other rules.
+	Errors []error
+}
+
+// Linter lints and fixes Markdown with one config and rule set. It
+// holds no per-call state and is safe for concurrent use. A config
+// with `plugins:` starts their processes on first use; call Close
+// to stop them.
+type Linter struct {
+	cfg      *config.Config
+	rules    []rule.Rule
+	plugins  *plugin.Pool
+	rootDir  string
+	maxBytes int64
+	strip    bool
+}
+
+// New validates opts and returns a Linter. It fails when a custom
+// rule has an empty ID or Name, or reuses the ID or Name of a
+// built-in or another custom rule.
+func New(opts Options) (*Linter, error) {
+	c := opts.Config
+	if c == nil {
+		c = DefaultConfig()
+	}
+	maxBytes := opts.MaxInputBytes
+	if maxBytes == 0 {
+		n, err := c.maxInputBytes()
+		if err != nil {
+			return nil, err
+		}
+		maxBytes = n
+	}
+	plugins := plugin.NewPool()
+	if opts.TrustPluginCommands {
+		plugins.TrustCommands()
+	}
+	base := customrule.With(rule.All(), c.cfg.CustomRules)
+	base = append(base, plugin.Rules(plugins, c.cfg.Plugins)...)
+	custom, err := wrapRules(base, opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+	rootDir := opts.RootDir
+	if rootDir == "" && c.path != "" {
+		rootDir = filepath.Dir(c.path)
+	}
+	return &Linter{
+		cfg:      withCustomRules(c.cfg, custom),
+		rules:    append(base, custom...),
+		plugins:  plugins,
+		rootDir:  rootDir,
+		maxBytes: maxBytes,
+		strip:    c.stripFrontMatter(),
+	}, nil
+}
+
+// Close stops the plugin processes l started. The Linter stays
+// usable; the next call that needs a plugin starts it again.
+func (l *Linter) Close() {
+	l.plugins.Close()
+}
+
+// wrapRules validates custom rules against base, the built-in and
+// config-declared rules, and adapts them for the engine.
+func wrapRules(base []rule.Rule, rules []Rule) ([]rule.Rule, error) {
+	ids := map[string]bool{}
+	names := map[string]bool{}
+	for _, r := range base {
+		ids[r.ID()] = true
+		names[r.Name()] = true
+	}
+	out := make([]rule.Rule, 0, len(rules))
+	for _, r := range rules {
+		id, name := r.ID(), r.Name()
+		switch {
+		case id == "" || name == "":
+			return nil, fmt.Errorf("custom rule %T: ID and Name must not be empty", r)
+		case ids[id]:
+			return nil, fmt.Errorf("custom rule %q: ID %s is already registered", name, id)
+		case names[name]:
+			return nil, fmt.Errorf("custom rule %s: name %q is already registered", id, name)
+		}
+		ids[id], names[name] = true, true
+		out = append(out, wrap(r))
+	}
+	return out, nil
+}
+
+// withCustomRules returns a copy of cfg in which every custom rule
+// the config does not mention is enabled. config.Defaults only
+// covers registered rules, and the engine skips rules absent from
+// the effective config.
+func withCustomRules(cfg *config.Config, custom []rule.Rule) *config.Config {
+	out := config.Merge(cfg, nil)
+	for _, r := range custom {
+		if _, ok := out.Rules[r.Name()]; !ok {
+			out.Rules[r.Name()] = config.RuleCfg{Enabled: true}
+		}
+	}
+	return out
+}
+
+// runner returns an engine runner for one call; fsys is the
+// directory view rules see for neighbouring files. Documents read
+// from an fs.FS have fsys-relative paths, so they get no disk root.
+func (l *Linter) runner(fsys fs.FS) *engine.Runner {
+	rootDir := l.rootDir
+	if fsys != nil {
+		rootDir = ""
+	}
+	return &engine.Runner{
+		Config:            l.cfg,
+		Rules:             l.ruleSet(),
+		StripFrontMatter:  l.strip,
+		RootDir:           rootDir,
+		MaxInputBytes:     l.maxBytes,
+		SkipSourceContext: true,
+		SourceFS:          fsys,
+	}
+}
+
+// ruleSet returns a private copy of the rule set for one call, the
+// way each engine worker clones its own, so concurrent calls never
+// share a rule instance's state.
+func (l *Linter) ruleSet() []rule.Rule {
+	out := make([]rule.Rule, len(l.rules))
+	for i, rl := range l.rules {
+		out[i] = rule.CloneInstance(rl)
+	}
+	return out
+}
+
+// Lint lints src as the document at path. path selects the config's
+// overrides and kinds and names the file in diagnostics; nothing is
+// read from disk, so rules that follow includes or links to other
+// files see no filesystem. Use LintFS for those.
+func (l *Linter) Lint(path string, src []byte) *Result {
+	res := l.runner(nil).RunSource(path, src)
+	return &Result{
+		FilesChecked: res.FilesChecked,
+		Diagnostics:  fromLint(res.Diagnostics),
+		Errors:       res.Errors,
+	}
+}
+
+// LintFS lints the named documents in fsys. Paths are slash-separated
+// and relative to the root of fsys, as for fs.ReadFile; with no paths
+// it lints every .md and .markdown file in fsys. Paths matching the
+// config's ignore list are skipped. Each document sees its own
+// directory in fsys as File.FS.
+func (l *Linter) LintFS(fsys fs.FS, paths ...string) *Result {
+	res := &Result{}
+	if len(paths) == 0 {
+		found, err := markdownFiles(fsys)
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+		}
+		paths = found
+	}
+	for _, p := range paths {
+		if config.IsIgnored(l.cfg.Ignore, p) {
+			continue
+		}
+		res.FilesChecked++
+		src, dir, err := readFS(fsys, p)
+		if err != nil {
+			res.Errors = append(res.Errors, err)
+			continue
+		}
+		r := l.runner(dir).RunSource(p, src)
+		res.Diagnostics = append(res.Diagnostics, fromLint(r.Diagnostics)...)
+		res.Errors = append(res.Errors, r.Errors...)
+	}
+	sortDiagnostics(res.Diagnostics)
+	return res
+}
+
+// Fix applies every enabled fixable rule to src as the document at
+// path and returns the fixed bytes, which equal src when nothing
+// changed. Like Lint it reads nothing from disk. Diagnostics that
+// remain can be listed by linting the result.
+func (l *Linter) Fix(path string, src []byte) ([]byte, error) {
+	return l.fix(path, src, nil)
+}
+
+// FixFS is Fix for the document at path in fsys. fsys is read-only;
+// writing the returned bytes back is up to the caller.
+func (l *Linter) FixFS(fsys fs.FS, path string) ([]byte, error) {
+	src, dir, err := readFS(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	return l.fix(path, src, dir)
+}
+
+func (l *Linter) fix(path string, src []byte, dir fs.FS) ([]byte, error) {
+	rootDir := l.rootDir
+	if dir != nil {
+		rootDir = ""
+	}
+	return fixpkg.Source(fixpkg.SourceOptions{
+		Config:           l.cfg,
+		Rules:            l.ruleSet(),
+		Path:             path,
+		Source:           src,
+		RootDir:          rootDir,
+		StripFrontMatter: l.strip,
+		MaxInputBytes:    l.maxBytes,
+		SourceFS:         dir,
+	})
+}
+
+// readFS reads p from fsys and returns it with the sub-filesystem
+// rooted at its directory.
+func readFS(fsys fs.FS, p string) ([]byte, fs.FS, error) {
+	src, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %q: %w", p, err)
+	}
+	dir, err := fs.Sub(fsys, path.Dir(p))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %q: %w", p, err)
+	}
+	return src, dir, nil
+}
+
+// markdownFiles lists the .md and .markdown files in fsys in lexical
+// order.
+func markdownFiles(fsys fs.FS) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(path.Ext(p))
+		if ext == ".md" || ext == ".markdown" {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return files, fmt.Errorf("walking files: %w", err)
+	}
+	return files, nil
+}
+
+// Err joins the Result's errors, or returns nil when there are none.
+func (r *Result) Err() error {
+	return errors.Join(r.Errors...)
+}
+
+// sortDiagnostics orders diagnostics by file, line, column, and
+// message, the order the engine uses within one file.
+func sortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Message < b.Message
+	})
+}
@@ -0,0 +1,256 @@
+package mdsmith_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jeduden/mdsmith/pkg/mdsmith"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noTodo flags lines containing a configurable marker word.
+type noTodo struct {
+	Word string
+}
+
+func (r *noTodo) ID() string       { return "X001" }
+func (r *noTodo) Name() string     { return "no-todo" }
+func (r *noTodo) Category() string { return "content" }
+
+func (r *noTodo) Check(f *mdsmith.File) []mdsmith.Diagnostic {
+	var diags []mdsmith.Diagnostic
+	word := []byte(r.Word)
+	for i, line := range bytes.Split(f.Source, []byte("\n")) {
+		if col := bytes.Index(line, word); col >= 0 {
+			diags = append(diags, mdsmith.Diagnostic{
+				Line: i + 1, Column: col + 1, Message: r.Word + " marker",
+			})
+		}
+	}
+	return diags
+}
+
+func (r *noTodo) Fix(f *mdsmith.File) []byte {
+	return bytes.ReplaceAll(f.Source, []byte(r.Word), []byte("DONE"))
+}
+
+func (r *noTodo) ApplySettings(settings map[string]any) error {
+	if w, ok := settings["word"].(string); ok {
+		r.Word = w
+	}
+	return nil
+}
+
+func (r *noTodo) DefaultSettings() map[string]any {
+	return map[string]any{"word": "TODO"}
+}
+
+// plainRule is a non-fixable, non-configurable custom rule.
+type plainRule struct{ id, name string }
+
+func (r plainRule) ID() string                                 { return r.id }
+func (r plainRule) Name() string                               { return r.name }
+func (r plainRule) Category() string                           { return "meta" }
+func (r plainRule) Check(_ *mdsmith.File) []mdsmith.Diagnostic { return nil }
+
+func newLinter(t *testing.T, opts mdsmith.Options) *mdsmith.Linter {
+	t.Helper()
+	l, err := mdsmith.New(opts)
+	require.NoError(t, err)
+	return l
+}
+
+func writeConfig(t *testing.T, yml string) *mdsmith.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(path, []byte(yml), 0o644))
+	cfg, err := mdsmith.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, cfg.Path())
+	return cfg
+}
+
+func TestLint_BuiltinRules(t *testing.T) {
+	l := newLinter(t, mdsmith.Options{})
+	res := l.Lint("doc.md", []byte("# Title\n\ntrailing  \n"))
+	require.NoError(t, res.Err())
+	assert.Equal(t, 1, res.FilesChecked)
+	require.Len(t, res.Diagnostics, 1)
+	d := res.Diagnostics[0]
+	assert.Equal(t, "doc.md", d.File)
+	assert.Equal(t, 3, d.Line)
+	assert.Equal(t, "MDS006", d.RuleID)
+	assert.Equal(t, "no-trailing-spaces", d.RuleName)
+	assert.Equal(t, mdsmith.Warning, d.Severity)
+}
+
+func TestLint_CustomRuleDefaultsAndFrontMatterOffset(t *testing.T) {
+	l := newLinter(t, mdsmith.Options{Rules: []mdsmith.Rule{&noTodo{Word: "TODO"}}})
+	res := l.Lint("doc.md", []byte("---\ntitle: x\n---\n# Title\n\nA TODO here.\n"))
+	require.NoError(t, res.Err())
+	require.Len(t, res.Diagnostics, 1)
+	assert.Equal(t, mdsmith.Diagnostic{
+		File: "doc.md", Line: 6, Column: 3, RuleID: "X001", RuleName: "no-todo",
+		Severity: mdsmith.Error, Message: "TODO marker",
+	}, res.Diagnostics[0])
+}
+
+func TestLint_CustomRuleConfiguredByName(t *testing.T) {
+	src := []byte("# Title\n\nA TODO and a FIXME.\n")
+
+	off := writeConfig(t, "rules:\n  no-todo: false\n")
+	l := newLinter(t, mdsmith.Options{Config: off, Rules: []mdsmith.Rule{&noTodo{}}})
+	assert.Empty(t, l.Lint("doc.md", src).Diagnostics)
+
+	set := writeConfig(t, "rules:\n  no-todo:\n    word: FIXME\n")
+	l = newLinter(t, mdsmith.Options{Config: set, Rules: []mdsmith.Rule{&noTodo{}}})
+	res := l.Lint("doc.md", src)
+	require.Len(t, res.Diagnostics, 1)
+	assert.Equal(t, "FIXME marker", res.Diagnostics[0].Message)
+	assert.Equal(t, 14, res.Diagnostics[0].Column)
+}
+
+func TestFix_CustomAndBuiltinRules(t *testing.T) {
+	l := newLinter(t, mdsmith.Options{Rules: []mdsmith.Rule{&noTodo{Word: "TODO"}}})
+	src := []byte("---\ntitle: x\n---\n# Title\n\nA TODO here.  \n")
+	out, err := l.Fix("doc.md", src)
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: x\n---\n# Title\n\nA DONE here.\n", string(out))
+	assert.Empty(t, l.Lint("doc.md", out).Diagnostics)
+}
+
+func TestNew_RejectsInvalidCustomRules(t *testing.T) {
+	for name, rules := range map[string][]mdsmith.Rule{
+		"empty ID":          {plainRule{name: "x"}},
+		"built-in ID":       {plainRule{id: "MDS001", name: "x"}},
+		"built-in name":     {plainRule{id: "X1", name: "line-length"}},
+		"duplicate custom":  {plainRule{id: "X1", name: "a"}, plainRule{id: "X1", name: "b"}},
+		"duplicate by name": {plainRule{id: "X1", name: "a"}, plainRule{id: "X2", name: "a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := mdsmith.New(mdsmith.Options{Rules: rules})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLint_ConfigCustomRules(t *testing.T) {
+	cfg := writeConfig(t, "custom-rules:\n"+
+		"  - id: ACME001\n    name: no-todo-heading\n    message: \"heading says {match}\"\n"+
+		"    select: heading\n    pattern: TODO\n")
+	l := newLinter(t, mdsmith.Options{Config: cfg})
+	res := l.Lint("doc.md", []byte("# TODO list\n"))
+	require.Len(t, res.Diagnostics, 1)
+	assert.Equal(t, "ACME001", res.Diagnostics[0].RuleID)
+	assert.Equal(t, "heading says TODO", res.Diagnostics[0].Message)
+
+	_, err := mdsmith.New(mdsmith.Options{
+		Config: cfg, Rules: []mdsmith.Rule{plainRule{id: "X1", name: "no-todo-heading"}},
+	})
+	assert.Error(t, err, "Go rules may not reuse a config custom rule name")
+}
+
+func TestLintFS_WalksSortsAndHonorsIgnore(t *testing.T) {
+	fsys := fstest.MapFS{
+		"b.md":          {Data: []byte("# B\n\nTODO\n")},
+		"a/a.md":        {Data: []byte("# A\n\nTODO\n")},
+		"vendor/x.md":   {Data: []byte("# X\n\nTODO\n")},
+		"notes.txt":     {Data: []byte("TODO\n")},
+		"c.markdown":    {Data: []byte("# C\n\nfine\n")},
+		"a/partial.txt": {Data: []byte("x")},
+	}
+	cfg := writeConfig(t, "ignore:\n  - vendor/**\n")
+	l := newLinter(t, mdsmith.Options{Config: cfg, Rules: []mdsmith.Rule{&noTodo{Word: "TODO"}}})
+
+	res := l.LintFS(fsys)
+	require.NoError(t, res.Err())
+	assert.Equal(t, 3, res.FilesChecked)
+	var files []string
+	for _, d := range res.Diagnostics {
+		files = append(files, d.File)
+	}
+	assert.Equal(t, []string{"a/a.md", "b.md"}, files)
+
+	res = l.LintFS(fsys, "missing.md")
+	assert.Error(t, res.Err())
+}
+
+func TestLintFS_RulesSeeDocumentDirectory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/index.md":   {Data: []byte("# Index\n\n[Guide](guide.md)\n")},
+		"docs/guide.md":   {Data: []byte("# Guide\n")},
+		"docs/broken.md":  {Data: []byte("# Broken\n\n[Gone](gone.md)\n")},
+		"docs/unused.txt": {Data: []byte("x")},
+	}
+	cfg := writeConfig(t, "rules:\n  cross-file-reference-integrity: true\n")
+	l := newLinter(t, mdsmith.Options{Config: cfg})
+
+	res := l.LintFS(fsys, "docs/index.md", "docs/broken.md")
+	require.NoError(t, res.Err())
+	require.Len(t, res.Diagnostics, 1)
+	assert.Equal(t, "docs/broken.md", res.Diagnostics[0].File)
+	assert.Equal(t, "cross-file-reference-integrity", res.Diagnostics[0].RuleName)
+}
+
+func TestFixFS(t *testing.T) {
+	fsys := fstest.MapFS{"doc.md": {Data: []byte("# Doc\n\nTODO  \n")}}
+	l := newLinter(t, mdsmith.Options{Rules: []mdsmith.Rule{&noTodo{Word: "TODO"}}})
+	out, err := l.FixFS(fsys, "doc.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Doc\n\nDONE\n", string(out))
+
+	_, err = l.FixFS(fsys, "missing.md")
+	assert.Error(t, err)
+}
+
+func TestLint_MaxInputBytes(t *testing.T) {
+	l := newLinter(t, mdsmith.Options{MaxInputBytes: 4})
+	res := l.Lint("doc.md", []byte("# Too long\n"))
+	assert.ErrorContains(t, res.Err(), "file too large")
+	_, err := l.Fix("doc.md", []byte("# Too long\n"))
+	assert.ErrorContains(t, err, "file too large")
+}
+
+func TestDiscoverConfig(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o755))
+	sub := filepath.Join(root, "docs")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+
+	cfg, err := mdsmith.DiscoverConfig(sub)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Path())
+
+	path := filepath.Join(root, ".mdsmith.yml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  no-trailing-spaces: false\n"), 0o644))
+	cfg, err = mdsmith.DiscoverConfig(sub)
+	require.NoError(t, err)
+	assert.Equal(t, path, cfg.Path())
+	l := newLinter(t, mdsmith.Options{Config: cfg})
+	assert.Empty(t, l.Lint("doc.md", []byte("# Title\n\ntrailing  \n")).Diagnostics)
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	_, err := mdsmith.LoadConfig(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+
+	cfg := writeConfig(t, "max-input-size: lots\n")
+	_, err = mdsmith.New(mdsmith.Options{Config: cfg})
+	assert.ErrorContains(t, err, "invalid max-input-size")
+}
+
+func TestLinter_ConcurrentUse(t *testing.T) {
+	l := newLinter(t, mdsmith.Options{Rules: []mdsmith.Rule{&noTodo{Word: "TODO"}}})
+	src := []byte("# Title\n\nA TODO here.  \n")
+	done := make(chan []mdsmith.Diagnostic)
+	for range 8 {
+		go func() { done <- l.Lint("doc.md", src).Diagnostics }()
+	}
+	for range 8 {
+		assert.Len(t, <-done, 2)
+	}
+}
@@ -0,0 +1,129 @@
+package mdsmith
+
+import (
+	"io/fs"
+
+	"github.com/jeduden/mdsmith/internal/lint"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Severity is a diagnostic's severity level.
+type Severity string
+
+// Severity levels, matching the `severity` field of
+// `mdsmith check --format json`.
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Diagnostic is one rule violation. Line and Column are 1-based and
+// count from the first line of the file, front matter included.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	RuleID   string
+	RuleName string
+	Severity Severity
+	Message  string
+}
+
+// Rule is a caller-defined lint rule. ID and Name must be unique
+// across the built-in rules and every other registered rule; Name is
+// the key that enables, disables, and configures the rule under
+// `rules:` in .mdsmith.yml.
+//
+// Check reports diagnostics with lines relative to File.Source; the
+// engine shifts them past stripped front matter. File, RuleID,
+// RuleName, and Severity may be left empty and are filled from the
+// file and the rule (Severity defaults to Error).
+type Rule interface {
+	ID() string
+	Name() string
+	Category() string
+	Check(f *File) []Diagnostic
+}
+
+// FixableRule is a Rule that can also auto-fix its violations. Fix
+// returns the corrected File.Source (front matter excluded); returning
+// the input unchanged means there is nothing to fix.
+type FixableRule interface {
+	Rule
+	Fix(f *File) []byte
+}
+
+// Configurable is implemented by rules with settings under
+// `rules.<name>:` in .mdsmith.yml. The engine configures a fresh
+// instance per file: for a pointer rule it allocates a zero value of
+// the same type, applies DefaultSettings, then the file's effective
+// settings, so the zero value must accept ApplySettings.
+type Configurable interface {
+	ApplySettings(settings map[string]any) error
+	DefaultSettings() map[string]any
+}
+
+// File is the document a Rule checks. It is a read-only view; rules
+// must not modify Source, FrontMatter, or the AST.
+type File struct {
+	// Path is the document path as given to Lint or LintFS.
+	Path string
+	// Source is the Markdown body, without front matter when the
+	// config strips it (the default).
+	Source []byte
+	// FrontMatter is the raw front-matter block including its
+	// `---` delimiters, or nil.
+	FrontMatter []byte
+	// AST is the goldmark document parsed from Source with the
+	// pkg/markdown parser configuration.
+	AST ast.Node
+	// FS is the directory containing the document, for rules that
+	// read neighbouring files. It is nil for Linter.Lint and
+	// Linter.Fix, which see no filesystem.
+	FS fs.FS
+
+	lf *lint.File
+}
+
+// LineOfOffset converts a byte offset in Source to a 1-based line.
+func (f *File) LineOfOffset(offset int) int {
+	return f.lf.LineOfOffset(offset)
+}
+
+// ColumnOfOffset converts a byte offset in Source to a 1-based column
+// on its line.
+func (f *File) ColumnOfOffset(offset int) int {
+	return f.lf.ColumnOfOffset(offset)
+}
+
+// newFile builds the public view of an engine file.
+func newFile(lf *lint.File) *File {
+	return &File{
+		Path:        lf.Path,
+		Source:      lf.Source,
+		FrontMatter: lf.FrontMatter,
+		AST:         lf.AST,
+		FS:          lf.FS,
+		lf:          lf,
+	}
+}
+
+// fromLint converts engine diagnostics to the public type.
+func fromLint(diags []lint.Diagnostic) []Diagnostic {
+	if len(diags) == 0 {
+		return nil
+	}
+	out := make([]Diagnostic, len(diags))
+	for i, d := range diags {
+		out[i] = Diagnostic{
+			File:     d.File,
+			Line:     d.Line,
+			Column:   d.Column,
+			RuleID:   d.RuleID,
+			RuleName: d.RuleName,
+			Severity: Severity(d.Severity),
+			Message:  d.Message,
+		}
+	}
+	return out
+}